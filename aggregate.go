@@ -0,0 +1,103 @@
+package binlookup
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Counts maps the values of a field to the number of BINs having each.
+// The BINs the field is unknown for are counted under "".
+type Counts map[string]int
+
+// Count is a value of a field along with the number of BINs having it.
+type Count struct {
+	Value string
+	N     int
+}
+
+// Sorted returns the counts of c, the most frequent first, ties broken by
+// value.
+func (c Counts) Sorted() []Count {
+	counts := make([]Count, 0, len(c))
+	for v, n := range c {
+		counts = append(counts, Count{v, n})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].N != counts[j].N {
+			return counts[i].N > counts[j].N
+		}
+
+		return counts[i].Value < counts[j].Value
+	})
+
+	return counts
+}
+
+// Breakdown is the aggregate of a set of lookup results, such as the
+// country and scheme breakdown a fraud review starts with.
+type Breakdown struct {
+	// Total is the number of results aggregated, Unresolved of which
+	// hold no BIN.
+	Total      int
+	Unresolved int
+
+	// Countries are keyed by the alpha-2 codes, Banks by the names, and
+	// Prepaid by "true" and "false".
+	Countries Counts
+	Schemes   Counts
+	Types     Counts
+	Prepaid   Counts
+	Banks     Counts
+}
+
+// Aggregate returns the `Breakdown` of bins, the nil ones being those
+// unresolved.
+func Aggregate(bins []*BIN) Breakdown {
+	a := Breakdown{
+		Countries: make(Counts),
+		Schemes:   make(Counts),
+		Types:     make(Counts),
+		Prepaid:   make(Counts),
+		Banks:     make(Counts),
+	}
+
+	for _, b := range bins {
+		a.Total++
+		if b == nil {
+			a.Unresolved++
+			continue
+		}
+
+		a.Countries[b.Country.Short]++
+		a.Schemes[string(b.Scheme)]++
+		a.Types[string(b.Type)]++
+
+		var prepaid string
+		if p, ok := b.IsPrepaid(); ok {
+			prepaid = strconv.FormatBool(p)
+		}
+		a.Prepaid[prepaid]++
+
+		var bank string
+		if b.Bank != nil {
+			bank = b.Bank.Name
+		}
+		a.Banks[bank]++
+	}
+
+	return a
+}
+
+// AggregateResults returns the `Breakdown` of results, such as those of
+// `Client.SearchBatch`, the failed ones being those unresolved.
+func AggregateResults(results []Result) Breakdown {
+	bins := make([]*BIN, len(results))
+	for i, r := range results {
+		if r.Err == nil {
+			bins[i] = r.BIN
+		}
+	}
+
+	return Aggregate(bins)
+}
@@ -0,0 +1,27 @@
+package binlookup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	yes, no := true, false
+	danish := &BIN{Scheme: SchemeVisa, Type: "debit", Prepaid: &no, Country: Country{Short: "DK"}, Bank: &Bank{Name: "Jyske Bank"}}
+	british := &BIN{Scheme: SchemeMastercard, Type: "credit", Prepaid: &yes, Country: Country{Short: "GB"}}
+
+	a := AggregateResults([]Result{{BIN: danish}, {BIN: british}, {BIN: danish}, {Err: ErrNotFound}, {BIN: &BIN{}}})
+
+	if a.Total != 5 || a.Unresolved != 1 {
+		t.Fatalf("aggregated %d results, %d unresolved, want 5 and 1", a.Total, a.Unresolved)
+	}
+
+	want := []Count{{"DK", 2}, {"", 1}, {"GB", 1}}
+	if got := a.Countries.Sorted(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("countries %v, want %v", got, want)
+	}
+
+	if a.Schemes["visa"] != 2 || a.Types["credit"] != 1 || a.Prepaid["false"] != 2 || a.Prepaid[""] != 1 || a.Banks["Jyske Bank"] != 2 || a.Banks[""] != 2 {
+		t.Fatalf("unexpected breakdown %+v", a)
+	}
+}
@@ -0,0 +1,79 @@
+// Package assets maps card schemes to canonical asset identifiers and
+// embedded SVG badges, so front ends rendering a card logo out of a BIN
+// detection share a single table.
+//
+// The embedded badges are plain, text based placeholders rather than the
+// networks' trademarked logos; front ends holding licensed artwork key it
+// by `Asset.ID`.
+package assets
+
+import (
+	"embed"
+	"encoding/base64"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+//go:embed svg/*.svg
+var svgs embed.FS
+
+// Asset identifies the artwork of a scheme.
+type Asset struct {
+	// ID is the canonical identifier of the asset, such as
+	// `card-scheme-visa`.
+	ID string
+
+	// Scheme is the scheme the asset stands for, empty for the generic
+	// card.
+	Scheme binlookup.Scheme
+
+	file string
+}
+
+// Generic is the asset of a card whose scheme is unknown.
+var Generic = Asset{ID: "card-scheme-generic", file: "svg/generic.svg"}
+
+var assets = map[binlookup.Scheme]Asset{}
+
+func init() {
+	for _, s := range []binlookup.Scheme{
+		binlookup.SchemeVisa,
+		binlookup.SchemeMastercard,
+		binlookup.SchemeAmex,
+		binlookup.SchemeDiscover,
+		binlookup.SchemeDiners,
+		binlookup.SchemeJCB,
+		binlookup.SchemeUnionPay,
+		binlookup.SchemeMaestro,
+		binlookup.SchemeMir,
+		binlookup.SchemeRuPay,
+		binlookup.SchemeElo,
+		binlookup.SchemeTroy,
+		binlookup.SchemeDankort,
+	} {
+		assets[s] = Asset{ID: "card-scheme-" + string(s), Scheme: s, file: "svg/" + string(s) + ".svg"}
+	}
+}
+
+// For returns the asset of scheme s, normalized with
+// `binlookup.ParseScheme`, or `Generic` and false when there is none.
+func For(s binlookup.Scheme) (Asset, bool) {
+	a, ok := assets[binlookup.ParseScheme(string(s))]
+	if !ok {
+		return Generic, false
+	}
+
+	return a, true
+}
+
+// SVG returns the embedded SVG badge of a.
+func (a Asset) SVG() []byte {
+	b, _ := svgs.ReadFile(a.file)
+	return b
+}
+
+// DataURI returns the embedded SVG badge of a as a data URI, ready to be
+// used as the src of an img element.
+func (a Asset) DataURI() string {
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(a.SVG())
+}
@@ -0,0 +1,49 @@
+package assets
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestFor(t *testing.T) {
+	a, ok := For(binlookup.SchemeVisa)
+	if !ok || a.ID != "card-scheme-visa" || a.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("unexpected asset %+v, %v", a, ok)
+	}
+
+	if !bytes.HasPrefix(a.SVG(), []byte("<svg")) {
+		t.Fatalf("visa has no embedded SVG: %q", a.SVG())
+	}
+
+	if !strings.HasPrefix(a.DataURI(), "data:image/svg+xml;base64,") {
+		t.Fatalf("unexpected data URI %q", a.DataURI())
+	}
+}
+
+func TestForNormalizesScheme(t *testing.T) {
+	if a, ok := For("MasterCard"); !ok || a.Scheme != binlookup.SchemeMastercard {
+		t.Fatalf("unexpected asset %+v, %v", a, ok)
+	}
+}
+
+func TestForUnknownScheme(t *testing.T) {
+	a, ok := For("bankcard")
+	if ok || a.ID != Generic.ID {
+		t.Fatalf("unknown scheme got %+v, %v", a, ok)
+	}
+
+	if len(a.SVG()) == 0 {
+		t.Fatal("generic asset has no embedded SVG")
+	}
+}
+
+func TestEverySchemeHasSVG(t *testing.T) {
+	for s, a := range assets {
+		if len(a.SVG()) == 0 {
+			t.Fatalf("%v has no embedded SVG", s)
+		}
+	}
+}
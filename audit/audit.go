@@ -0,0 +1,193 @@
+// Package audit records the BIN lookups made, one JSON object per line,
+// for the trail compliance asks of payment systems. `RotatingFile` keeps
+// the trail of long-running servers from filling their disks.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Record is the audit record of a lookup.
+type Record struct {
+	Time time.Time `json:"time"`
+
+	// RequestID is the ID of the request the lookup was made for, as the
+	// caller attached it to the context with `WithRequestID`.
+	RequestID string `json:"request_id,omitempty"`
+
+	// BIN is the BIN looked up, truncated to its first 8 digits for the
+	// rest of a card number never to be recorded, or its token when the
+	// `Lookuper` recording it has a Tokenizer.
+	BIN string `json:"bin"`
+
+	// Provider is the name of the provider which answered, or that of
+	// the `binlookup.Lookuper` looked up through when none did.
+	Provider string `json:"provider,omitempty"`
+
+	// Status is the outcome of the lookup: found, stale, not_found or
+	// failed, and StatusCode the status upstream answered with, if any.
+	Status     string `json:"status"`
+	StatusCode int    `json:"status_code,omitempty"`
+
+	Found bool   `json:"found"`
+	Error string `json:"error,omitempty"`
+
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Sink is where audit records go.
+type Sink interface {
+	Audit(r Record) error
+}
+
+// SinkFunc is a `Sink` calling itself, such as to insert the records
+// into a table.
+type SinkFunc func(r Record) error
+
+// Audit calls f.
+func (f SinkFunc) Audit(r Record) error {
+	return f(r)
+}
+
+// Writer is a `Sink` writing records to an io.Writer as JSON Lines. It's
+// safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter returns a `Writer` writing to w, such as a `RotatingFile`.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Audit writes r as a line.
+func (w *Writer) Audit(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, the ID of the request
+// the lookups made with it are for, which the `Lookuper` records.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID of the request ctx carries, see
+// `WithRequestID`.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Lookuper is a `binlookup.Lookuper` recording the lookups it makes
+// through Lookuper to Sink.
+//
+// Failing to record doesn't fail the lookup; the error goes to OnError
+// instead, when set.
+type Lookuper struct {
+	Lookuper binlookup.Lookuper
+	Sink     Sink
+
+	// Tokenizer replaces the BINs recorded with their tokens when set,
+	// in the errors recorded too. Lookups whose BIN it fails on are
+	// recorded without any.
+	Tokenizer binlookup.Tokenizer
+
+	// OnError is given the errors of Sink and Tokenizer.
+	OnError func(err error)
+}
+
+// Search looks bin up through l.Lookuper, recording the lookup.
+func (l *Lookuper) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	start := time.Now()
+	b, err := l.Lookuper.Search(ctx, bin)
+
+	r := Record{
+		Time:      start,
+		RequestID: RequestID(ctx),
+		BIN:       truncate(bin),
+		Status:    status(b, err),
+		Found:     err == nil && b != nil,
+		Duration:  time.Since(start),
+	}
+	if b != nil {
+		r.Provider = b.Meta.Provider
+	}
+	if n, ok := l.Lookuper.(interface{ Name() string }); ok && r.Provider == "" {
+		r.Provider = n.Name()
+	}
+
+	var he *binlookup.HTTPError
+	if errors.As(err, &he) {
+		r.StatusCode = he.StatusCode
+	}
+
+	if err != nil {
+		// Errors such as those of net/http quote the URL, BIN included,
+		// and the card number may have been looked up whole.
+		r.Error = binlookup.Redact(strings.ReplaceAll(err.Error(), bin, r.BIN))
+	}
+
+	if l.Tokenizer != nil {
+		tok, terr := l.Tokenizer(bin)
+		if terr != nil {
+			l.fail(terr)
+		}
+		r.Error = strings.ReplaceAll(r.Error, r.BIN, tok)
+		r.BIN = tok
+	}
+
+	if serr := l.Sink.Audit(r); serr != nil {
+		l.fail(serr)
+	}
+
+	return b, err
+}
+
+// truncate returns the first 8 digits of bin at most.
+func truncate(bin string) string {
+	if len(bin) > 8 {
+		return bin[:8]
+	}
+
+	return bin
+}
+
+// status returns the outcome of a lookup answering b and err.
+func status(b *binlookup.BIN, err error) string {
+	switch {
+	case errors.Is(err, binlookup.ErrNotFound):
+		return "not_found"
+	case err != nil || b == nil:
+		return "failed"
+	case b.Meta.Stale:
+		return "stale"
+	}
+
+	return "found"
+}
+
+func (l *Lookuper) fail(err error) {
+	if l.OnError != nil {
+		l.OnError(err)
+	}
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestLookuper(t *testing.T) {
+	fake := binlookup.LookuperFunc(func(_ context.Context, bin string) (*binlookup.BIN, error) {
+		if bin == "45717360" {
+			return &binlookup.BIN{Meta: binlookup.Meta{Provider: "lookup.binlist.net"}}, nil
+		}
+
+		return nil, errors.New(`Get "https://lookup.binlist.net/` + bin + `": timeout`)
+	})
+
+	var buf bytes.Buffer
+	l := &Lookuper{Lookuper: fake, Sink: NewWriter(&buf)}
+	l.Search(context.TODO(), "45717360")
+
+	l.Tokenizer = func(bin string) (string, error) { return "tok_" + bin[6:], nil }
+	l.Search(context.TODO(), "52882300")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d records written for 2 lookups", len(lines))
+	}
+
+	var found, failed Record
+	json.Unmarshal([]byte(lines[0]), &found)
+	json.Unmarshal([]byte(lines[1]), &failed)
+
+	if found.BIN != "45717360" || !found.Found || found.Provider != "lookup.binlist.net" || found.Time.IsZero() {
+		t.Fatalf("unexpected record %+v", found)
+	}
+
+	if failed.BIN != "tok_00" || failed.Found || strings.Contains(failed.Error, "52882300") || !strings.Contains(failed.Error, "tok_00") {
+		t.Fatalf("unexpected record %+v", failed)
+	}
+}
+
+type namedLookuper struct{ binlookup.LookuperFunc }
+
+func (namedLookuper) Name() string { return "binlist" }
+
+func TestLookuperRecords(t *testing.T) {
+	fake := binlookup.LookuperFunc(func(_ context.Context, bin string) (*binlookup.BIN, error) {
+		switch bin {
+		case "4571736012345678":
+			return &binlookup.BIN{Meta: binlookup.Meta{Stale: true}}, nil
+		case "52882300":
+			return nil, fmt.Errorf("Failed Due to Status Code Error: %w", &binlookup.HTTPError{StatusCode: http.StatusNotFound})
+		}
+
+		return nil, errors.New(`Get "https://lookup.binlist.net/` + bin + `": timeout`)
+	})
+
+	var records []Record
+	l := &Lookuper{Lookuper: namedLookuper{fake}, Sink: SinkFunc(func(r Record) error {
+		records = append(records, r)
+		return nil
+	})}
+
+	ctx := WithRequestID(context.TODO(), "req-1")
+	l.Search(ctx, "4571736012345678")
+	l.Search(ctx, "52882300")
+	l.Search(context.TODO(), "4242424242424242")
+
+	if r := records[0]; r.RequestID != "req-1" || r.BIN != "45717360" || r.Status != "stale" || r.Provider != "binlist" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.Status != "not_found" || r.StatusCode != http.StatusNotFound || r.Found {
+		t.Fatalf("unexpected record of a BIN not found %+v", r)
+	}
+	if r := records[2]; r.Status != "failed" || r.RequestID != "" || r.BIN != "42424242" || strings.Contains(r.Error, "4242424242424242") {
+		t.Fatalf("unexpected record of a failure %+v", r)
+	}
+}
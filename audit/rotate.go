@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned writing to a `RotatingFile` that is closed.
+var ErrClosed = errors.New("Audit File Closed")
+
+// segmentLayout is the timestamp suffixed to the rotated segments, which
+// sorts them chronologically.
+const segmentLayout = "20060102T150405.000000000"
+
+// RotatingFile is an io.WriteCloser appending to the file at Path and
+// rotating it by size and age. The rotated segments are kept as Path
+// followed by the time of their rotation, as in
+// audit.log.20260102T030405.000000000.
+//
+// The zero value with a Path is ready to use, opening the file with the
+// first write. It's safe for concurrent use.
+//
+// Rotation happens before a write that would make the file larger than
+// MaxSize, or be written more than Interval after the file was opened.
+type RotatingFile struct {
+	Path string
+
+	// MaxSize is the size in bytes the file is rotated at, none when
+	// zero.
+	MaxSize int64
+
+	// Interval is how long a file is written to before being rotated,
+	// forever when zero.
+	Interval time.Duration
+
+	// MaxBackups is the number of rotated segments kept, all of them
+	// when zero.
+	MaxBackups int
+
+	// MaxAge is how long rotated segments are kept, forever when zero.
+	MaxAge time.Duration
+
+	// OnRotate is given the path of every segment rotated, to ship it
+	// elsewhere such as to object storage. It's called before retention
+	// applies, on the goroutine writing: shipping slow enough to hold up
+	// writes is better done on another goroutine, with a retention
+	// leaving it time.
+	OnRotate func(path string)
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+	closed bool
+	now    func() time.Time
+}
+
+// Write appends p to the file, rotating it first when due.
+func (r *RotatingFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, ErrClosed
+	}
+
+	if r.f == nil {
+		if err = r.open(); err != nil {
+			return
+		}
+	}
+
+	if r.due(int64(len(p))) {
+		if err = r.rotate(); err != nil {
+			return
+		}
+	}
+
+	n, err = r.f.Write(p)
+	r.size += int64(n)
+
+	return
+}
+
+// Rotate rotates the file at once, such as on SIGHUP.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	return r.rotate()
+}
+
+// Close closes the file, without rotating it.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.f == nil {
+		return nil
+	}
+
+	err := r.f.Close()
+	r.f = nil
+
+	return err
+}
+
+func (r *RotatingFile) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+
+	return time.Now()
+}
+
+// open opens the file at Path, carrying on with its size and age when it
+// exists.
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	r.f, r.size, r.opened = f, fi.Size(), r.clock()
+	if fi.Size() > 0 && fi.ModTime().Before(r.opened) {
+		r.opened = fi.ModTime()
+	}
+
+	return nil
+}
+
+// due reports whether the file must be rotated before writing n bytes.
+func (r *RotatingFile) due(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+
+	if r.MaxSize > 0 && r.size+n > r.MaxSize {
+		return true
+	}
+
+	return r.Interval > 0 && r.clock().Sub(r.opened) >= r.Interval
+}
+
+// rotate moves the file aside as a segment, opens a new one and applies
+// retention.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	segment := r.Path + "." + r.clock().UTC().Format(segmentLayout)
+	if err := os.Rename(r.Path, segment); err != nil {
+		return err
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if r.OnRotate != nil {
+		r.OnRotate(segment)
+	}
+
+	return r.retain()
+}
+
+// retain removes the segments beyond MaxBackups or older than MaxAge.
+func (r *RotatingFile) retain() error {
+	if r.MaxBackups <= 0 && r.MaxAge <= 0 {
+		return nil
+	}
+
+	segments, err := filepath.Glob(r.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	var times []time.Time
+	var kept []string
+	for _, s := range segments {
+		t, err := time.Parse(segmentLayout, strings.TrimPrefix(s, r.Path+"."))
+		if err != nil {
+			continue
+		}
+
+		kept = append(kept, s)
+		times = append(times, t)
+	}
+	sort.Sort(byTime{kept, times})
+
+	now := r.clock()
+	for i, s := range kept {
+		old := r.MaxAge > 0 && now.Sub(times[i]) >= r.MaxAge
+		extra := r.MaxBackups > 0 && len(kept)-i > r.MaxBackups
+
+		if old || extra {
+			if err := os.Remove(s); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// byTime sorts segments by their rotation time, the oldest first.
+type byTime struct {
+	segments []string
+	times    []time.Time
+}
+
+func (b byTime) Len() int           { return len(b.segments) }
+func (b byTime) Less(i, j int) bool { return b.times[i].Before(b.times[j]) }
+
+func (b byTime) Swap(i, j int) {
+	b.segments[i], b.segments[j] = b.segments[j], b.segments[i]
+	b.times[i], b.times[j] = b.times[j], b.times[i]
+}
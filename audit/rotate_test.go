@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func segments(t *testing.T, path string) []string {
+	t.Helper()
+
+	s, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var rotated []string
+	r := &RotatingFile{Path: path, MaxSize: 10, MaxBackups: 2, OnRotate: func(s string) { rotated = append(rotated, s) }}
+	r.now = func() time.Time { now = now.Add(time.Second); return now }
+	defer r.Close()
+
+	for range 5 {
+		if _, err := r.Write([]byte("0123456\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(rotated) != 4 {
+		t.Fatalf("%d rotations for 5 writes each filling the file, want 4", len(rotated))
+	}
+
+	if s := segments(t, path); len(s) != 2 || s[1] != rotated[3] {
+		t.Fatalf("segments kept are %v, want the last 2 rotated of %v", s, rotated)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "0123456\n" {
+		t.Fatalf("file holds %q", data)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &RotatingFile{Path: path, Interval: time.Hour, MaxAge: 3 * time.Hour}
+	r.now = func() time.Time { return now }
+	defer r.Close()
+
+	for range 6 {
+		r.Write([]byte("record\n"))
+		now = now.Add(time.Hour)
+	}
+
+	s := segments(t, path)
+	if len(s) != 3 {
+		t.Fatalf("segments kept are %v, want those of the last 3 hours", s)
+	}
+
+	if !strings.HasSuffix(s[2], ".20260102T080405.000000000") {
+		t.Fatalf("last segment is %v", s[2])
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	r := &RotatingFile{Path: filepath.Join(t.TempDir(), "audit.log")}
+	r.Close()
+
+	if _, err := r.Write([]byte("record\n")); err != ErrClosed {
+		t.Fatalf("write after Close returned %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,252 @@
+// Package backfill resolves the BINs of large historical transaction sets
+// under an upstream quota: it extracts the unique BINs, estimates what
+// resolving them costs and executes the plan resumably.
+package backfill
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Source yields the card numbers, or BINs, of historical transactions.
+// Next returns io.EOF once exhausted.
+type Source interface {
+	Next() (string, error)
+}
+
+// Lines returns a `Source` yielding the lines of r.
+func Lines(r io.Reader) Source {
+	return &lines{s: bufio.NewScanner(r)}
+}
+
+type lines struct {
+	s *bufio.Scanner
+}
+
+func (l *lines) Next() (string, error) {
+	if l.s.Scan() {
+		return l.s.Text(), nil
+	}
+
+	if err := l.s.Err(); err != nil {
+		return "", err
+	}
+
+	return "", io.EOF
+}
+
+// Searcher resolves a single BIN, as `binlookup.Client` does.
+type Searcher interface {
+	Search(ctx context.Context, bin string) (*binlookup.BIN, error)
+}
+
+// Limits is the quota a plan is executed under: Requests per Per.
+type Limits struct {
+	Requests int
+	Per      time.Duration
+}
+
+// interval is the pause between two requests spreading them evenly.
+func (l Limits) interval() time.Duration {
+	if l.Requests <= 0 {
+		return 0
+	}
+
+	return l.Per / time.Duration(l.Requests)
+}
+
+// Plan is the set of unique BINs extracted out of a `Source`.
+type Plan struct {
+	// BINs are the unique BINs, in the order they were first seen.
+	BINs []string
+
+	// Transactions is the number of values read out of the `Source`.
+	Transactions int
+
+	// Skipped is the number of values that didn't hold enough digits.
+	Skipped int
+}
+
+// Extract reads src to the end, keeping the first digits digits of each
+// card number once. Spaces and dashes in the numbers are ignored.
+func Extract(src Source, digits int) (*Plan, error) {
+	p := &Plan{}
+	seen := make(map[string]bool)
+
+	for {
+		v, err := src.Next()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.Transactions++
+
+		bin, ok := prefix(v, digits)
+		if !ok {
+			p.Skipped++
+			continue
+		}
+
+		if !seen[bin] {
+			seen[bin] = true
+			p.BINs = append(p.BINs, bin)
+		}
+	}
+}
+
+// prefix returns the first n digits of v, ignoring spaces and dashes.
+func prefix(v string, n int) (string, bool) {
+	var b strings.Builder
+	for _, r := range v {
+		switch {
+		case r == ' ' || r == '-':
+			continue
+		case r < '0' || r > '9':
+			return "", false
+		}
+
+		b.WriteRune(r)
+		if b.Len() == n {
+			return b.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// Estimate is what executing a `Plan` costs.
+type Estimate struct {
+	// Lookups is the number of BINs left to resolve.
+	Lookups int
+
+	// Windows is the number of quota windows the lookups span.
+	Windows int
+
+	// Duration is how long the lookups take evenly spread under the
+	// quota.
+	Duration time.Duration
+}
+
+// Estimate returns the cost of resolving the BINs of p not yet marked in
+// cp, which may be nil, under l.
+func (p *Plan) Estimate(l Limits, cp *Checkpoint) (e Estimate) {
+	for _, bin := range p.BINs {
+		if !cp.Done(bin) {
+			e.Lookups++
+		}
+	}
+
+	if l.Requests > 0 {
+		e.Windows = int(math.Ceil(float64(e.Lookups) / float64(l.Requests)))
+	}
+	e.Duration = time.Duration(e.Lookups) * l.interval()
+
+	return
+}
+
+// Executor carries out a `Plan`.
+type Executor struct {
+	Searcher Searcher
+	Limits   Limits
+
+	// Checkpoint, when set, records the BINs resolved so that executing
+	// the plan again resumes where it stopped.
+	Checkpoint *Checkpoint
+
+	// OnResult receives the outcome of each BIN. A BIN upstream doesn't
+	// know is reported with binlookup.ErrNotFound and counts as resolved.
+	OnResult func(bin string, b *binlookup.BIN, err error)
+}
+
+// Execute resolves the BINs of p left to resolve, spreading the lookups
+// evenly under the limits of e. When upstream rate limits a lookup, it's
+// retried after the Retry-After of upstream, or otherwise after a whole
+// quota window, and a second at least for limits of zero.
+//
+// Execution stops at the first error other than binlookup.ErrNotFound
+// and returns it; executing p again with the same checkpoint skips the
+// BINs resolved so far.
+func (p *Plan) Execute(ctx context.Context, e Executor) error {
+	interval := e.Limits.interval()
+
+	var last time.Time
+	for _, bin := range p.BINs {
+		if e.Checkpoint.Done(bin) {
+			continue
+		}
+
+		for {
+			if err := sleep(ctx, time.Until(last.Add(interval))); err != nil {
+				return err
+			}
+			last = time.Now()
+
+			b, err := e.Searcher.Search(ctx, bin)
+			if errors.Is(err, binlookup.ErrRateLimited) {
+				if err := sleep(ctx, rateLimitWait(err, e.Limits.Per)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err != nil && !errors.Is(err, binlookup.ErrNotFound) {
+				return err
+			}
+
+			if e.OnResult != nil {
+				e.OnResult(bin, b, err)
+			}
+
+			if err := e.Checkpoint.Mark(bin); err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// minRateLimitWait is the least a rate limited lookup waits for before
+// it's retried, for a plan executed without limits not to spin on 429s.
+const minRateLimitWait = time.Second
+
+// rateLimitWait returns how long to wait for before retrying the lookup
+// rate limited with err: the Retry-After of upstream, when told, or per.
+func rateLimitWait(err error, per time.Duration) time.Duration {
+	var he *binlookup.HTTPError
+	if errors.As(err, &he) {
+		if s, perr := strconv.Atoi(he.Header.Get("Retry-After")); perr == nil && s > 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	return max(per, minRateLimitWait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,174 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+const transactions = `4571 7360 1234 5678
+5288-2301-0000-0000
+4571736099999999
+not a card
+457
+5288230100000001
+`
+
+// searcher answers from bins, failing the BINs in fail once with the
+// error mapped to them.
+type searcher struct {
+	bins  map[string]*binlookup.BIN
+	fail  map[string]error
+	calls []string
+}
+
+func (s *searcher) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	s.calls = append(s.calls, bin)
+
+	if err, ok := s.fail[bin]; ok {
+		delete(s.fail, bin)
+		return nil, err
+	}
+
+	if b, ok := s.bins[bin]; ok {
+		return b, nil
+	}
+
+	return nil, binlookup.ErrNotFound
+}
+
+func TestExtract(t *testing.T) {
+	p, err := Extract(Lines(strings.NewReader(transactions)), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(p.BINs, ",") != "45717360,52882301" {
+		t.Fatalf("extracted %v", p.BINs)
+	}
+
+	if p.Transactions != 6 || p.Skipped != 2 {
+		t.Fatalf("read %d transactions skipping %d, want 6 and 2", p.Transactions, p.Skipped)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	p := &Plan{BINs: []string{"457173", "528823", "411111", "520000", "601100"}}
+
+	e := p.Estimate(Limits{Requests: 2, Per: time.Minute}, nil)
+	if e.Lookups != 5 || e.Windows != 3 || e.Duration != 150*time.Second {
+		t.Fatalf("unexpected estimate %+v", e)
+	}
+}
+
+func TestExecuteResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	p := &Plan{BINs: []string{"457173", "528823", "411111"}}
+	s := &searcher{
+		bins: map[string]*binlookup.BIN{"457173": {}, "411111": {}},
+		fail: map[string]error{"411111": errors.New("connection reset")},
+	}
+
+	cp, err := OpenCheckpoint(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []string
+	e := Executor{Searcher: s, Checkpoint: cp, OnResult: func(bin string, b *binlookup.BIN, err error) {
+		results = append(results, bin)
+	}}
+
+	if err := p.Execute(context.TODO(), e); err == nil {
+		t.Fatal("execution didn't stop on a failed lookup")
+	}
+	cp.Close()
+
+	if strings.Join(results, ",") != "457173,528823" {
+		t.Fatalf("results before the failure were %v", results)
+	}
+
+	if e.Checkpoint, err = OpenCheckpoint(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Checkpoint.Close()
+
+	if est := p.Estimate(Limits{}, e.Checkpoint); est.Lookups != 1 {
+		t.Fatalf("%d lookups left after resuming, want 1", est.Lookups)
+	}
+
+	s.calls = nil
+	if err := p.Execute(context.TODO(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(s.calls, ",") != "411111" {
+		t.Fatalf("resumed execution looked up %v", s.calls)
+	}
+}
+
+func TestExecuteRetriesRateLimited(t *testing.T) {
+	p := &Plan{BINs: []string{"457173"}}
+	s := &searcher{
+		bins: map[string]*binlookup.BIN{"457173": {}},
+		fail: map[string]error{"457173": binlookup.ErrRateLimited},
+	}
+
+	e := Executor{Searcher: s, Limits: Limits{Requests: 1, Per: 10 * time.Millisecond}}
+	if err := p.Execute(context.TODO(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.calls) != 2 {
+		t.Fatalf("rate limited BIN was looked up %d times, want 2", len(s.calls))
+	}
+}
+
+// limited fails every lookup with err, counting them.
+type limited struct {
+	err   error
+	calls int
+}
+
+func (l *limited) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	l.calls++
+	return nil, l.err
+}
+
+func TestExecuteRateLimitedWithoutLimits(t *testing.T) {
+	p := &Plan{BINs: []string{"457173"}}
+	l := &limited{err: &binlookup.HTTPError{StatusCode: http.StatusTooManyRequests}}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := p.Execute(ctx, Executor{Searcher: l}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("execution returned %v", err)
+	}
+	if l.calls != 1 {
+		t.Fatalf("rate limited BIN was looked up %d times without limits, want it to wait", l.calls)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	he := &binlookup.HTTPError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"30"}}}
+	for _, tc := range []struct {
+		err  error
+		per  time.Duration
+		want time.Duration
+	}{
+		{he, time.Hour, 30 * time.Second},
+		{binlookup.ErrRateLimited, time.Hour, time.Hour},
+		{binlookup.ErrRateLimited, 0, minRateLimitWait},
+	} {
+		if d := rateLimitWait(tc.err, tc.per); d != tc.want {
+			t.Errorf("waits %v after %v under a window of %v, want %v", d, tc.err, tc.per, tc.want)
+		}
+	}
+}
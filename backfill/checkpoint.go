@@ -0,0 +1,106 @@
+package backfill
+
+import (
+	"bufio"
+	"os"
+	"sync"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Checkpoint is the record of the BINs of a `Plan` already resolved,
+// persisted to a file holding one per line. A nil *Checkpoint records
+// nothing.
+type Checkpoint struct {
+	mu       sync.Mutex
+	f        *os.File
+	done     map[string]bool
+	tokenize binlookup.Tokenizer
+}
+
+// OpenCheckpoint opens the checkpoint file at path, creating it when it
+// doesn't exist. When tok isn't nil, the file holds the tokens of the
+// BINs instead of the BINs themselves.
+func OpenCheckpoint(path string, tok binlookup.Tokenizer) (*Checkpoint, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{f: f, done: make(map[string]bool), tokenize: tok}
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if s.Text() != "" {
+			cp.done[s.Text()] = true
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// Done reports whether bin is marked as resolved.
+func (cp *Checkpoint) Done(bin string) bool {
+	if cp == nil {
+		return false
+	}
+
+	key, err := cp.key(bin)
+	if err != nil {
+		return false
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return cp.done[key]
+}
+
+// Mark records bin as resolved.
+func (cp *Checkpoint) Mark(bin string) error {
+	if cp == nil {
+		return nil
+	}
+
+	key, err := cp.key(bin)
+	if err != nil {
+		return err
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.done[key] {
+		return nil
+	}
+
+	if _, err := cp.f.WriteString(key + "\n"); err != nil {
+		return err
+	}
+	cp.done[key] = true
+
+	return nil
+}
+
+// key returns what bin is persisted as.
+func (cp *Checkpoint) key(bin string) (string, error) {
+	if cp.tokenize == nil {
+		return bin, nil
+	}
+
+	return cp.tokenize(bin)
+}
+
+// Close closes the checkpoint file.
+func (cp *Checkpoint) Close() error {
+	if cp == nil {
+		return nil
+	}
+
+	return cp.f.Close()
+}
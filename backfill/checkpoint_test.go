@@ -0,0 +1,76 @@
+package backfill
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	cp, err := OpenCheckpoint(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bin := range []string{"457173", "528823", "457173"} {
+		if err := cp.Mark(bin); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cp.Close()
+
+	raw, _ := os.ReadFile(path)
+	if string(raw) != "457173\n528823\n" {
+		t.Fatalf("checkpoint file holds %q", raw)
+	}
+
+	if cp, err = OpenCheckpoint(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	if !cp.Done("457173") || !cp.Done("528823") || cp.Done("411111") {
+		t.Fatal("reopened checkpoint lost track of the marked BINs")
+	}
+}
+
+func TestCheckpointTokenized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	tok := func(bin string) (string, error) {
+		return fmt.Sprintf("tok_%x", sha256.Sum256([]byte(bin)))[:12], nil
+	}
+
+	cp, err := OpenCheckpoint(path, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Mark("457173")
+	cp.Close()
+
+	raw, _ := os.ReadFile(path)
+	if strings.Contains(string(raw), "457173") || !strings.HasPrefix(string(raw), "tok_") {
+		t.Fatalf("checkpoint file holds %q", raw)
+	}
+
+	if cp, err = OpenCheckpoint(path, tok); err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	if !cp.Done("457173") {
+		t.Fatal("reopened checkpoint lost track of the tokenized BIN")
+	}
+}
+
+func TestNilCheckpoint(t *testing.T) {
+	var cp *Checkpoint
+
+	if cp.Done("457173") || cp.Mark("457173") != nil || cp.Close() != nil {
+		t.Fatal("nil checkpoint isn't a no-op")
+	}
+}
@@ -0,0 +1,74 @@
+package binlookup
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff paces the attempts of a lookup, see `WithBackoff`. It's shared
+// between the lookups of a `Client`, and must be safe for concurrent use.
+type Backoff interface {
+	// Delay returns how long to wait after the attempt i of a lookup
+	// failed, i starting at 0, given the delay returned after the
+	// previous attempt, 0 for the first one.
+	Delay(i int, last time.Duration) time.Duration
+}
+
+// ConstantBackoff waits as long after every attempt.
+type ConstantBackoff time.Duration
+
+// Delay returns b.
+func (b ConstantBackoff) Delay(int, time.Duration) time.Duration {
+	return time.Duration(b)
+}
+
+// ExponentialBackoff waits Base after the first attempt, doubling the delay
+// after each of the next ones up to Max. It's the backoff of a `Client`
+// unless changed with `WithBackoff`, with a Base of 100ms and a Max of 5s.
+type ExponentialBackoff struct {
+	Base, Max time.Duration
+}
+
+// Delay returns Base << i, capped by Max.
+func (b ExponentialBackoff) Delay(i int, _ time.Duration) time.Duration {
+	d := b.Base << uint(i)
+	if d <= 0 || (b.Max > 0 && d > b.Max) {
+		d = b.Max
+	}
+
+	return d
+}
+
+// DecorrelatedJitter waits a random delay between Base and thrice the
+// previous one, capped by Max, which spreads the retries of the clients
+// failing together instead of having them retry in lockstep.
+type DecorrelatedJitter struct {
+	Base, Max time.Duration
+}
+
+// Delay returns a random delay in [Base, 3*last), capped by Max.
+func (b DecorrelatedJitter) Delay(_ int, last time.Duration) time.Duration {
+	hi := 3 * max(last, b.Base)
+
+	d := b.Base
+	if hi > b.Base {
+		d += rand.N(hi - b.Base)
+	}
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+
+	return d
+}
+
+// defaultBackoff is the backoff of a `Client` unless changed.
+var defaultBackoff Backoff = ExponentialBackoff{Base: retryBaseDelay, Max: retryMaxDelay}
+
+// WithBackoff makes the `Client` space the attempts of a lookup with b,
+// an `ExponentialBackoff` unless changed. The Retry-After sent by upstream
+// along a 429 takes precedence over b.
+func WithBackoff(b Backoff) Option {
+	return func(c *Client) {
+		c.backoff = b
+	}
+}
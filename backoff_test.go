@@ -0,0 +1,62 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Base: time.Second, Max: 5 * time.Second}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if d := b.Delay(i, 0); d != want {
+			t.Fatalf("delay after attempt %d is %v, want %v", i, d, want)
+		}
+	}
+
+	if d := b.Delay(70, 0); d != b.Max {
+		t.Fatalf("delay overflowing isn't capped: %v", d)
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	b := DecorrelatedJitter{Base: 100 * time.Millisecond, Max: time.Second}
+
+	var last time.Duration
+	for i := range 100 {
+		d := b.Delay(i, last)
+		if d < b.Base || d > b.Max || (last > 0 && d >= 3*last && d != b.Max) {
+			t.Fatalf("delay after %v is %v", last, d)
+		}
+		last = d
+	}
+}
+
+type recordingBackoff struct {
+	mu    sync.Mutex
+	calls [][2]time.Duration
+}
+
+func (b *recordingBackoff) Delay(i int, last time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls = append(b.calls, [2]time.Duration{time.Duration(i), last})
+	return time.Duration(i+1) * time.Millisecond
+}
+
+func TestClientWithBackoff(t *testing.T) {
+	b := &recordingBackoff{}
+	c := New(WithRetries(2), WithBackoff(b), WithMiddleware(canned(http.StatusBadGateway, "")))
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup failing upstream succeeded")
+	}
+
+	want := [][2]time.Duration{{0, 0}, {1, time.Millisecond}}
+	if len(b.calls) != len(want) || b.calls[0] != want[0] || b.calls[1] != want[1] {
+		t.Fatalf("backoff called with %v, want %v", b.calls, want)
+	}
+}
@@ -0,0 +1,200 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is how many lookups of a batch `SearchBatch`
+// makes at once, unless changed with `WithBatchConcurrency`.
+const DefaultBatchConcurrency = 4
+
+// ErrDeadlinePartial matches the `PartialError` of a batch whose context
+// was done before all of its BINs were resolved.
+var ErrDeadlinePartial = errors.New("Deadline Reached Before the Batch Completed")
+
+// PartialError is returned by `Client.SearchBatch` along with the results
+// of a batch its context didn't leave time to complete.
+type PartialError struct {
+	// Unresolved are the BINs of the batch left unresolved, in order, for
+	// the caller to reschedule.
+	Unresolved []string
+
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%v: %d BINs Unresolved: %v", ErrDeadlinePartial, len(e.Unresolved), e.Err)
+}
+
+// Is makes errors.Is(err, ErrDeadlinePartial) hold.
+func (e *PartialError) Is(target error) bool {
+	return target == ErrDeadlinePartial
+}
+
+// Unwrap returns the error of the context.
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Result is the outcome of the lookup of a BIN of a batch: the BIN, or
+// the error looking it up failed with.
+type Result struct {
+	BIN *BIN
+	Err error
+}
+
+// WithBatchConcurrency sets how many lookups of a batch `SearchBatch`
+// makes at once, `DefaultBatchConcurrency` unless changed.
+func WithBatchConcurrency(n int) Option {
+	return func(c *Client) {
+		c.batchConcurrency = n
+	}
+}
+
+// SearchBatch looks bins up, returning their results in the same order.
+// Lookups failing don't fail the batch, their errors being those of their
+// `Result`.
+//
+// Under a deadline, the batch is split into what can finish in time: the
+// BINs it doesn't leave the time of a lookup upstream for, as measured on
+// the recent ones, are served from cache as `Client.Search` serves them
+// when they can and left unresolved otherwise, rather than started only
+// to be cut short. The results of the others are returned along with a
+// `PartialError` listing the BINs unresolved, those cut short by the
+// context included, so that callers reschedule the remainder.
+//
+// BINs repeated in bins, as those of transaction files are hundreds of
+// times, are looked up once, their results sharing the same *BIN.
+func (c *Client) SearchBatch(ctx context.Context, bins []string) ([]Result, error) {
+	results := make([]Result, len(bins))
+	unresolved := make([]bool, len(bins))
+
+	groups := dedupe(bins, c.batchKey)
+
+	c.reconf.RLock()
+	n := max(c.batchConcurrency, 1)
+	c.reconf.RUnlock()
+	next := make(chan []int)
+
+	var wg sync.WaitGroup
+	for range min(n, len(groups)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for g := range next {
+				r, u := c.batchLookup(ctx, bins[g[0]])
+				for _, i := range g {
+					results[i], unresolved[i] = r, u
+				}
+			}
+		}()
+	}
+
+	for _, g := range groups {
+		next <- g
+	}
+	close(next)
+	wg.Wait()
+
+	var partial []string
+	for i, u := range unresolved {
+		if u {
+			partial = append(partial, bins[i])
+		}
+	}
+
+	if len(partial) > 0 {
+		return results, &PartialError{Unresolved: partial, Err: ctx.Err()}
+	}
+
+	return results, nil
+}
+
+// dedupe groups the positions of bins by their key, the groups in the
+// order of their first position.
+func dedupe(bins []string, key func(bin string) string) (groups [][]int) {
+	seen := make(map[string]int, len(bins))
+	for i, bin := range bins {
+		k := key(bin)
+		if g, ok := seen[k]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+
+		seen[k] = len(groups)
+		groups = append(groups, []int{i})
+	}
+
+	return
+}
+
+// batchKey returns the key of bin in a batch: the BIN it's looked up as,
+// or bin itself when it's invalid.
+func (c *Client) batchKey(bin string) string {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if c.validate(bin) != nil {
+		return bin
+	}
+
+	return c.sent(bin)
+}
+
+// batchLookup looks bin up as part of a batch under ctx, reporting
+// whether it was left unresolved for lack of time.
+func (c *Client) batchLookup(ctx context.Context, bin string) (r Result, unresolved bool) {
+	if ctx.Err() == nil && !c.inTime(ctx) {
+		r.BIN, r.Err = c.search(ctx, bin, true)
+		if r.Err == errNotCached {
+			return Result{Err: context.DeadlineExceeded}, true
+		}
+
+		return r, false
+	}
+
+	if ctx.Err() != nil {
+		return Result{Err: ctx.Err()}, true
+	}
+
+	r.BIN, r.Err = c.Search(ctx, bin)
+	if r.Err != nil && ctx.Err() != nil {
+		return r, true
+	}
+
+	return r, false
+}
+
+// inTime reports whether the deadline of ctx, if any, leaves the time of
+// a lookup upstream as measured on the recent ones.
+func (c *Client) inTime(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+
+	return time.Until(deadline) >= time.Duration(c.latency.Load())
+}
+
+// observeLatency adds d, the duration of a lookup upstream, to the moving
+// average of them.
+func (c *Client) observeLatency(d time.Duration) {
+	for {
+		old := c.latency.Load()
+
+		avg := int64(d)
+		if old != 0 {
+			avg = old + (int64(d)-old)/5
+		}
+
+		if c.latency.CompareAndSwap(old, avg) {
+			return
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slow delays the requests by d.
+func slow(d time.Duration) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			time.Sleep(d)
+			return next.Do(req)
+		})
+	}
+}
+
+func TestClientSearchBatch(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	results, err := c.SearchBatch(context.TODO(), []string{CorrectBIN, IncorrectBIN, "45717360"})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(results) != 3 || results[0].BIN == nil || !errors.Is(results[1].Err, ErrInvalidBIN) || results[2].Err != nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestClientSearchBatchDedupe(t *testing.T) {
+	var requested []string
+	c := New(WithMiddleware(paths(&requested, "45717360")), WithBatchConcurrency(1))
+
+	bins := []string{"45717360", "4571736012345678", IncorrectBIN, "45717360", IncorrectBIN, "41111111"}
+	results, err := c.SearchBatch(context.TODO(), bins)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(requested) != 2 {
+		t.Fatalf("requested %v", requested)
+	}
+
+	if results[0].BIN == nil || results[1].BIN != results[0].BIN || results[3].BIN != results[0].BIN {
+		t.Fatalf("repeated BINs resolved as %+v", results)
+	}
+
+	if !errors.Is(results[2].Err, ErrInvalidBIN) || !errors.Is(results[4].Err, ErrInvalidBIN) || !errors.Is(results[5].Err, ErrNotFound) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestClientSearchBatchDeadline(t *testing.T) {
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithBatchConcurrency(1), WithMiddleware(slow(50*time.Millisecond), canned(http.StatusOK, cannedBIN)))
+
+	// Measures the latency of upstream, and caches the BIN.
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 80*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results, err := c.SearchBatch(ctx, []string{"52882301", "41111111", "45717360", "51000000"})
+
+	var pe *PartialError
+	if !errors.Is(err, ErrDeadlinePartial) || !errors.As(err, &pe) {
+		t.Fatalf("batch past its deadline returned %v", err)
+	}
+
+	if strings.Join(pe.Unresolved, ",") != "41111111,51000000" {
+		t.Fatalf("unresolved %v, want those not leaving time nor cached", pe.Unresolved)
+	}
+
+	if results[0].Err != nil || results[2].BIN == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	if elapsed := time.Since(start); elapsed > 75*time.Millisecond {
+		t.Fatalf("batch took %v, the lookups it had no time for weren't skipped", elapsed)
+	}
+}
+
+func TestClientSearchBatchDeadlineCached(t *testing.T) {
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), CorrectBIN, Entry{BIN: &BIN{Country: Country{Short: "DK", Name: "Denmark"}}, Expires: time.Now().Add(time.Hour)})
+
+	var hits int
+	c := New(WithCache(cache), WithLocale("fr-FR", nil), WithHooks(Hooks{OnCacheHit: func(Event) { hits++ }}),
+		WithBatchConcurrency(1), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+	c.observeLatency(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	results, err := c.SearchBatch(ctx, []string{CorrectBIN})
+	if err != nil || results[0].BIN == nil || results[0].BIN.Country.Name != "Danemark" || hits != 1 {
+		t.Fatalf("cached BIN served as %+v with %v, %d hits, want it as by Search", results, err, hits)
+	}
+
+	c.Close()
+	if results, _ := c.SearchBatch(ctx, []string{CorrectBIN}); !errors.Is(results[0].Err, ErrClosed) {
+		t.Fatalf("batch of a closed client resolved as %+v", results)
+	}
+}
+
+// keysCache records the keys its entries are looked up under.
+type keysCache struct {
+	Cache
+	keys []string
+}
+
+func (c *keysCache) Get(ctx context.Context, key string) (Entry, bool, error) {
+	c.keys = append(c.keys, key)
+	return c.Cache.Get(ctx, key)
+}
+
+func TestClientSearchBatchDeadlineCardNumbers(t *testing.T) {
+	cache := &keysCache{Cache: NewMemoryCache(0)}
+	c := New(WithCache(cache), WithBatchConcurrency(1), WithMiddleware(slow(50*time.Millisecond), canned(http.StatusOK, cannedBIN)))
+
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	cache.keys = nil
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+
+	results, _ := c.SearchBatch(ctx, []string{"4571736012345678", "4571736099999999x"})
+	if results[0].Err != nil || results[0].BIN == nil {
+		t.Fatalf("card number of a cached BIN resolved as %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, ErrInvalidBIN) {
+		t.Fatalf("invalid card number resolved as %+v", results[1])
+	}
+
+	for _, key := range cache.keys {
+		if len(key) > 8 {
+			t.Fatalf("cache looked up under %q, past the digits of the BIN", key)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalEntryBinary(t *testing.T) {
+	var b BIN
+	if err := json.Unmarshal([]byte(`{"number":{"length":16,"luhn":true},"scheme":"visa","type":"debit","brand":"Visa/Dankort","prepaid":false,"country":{"numeric":"208","alpha2":"DK","name":"Denmark","emoji":"🇩🇰","currency":"DKK","latitude":56,"longitude":10},"bank":{"name":"Jyske Bank","url":"www.jyskebank.dk","phone":"+4589893300","city":"Hjørring"},"tier":"gold"}`), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []Entry{
+		{BIN: &b, Expires: time.Now().Add(time.Hour).Round(0), ETag: `"v1"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{NotFound: true, Expires: time.Now().Round(0)},
+		{Status: 503},
+		{BIN: &BIN{Scheme: SchemeAmex}},
+	} {
+		data, err := MarshalEntryBinary(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := UnmarshalEntryBinary(data)
+		if err != nil {
+			t.Fatalf("%x: %v", data, err)
+		}
+
+		if !got.Expires.Equal(e.Expires) {
+			t.Fatalf("expires %v, want %v", got.Expires, e.Expires)
+		}
+		got.Expires = e.Expires
+		if !reflect.DeepEqual(got, e) {
+			t.Fatalf("decoded %+v, want %+v", got, e)
+		}
+	}
+
+	e := Entry{BIN: &b, Expires: time.Now()}
+	data, _ := MarshalEntryBinary(e)
+	js, _ := MarshalEntry(e)
+	if len(data) >= len(js)*3/4 {
+		t.Fatalf("binary entry is %d bytes, JSON %d", len(data), len(js))
+	}
+}
+
+func TestUnmarshalEntryBinaryMalformed(t *testing.T) {
+	data, _ := MarshalEntryBinary(Entry{BIN: &BIN{Scheme: SchemeVisa, Bank: &Bank{Name: "Jyske Bank"}}, Expires: time.Now()})
+
+	for i := range data {
+		if _, err := UnmarshalEntryBinary(data[:i]); err == nil {
+			t.Fatalf("entry truncated to %d bytes decoded", i)
+		}
+	}
+	if _, err := UnmarshalEntryBinary(append(data, 0)); !errors.Is(err, ErrMalformedEntry) {
+		t.Fatalf("entry with trailing bytes decoded with %v", err)
+	}
+
+	future := append([]byte(nil), data...)
+	future[1] = binaryVersion + 1
+	if _, err := UnmarshalEntryBinary(future); !errors.Is(err, ErrEntryVersion) {
+		t.Fatalf("entry of an unknown layout decoded with %v", err)
+	}
+}
+
+func TestEntryCodecBinary(t *testing.T) {
+	e := Entry{BIN: &BIN{Scheme: SchemeVisa, Brand: "Visa/Dankort"}, Expires: time.Now().Add(time.Hour).Round(0)}
+
+	plain, _ := new(EntryCodec).Marshal(e)
+	binary, _ := (&EntryCodec{Binary: true}).Marshal(e)
+	gzipped, _ := (&EntryCodec{Binary: true, Compressor: Gzip}).Marshal(e)
+
+	if binary[0] != binaryMagic {
+		t.Fatalf("binary value starts with %x", binary[0])
+	}
+
+	for _, data := range [][]byte{plain, binary, gzipped} {
+		got, err := new(EntryCodec).Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.BIN.Brand != e.BIN.Brand || !got.Expires.Equal(e.Expires) {
+			t.Fatalf("%x decoded as %+v", data, got)
+		}
+	}
+}
+
+func BenchmarkMarshalEntry(b *testing.B) {
+	e := Entry{BIN: &BIN{Number: &Number{Length: 16, Luhn: true}, Scheme: SchemeVisa, Type: TypeDebit, Brand: "Visa/Dankort", Country: Country{Numeric: "208", Short: "DK", Name: "Denmark", Currency: "DKK", Lat: 56, Long: 10}, Bank: &Bank{Name: "Jyske Bank", URL: "www.jyskebank.dk"}}, Expires: time.Now()}
+
+	b.Run("JSON", func(b *testing.B) {
+		for range b.N {
+			data, _ := MarshalEntry(e)
+			UnmarshalEntry(data)
+		}
+	})
+	b.Run("Binary", func(b *testing.B) {
+		for range b.N {
+			data, _ := MarshalEntryBinary(e)
+			UnmarshalEntryBinary(data)
+		}
+	})
+}
@@ -3,40 +3,52 @@
 package binlookup
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"net/http"
-	"regexp"
-	"time"
-
-	"github.com/pkg/errors"
+	"sync/atomic"
 )
 
-// Client is the default HTTP client used by the package.
-var Client = &http.Client{Timeout: 10 * time.Second}
+// defaultClient backs the package level functions, see `Default`.
+var defaultClient atomic.Pointer[Client]
+
+func init() {
+	defaultClient.Store(New())
+}
 
-// StatusCodeError is an error returned by `Search` in the event
-// of a HTTP status code, other than `http.StatusOK`, sent by upstream.
-type StatusCodeError int
+// Default returns the `Client` the package level functions, such as
+// `Search`, look up through: one constructed with no option, unless
+// replaced with `SetDefault`.
+func Default() *Client {
+	return defaultClient.Load()
+}
 
-func (s StatusCodeError) Error() string {
-	return fmt.Sprintf("%d %v", s, http.StatusText(int(s)))
+// SetDefault makes c the `Client` the package level functions look up
+// through. It's safe to call while they're in use, as the configuration
+// of a `Client` is fixed once constructed: the lookups in progress finish
+// through the previous one, which the caller may close afterwards.
+func SetDefault(c *Client) {
+	defaultClient.Store(c)
 }
 
 // Number is a placeholder for the `number` JSON object in `BIN`.
 type Number struct {
-	Length int
-	Luhn   bool
+	Length int  `json:"length,omitempty"`
+	Luhn   bool `json:"luhn"`
 }
 
 // Country is a placeholder for the `country` JSON object in `BIN`.
 type Country struct {
-	Numeric,
-	Name,
-	Emoji,
-	Currency string
+	Numeric string `json:"numeric,omitempty"`
+	Short   string `json:"alpha2,omitempty"`
 
-	Short string `json:"alpha2"`
+	// Alpha3 is the ISO 3166-1 alpha-3 code. Upstream doesn't send it:
+	// it's resolved out of Short, see `Country.Normalize`, and left out of
+	// the JSON encoding for it to stay that of upstream.
+	Alpha3 string `json:"-"`
+
+	Name     string `json:"name,omitempty"`
+	Emoji    string `json:"emoji,omitempty"`
+	Currency string `json:"currency,omitempty"`
 
 	Lat  float64 `json:"latitude"`
 	Long float64 `json:"longitude"`
@@ -44,68 +56,136 @@ type Country struct {
 
 // Bank is a placeholder for the `bank` JSON object in `BIN`.
 type Bank struct {
-	Name, URL, Phone, City string
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	City  string `json:"city,omitempty"`
 }
 
 // BIN is the placeholder to host the deserialized JSON payload
-// returned by upstream.
+// returned by upstream. It encodes back to the same JSON, fields of Extra
+// included.
+//
+// Upstream omits or nulls the prepaid, number and bank fields of many
+// BINs, so Prepaid, Number and Bank are nil when unknown; a BIN known not
+// to be prepaid has Prepaid pointing to false, see `BIN.IsPrepaid`.
 type BIN struct {
-	Number              Number
-	Scheme, Type, Brand string
-	Prepaid             bool
-	Country             Country
-	Bank                Bank
+	Number  *Number  `json:"number,omitempty"`
+	Scheme  Scheme   `json:"scheme,omitempty"`
+	Type    CardType `json:"type,omitempty"`
+	Brand   string   `json:"brand,omitempty"`
+	Prepaid *bool    `json:"prepaid,omitempty"`
+	Country Country  `json:"country"`
+	Bank    *Bank    `json:"bank,omitempty"`
+
+	// Extra holds the fields of the payload `BIN` doesn't model, as sent,
+	// keyed by their JSON name, so that attributes added by upstream or
+	// returned by other providers aren't lost. It's nil when there are
+	// none.
+	Extra map[string]json.RawMessage `json:"-"`
+
+	// Meta describes how the BIN was obtained. It isn't part of the
+	// payload sent by upstream.
+	Meta Meta `json:"-"`
+}
+
+// IsPrepaid reports whether the cards of b are prepaid, with known false
+// when upstream didn't tell. A Type of prepaid, which some providers send
+// in place of the flag, tells them prepaid.
+func (b *BIN) IsPrepaid() (prepaid, known bool) {
+	if b.Prepaid == nil {
+		if ParseCardType(string(b.Type)) == "prepaid" {
+			return true, true
+		}
+		return false, false
+	}
+
+	return *b.Prepaid, true
+}
+
+// IsCredit reports whether the cards of b are credit cards, by their Type
+// normalized with `ParseCardType`, with known false when it's neither
+// credit, debit nor charge.
+func (b *BIN) IsCredit() (credit, known bool) {
+	return b.isType(TypeCredit)
+}
+
+// IsDebit reports whether the cards of b are debit cards, the way
+// `BIN.IsCredit` does.
+func (b *BIN) IsDebit() (debit, known bool) {
+	return b.isType(TypeDebit)
+}
+
+// isType reports whether the Type of b is t, with known false when it's
+// none of those upstream reports.
+func (b *BIN) isType(t CardType) (is, known bool) {
+	switch typ := ParseCardType(string(b.Type)); typ {
+	case TypeDebit, TypeCredit, TypeCharge:
+		return typ == t, true
+	}
+
+	return false, false
+}
+
+// Meta is a placeholder for the information about how a `BIN` was
+// obtained.
+type Meta struct {
+	// Provider is the name of the `Provider` which answered.
+	Provider string
+
+	// Sources maps the fields of a `BIN` put together by `Merge`, keyed
+	// by their JSON path such as `country.alpha2`, to the name of the
+	// provider which supplied each.
+	Sources map[string]string
+
+	// Degraded is set on the partial answers of `Static`, resolving the
+	// scheme and at best the country of the BIN.
+	Degraded bool
+
+	// Compressed is set when upstream compressed the response the BIN
+	// was decoded from.
+	Compressed bool
+
+	// Stale is set on the expired BINs a `Client` answers with while
+	// upstream fails, see `WithStaleIfError`.
+	Stale bool
 }
 
 // Search makes a BIN lookup request to Upstream.
 //
 // An error is returned when:
-// 	- The bin parameter given to the function is incorrect in format.
-// 	- HTTP request fails.
-// 	- HTTP status code is not equal to 200, otherwise known as http.StatusOK.
-// 	- The unmarshaling of the returned raw JSON payload fails.
+//   - The bin parameter given to the function is incorrect in format.
+//   - HTTP request fails.
+//   - HTTP status code is not equal to 200, otherwise known as http.StatusOK.
+//   - The unmarshaling of the returned raw JSON payload fails.
 //
 // Since this function is dependent on a 3rd party service, the most flexible way
-// to handle status codes would be returning a special error, which is StatusCodeError
+// to handle status codes would be returning a special error, which is HTTPError
 // in this case.
 // This is because there are many and many status codes that can be returned by a Web service.
 // Thus, by returning the status code as an error, it's being made possible for clients
 // to handle them on their own.
 //
 // Possible status codes that may be returned by upstream are (according to https://binlist.net/):
-// 	- 400, http.StatusBadRequest: Returned when given BIN is incorrect in format. Since it's being checked initially, this status code isn't possible.
-//	- 429, http.StatusTooManyRequests: Returned in possible throttling. See the link above to check the toleration.
-//	- 200, http.StatusOK: In case of 200, the error would already be nil.
-//	- 404, http.StatusNotFound: This is returned when BIN isn't present in the DB which upstream queries.
-//	- And there may happen many more if the service is upset.
+//   - 400, http.StatusBadRequest: Returned when given BIN is incorrect in format. Since it's being checked initially, this status code isn't possible.
+//   - 429, http.StatusTooManyRequests: Returned in possible throttling. See the link above to check the toleration.
+//   - 200, http.StatusOK: In case of 200, the error would already be nil.
+//   - 404, http.StatusNotFound: This is returned when BIN isn't present in the DB which upstream queries.
+//   - And there may happen many more if the service is upset.
 //
-// These codes can be extracted by asserting StatusCodeError type over
-// the error returned by Cause function of https://github.com/pkg/errors.
-func Search(bin string) (b *BIN, err error) {
-	ok := regexp.MustCompile(`^[1-9]\d{3,15}$`).MatchString(bin)
-	if !ok {
-		err = errors.New("BIN must be fully numerical, first digit must be in range of 1-9, and the next digits must be 3-15 characters long.")
-		return
-	}
-
-	resp, err := Client.Get(fmt.Sprintf("https://lookup.binlist.net/%v", bin))
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
-
-	switch s := resp.StatusCode; s {
-	case http.StatusOK:
-		break
-	default:
-		err = errors.Wrap(StatusCodeError(s), "Failed Due to Status Code Error")
-		return
-	}
-
-	if err = json.NewDecoder(resp.Body).Decode(&b); err != nil {
-		err = errors.WithMessage(err, "JSON Unmarshaling Failed")
-		return
-	}
+// The common cases can be told apart with errors.Is against ErrInvalidBIN,
+// ErrBadRequest, ErrNotFound and ErrRateLimited, while the raw code can be
+// extracted with errors.As into an *HTTPError, along with the beginning of
+// the response body and its debugging headers.
+//
+// Search uses the `Default` client; construct a `Client` with `New` for
+// anything more configurable.
+func Search(bin string) (*BIN, error) {
+	return Default().Search(context.Background(), bin)
+}
 
-	return
+// SearchInt makes a BIN lookup request to Upstream for the BIN bin handed
+// over as an integer, see `FormatBIN` and `Search`.
+func SearchInt(bin uint64) (*BIN, error) {
+	return Default().SearchInt(context.Background(), bin)
 }
@@ -2,11 +2,11 @@ package binlookup
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 const (
@@ -22,8 +22,7 @@ func TestSearchWithCorrectBIN(t *testing.T) {
 expedition:
 	_, err := Search(CorrectBIN)
 	if err != nil {
-		sce, ok := errors.Cause(err).(StatusCodeError)
-		if ok && sce == http.StatusTooManyRequests {
+		if errors.Is(err, ErrRateLimited) {
 			select {
 			case <-ctx.Done():
 				t.Fatal(ctx.Err())
@@ -50,10 +49,62 @@ func TestSearchWithCorrectButOrphanBIN(t *testing.T) {
 	}
 }
 
-func TestStatusCodeError(t *testing.T) {
-	ise := StatusCodeError(http.StatusInternalServerError)
+func TestBINCardType(t *testing.T) {
+	yes, no := true, false
+
+	for _, tt := range []struct {
+		b                        BIN
+		credit, debit, typeKnown bool
+		prepaid, prepaidKnown    bool
+	}{
+		{b: BIN{Type: TypeCredit, Prepaid: &no}, credit: true, typeKnown: true, prepaidKnown: true},
+		{b: BIN{Type: " DEBIT ", Prepaid: &yes}, debit: true, prepaid: true, typeKnown: true, prepaidKnown: true},
+		{b: BIN{Type: TypeCharge}, typeKnown: true},
+		{b: BIN{Type: "Prepaid"}, prepaid: true, prepaidKnown: true},
+		{b: BIN{}},
+	} {
+		credit, known := tt.b.IsCredit()
+		if credit != tt.credit || known != tt.typeKnown {
+			t.Errorf("IsCredit of %q = %v, %v", tt.b.Type, credit, known)
+		}
+		debit, known := tt.b.IsDebit()
+		if debit != tt.debit || known != tt.typeKnown {
+			t.Errorf("IsDebit of %q = %v, %v", tt.b.Type, debit, known)
+		}
+		prepaid, known := tt.b.IsPrepaid()
+		if prepaid != tt.prepaid || known != tt.prepaidKnown {
+			t.Errorf("IsPrepaid of %q = %v, %v", tt.b.Type, prepaid, known)
+		}
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	ise := &HTTPError{StatusCode: http.StatusInternalServerError}
 
 	if ise.Error() != "500 Internal Server Error" {
 		t.FailNow()
 	}
 }
+
+func TestSetDefault(t *testing.T) {
+	defer SetDefault(Default())
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefault(New(WithMiddleware(canned(http.StatusOK, cannedBIN))))
+		}()
+		go func() {
+			defer wg.Done()
+			Default()
+		}()
+	}
+	wg.Wait()
+
+	b, err := Search("45717360")
+	if err != nil || b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("Search through the default set returned %+v, %v", b, err)
+	}
+}
@@ -0,0 +1,123 @@
+package binlookuptest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// ErrInvalidPANSpec is returned generating a card number out of a BIN or
+// scheme it can't be made of.
+var ErrInvalidPANSpec = errors.New("Invalid PAN Specification")
+
+// schemePrefixes are prefixes of each scheme, which `binlookup.DetectScheme`
+// tells the scheme of.
+var schemePrefixes = map[binlookup.Scheme][]string{
+	binlookup.SchemeVisa:       {"4"},
+	binlookup.SchemeMastercard: {"51", "52", "53", "54", "55", "2221", "2720"},
+	binlookup.SchemeAmex:       {"34", "37"},
+	binlookup.SchemeDiscover:   {"6011", "65"},
+	binlookup.SchemeDiners:     {"36", "38"},
+	binlookup.SchemeJCB:        {"3528", "3589"},
+	binlookup.SchemeUnionPay:   {"62"},
+	binlookup.SchemeMaestro:    {"6759", "6304"},
+	binlookup.SchemeMir:        {"2200", "2204"},
+	binlookup.SchemeRuPay:      {"60", "6521"},
+	binlookup.SchemeElo:        {"509000", "636368"},
+	binlookup.SchemeTroy:       {"9792"},
+	binlookup.SchemeDankort:    {"5019"},
+}
+
+// panLength returns the usual length of the card numbers of scheme.
+func panLength(scheme binlookup.Scheme) int {
+	switch scheme {
+	case binlookup.SchemeAmex:
+		return 15
+	case binlookup.SchemeDiners:
+		return 14
+	}
+
+	return 16
+}
+
+// PAN returns a random card number of length digits starting with bin,
+// whose last digit is the Luhn check digit, for end-to-end tests to
+// validate as real card numbers would be. A length of zero picks the
+// usual one of the scheme of bin: 15 for Amex, 14 for Diners and 16
+// otherwise. r is the source of randomness, the global one when nil, for
+// tests to seed theirs.
+//
+// The numbers are synthetic: neither issued nor meant to be charged.
+func PAN(r *rand.Rand, bin string, length int) (string, error) {
+	if bin == "" || strings.Trim(bin, "0123456789") != "" {
+		return "", fmt.Errorf("%w: BIN %q Isn't Numerical", ErrInvalidPANSpec, bin)
+	}
+
+	if length == 0 {
+		scheme, _ := binlookup.DetectScheme(bin)
+		length = panLength(scheme)
+	}
+
+	if length < 12 || length > 19 || len(bin) >= length {
+		return "", fmt.Errorf("%w: Length %d Doesn't Fit BIN %q", ErrInvalidPANSpec, length, bin)
+	}
+
+	digits := []byte(bin)
+	for len(digits) < length-1 {
+		digits = append(digits, byte('0'+intN(r, 10)))
+	}
+
+	return string(append(digits, checkDigit(digits))), nil
+}
+
+// SchemePAN returns a random card number of the usual length of scheme,
+// which `binlookup.DetectScheme` tells the scheme of, see `PAN`.
+func SchemePAN(r *rand.Rand, scheme binlookup.Scheme) (string, error) {
+	prefixes, ok := schemePrefixes[scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: Unknown Scheme %q", ErrInvalidPANSpec, scheme)
+	}
+
+	// Random digits after a short prefix may fall in the range of another
+	// scheme nested within, such as Elo's within Visa's 4.
+	for {
+		pan, err := PAN(r, prefixes[intN(r, len(prefixes))], panLength(scheme))
+		if err != nil {
+			return "", err
+		}
+
+		if s, _ := binlookup.DetectScheme(pan); s == scheme {
+			return pan, nil
+		}
+	}
+}
+
+func intN(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.IntN(n)
+	}
+
+	return r.IntN(n)
+}
+
+// checkDigit returns the Luhn check digit of digits.
+func checkDigit(digits []byte) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+
+		// The check digit to be appended is the first one not doubled.
+		if (len(digits)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,59 @@
+package binlookuptest
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestPAN(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for bin, length := range map[string]int{"45717360": 16, "378282": 15, "36": 14, "52882301": 19} {
+		want := length
+		if bin != "52882301" {
+			length = 0
+		}
+
+		for range 50 {
+			pan, err := PAN(r, bin, length)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(pan, bin) || len(pan) != want || !binlookup.Luhn(pan) {
+				t.Fatalf("PAN(%q, %d) generated %v", bin, length, pan)
+			}
+		}
+	}
+
+	for _, bin := range []string{"", "4571-73"} {
+		if _, err := PAN(r, bin, 0); !errors.Is(err, ErrInvalidPANSpec) {
+			t.Fatalf("PAN(%q) returned %v", bin, err)
+		}
+	}
+
+	if _, err := PAN(r, "45717360", 8); !errors.Is(err, ErrInvalidPANSpec) {
+		t.Fatalf("PAN no longer than its BIN returned %v", err)
+	}
+}
+
+func TestSchemePAN(t *testing.T) {
+	for scheme := range schemePrefixes {
+		pan, err := SchemePAN(nil, scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _ := binlookup.DetectScheme(pan); got != scheme || !binlookup.Luhn(pan) {
+			t.Fatalf("%v card number %v detected as %v", scheme, pan, got)
+		}
+	}
+
+	if _, err := SchemePAN(nil, "unknown"); !errors.Is(err, ErrInvalidPANSpec) {
+		t.Fatalf("unknown scheme returned %v", err)
+	}
+}
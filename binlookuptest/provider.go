@@ -0,0 +1,206 @@
+package binlookuptest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Step is a scripted answer of a `FakeProvider` to a lookup: Err, or the
+// BIN looked up when nil, after Latency.
+type Step struct {
+	Err     error
+	Latency time.Duration
+}
+
+// FakeProvider is a `binlookup.Provider` answering lookups out of the BINs
+// added to it, without any network call, for the tests of retries and
+// failover to run deterministically in CI:
+//
+//	p := binlookuptest.NewFakeProvider()
+//	p.Add("45717360", fixtures.VisaDebit.Value())
+//	p.RateLimitNext(2, time.Second)
+//
+//	chain := binlookup.Chain(p, binlookup.Static())
+//
+// A lookup is answered with the next step scripted, if any, then with the
+// failure set for its BIN, then with the BIN added that is the longest
+// prefix of the one looked up, and with `binlookup.ErrNotFound` when there
+// is none. It's safe for concurrent use.
+type FakeProvider struct {
+	mu       sync.Mutex
+	bins     map[string]*binlookup.BIN
+	failures map[string]error
+	script   []Step
+	latency  time.Duration
+	caps     binlookup.Capabilities
+	calls    []string
+}
+
+var _ binlookup.Provider = (*FakeProvider)(nil)
+
+// NewFakeProvider returns a `FakeProvider` without any BIN, capable of 8
+// digit BINs and bank data, offline.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		bins:     make(map[string]*binlookup.BIN),
+		failures: make(map[string]error),
+		caps:     binlookup.Capabilities{EightDigit: true, BankData: true, Offline: true},
+	}
+}
+
+// Name returns fake.
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Add makes p answer the lookups of bin with a copy of b.
+func (p *FakeProvider) Add(bin string, b *binlookup.BIN) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bins[bin] = b
+}
+
+// Fail makes p answer the lookups of bin with err, until reset with a nil
+// err.
+func (p *FakeProvider) Fail(bin string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		delete(p.failures, bin)
+		return
+	}
+	p.failures[bin] = err
+}
+
+// Script makes p answer the next lookups, whatever their BIN, with steps
+// in order, after those scripted already.
+func (p *FakeProvider) Script(steps ...Step) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.script = append(p.script, steps...)
+}
+
+// FailNext makes p answer the next n lookups with err.
+func (p *FakeProvider) FailNext(n int, err error) {
+	for range n {
+		p.Script(Step{Err: err})
+	}
+}
+
+// RateLimitNext makes p answer the next n lookups with the 429 of
+// `RateLimited`, retryAfter rounded up to the second.
+func (p *FakeProvider) RateLimitNext(n int, retryAfter time.Duration) {
+	p.FailNext(n, RateLimited(retryAfter))
+}
+
+// SetLatency delays the answers of p by d, or less when the lookup is
+// canceled first. Scripted steps with a Latency take theirs instead.
+func (p *FakeProvider) SetLatency(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.latency = d
+}
+
+// SetCapabilities makes p report c.
+func (p *FakeProvider) SetCapabilities(c binlookup.Capabilities) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.caps = c
+}
+
+// Capabilities returns those of p, see `FakeProvider.SetCapabilities`.
+func (p *FakeProvider) Capabilities() binlookup.Capabilities {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.caps
+}
+
+// Calls returns the BINs looked up through p so far, in order.
+func (p *FakeProvider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.calls...)
+}
+
+// Search looks bin up, see `FakeProvider`. A malformed bin fails with
+// `binlookup.ErrInvalidBIN` without taking a step of the script.
+func (p *FakeProvider) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	if err := binlookup.ValidateBIN(bin); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.calls = append(p.calls, bin)
+	step := Step{Latency: p.latency}
+	if len(p.script) > 0 {
+		step, p.script = p.script[0], p.script[1:]
+	} else if err, ok := p.failures[bin]; ok {
+		step.Err = err
+	}
+	b := p.lookup(bin)
+	p.mu.Unlock()
+
+	if step.Latency > 0 {
+		t := time.NewTimer(step.Latency)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case step.Err != nil:
+		return nil, step.Err
+	case b == nil:
+		return nil, binlookup.ErrNotFound
+	}
+
+	c := *b
+	return &c, nil
+}
+
+// lookup returns the longest prefix of bin added to p. p.mu must be held.
+func (p *FakeProvider) lookup(bin string) *binlookup.BIN {
+	for n := len(bin); n > 0; n-- {
+		if b, ok := p.bins[bin[:n]]; ok {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// RateLimited returns the `binlookup.HTTPError` of upstream throttling,
+// a 429 with a Retry-After of retryAfter rounded up to the second, left out
+// when zero, which `binlookup.IsRetryable` counts as retryable.
+func RateLimited(retryAfter time.Duration) *binlookup.HTTPError {
+	e := StatusError(http.StatusTooManyRequests)
+	if retryAfter > 0 {
+		e.Header.Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+	}
+
+	return e
+}
+
+// StatusError returns the `binlookup.HTTPError` of upstream answering with
+// status, such as http.StatusBadGateway.
+func StatusError(status int) *binlookup.HTTPError {
+	return &binlookup.HTTPError{StatusCode: status, Header: make(http.Header)}
+}
@@ -0,0 +1,104 @@
+package binlookuptest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestFakeProvider(t *testing.T) {
+	p := NewFakeProvider()
+	p.Add("457173", &binlookup.BIN{Scheme: binlookup.SchemeVisa})
+
+	b, err := p.Search(context.TODO(), "45717360")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if b.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("scheme is %q, want visa", b.Scheme)
+	}
+
+	if _, err := p.Search(context.TODO(), "99999999"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("unknown BIN returned %v, want ErrNotFound", err)
+	}
+	if _, err := p.Search(context.TODO(), "4571x"); !errors.Is(err, binlookup.ErrInvalidBIN) {
+		t.Fatalf("malformed BIN returned %v, want ErrInvalidBIN", err)
+	}
+
+	if calls := p.Calls(); len(calls) != 2 || calls[0] != "45717360" {
+		t.Fatalf("calls are %v, want the 2 well-formed BINs", calls)
+	}
+}
+
+func TestFakeProviderScript(t *testing.T) {
+	p := NewFakeProvider()
+	p.Add("45717360", &binlookup.BIN{Scheme: binlookup.SchemeVisa})
+	p.RateLimitNext(2, 1500*time.Millisecond)
+	p.Script(Step{Err: StatusError(http.StatusBadGateway)})
+
+	for i, want := range []error{binlookup.ErrRateLimited, binlookup.ErrRateLimited, nil, nil} {
+		_, err := p.Search(context.TODO(), "45717360")
+		if i == 2 {
+			var he *binlookup.HTTPError
+			if !errors.As(err, &he) || he.StatusCode != http.StatusBadGateway {
+				t.Fatalf("lookup %d returned %v, want 502", i, err)
+			}
+			continue
+		}
+		if !errors.Is(err, want) && err != want {
+			t.Fatalf("lookup %d returned %v, want %v", i, err, want)
+		}
+		if want != nil && !binlookup.IsRetryable(err) {
+			t.Fatalf("lookup %d returned %v, not retryable", i, err)
+		}
+	}
+
+	if h := RateLimited(1500 * time.Millisecond).Header.Get("Retry-After"); h != "2" {
+		t.Fatalf("Retry-After is %q, want 2", h)
+	}
+}
+
+func TestFakeProviderFail(t *testing.T) {
+	p := NewFakeProvider()
+	p.Add("45717360", &binlookup.BIN{Scheme: binlookup.SchemeVisa})
+	p.Fail("45717360", StatusError(http.StatusServiceUnavailable))
+
+	if _, err := p.Search(context.TODO(), "45717360"); err == nil {
+		t.Fatal("lookup succeeded, want the failure set")
+	}
+
+	p.Fail("45717360", nil)
+	if _, err := p.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+func TestFakeProviderFailover(t *testing.T) {
+	primary, secondary := NewFakeProvider(), NewFakeProvider()
+	primary.FailNext(1, StatusError(http.StatusInternalServerError))
+	secondary.Add("45717360", &binlookup.BIN{Scheme: binlookup.SchemeVisa})
+
+	if _, err := binlookup.Chain(primary, secondary).Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if n := len(secondary.Calls()); n != 1 {
+		t.Fatalf("secondary was called %d times, want 1", n)
+	}
+}
+
+func TestFakeProviderLatency(t *testing.T) {
+	p := NewFakeProvider()
+	p.SetLatency(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Search(ctx, "45717360"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("lookup returned %v, want context.DeadlineExceeded", err)
+	}
+}
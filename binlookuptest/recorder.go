@@ -0,0 +1,195 @@
+package binlookuptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mode tells a `Recorder` whether to replay fixtures or record them.
+type Mode int
+
+const (
+	// Replay answers every request out of its fixture, failing those
+	// without one with `ErrNoFixture`. Nothing reaches the network.
+	Replay Mode = iota
+
+	// ReplayOrRecord replays the requests having a fixture and records
+	// the others.
+	ReplayOrRecord
+
+	// Record sends every request to the network, recording the fixtures
+	// anew.
+	Record
+)
+
+// RecordEnv is the environment variable `ModeFromEnv` reads.
+const RecordEnv = "BINLOOKUP_RECORD"
+
+// ModeFromEnv returns the `Mode` selected by the BINLOOKUP_RECORD
+// environment variable: `Record` when it's "all", `ReplayOrRecord` when
+// it's set to anything else, and `Replay` otherwise. CI replays while
+// fixtures are recorded locally with:
+//
+//	BINLOOKUP_RECORD=1 go test ./...
+func ModeFromEnv() Mode {
+	switch v := os.Getenv(RecordEnv); {
+	case v == "all":
+		return Record
+	case v != "":
+		return ReplayOrRecord
+	}
+
+	return Replay
+}
+
+// ErrNoFixture is returned replaying a request without a fixture.
+var ErrNoFixture = errors.New("No Fixture")
+
+// Recorder is an http.RoundTripper capturing the responses of upstream
+// to fixture files in Dir and replaying them in later runs, so that tests
+// neither depend on the network nor flake on rate limits. Plug it in with
+// binlookup.WithTransport.
+//
+// Requests are told apart by their method and URL. Responses signaling a
+// transient failure, 429 and 5xx, are passed through without being
+// recorded.
+type Recorder struct {
+	// Dir holds the fixtures, one JSON file per request.
+	Dir string
+
+	// Mode tells whether to replay or record.
+	Mode Mode
+
+	// Transport sends the requests recorded, http.DefaultTransport when
+	// nil.
+	Transport http.RoundTripper
+}
+
+// NewRecorder returns a `Recorder` of the fixtures in dir.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode}
+}
+
+// fixture is the content of a fixture file.
+type fixture struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// RoundTrip replays or records the response to req.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := r.path(req)
+
+	if r.Mode != Record {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var f fixture
+			if err := json.Unmarshal(data, &f); err != nil {
+				return nil, fmt.Errorf("Malformed Fixture %v: %w", path, err)
+			}
+
+			return f.response(req), nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		if r.Mode == Replay {
+			return nil, fmt.Errorf("%w for %v %v, record it with %v=1", ErrNoFixture, req.Method, req.URL, RecordEnv)
+		}
+	}
+
+	return r.record(req, path)
+}
+
+func (r *Recorder) record(req *http.Request, path string) (*http.Response, error) {
+	t := r.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+
+	resp, err := t.RoundTrip(req)
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	data, err := json.MarshalIndent(fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       string(body),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// path returns the fixture file of req, named after its method, host and
+// path.
+func (r *Recorder) path(req *http.Request) string {
+	name := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-':
+			return c
+		}
+
+		return '_'
+	}, req.Method+"_"+req.URL.Host+req.URL.EscapedPath())
+
+	if req.URL.RawQuery != "" {
+		name += "_" + fmt.Sprintf("%x", req.URL.RawQuery)
+	}
+
+	return filepath.Join(r.Dir, name+".json")
+}
+
+func (f *fixture) response(req *http.Request) *http.Response {
+	header := f.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %v", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
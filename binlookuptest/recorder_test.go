@@ -0,0 +1,59 @@
+package binlookuptest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := NewServer()
+	srv.Add("45717360", JyskeBank)
+	srv.RateLimitNext(1, 0)
+
+	c := srv.Client(binlookup.WithTransport(NewRecorder(dir, ReplayOrRecord)), binlookup.WithRetries(1))
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Search(context.TODO(), "52882300"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("lookup of a BIN not added returned %v", err)
+	}
+	url := srv.URL
+	srv.Close()
+
+	replay := binlookup.New(binlookup.WithBaseURL(url), binlookup.WithTransport(NewRecorder(dir, Replay)))
+
+	b, err := replay.Search(context.TODO(), "45717360")
+	if err != nil || b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("replayed lookup returned %+v, %v", b, err)
+	}
+
+	if _, err := replay.Search(context.TODO(), "52882300"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("replayed 404 returned %v", err)
+	}
+
+	if _, err := replay.Search(context.TODO(), "37828200"); !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("lookup without fixture returned %v, want ErrNoFixture", err)
+	}
+}
+
+func TestRecorderSkipsTransientFailures(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.FailNext(1, http.StatusServiceUnavailable)
+
+	c := srv.Client(binlookup.WithTransport(NewRecorder(dir, ReplayOrRecord)))
+	c.Search(context.TODO(), "45717360")
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("503 was recorded as %v", files[0].Name())
+	}
+}
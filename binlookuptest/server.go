@@ -0,0 +1,293 @@
+// Package binlookuptest provides a fake upstream for the tests of the
+// code using binlookup, so that they neither depend on the real API nor
+// run into its rate limits in CI.
+//
+//	srv := binlookuptest.NewServer()
+//	defer srv.Close()
+//
+//	srv.Add("45717360", binlookuptest.JyskeBank)
+//	srv.FailNext(1, http.StatusTooManyRequests)
+//
+//	c := srv.Client(binlookup.WithRetries(1))
+//
+// `FakeProvider` stands in for a provider without any network at all.
+package binlookuptest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// JyskeBank is the answer of upstream for 45717360, a Danish Visa debit
+// BIN, as canned data for tests.
+const JyskeBank = `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"debit","brand":"Visa/Dankort","prepaid":false,"country":{"numeric":"208","alpha2":"DK","name":"Denmark","emoji":"🇩🇰","currency":"DKK","latitude":56,"longitude":10},"bank":{"name":"Jyske Bank","url":"www.jyskebank.dk","phone":"+4589893300","city":"Hjørring"}}`
+
+// Server is a fake upstream answering lookups out of the BINs added to
+// it, the way binlist does: a lookup is answered with the BIN added that
+// is the longest prefix of the one looked up, and with 404 when there is
+// none. It's safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	bins     map[string]string
+	statuses map[string]int
+	failures []failure
+	latency  time.Duration
+	requests []*http.Request
+
+	limit     *bucket
+	throttled int
+	now       func() time.Time
+}
+
+type failure struct {
+	status     int
+	retryAfter time.Duration
+}
+
+// NewServer starts and returns a `Server` without any BIN. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{bins: make(map[string]string), statuses: make(map[string]int), now: time.Now}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client returns a `binlookup.Client` looking up against s, configured
+// with opts.
+func (s *Server) Client(opts ...binlookup.Option) *binlookup.Client {
+	return binlookup.New(append([]binlookup.Option{binlookup.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// Add makes s answer the lookups of bin with body, the JSON payload of
+// upstream.
+func (s *Server) Add(bin, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bins[bin] = body
+}
+
+// AddBIN makes s answer the lookups of bin with b.
+func (s *Server) AddBIN(bin string, b *binlookup.BIN) error {
+	body, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	s.Add(bin, string(body))
+
+	return nil
+}
+
+// Fail makes s answer the lookups of bin with status, such as
+// http.StatusInternalServerError, until reset with a zero status.
+func (s *Server) Fail(bin string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status == 0 {
+		delete(s.statuses, bin)
+		return
+	}
+	s.statuses[bin] = status
+}
+
+// FailNext makes s answer the next n lookups, whatever their BIN, with
+// status. A 429 comes without Retry-After, see `Server.RateLimitNext`.
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range n {
+		s.failures = append(s.failures, failure{status: status})
+	}
+}
+
+// RateLimitNext makes s answer the next n lookups with 429 and a
+// Retry-After of retryAfter, rounded up to the second.
+func (s *Server) RateLimitNext(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range n {
+		s.failures = append(s.failures, failure{status: http.StatusTooManyRequests, retryAfter: retryAfter})
+	}
+}
+
+// SetLatency delays the answers of s by d, or less when the request is
+// canceled first.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// Requests returns the requests s received so far, in order.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	bin := strings.TrimPrefix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	latency := s.latency
+
+	var f failure
+	if len(s.failures) > 0 {
+		f, s.failures = s.failures[0], s.failures[1:]
+	} else if status, ok := s.statuses[bin]; ok {
+		f.status = status
+	}
+
+	body, found := s.lookup(bin)
+	if f.status == 0 && s.limit != nil {
+		f = s.limit.take(w.Header(), s.now())
+		if f.status != 0 {
+			s.throttled++
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		t := time.NewTimer(latency)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case f.status != 0:
+		if f.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.retryAfter+time.Second-1)/time.Second)))
+		}
+		w.WriteHeader(f.status)
+	case !found:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+// lookup returns the body of the longest prefix of bin added to s. s.mu
+// must be held.
+func (s *Server) lookup(bin string) (body string, ok bool) {
+	for n := len(bin); n > 0; n-- {
+		if body, ok = s.bins[bin[:n]]; ok {
+			return
+		}
+	}
+
+	return
+}
+
+// RateLimit is a request allowance of Requests per period Per, with
+// bursts of up to Burst requests, Requests when zero.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// BinlistRateLimit is the allowance binlist documents for the free API:
+// 5 requests per hour, all of which may be made at once.
+var BinlistRateLimit = RateLimit{Requests: 5, Per: time.Hour}
+
+// SetRateLimit makes s throttle lookups with a token bucket the way
+// upstream does, answering those over l with 429 and a Retry-After of when
+// the next one is allowed. Every answer carries the X-RateLimit-Limit,
+// -Remaining and -Reset headers that binlookup.ParseQuota reads. A zero l
+// removes the limit.
+//
+// Replaying a day of traffic against s, with `Server.SetClock` to make it
+// go by faster, shows whether a limiter and retry configuration stays
+// under quota before it's deployed.
+func (s *Server) SetRateLimit(l RateLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l.Requests <= 0 || l.Per <= 0 {
+		s.limit = nil
+		return
+	}
+
+	if l.Burst <= 0 {
+		l.Burst = l.Requests
+	}
+	s.limit = &bucket{RateLimit: l, tokens: float64(l.Burst), last: s.now()}
+}
+
+// SetClock makes s tell the time with now, time.Now unless changed, for
+// simulations of the rate limit to run faster than the real time.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = now
+	if s.limit != nil {
+		s.limit.last = now()
+	}
+}
+
+// Throttled returns the number of lookups s answered with 429 because of
+// its rate limit.
+func (s *Server) Throttled() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.throttled
+}
+
+// bucket is the token bucket of a `RateLimit`.
+type bucket struct {
+	RateLimit
+
+	tokens float64
+	last   time.Time
+}
+
+// take spends a token at now, returning the failure to answer with when
+// there is none left, and sets the rate limit headers of the answer.
+func (b *bucket) take(h http.Header, now time.Time) (f failure) {
+	rate := float64(b.Requests) / float64(b.Per)
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) * rate
+		if b.tokens > float64(b.Burst) {
+			b.tokens = float64(b.Burst)
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+	} else {
+		f = failure{status: http.StatusTooManyRequests, retryAfter: time.Duration((1 - b.tokens) / rate)}
+	}
+
+	reset := time.Duration((float64(b.Burst) - b.tokens) / rate)
+	h.Set("X-RateLimit-Limit", strconv.Itoa(b.Requests))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(int(b.tokens)))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(int((reset+time.Second-1)/time.Second)))
+
+	return
+}
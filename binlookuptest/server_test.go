@@ -0,0 +1,106 @@
+package binlookuptest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Add("457173", JyskeBank)
+	c := srv.Client()
+
+	b, err := c.Search(context.TODO(), "45717360")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("unexpected BIN decoded: %+v", b)
+	}
+
+	if _, err := c.Search(context.TODO(), "52882300"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("lookup of a BIN not added returned %v, want ErrNotFound", err)
+	}
+
+	if n := len(srv.Requests()); n != 2 {
+		t.Fatalf("server received %d requests, want 2", n)
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.AddBIN("45717360", &binlookup.BIN{Scheme: binlookup.SchemeVisa})
+	srv.Fail("45717360", http.StatusInternalServerError)
+
+	var herr *binlookup.HTTPError
+	if _, err := srv.Client().Search(context.TODO(), "45717360"); !errors.As(err, &herr) || herr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("failing BIN returned %v, want a 500", err)
+	}
+
+	srv.Fail("45717360", 0)
+	srv.RateLimitNext(1, time.Second)
+
+	_, err := srv.Client().Search(context.TODO(), "45717360")
+	if !errors.Is(err, binlookup.ErrRateLimited) || !errors.As(err, &herr) || herr.Header.Get("Retry-After") != "1" {
+		t.Fatalf("rate limited lookup returned %v", err)
+	}
+
+	b, err := srv.Client().Search(context.TODO(), "45717360")
+	if err != nil || b.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("lookup after the failures returned %+v, %v", b, err)
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	srv.Add("45717360", JyskeBank)
+	srv.SetLatency(time.Second)
+
+	if _, err := srv.Client(binlookup.WithTimeout(20*time.Millisecond)).Search(context.TODO(), "45717360"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("slow lookup returned %v, want the client timeout", err)
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	srv.SetRateLimit(BinlistRateLimit)
+	srv.Add("45717360", JyskeBank)
+
+	c := srv.Client()
+	for i := range 5 {
+		if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+			t.Fatalf("lookup %d within the burst failed: %v", i, err)
+		}
+	}
+
+	q, ok := c.Quota()
+	if !ok || q.Limit != 5 || q.Remaining != 0 {
+		t.Fatalf("quota reported is %+v, %v", q, ok)
+	}
+
+	var herr *binlookup.HTTPError
+	if _, err := c.Search(context.TODO(), "45717360"); !errors.As(err, &herr) || herr.Header.Get("Retry-After") != "720" {
+		t.Fatalf("lookup over the limit returned %v", err)
+	}
+
+	now = now.Add(12 * time.Minute)
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil || srv.Throttled() != 1 {
+		t.Fatalf("lookup once a token is refilled returned %v, %d throttled", err, srv.Throttled())
+	}
+}
@@ -0,0 +1,605 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached lookup stays fresh, unless changed
+// with `WithCacheTTL`.
+const DefaultCacheTTL = 24 * time.Hour
+
+// DefaultNotFoundTTL is how long a cached "not found" stays fresh, unless
+// changed with `WithNotFoundTTL`.
+const DefaultNotFoundTTL = time.Hour
+
+// Cache stores lookup results between lookups. `MemoryCache` is the one
+// kept in process; the adapters in the sub-packages of this module keep
+// them in external stores.
+//
+// A Cache doesn't decide whether an entry is fresh: the `Client` does, out
+// of its Expires, so a Cache may keep expired entries around.
+//
+// The methods are given the context of the lookup they serve: a Cache
+// reaching over the network gives up once it's done, returning its error.
+type Cache interface {
+	// Get returns the entry stored under key, with ok false when there
+	// is none.
+	Get(ctx context.Context, key string) (e Entry, ok bool, err error)
+
+	// Set stores e under key.
+	Set(ctx context.Context, key string, e Entry) error
+
+	// Delete removes the entry stored under key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// Entry is a lookup result stored in a `Cache`: a BIN, the BIN not being
+// found when NotFound is set, or upstream failing with Status.
+type Entry struct {
+	BIN      *BIN      `json:"bin,omitempty"`
+	NotFound bool      `json:"not_found,omitempty"`
+	Status   int       `json:"status,omitempty"`
+	Expires  time.Time `json:"expires"`
+
+	// ETag and LastModified are the validators upstream sent along BIN,
+	// which the `Client` revalidates it with once expired.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Fresh reports whether e can still be served at now.
+func (e Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// EntryVersion is the schema version stamped on the entries encoded by
+// `MarshalEntry`. It's bumped whenever the shape of `BIN` changes in a way
+// the entries encoded before don't decode into correctly, along with the
+// registration of the migration from the previous version.
+//
+// Version 1 is that of the entries encoded before versioning, which carry
+// no version.
+const EntryVersion = 2
+
+// ErrEntryVersion is returned decoding an entry of a version that can't be
+// migrated to `EntryVersion`, such as one encoded by a newer release. The
+// `Client` treats such entries as misses, replacing them once looked up
+// again.
+var ErrEntryVersion = errors.New("Unsupported Entry Version")
+
+// EntryMigration upgrades the JSON of an entry to the next version.
+type EntryMigration func(data []byte) ([]byte, error)
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = map[int]EntryMigration{
+		// Entries of version 1 decode as they are, the keys of their
+		// Go-cased BINs matching case-insensitively.
+		1: func(data []byte) ([]byte, error) { return data, nil },
+	}
+)
+
+// RegisterEntryMigration registers m as the migration of the entries of
+// version from to version from+1, for `UnmarshalEntry` to upgrade the
+// entries of older versions step by step instead of dropping them.
+func RegisterEntryMigration(from int, m EntryMigration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+
+	migrations[from] = m
+}
+
+// MarshalEntry encodes e for the caches keeping their entries as bytes,
+// stamped with `EntryVersion`.
+func MarshalEntry(e Entry) ([]byte, error) {
+	return json.Marshal(struct {
+		Version int `json:"v"`
+		Entry
+	}{EntryVersion, e})
+}
+
+// UnmarshalEntry decodes an entry encoded by `MarshalEntry`, migrating it
+// first when it's of an older version.
+func UnmarshalEntry(data []byte) (e Entry, err error) {
+	var v struct {
+		Version int `json:"v"`
+	}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return
+	}
+	if v.Version == 0 {
+		v.Version = 1
+	}
+
+	if v.Version > EntryVersion {
+		return e, fmt.Errorf("%w: %d", ErrEntryVersion, v.Version)
+	}
+
+	for ; v.Version < EntryVersion; v.Version++ {
+		migrationsMu.RLock()
+		m := migrations[v.Version]
+		migrationsMu.RUnlock()
+
+		if m == nil {
+			return e, fmt.Errorf("%w: %d", ErrEntryVersion, v.Version)
+		}
+
+		if data, err = m(data); err != nil {
+			return e, fmt.Errorf("Migration from Version %d Failed: %w", v.Version, err)
+		}
+	}
+
+	err = json.Unmarshal(data, &e)
+	return
+}
+
+// WithCache makes the `Client` serve lookups from cache while they're
+// fresh, and store there the results of the others. The BINs served from
+// cache are shared between lookups and mustn't be modified.
+//
+// BINs not found upstream are cached too, see `WithNotFoundTTL`; their
+// lookups fail with `ErrNotFound` while cached. Errors of cache are not
+// those of the lookups: a failing Get is a miss, and a failing Set leaves
+// the result uncached.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long the results cached by the `Client` stay
+// fresh, `DefaultCacheTTL` unless changed.
+//
+// The BINs upstream sent an ETag or Last-Modified for are revalidated once
+// expired, with a conditional request which upstream answers with 304 Not
+// Modified when they're unchanged, refreshing them for another TTL. Where
+// the quota counts 304s lightly, this keeps data fresh for less of it.
+func WithCacheTTL(d time.Duration) Option {
+	return func(c *Client) {
+		c.cacheTTL = d
+	}
+}
+
+// WithCacheJitter makes the `Client` shorten the TTL of each result it
+// caches by a random share of it up to j, between 0 and 1, so that a cache
+// filled in one burst, such as by `Client.Preload` at startup, doesn't
+// expire all at once and have its lookups stampede upstream, exactly a TTL
+// later, into a wall of 429s. No TTL is made longer than set; 0.1 spreads
+// the expirations of a day over its last 2.4 hours.
+func WithCacheJitter(j float64) Option {
+	return func(c *Client) {
+		c.cacheJitter = min(max(j, 0), 1)
+	}
+}
+
+// expires returns when a result cached now for ttl expires, ttl shortened
+// by the jitter of c.
+func (c *Client) expires(ttl time.Duration) time.Time {
+	if c.cacheJitter > 0 && ttl > 0 {
+		ttl -= time.Duration(rand.Float64() * c.cacheJitter * float64(ttl))
+	}
+
+	return time.Now().Add(ttl)
+}
+
+// WithNotFoundTTL sets how long the `Client` caches that a BIN isn't found
+// upstream, `DefaultNotFoundTTL` unless changed, so that repeated lookups
+// of unknown BINs, such as the garbage card numbers of bots probing a
+// checkout, don't each take a request. Zero disables caching them.
+func WithNotFoundTTL(d time.Duration) Option {
+	return func(c *Client) {
+		c.notFoundTTL = d
+	}
+}
+
+// WithErrorTTL makes the `Client` cache the 5xx errors of upstream for d,
+// failing the lookups of the BIN with the same `HTTPError` meanwhile, so
+// that an outage of upstream isn't made worse by every lookup retrying it.
+// Errors aren't cached unless set; d is best kept to seconds, next to the
+// days `WithCacheTTL` may be set to and the hours of `WithNotFoundTTL`.
+func WithErrorTTL(d time.Duration) Option {
+	return func(c *Client) {
+		c.errorTTL = d
+	}
+}
+
+// WithCacheTokenizer makes the `Client` key its cache by the tokens tok
+// issues instead of by the BINs. Lookups whose BIN tok fails on bypass the
+// cache.
+func WithCacheTokenizer(tok Tokenizer) Option {
+	return func(c *Client) {
+		c.cacheTokenizer = tok
+	}
+}
+
+// RangeFunc returns the prefix of bin its issuer range is defined by,
+// such as its first 6 digits when the range isn't split into 8-digit
+// BINs, with ok false when unknown.
+type RangeFunc func(bin string) (prefix string, ok bool)
+
+// WithCacheRanges makes the `Client` key its cache by the prefixes fn
+// returns rather than by the BINs, so that the BINs of a range, whatever
+// their digits, share the same entry: an 8-digit lookup defined by its
+// 6-digit prefix is answered by, and answers, the lookup of the 6 digits
+// and of the other BINs of the range, rather than each being looked up
+// upstream and cached apart, possibly answering differently. Prefixes
+// fn returns that aren't shorter prefixes of the BIN are ignored.
+//
+// The Prefix method of a `dataset.Dataset` of the issuer ranges is one,
+// for the cache of a `Client` to follow the ranges of the dataset.
+func WithCacheRanges(fn RangeFunc) Option {
+	return func(c *Client) {
+		c.cacheRanges = fn
+	}
+}
+
+// BINChange is a BIN whose data changed upstream since it was cached, as
+// found refreshing it once expired: Old is the BIN cached, New the one
+// upstream now answers with, nil when it's no longer found.
+type BINChange struct {
+	// BIN is the BIN looked up, unmasked unlike the `Event` of hooks, for
+	// the data derived of it to be found.
+	BIN string
+
+	Old, New *BIN
+}
+
+// WithChangeHandler makes the `Client` call fn whenever a lookup refreshing
+// the expired BIN of its cache finds upstream changed it, such as the name
+// of its bank or its country, for the systems downstream to invalidate the
+// data they derived of it. A 304 Not Modified never changes a BIN. fn is
+// called on the goroutine of the lookup, which it holds up until it
+// returns, and mustn't modify the BINs it's given.
+func WithChangeHandler(fn func(BINChange)) Option {
+	return func(c *Client) {
+		c.onChange = fn
+	}
+}
+
+// changed calls the change handler of c when the refresh of the BIN
+// cached as stale returned b, or err not found, and the BIN changed.
+func (c *Client) changed(bin string, stale *Entry, b *BIN, err error) {
+	if c.onChange == nil || stale == nil {
+		return
+	}
+
+	switch {
+	case err == nil && b != stale.BIN && !reflect.DeepEqual(b, stale.BIN):
+		c.onChange(BINChange{BIN: bin, Old: stale.BIN, New: b})
+	case errors.Is(err, ErrNotFound):
+		c.onChange(BINChange{BIN: bin, Old: stale.BIN})
+	}
+}
+
+// Invalidate removes the result of the lookup of bin cached by c, if any,
+// for the next lookup to go upstream, such as once its issuer is known to
+// have changed. bin is the BIN as it's looked up, the cache keyed by the
+// digits c sends of it, or their token, see `WithCacheTokenizer`.
+func (c *Client) Invalidate(ctx context.Context, bin string) error {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if err := c.validate(bin); err != nil {
+		return err
+	}
+
+	key, ok := c.cacheKey(c.sent(bin))
+	if !ok {
+		return nil
+	}
+
+	return c.cache.Delete(ctx, key)
+}
+
+// cacheKey returns the key bin is cached under, with ok false when it
+// mustn't be cached.
+func (c *Client) cacheKey(bin string) (key string, ok bool) {
+	if c.cache == nil {
+		return "", false
+	}
+
+	if c.cacheRanges != nil {
+		if p, ok := c.cacheRanges(bin); ok && len(p) < len(bin) && strings.HasPrefix(bin, p) {
+			bin = p
+		}
+	}
+
+	if c.cacheTokenizer == nil {
+		return bin, true
+	}
+
+	key, err := c.cacheTokenizer(bin)
+	return key, err == nil
+}
+
+// cached returns the fresh cached result of bin, if any: its BIN,
+// ErrNotFound or the `HTTPError` of upstream, the BIN it was cached over
+// stale instead when the error is served stale, see `WithStaleIfError`.
+// Otherwise it returns the expired entry of the BIN, if any, as stale.
+func (c *Client) cached(ctx context.Context, bin string) (b *BIN, stale *Entry, ok bool, err error) {
+	key, ok := c.cacheKey(bin)
+	if !ok {
+		return nil, nil, false, nil
+	}
+
+	defer func() {
+		if ok {
+			c.cacheHits.Add(1)
+			c.count("cache_hits")
+		} else {
+			c.cacheMisses.Add(1)
+			c.count("cache_misses")
+		}
+	}()
+
+	e, ok, gerr := c.cache.Get(ctx, key)
+	if errors.Is(gerr, ErrEntryVersion) {
+		c.cacheIncompatible.Add(1)
+		c.count("cache_incompatible")
+	}
+	if gerr != nil || !ok {
+		return nil, nil, false, nil
+	}
+
+	fresh := e.Fresh(time.Now())
+	switch {
+	case e.BIN != nil && !fresh:
+		expired := e
+		expired.Status = 0
+		return nil, &expired, false, nil
+	case !fresh:
+		return nil, nil, false, nil
+	case e.NotFound:
+		return nil, nil, true, ErrNotFound
+	case e.Status != 0:
+		err := &HTTPError{StatusCode: e.Status, Header: make(http.Header)}
+		if e.BIN != nil && c.serveStale(err) {
+			return staleBIN(e.BIN), nil, true, nil
+		}
+		return nil, nil, true, err
+	case e.BIN != nil:
+		return e.BIN, nil, true, nil
+	}
+
+	return nil, nil, false, nil
+}
+
+// store caches the result of the lookup of bin, when it's either a BIN,
+// along with its validators v, the BIN not being found, or a 5xx of
+// upstream while errors are cached. The BIN cached and expired before,
+// stale, is kept along the error, for it to be served stale and
+// revalidated once the error expires.
+func (c *Client) store(ctx context.Context, bin string, b *BIN, v validators, stale *Entry, err error) {
+	key, ok := c.cacheKey(bin)
+	if !ok {
+		return
+	}
+
+	switch {
+	case err == nil:
+		if ttl, ok := c.binTTL(v); ok {
+			c.cache.Set(ctx, key, Entry{BIN: b, Expires: c.expires(ttl), ETag: v.etag, LastModified: v.lastModified})
+		}
+	case errors.Is(err, ErrNotFound) && c.notFoundTTL > 0:
+		c.cache.Set(ctx, key, Entry{NotFound: true, Expires: c.expires(c.notFoundTTL)})
+	case c.errorTTL > 0:
+		var he *HTTPError
+		if errors.As(err, &he) && he.StatusCode >= http.StatusInternalServerError {
+			e := Entry{Status: he.StatusCode, Expires: c.expires(c.errorTTL)}
+			if stale != nil {
+				e.BIN, e.ETag, e.LastModified = stale.BIN, stale.ETag, stale.LastModified
+			}
+			c.cache.Set(ctx, key, e)
+		}
+	}
+}
+
+// CacheStats are the figures of a cache, for its TTLs and size to be tuned
+// on real numbers.
+type CacheStats struct {
+	// Hits and Misses count the lookups served out of the cache and
+	// those that weren't, expired entries included.
+	Hits   int64
+	Misses int64
+
+	// Evictions counts the entries evicted to make room for others.
+	Evictions int64
+
+	// Incompatible counts the entries of versions that couldn't be
+	// migrated, see `ErrEntryVersion`, missed and replaced once looked up
+	// again, such as those of a newer release sharing the cache during a
+	// rollout.
+	Incompatible int64
+
+	// Size is the number of entries held, NotFound of which are BINs not
+	// found upstream.
+	Size     int
+	NotFound int
+}
+
+// HitRate returns the share of lookups served out of the cache, zero
+// before any.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// CacheStatsReporter is implemented by the caches able to report their
+// `CacheStats`, such as `MemoryCache`.
+type CacheStatsReporter interface {
+	Stats() CacheStats
+}
+
+// CacheStats returns the figures of the cache of c: the lookups it served
+// out of it or not, along with the evictions and entries the cache
+// reports when it's a `CacheStatsReporter`.
+func (c *Client) CacheStats() (s CacheStats) {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if r, ok := c.cache.(CacheStatsReporter); ok {
+		s = r.Stats()
+	}
+	s.Hits, s.Misses = c.cacheHits.Load(), c.cacheMisses.Load()
+	s.Incompatible = c.cacheIncompatible.Load()
+
+	return
+}
+
+// MemoryCache is a `Cache` kept in process, evicting the least recently
+// used entries beyond its size, or those its `EvictionPolicy` picks. It's
+// safe for concurrent use.
+type MemoryCache struct {
+	size int
+
+	mu        sync.Mutex
+	policy    EvictionPolicy
+	entries   map[string]Entry
+	hits      int64
+	misses    int64
+	evictions int64
+	notFound  int
+}
+
+// NewMemoryCache returns a `MemoryCache` holding up to size entries, or
+// any number of them when size isn't positive, evicting the least
+// recently used ones, see `NewMemoryCacheWithPolicy`.
+func NewMemoryCache(size int) *MemoryCache {
+	return NewMemoryCacheWithPolicy(size, LRU())
+}
+
+// Get returns the entry stored under key, marking it as used.
+func (m *MemoryCache) Get(_ context.Context, key string) (e Entry, ok bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok = m.entries[key]
+	if !ok {
+		m.misses++
+		return
+	}
+	m.hits++
+	m.policy.Touch(key)
+
+	return e, true, nil
+}
+
+// Set stores e under key, evicting the entry the policy of m picks when m
+// is full.
+func (m *MemoryCache) Set(_ context.Context, key string, e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e.NotFound {
+		m.notFound++
+	}
+
+	if old, ok := m.entries[key]; ok {
+		m.forget(old)
+		m.entries[key] = e
+		m.policy.Touch(key)
+		return nil
+	}
+
+	full := m.size > 0 && len(m.entries) >= m.size
+	if victim := m.policy.Add(key, full); full {
+		m.forget(m.entries[victim])
+		delete(m.entries, victim)
+		m.evictions++
+	}
+	m.entries[key] = e
+
+	return nil
+}
+
+// forget uncounts the entry e, about to be removed or replaced. m.mu must
+// be held.
+func (m *MemoryCache) forget(e Entry) {
+	if e.NotFound {
+		m.notFound--
+	}
+}
+
+// Delete removes the entry stored under key.
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		m.forget(e)
+		delete(m.entries, key)
+		m.policy.Remove(key)
+	}
+
+	return nil
+}
+
+// Range calls fn with each entry of m, the last its policy would evict
+// first, the most recently used for `LRU`, without marking them as used.
+// The entries set meanwhile may be missed.
+func (m *MemoryCache) Range(ctx context.Context, fn func(key string, e Entry) bool) error {
+	type keyed struct {
+		key   string
+		entry Entry
+	}
+
+	m.mu.Lock()
+	keys := m.policy.Keys()
+	entries := make([]keyed, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, keyed{key, m.entries[key]})
+	}
+	m.mu.Unlock()
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(e.key, e.entry) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Clear removes every entry of m.
+func (m *MemoryCache) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		m.policy.Remove(key)
+	}
+	clear(m.entries)
+	m.notFound = 0
+}
+
+// Len returns the number of entries in m.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+// Stats returns the figures of m. Its hits and misses are those of Get,
+// which serves expired entries too.
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return CacheStats{Hits: m.hits, Misses: m.misses, Evictions: m.evictions, Size: len(m.entries), NotFound: m.notFound}
+}
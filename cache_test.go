@@ -0,0 +1,496 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientWithCache(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+
+	for range 3 {
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("%d requests made for 3 lookups of the same BIN, want 1", requests)
+	}
+
+	e, _, _ := cache.Get(context.TODO(), CorrectBIN)
+	e.Expires = time.Now().Add(-time.Second)
+	cache.Set(context.TODO(), CorrectBIN, e)
+
+	c.Search(context.TODO(), CorrectBIN)
+	if requests != 2 {
+		t.Fatal("expired entry was served")
+	}
+}
+
+func TestClientWithCacheTokenizer(t *testing.T) {
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithCacheTokenizer(func(bin string) (string, error) {
+		return "tok_" + bin[len(bin)-2:], nil
+	}), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	c.Search(context.TODO(), CorrectBIN)
+
+	if _, ok, _ := cache.Get(context.TODO(), CorrectBIN); ok {
+		t.Fatal("cache is keyed by the BIN")
+	}
+
+	if _, ok, _ := cache.Get(context.TODO(), "tok_"+CorrectBIN[len(CorrectBIN)-2:]); !ok {
+		t.Fatal("cache isn't keyed by the token of the BIN")
+	}
+}
+
+func TestClientWithCacheRanges(t *testing.T) {
+	var requested []string
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithCacheRanges(func(bin string) (string, bool) {
+		return "457173", strings.HasPrefix(bin, "457173")
+	}), WithMiddleware(paths(&requested, "45717360", "41111111")))
+
+	for _, bin := range []string{"45717360", "45717399", "457173", "41111111"} {
+		if _, err := c.Search(context.TODO(), bin); err != nil {
+			t.Fatalf("Search(%q) returned %v", bin, err)
+		}
+	}
+
+	if strings.Join(requested, ",") != "45717360,41111111" {
+		t.Fatalf("requested %v, want the BINs of the range looked up once", requested)
+	}
+
+	if _, ok, _ := cache.Get(context.TODO(), "457173"); !ok || cache.Len() != 2 {
+		t.Fatalf("cached %d entries, not keyed by the range", cache.Len())
+	}
+}
+
+func TestMemoryCacheEvicts(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set(context.TODO(), "a", Entry{})
+	m.Set(context.TODO(), "b", Entry{})
+	m.Get(context.TODO(), "a")
+	m.Set(context.TODO(), "c", Entry{})
+
+	if _, ok, _ := m.Get(context.TODO(), "b"); ok {
+		t.Fatal("least recently used entry wasn't evicted")
+	}
+
+	if _, ok, _ := m.Get(context.TODO(), "a"); !ok || m.Len() != 2 {
+		t.Fatalf("cache holds %d entries, recently used one evicted: %v", m.Len(), !ok)
+	}
+
+	m.Delete(context.TODO(), "a")
+	if _, ok, _ := m.Get(context.TODO(), "a"); ok {
+		t.Fatal("deleted entry is still cached")
+	}
+}
+
+func TestMarshalEntry(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	data, err := MarshalEntry(Entry{BIN: &BIN{Scheme: SchemeVisa, Bank: &Bank{Name: "Jyske Bank"}}, Expires: expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := UnmarshalEntry(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !e.Expires.Equal(expires) || e.BIN.Scheme != SchemeVisa || e.BIN.Bank.Name != "Jyske Bank" {
+		t.Fatalf("entry decoded as %+v", e)
+	}
+}
+
+func TestUnmarshalEntryVersions(t *testing.T) {
+	data, _ := MarshalEntry(Entry{BIN: &BIN{Scheme: SchemeVisa}})
+	if !strings.HasPrefix(string(data), `{"v":2,`) {
+		t.Fatalf("entry isn't stamped with its version: %s", data)
+	}
+
+	e, err := UnmarshalEntry([]byte(`{"bin":{"Scheme":"visa","Bank":{"Name":"Jyske Bank"}},"expires":"2026-01-02T03:04:05Z"}`))
+	if err != nil || e.BIN.Bank.Name != "Jyske Bank" {
+		t.Fatalf("unversioned entry decoded as %+v, %v", e, err)
+	}
+
+	if _, err := UnmarshalEntry([]byte(`{"v":99,"bin":{}}`)); !errors.Is(err, ErrEntryVersion) {
+		t.Fatalf("entry of a newer version decoded with %v", err)
+	}
+}
+
+// byteCache keeps its entries encoded, the way the caches of the
+// sub-packages do.
+type byteCache map[string][]byte
+
+func (c byteCache) Get(_ context.Context, key string) (e Entry, ok bool, err error) {
+	data, ok := c[key]
+	if !ok {
+		return
+	}
+
+	e, err = UnmarshalEntry(data)
+	return e, err == nil, err
+}
+
+func (c byteCache) Set(_ context.Context, key string, e Entry) (err error) {
+	c[key], err = MarshalEntry(e)
+	return
+}
+
+func (c byteCache) Delete(_ context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestClientReplacesIncompatibleEntries(t *testing.T) {
+	cache := byteCache{CorrectBIN: []byte(`{"v":99,"bin":{"scheme":"visa"}}`)}
+	c := New(WithCache(cache), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if s := c.CacheStats(); s.Incompatible != 1 || s.Misses != 1 {
+		t.Fatalf("stats are %+v, want 1 incompatible miss", s)
+	}
+	if !strings.HasPrefix(string(cache[CorrectBIN]), `{"v":2,`) {
+		t.Fatalf("entry wasn't replaced: %s", cache[CorrectBIN])
+	}
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil || c.CacheStats().Hits != 1 {
+		t.Fatalf("replaced entry not served: %v, %+v", err, c.CacheStats())
+	}
+}
+
+func TestRegisterEntryMigration(t *testing.T) {
+	RegisterEntryMigration(1, func(data []byte) ([]byte, error) {
+		return []byte(strings.Replace(string(data), `"brand_name"`, `"brand"`, 1)), nil
+	})
+	defer RegisterEntryMigration(1, func(data []byte) ([]byte, error) { return data, nil })
+
+	e, err := UnmarshalEntry([]byte(`{"v":1,"bin":{"brand_name":"Visa/Dankort"}}`))
+	if err != nil || e.BIN.Brand != "Visa/Dankort" {
+		t.Fatalf("entry migrated as %+v, %v", e, err)
+	}
+}
+
+func TestClientWithNetworkDisabled(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), CorrectBIN, Entry{BIN: &BIN{Scheme: SchemeVisa}, Expires: time.Now().Add(time.Hour)})
+
+	c := New(WithNetworkDisabled(), WithCache(cache), WithPreconnect(1), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+	defer c.Close()
+
+	if b, err := c.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeVisa {
+		t.Fatalf("cached lookup returned %+v, %v", b, err)
+	}
+
+	if _, err := c.Search(context.TODO(), "45717360"); !errors.Is(err, ErrNetworkDisabled) {
+		t.Fatalf("uncached lookup returned %v, want ErrNetworkDisabled", err)
+	}
+
+	if requests != 0 || !c.Capabilities().Offline {
+		t.Fatalf("%d requests made with the network disabled", requests)
+	}
+}
+
+func TestClientCachesNotFound(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithNotFoundTTL(time.Minute), WithMiddleware(count, canned(http.StatusNotFound, "")))
+
+	for range 3 {
+		if _, err := c.Search(context.TODO(), CorrectButOrphanBIN); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("lookup of an orphan BIN returned %v, want ErrNotFound", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("%d requests made for 3 lookups of the same orphan BIN, want 1", requests)
+	}
+
+	if e, _, _ := cache.Get(context.TODO(), CorrectButOrphanBIN); !e.NotFound || time.Until(e.Expires) > time.Minute {
+		t.Fatalf("orphan BIN cached as %+v", e)
+	}
+
+	New(WithCache(cache), WithNotFoundTTL(0), WithMiddleware(canned(http.StatusNotFound, ""))).Search(context.TODO(), "45717360")
+	if _, ok, _ := cache.Get(context.TODO(), "45717360"); ok {
+		t.Fatal("orphan BIN cached with caching of them disabled")
+	}
+}
+
+func TestClientCachesErrors(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithErrorTTL(time.Minute), WithMiddleware(count, canned(http.StatusServiceUnavailable, "")))
+
+	for range 3 {
+		var he *HTTPError
+		if _, err := c.Search(context.TODO(), CorrectBIN); !errors.As(err, &he) || he.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("lookup during an outage returned %v, want a 503", err)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("%d requests made for 3 lookups during an outage, want 1", requests)
+	}
+
+	if e, _, _ := cache.Get(context.TODO(), CorrectBIN); e.Status != http.StatusServiceUnavailable || time.Until(e.Expires) > time.Minute {
+		t.Fatalf("error cached as %+v", e)
+	}
+
+	New(WithCache(cache), WithErrorTTL(time.Minute), WithMiddleware(canned(http.StatusTooManyRequests, ""))).Search(context.TODO(), "45717360")
+	New(WithCache(cache), WithMiddleware(canned(http.StatusBadGateway, ""))).Search(context.TODO(), "52882301")
+	if cache.Len() != 1 {
+		t.Fatal("error other than a 5xx cached, or with caching of them disabled")
+	}
+}
+
+func TestClientCachesErrorsKeepingStale(t *testing.T) {
+	var inm []string
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			inm = append(inm, req.Header.Get("If-None-Match"))
+			return canned(http.StatusServiceUnavailable, "")(nil).Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), "45717360", Entry{BIN: &BIN{Scheme: SchemeVisa}, Expires: time.Now().Add(-time.Hour), ETag: `"v1"`})
+
+	c := New(WithCache(cache), WithErrorTTL(time.Millisecond), WithStaleIfError(FailureServerError), WithMiddleware(upstream))
+	for range 2 {
+		b, err := c.Search(context.TODO(), "45717360")
+		if err != nil || b.Scheme != SchemeVisa || !b.Meta.Stale {
+			t.Fatalf("lookup during an outage returned %+v, %v, want the stale BIN", b, err)
+		}
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	c.Search(context.TODO(), "45717360")
+
+	if len(inm) != 2 || inm[0] != `"v1"` || inm[1] != `"v1"` {
+		t.Fatalf("requests sent with If-None-Match %q, want the error to keep the validators", inm)
+	}
+}
+
+func TestClientCacheStats(t *testing.T) {
+	cache := NewMemoryCache(2)
+	c := New(WithCache(cache), WithMiddleware(canned(http.StatusNotFound, "")))
+
+	for range 3 {
+		c.Search(context.TODO(), CorrectButOrphanBIN)
+	}
+	c.Search(context.TODO(), "45717360")
+	c.Search(context.TODO(), "52882301")
+
+	s := c.CacheStats()
+	if s.Hits != 2 || s.Misses != 3 || s.Evictions != 1 || s.Size != 2 || s.NotFound != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	if s.HitRate() != 0.4 {
+		t.Fatalf("hit rate of %+v is %v, want 0.4", s, s.HitRate())
+	}
+
+	cache.Set(context.TODO(), "52882301", Entry{BIN: &BIN{}})
+	cache.Delete(context.TODO(), "45717360")
+	if s := cache.Stats(); s.Size != 1 || s.NotFound != 0 {
+		t.Fatalf("unexpected stats after replacing and deleting entries %+v", s)
+	}
+}
+
+func TestClientRevalidates(t *testing.T) {
+	var conditional []string
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			conditional = append(conditional, req.Header.Get("If-None-Match"))
+
+			resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(cannedBIN)), Request: req}
+			resp.Header.Set("ETag", `"v1"`)
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				resp.StatusCode, resp.Body = http.StatusNotModified, http.NoBody
+			}
+
+			return resp, nil
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithCacheTTL(time.Minute), WithMiddleware(upstream))
+
+	first, err := c.Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	e, _, _ := cache.Get(context.TODO(), CorrectBIN)
+	if e.ETag != `"v1"` {
+		t.Fatalf("ETag cached as %q", e.ETag)
+	}
+	e.Expires = time.Now().Add(-time.Second)
+	cache.Set(context.TODO(), CorrectBIN, e)
+
+	b, err := c.Search(context.TODO(), CorrectBIN)
+	if err != nil || b != first {
+		t.Fatalf("revalidated lookup returned %v, %v, want the cached BIN", b, err)
+	}
+
+	if strings.Join(conditional, ",") != `,"v1"` {
+		t.Fatalf("sent If-None-Match %q", conditional)
+	}
+
+	if e, _, _ := cache.Get(context.TODO(), CorrectBIN); !e.Fresh(time.Now()) {
+		t.Fatal("BIN not refreshed by the 304")
+	}
+}
+
+func TestClientCacheJitter(t *testing.T) {
+	c := New(WithCacheTTL(time.Hour), WithCacheJitter(0.5))
+
+	seen := make(map[time.Duration]bool)
+	for range 100 {
+		ttl := time.Until(c.expires(c.cacheTTL))
+		if ttl > time.Hour || ttl < 29*time.Minute {
+			t.Fatalf("jittered TTL %v out of [30m, 1h]", ttl)
+		}
+		seen[ttl.Truncate(time.Minute)] = true
+	}
+	if len(seen) < 5 {
+		t.Fatalf("expirations not spread, %d distinct minutes", len(seen))
+	}
+
+	if ttl := time.Until(New(WithCacheTTL(time.Hour)).expires(time.Hour)); ttl < 59*time.Minute {
+		t.Fatalf("TTL %v jittered without jitter set", ttl)
+	}
+}
+
+func TestClientChangeHandler(t *testing.T) {
+	bodies := []string{cannedBIN, cannedBIN, strings.Replace(cannedBIN, "Jyske Bank", "Nykredit", 1)}
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if len(bodies) == 0 {
+				return &http.Response{StatusCode: http.StatusNotFound, Header: make(http.Header), Body: http.NoBody, Request: req}, nil
+			}
+
+			body := bodies[0]
+			bodies = bodies[1:]
+			return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		})
+	}
+
+	var changes []BINChange
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(upstream), WithChangeHandler(func(ch BINChange) {
+		changes = append(changes, ch)
+	}))
+
+	expire := func() {
+		e, _, _ := cache.Get(context.TODO(), CorrectBIN)
+		e.Expires = time.Now().Add(-time.Second)
+		cache.Set(context.TODO(), CorrectBIN, e)
+	}
+
+	for range 3 {
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		expire()
+	}
+	c.Search(context.TODO(), CorrectBIN)
+
+	if len(changes) != 2 {
+		t.Fatalf("%d changes, want the bank renamed then the BIN removed", len(changes))
+	}
+
+	if ch := changes[0]; ch.BIN != CorrectBIN || ch.Old.Bank.Name != "Jyske Bank" || ch.New.Bank.Name != "Nykredit" {
+		t.Fatalf("unexpected change %+v", ch)
+	}
+
+	if ch := changes[1]; ch.Old.Bank.Name != "Nykredit" || ch.New != nil {
+		t.Fatalf("unexpected removal %+v", ch)
+	}
+}
+
+func BenchmarkClientSearchCached(b *testing.B) {
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), CorrectBIN, Entry{BIN: &BIN{Scheme: SchemeVisa}, Expires: time.Now().Add(time.Hour)})
+	c := New(WithCache(cache))
+
+	b.ReportAllocs()
+	for range b.N {
+		c.Search(context.TODO(), CorrectBIN)
+	}
+}
+
+func TestClientInvalidate(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+
+	c.Search(context.TODO(), CorrectBIN)
+	if err := c.Invalidate(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	c.Search(context.TODO(), CorrectBIN)
+
+	if requests != 2 {
+		t.Fatalf("%d requests made around an invalidation, want 2", requests)
+	}
+
+	if err := c.Invalidate(context.TODO(), IncorrectBIN); !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("invalidating a malformed BIN failed with %v, want ErrInvalidBIN", err)
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Fatalf("%d entries left once cleared", cache.Len())
+	}
+}
@@ -0,0 +1,165 @@
+// Package cachebench measures `binlookup.Cache` backends under a
+// workload shaped after that of a `binlookup.Client`: lookups hitting the
+// cache at a given ratio, the misses being filled in, from a number of
+// concurrent callers. Its `Result` encodes to JSON, to compare backends
+// on the numbers measured for a workload rather than on guesses.
+//
+// cmd/cachebench runs it against the backends of the module.
+package cachebench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Workload describes the lookups a benchmark makes.
+type Workload struct {
+	// Keys is the number of distinct BINs stored before measuring, the
+	// ones the hits are drawn from.
+	Keys int
+
+	// HitRatio is the share of the lookups made for stored BINs, between
+	// 0 and 1. The others are made for BINs never seen before, and are
+	// followed by storing them as a `Client` does.
+	HitRatio float64
+
+	// Concurrency is the number of concurrent callers.
+	Concurrency int
+
+	// Ops is the total number of lookups made.
+	Ops int
+}
+
+// Result is what a benchmark measured.
+type Result struct {
+	Backend     string  `json:"backend"`
+	Keys        int     `json:"keys"`
+	HitRatio    float64 `json:"hit_ratio"`
+	Concurrency int     `json:"concurrency"`
+	Ops         int     `json:"ops"`
+
+	// Hits and Misses are the outcomes observed, which depart from
+	// HitRatio when the backend evicts entries.
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+	Errors int `json:"errors"`
+
+	Elapsed   time.Duration `json:"elapsed_ns"`
+	OpsPerSec float64       `json:"ops_per_sec"`
+
+	// The latency percentiles of the lookups, misses including the time
+	// taken to store their entry.
+	P50 time.Duration `json:"p50_ns"`
+	P90 time.Duration `json:"p90_ns"`
+	P99 time.Duration `json:"p99_ns"`
+	Max time.Duration `json:"max_ns"`
+}
+
+// sample is the BIN stored for every key, a typical upstream answer.
+var sample = &binlookup.BIN{
+	Number:  &binlookup.Number{Length: 16, Luhn: true},
+	Scheme:  binlookup.SchemeVisa,
+	Type:    binlookup.TypeDebit,
+	Brand:   "Visa/Dankort",
+	Country: binlookup.Country{Numeric: "208", Short: "DK", Name: "Denmark", Emoji: "🇩🇰", Currency: "DKK", Lat: 56, Long: 10},
+	Bank:    &binlookup.Bank{Name: "Jyske Bank", URL: "www.jyskebank.dk", Phone: "+4589893300", City: "Hjørring"},
+}
+
+// Run stores w.Keys entries in cache, then measures w against it. It
+// stops early when ctx is done, measuring the lookups made until then.
+func Run(ctx context.Context, backend string, cache binlookup.Cache, w Workload) (r Result, err error) {
+	if w.Concurrency < 1 {
+		w.Concurrency = 1
+	}
+
+	r = Result{Backend: backend, Keys: w.Keys, HitRatio: w.HitRatio, Concurrency: w.Concurrency}
+
+	e := binlookup.Entry{BIN: sample, Expires: time.Now().Add(time.Hour)}
+	for i := range w.Keys {
+		if err = cache.Set(ctx, key(i), e); err != nil {
+			return r, fmt.Errorf("Preloading Failed: %w", err)
+		}
+	}
+
+	var (
+		wg                   sync.WaitGroup
+		next                 atomic.Int64
+		hits, misses, failed atomic.Int64
+		latencies            = make([][]time.Duration, w.Concurrency)
+	)
+
+	start := time.Now()
+	for worker := range w.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(int64(worker)))
+			for ctx.Err() == nil {
+				op := int(next.Add(1)) - 1
+				if op >= w.Ops {
+					return
+				}
+
+				k := key(w.Keys + op)
+				if w.Keys > 0 && rnd.Float64() < w.HitRatio {
+					k = key(rnd.Intn(w.Keys))
+				}
+
+				t := time.Now()
+				_, ok, err := cache.Get(ctx, k)
+				if err == nil && !ok {
+					err = cache.Set(ctx, k, e)
+				}
+				latencies[worker] = append(latencies[worker], time.Since(t))
+
+				switch {
+				case err != nil:
+					failed.Add(1)
+				case ok:
+					hits.Add(1)
+				default:
+					misses.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	r.Elapsed = time.Since(start)
+
+	var all []time.Duration
+	for _, l := range latencies {
+		all = append(all, l...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	r.Ops = len(all)
+	r.Hits, r.Misses, r.Errors = int(hits.Load()), int(misses.Load()), int(failed.Load())
+	if r.Elapsed > 0 {
+		r.OpsPerSec = float64(r.Ops) / r.Elapsed.Seconds()
+	}
+	if len(all) > 0 {
+		r.P50, r.P90, r.P99 = percentile(all, 0.50), percentile(all, 0.90), percentile(all, 0.99)
+		r.Max = all[len(all)-1]
+	}
+
+	return r, nil
+}
+
+// key returns the BIN of the i-th key, 8 digits long like the BINs
+// cached for real.
+func key(i int) string {
+	return fmt.Sprintf("4%07d", i)
+}
+
+// percentile returns the p-th percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	return sorted[int(p*float64(len(sorted)-1))]
+}
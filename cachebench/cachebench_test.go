@@ -0,0 +1,47 @@
+package cachebench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestRun(t *testing.T) {
+	w := Workload{Keys: 100, HitRatio: 0.75, Concurrency: 4, Ops: 2000}
+
+	r, err := Run(context.TODO(), "memory", binlookup.NewMemoryCache(0), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Ops != w.Ops || r.Hits+r.Misses != w.Ops || r.Errors != 0 {
+		t.Fatalf("unexpected result %+v", r)
+	}
+
+	if ratio := float64(r.Hits) / float64(r.Ops); ratio < 0.65 || ratio > 0.85 {
+		t.Fatalf("hit ratio measured is %v, want about %v", ratio, w.HitRatio)
+	}
+
+	if r.P50 > r.P99 || r.P99 > r.Max || r.OpsPerSec <= 0 {
+		t.Fatalf("inconsistent latencies %+v", r)
+	}
+
+	if _, err := json.Marshal(r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failingCache struct{ binlookup.Cache }
+
+func (failingCache) Set(context.Context, string, binlookup.Entry) error {
+	return errors.New("read only")
+}
+
+func TestRunPreloadFails(t *testing.T) {
+	if _, err := Run(context.TODO(), "failing", failingCache{}, Workload{Keys: 1, Ops: 1}); err == nil {
+		t.Fatal("preloading into a failing cache succeeded")
+	}
+}
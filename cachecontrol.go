@@ -0,0 +1,76 @@
+package binlookup
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithCacheControl makes the `Client` cache the BINs upstream answers
+// with for as long as its Cache-Control max-age, or its Expires, tells
+// them fresh for, clamped between minTTL and maxTTL, instead of for the
+// TTL of `WithCacheTTL`, which stays that of the answers telling none. A
+// maxTTL of zero leaves the TTL unbounded above. Answers upstream marks
+// no-store or no-cache are cached for minTTL only, and not at all when
+// it's zero.
+func WithCacheControl(minTTL, maxTTL time.Duration) Option {
+	return func(c *Client) {
+		c.cacheControl = true
+		c.minTTL, c.maxTTL = minTTL, maxTTL
+	}
+}
+
+// freshness returns how long the response of header h is fresh for, out
+// of its Cache-Control or Expires, with ok false when they tell nothing.
+func freshness(h http.Header) (ttl time.Duration, ok bool) {
+	if cc := h.Get("Cache-Control"); cc != "" {
+		for _, d := range strings.Split(cc, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache":
+				return 0, true
+			case "max-age":
+				if s, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil {
+					ttl, ok = time.Duration(max(s, 0))*time.Second, true
+				}
+			}
+		}
+		if ok {
+			return
+		}
+	}
+
+	if e := h.Get("Expires"); e != "" {
+		// An invalid Expires, such as 0, tells of a response already
+		// expired.
+		expires, err := http.ParseTime(e)
+		if err != nil {
+			return 0, true
+		}
+
+		now := time.Now()
+		if date, err := http.ParseTime(h.Get("Date")); err == nil {
+			now = date
+		}
+
+		return max(expires.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// binTTL returns how long c caches a BIN upstream answered with along
+// the validators v, with ok false when it's not to be cached.
+func (c *Client) binTTL(v validators) (ttl time.Duration, ok bool) {
+	if !c.cacheControl || !v.fresh {
+		return c.cacheTTL, true
+	}
+
+	ttl = max(v.ttl, c.minTTL)
+	if c.maxTTL > 0 {
+		ttl = min(ttl, c.maxTTL)
+	}
+
+	return ttl, ttl > 0
+}
@@ -0,0 +1,78 @@
+package binlookup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFreshness(t *testing.T) {
+	date := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		header http.Header
+		ttl    time.Duration
+		ok     bool
+	}{
+		{http.Header{"Cache-Control": {"public, max-age=3600"}}, time.Hour, true},
+		{http.Header{"Cache-Control": {"max-age=60"}, "Expires": {date.Add(time.Hour).Format(http.TimeFormat)}}, time.Minute, true},
+		{http.Header{"Cache-Control": {"no-store"}}, 0, true},
+		{http.Header{"Cache-Control": {"public"}, "Expires": {date.Add(2 * time.Hour).Format(http.TimeFormat)}, "Date": {date.Format(http.TimeFormat)}}, 2 * time.Hour, true},
+		{http.Header{"Expires": {"0"}}, 0, true},
+		{http.Header{"Cache-Control": {"max-age=oops"}}, 0, false},
+		{http.Header{}, 0, false},
+	} {
+		if ttl, ok := freshness(tc.header); ttl != tc.ttl || ok != tc.ok {
+			t.Errorf("freshness(%v) = %v, %v, want %v, %v", tc.header, ttl, ok, tc.ttl, tc.ok)
+		}
+	}
+}
+
+func TestClientWithCacheControl(t *testing.T) {
+	cacheControl := ""
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(cannedBIN)), Request: req}
+			if cacheControl != "" {
+				resp.Header.Set("Cache-Control", cacheControl)
+			}
+			return resp, nil
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(upstream), WithCacheTTL(24*time.Hour), WithCacheControl(time.Minute, time.Hour))
+
+	for _, tc := range []struct {
+		cacheControl string
+		ttl          time.Duration
+	}{
+		{"max-age=600", 10 * time.Minute},
+		{"max-age=5", time.Minute},
+		{"max-age=86400", time.Hour},
+		{"", 24 * time.Hour},
+		{"no-store", time.Minute},
+	} {
+		cacheControl = tc.cacheControl
+		cache.Delete(context.TODO(), CorrectBIN)
+
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+
+		e, _, _ := cache.Get(context.TODO(), CorrectBIN)
+		if ttl := time.Until(e.Expires); ttl > tc.ttl || ttl < tc.ttl-time.Second {
+			t.Errorf("%q cached for %v, want %v", tc.cacheControl, ttl, tc.ttl)
+		}
+	}
+
+	cacheControl = "no-store"
+	cache.Delete(context.TODO(), CorrectBIN)
+	New(WithCache(cache), WithMiddleware(upstream), WithCacheControl(0, 0)).Search(context.TODO(), CorrectBIN)
+	if _, ok, _ := cache.Get(context.TODO(), CorrectBIN); ok {
+		t.Fatal("no-store BIN cached without a minimum TTL")
+	}
+}
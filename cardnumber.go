@@ -0,0 +1,94 @@
+package binlookup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schemeLengths are the lengths of the card numbers of each scheme.
+var schemeLengths = map[Scheme][]int{
+	SchemeVisa:       {13, 16, 19},
+	SchemeMastercard: {16},
+	SchemeAmex:       {15},
+	SchemeDiscover:   {16, 17, 18, 19},
+	SchemeDiners:     {14, 15, 16, 17, 18, 19},
+	SchemeJCB:        {16, 17, 18, 19},
+	SchemeUnionPay:   {16, 17, 18, 19},
+	SchemeMaestro:    {12, 13, 14, 15, 16, 17, 18, 19},
+	SchemeMir:        {16, 17, 18, 19},
+	SchemeRuPay:      {16},
+	SchemeElo:        {16},
+	SchemeTroy:       {16},
+	SchemeDankort:    {16},
+}
+
+// ValidateCardNumber checks the full card number pan, which may be grouped
+// with spaces or dashes, the way a checkout would before any lookup: its
+// digits, the Luhn checksum, and the lengths and prefixes the scheme its
+// first digits tell allows, such as 13, 16 or 19 digits for Visa and 15
+// for Amex. The numbers of unknown schemes must be 12 to 19 digits long.
+// UnionPay, some of whose cards don't use it, is exempted of the Luhn
+// checksum.
+//
+// The error is an `ErrInvalidPAN` one, which never includes the card
+// number.
+func ValidateCardNumber(pan string) error {
+	_, err := validateCardNumber(pan)
+	return err
+}
+
+// ValidateCardNumberFor checks pan as `ValidateCardNumber` does, and
+// against b, the result of the lookup of its BIN, as well: the length and
+// the use of the Luhn checksum b tells, and b's scheme when known.
+func ValidateCardNumberFor(pan string, b *BIN) error {
+	digits, err := validateCardNumber(pan)
+	if err != nil || b == nil {
+		return err
+	}
+
+	if !b.MatchesPAN(digits) {
+		return fmt.Errorf("%w: PAN doesn't match the length or checksum of its BIN", ErrInvalidPAN)
+	}
+
+	if detected, ok := DetectScheme(digits); ok && b.Scheme != "" && b.Scheme != detected {
+		return fmt.Errorf("%w: PAN prefix is that of %v, not of %v", ErrInvalidPAN, detected.DisplayName(), b.Scheme.DisplayName())
+	}
+
+	return nil
+}
+
+// validateCardNumber returns the digits of pan once validated.
+func validateCardNumber(pan string) (string, error) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(pan)
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("%w: PAN must only hold digits, spaces and dashes", ErrInvalidPAN)
+	}
+
+	if digits[0] == '0' {
+		return "", fmt.Errorf("%w: PAN must not start with 0", ErrInvalidPAN)
+	}
+
+	scheme, known := DetectScheme(digits)
+	lengths, ok := schemeLengths[scheme]
+	if !known || !ok {
+		lengths = []int{12, 13, 14, 15, 16, 17, 18, 19}
+	}
+
+	valid := false
+	for _, n := range lengths {
+		valid = valid || len(digits) == n
+	}
+	if !valid {
+		if known {
+			return "", fmt.Errorf("%w: %v PAN holds %d digits, not one of %v", ErrInvalidPAN, scheme.DisplayName(), len(digits), lengths)
+		}
+
+		return "", fmt.Errorf("%w: PAN holds %d digits, not 12 to 19", ErrInvalidPAN, len(digits))
+	}
+
+	if scheme != SchemeUnionPay && !Luhn(digits) {
+		return "", fmt.Errorf("%w: PAN fails the Luhn checksum", ErrInvalidPAN)
+	}
+
+	return digits, nil
+}
@@ -0,0 +1,57 @@
+package binlookup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCardNumber(t *testing.T) {
+	for _, pan := range []string{
+		"4111 1111 1111 1111",
+		"4222222222222",
+		"3782-822463-10005",
+		"30569309025904",
+		"5555555555554444",
+		"6200000000000005",
+	} {
+		if err := ValidateCardNumber(pan); err != nil {
+			t.Fatalf("ValidateCardNumber(%q) returned %v", pan, err)
+		}
+	}
+
+	for _, pan := range []string{
+		"",
+		"4111x111111111111",
+		"0111111111111111",
+		"4111111111111112",
+		"378282246310005" + "0",
+		"55555555555544",
+		"12345678901",
+	} {
+		err := ValidateCardNumber(pan)
+		if !errors.Is(err, ErrInvalidPAN) {
+			t.Fatalf("ValidateCardNumber(%q) returned %v, want ErrInvalidPAN", pan, err)
+		}
+
+		if pan != "" && strings.Contains(err.Error(), pan) {
+			t.Fatalf("error holds the card number: %v", err)
+		}
+	}
+}
+
+func TestValidateCardNumberFor(t *testing.T) {
+	visa := &BIN{Scheme: SchemeVisa, Number: &Number{Length: 16, Luhn: true}}
+
+	if err := ValidateCardNumberFor("4111111111111111", visa); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateCardNumberFor("4222222222222", visa); !errors.Is(err, ErrInvalidPAN) {
+		t.Fatalf("PAN of another length than its BIN's returned %v", err)
+	}
+
+	if err := ValidateCardNumberFor("5555555555554444", visa); !errors.Is(err, ErrInvalidPAN) {
+		t.Fatalf("Mastercard PAN checked against a Visa BIN returned %v", err)
+	}
+}
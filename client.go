@@ -0,0 +1,729 @@
+package binlookup
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Doer is the interface implemented by *http.Client that is used by
+// `Client` to send upstream requests. Middleware wrap it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as `Doer`.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a `Doer` with additional behaviour such as auth,
+// logging, metrics or fault injection.
+type Middleware func(next Doer) Doer
+
+// Version is the version of the package, sent in the default User-Agent.
+const Version = "0.1.0"
+
+// DefaultUserAgent identifies the package to upstream.
+const DefaultUserAgent = "binlookup-go/" + Version + " (+https://github.com/0xbkt/binlookup-go)"
+
+// upstreamURL is the base URL lookups are made against.
+const upstreamURL = "https://lookup.binlist.net"
+
+// DefaultTimeout bounds the lookups whose context carries no deadline.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultAPIVersion is the upstream API version requested by default
+// through the Accept-Version header.
+const DefaultAPIVersion = 3
+
+// Client makes BIN lookup requests to upstream. It is safe for
+// concurrent use once constructed with `New`.
+type Client struct {
+	baseURL        string
+	apiVersion     int
+	userAgent      string
+	header         http.Header
+	signer         Signer
+	timeout        time.Duration
+	retries        int
+	backoff        Backoff
+	retryPolicy    RetryPolicy
+	hooks          Hooks
+	logger         *slog.Logger
+	logLevel       slog.Level
+	locale         string
+	translator     CountryTranslator
+	localCache     bool
+	perAttempt     time.Duration
+	httpClient     *http.Client
+	redirectPolicy RedirectPolicy
+	doer           Doer
+	middleware     []Middleware
+	ipFamily       IPFamily
+	dnsCache       *DNSCache
+	resolver       *net.Resolver
+	dial           DialFunc
+	proxy          func(*http.Request) (*url.URL, error)
+	tlsConfig      *tls.Config
+	pins           []string
+	clientCert     func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+	rootCAs        *x509.CertPool
+	preconnect     int
+
+	connectTimeout        time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	retryElapsed          time.Duration
+	retryBackoff          time.Duration
+
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	keepAlive           time.Duration
+	http2Only           bool
+
+	roundTripper http.RoundTripper
+
+	offline     bool
+	dryRun      bool
+	strict      bool
+	complete    bool
+	lenient     bool
+	fields      Field
+	jsonDecoder JSONDecoder
+	binDigits   BINDigits
+	limiter     *limiter
+	queue       *queue
+
+	batchConcurrency int
+	latency          atomic.Int64 // moving average of upstream lookups, in ns
+	upstream         atomic.Int64 // lookups gone upstream, see `WithPreconnect`
+
+	cache             Cache
+	cacheTTL          time.Duration
+	cacheJitter       float64
+	cacheControl      bool
+	minTTL            time.Duration
+	maxTTL            time.Duration
+	notFoundTTL       time.Duration
+	errorTTL          time.Duration
+	cacheTokenizer    Tokenizer
+	cacheRanges       RangeFunc
+	staleIfError      Failure
+	cacheHits         atomic.Int64
+	cacheMisses       atomic.Int64
+	cacheIncompatible atomic.Int64
+	vars              *expvar.Map
+	onChange          func(BINChange)
+
+	keys         *apiKeys
+	quotaFetcher QuotaFetcher
+
+	// quotaSaved is the state of the quotas saved last in quotaStore.
+	quotaStore        QuotaStore
+	quotaSaveInterval time.Duration
+	quotaSaveMu       sync.Mutex
+	quotaSaved        []byte
+
+	// reconf is held by the lookups, and locked by `Client.Reconfigure`
+	// for the configuration to change between them.
+	reconf sync.RWMutex
+
+	mu       sync.Mutex
+	closed   bool
+	quota    *sharedQuota
+	inflight sync.WaitGroup
+	done     chan struct{}
+
+	errorSamples []errorSample
+
+	// lookups is canceled once the grace period of Close is over, which
+	// cancels the lookups still in progress.
+	lookups       context.Context
+	cancelLookups context.CancelFunc
+	gracePeriod   time.Duration
+
+	// opts are the options c was configured with, and derived is set on
+	// the clients of `Client.With`, which share the HTTP client of
+	// another.
+	opts    []Option
+	derived bool
+}
+
+// Option configures a `Client`.
+type Option func(*Client)
+
+// WithMiddleware registers middleware wrapping every upstream request.
+// Middleware run in the order they are registered, so the first one is
+// the outermost.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// WithBaseURL points the `Client` at another upstream, such as a
+// self-hosted mirror of binlist, instead of lookup.binlist.net. Lookups
+// are made against u followed by a slash and the BIN.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(u, "/")
+	}
+}
+
+// WithAPIVersion sets the upstream API version sent in the Accept-Version
+// header of every lookup. It defaults to `DefaultAPIVersion`, which is the
+// version `BIN` models.
+func WithAPIVersion(v int) Option {
+	return func(c *Client) {
+		c.apiVersion = v
+	}
+}
+
+// WithUserAgent sets the User-Agent sent upstream, `DefaultUserAgent`
+// unless changed, so that requests identify the application making them.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHeader makes the `Client` send the header key with value upstream,
+// such as a tracing ID or the token of a gateway, replacing the value it
+// would send otherwise, User-Agent included. The API keys of `WithAPIKeys`
+// take precedence over the header of the same name.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Set(key, value)
+	}
+}
+
+// WithHeaders makes the `Client` send the headers of h upstream, the way
+// `WithHeader` does for each.
+func WithHeaders(h http.Header) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		for k, v := range h {
+			c.header[http.CanonicalHeaderKey(k)] = slices.Clone(v)
+		}
+	}
+}
+
+// WithTimeout sets the timeout applied to the lookups whose context has
+// no deadline, `DefaultTimeout` unless changed. Zero disables it.
+//
+// The deadline of a lookup's context always takes precedence, which is
+// how a single `Client` serves both latency critical and relaxed callers:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+//	defer cancel()
+//	b, err := c.Search(ctx, bin)
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// DefaultCloseGracePeriod is how long `Close` lets the lookups in
+// progress finish before canceling them, unless changed with
+// `WithCloseGracePeriod`.
+const DefaultCloseGracePeriod = 5 * time.Second
+
+// WithCloseGracePeriod sets how long `Close` lets the lookups in progress
+// finish before canceling them. Zero cancels them at once.
+func WithCloseGracePeriod(d time.Duration) Option {
+	return func(c *Client) {
+		c.gracePeriod = d
+	}
+}
+
+// WithNetworkDisabled makes the `Client` serve lookups from its cache only,
+// never making any network call, not even to keep connections warm. The
+// lookups missing the cache fail with `ErrNetworkDisabled`. This is for
+// the environments where egress to third parties is prohibited, along
+// with a cache filled beforehand or a local dataset chained in front of
+// the `Client`.
+func WithNetworkDisabled() Option {
+	return func(c *Client) {
+		c.offline = true
+	}
+}
+
+// WithDryRun makes the `Client` go through every step of a lookup but the
+// request: validation, the cache, the queue and the rate limit, failing
+// the lookups missing the cache with `ErrDryRun` instead of sending them.
+// This is for load testing an integration without spending the quota of
+// upstream, and for staging environments without egress.
+func WithDryRun() Option {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+// New returns a `Client` configured with the given options.
+func New(opts ...Option) *Client {
+	c := newClient()
+	c.apply(opts)
+	c.start(context.Background())
+
+	c.httpClient = &http.Client{Transport: c.transport(), CheckRedirect: c.redirectPolicy}
+	c.chain()
+
+	if c.quotaStore != nil {
+		c.loadQuota()
+		go c.persistQuota()
+	}
+
+	if c.preconnect > 0 && !c.offline && !c.dryRun {
+		go c.keepWarm()
+	}
+
+	return c
+}
+
+// With returns a `Client` configured as c along with opts, such as
+// another timeout or cache for a call site, which shares the connections
+// of c, its rate limit, API keys, quota and queue rather than having
+// pools and limits of its own. The options of the transport, such as
+// `WithProxy` or `WithTLSConfig`, and those of the limits, such as
+// `WithRateLimit`, `WithAPIKeys` or `WithQueue`, are thereby those of c
+// whatever opts set; middleware added by opts runs after that of c. It's
+// cheap enough to be made per call site, though not per lookup.
+//
+// The lookups of the derived `Client` end along those of c once it's
+// closed. Closing the derived one leaves c and its connections be.
+func (c *Client) With(opts ...Option) *Client {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	d := newClient()
+	d.apply(c.opts)
+	d.apply(opts)
+	d.limiter, d.queue, d.keys, d.quota = c.limiter, c.queue, c.keys, c.quota
+	d.start(c.lookups)
+
+	d.derived = true
+	d.httpClient = c.httpClient
+	d.chain()
+
+	return d
+}
+
+// newClient returns a `Client` of the default configuration.
+func newClient() *Client {
+	return &Client{
+		baseURL:             upstreamURL,
+		apiVersion:          DefaultAPIVersion,
+		userAgent:           DefaultUserAgent,
+		timeout:             DefaultTimeout,
+		connectTimeout:      DefaultConnectTimeout,
+		tlsHandshakeTimeout: DefaultTLSHandshakeTimeout,
+		maxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		idleConnTimeout:     DefaultIdleConnTimeout,
+		keepAlive:           DefaultKeepAlive,
+		fields:              AllFields,
+		cacheTTL:            DefaultCacheTTL,
+		notFoundTTL:         DefaultNotFoundTTL,
+		backoff:             defaultBackoff,
+		gracePeriod:         DefaultCloseGracePeriod,
+		batchConcurrency:    DefaultBatchConcurrency,
+		quota:               new(sharedQuota),
+		done:                make(chan struct{}),
+	}
+}
+
+// apply configures c with opts, recorded for `Client.With` to derive
+// clients of the same configuration.
+func (c *Client) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.opts = append(slices.Clip(c.opts), opts...)
+}
+
+// start readies c for lookups, which end along parent.
+func (c *Client) start(parent context.Context) {
+	_, c.localCache = c.cache.(*MemoryCache)
+
+	c.lookups, c.cancelLookups = context.WithCancel(parent)
+}
+
+// chain wraps the HTTP client of c in its middleware.
+func (c *Client) chain() {
+	c.doer = c.httpClient
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		c.doer = c.middleware[i](c.doer)
+	}
+}
+
+// Close stops the background work of c and makes further lookups fail
+// with `ErrClosed`. It lets the lookups in progress finish within the
+// grace period of c, cancels those still running past it, then closes
+// the idle connections so none of them linger. Shutdown therefore takes
+// about the grace period at most. Moving to another base URL or proxy
+// without closing c is done with `Client.Reconfigure`.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	close(c.done)
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	c.reconf.RLock()
+	grace := c.gracePeriod
+	c.reconf.RUnlock()
+
+	t := time.NewTimer(grace)
+	select {
+	case <-drained:
+		t.Stop()
+	case <-t.C:
+		c.cancelLookups()
+		<-drained
+	}
+
+	c.cancelLookups()
+	if !c.derived {
+		c.httpClient.CloseIdleConnections()
+
+		if c.quotaStore != nil {
+			c.saveQuota()
+		}
+	}
+
+	return nil
+}
+
+// acquire registers a lookup in progress, failing once c is closed.
+// The lookup must call c.inflight.Done when it's over.
+func (c *Client) acquire() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The lookups of a client of `Client.With` end once the client it
+	// derives from is closed.
+	if c.closed || c.lookups.Err() != nil {
+		return ErrClosed
+	}
+	c.inflight.Add(1)
+
+	return nil
+}
+
+// Search makes a BIN lookup request to upstream, see the package level
+// `Search` for the errors returned. The lookup is bounded by the deadline
+// of ctx, or by the timeout of c when ctx has none. Fresh results cached
+// by c are served without any request, see `WithCache`.
+func (c *Client) Search(ctx context.Context, bin string) (*BIN, error) {
+	return c.search(ctx, bin, false)
+}
+
+// errNotCached is returned by `Client.search` for the BINs it's to serve
+// from cache only and can't.
+var errNotCached = errors.New("Not Cached")
+
+// search looks bin up as `Client.Search` does, from cache only when
+// cacheOnly is set, returning errNotCached when it isn't cached.
+func (c *Client) search(ctx context.Context, bin string, cacheOnly bool) (b *BIN, err error) {
+	if err = c.acquire(); err != nil {
+		return
+	}
+	defer c.inflight.Done()
+
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	c.count("lookups")
+	var (
+		outcome = "none"
+		begin   = time.Now()
+		input   = bin
+	)
+	defer func() {
+		// The BINs not cached are left to be looked up, not failed.
+		if err == errNotCached {
+			return
+		}
+		if err != nil && c.lookups.Err() != nil {
+			err = fmt.Errorf("%w: %w", ErrClosed, err)
+		}
+		// Inputs longer than a BIN may be whole card numbers, none of
+		// which is to be quoted past its BIN, whatever failed.
+		if err != nil && len(input) > 8 {
+			err = &redactedError{err: err}
+		}
+		c.countError(err)
+		c.log(ctx, "Lookup", bin, err, slog.String("cache", outcome), slog.Duration("duration", time.Since(begin)))
+
+		if b != nil {
+			b = c.localize(b)
+		}
+
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			hook(c.hooks.OnError, bin, Event{Err: err})
+
+			if !errors.Is(err, ErrDryRun) {
+				c.recordError(bin, err)
+			}
+		}
+	}()
+
+	if err = c.validate(bin); err != nil {
+		return
+	}
+
+	// The digits past those of a BIN, which may be those of a whole card
+	// number, are never sent nor cached.
+	bin = c.sent(bin)
+
+	// A cache in process answers before the context of the lookup is set
+	// up, which costs allocations the lookups it serves needn't.
+	var (
+		stale *Entry
+		ok    bool
+	)
+	if c.localCache {
+		b, stale, ok, err = c.cached(ctx, bin)
+		if outcome = c.cacheOutcome(ok, stale); ok {
+			hook(c.hooks.OnCacheHit, bin, Event{Err: err})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(c.lookups, cancel)()
+
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if !c.localCache {
+		b, stale, ok, err = c.cached(ctx, bin)
+		if outcome = c.cacheOutcome(ok, stale); ok {
+			hook(c.hooks.OnCacheHit, bin, Event{Err: err})
+			return
+		}
+	}
+
+	if cacheOnly {
+		return nil, errNotCached
+	}
+
+	if c.offline {
+		return nil, ErrNetworkDisabled
+	}
+
+	if c.queue != nil {
+		if err = c.queue.acquire(ctx); err != nil {
+			return
+		}
+		defer c.queue.release()
+	}
+
+	c.upstream.Add(1)
+	start := time.Now()
+	b, v, err := c.retry(ctx, bin, stale)
+	fellBack := c.fallback(bin, err)
+	if fellBack {
+		hook(c.hooks.OnFailover, bin, Event{Err: err})
+		b, v, err = c.retry(ctx, bin[:6], nil)
+	}
+	if stale != nil && c.serveStale(err) {
+		// The error is cached along with the stale BIN, which the lookups
+		// meanwhile are answered with.
+		c.store(ctx, bin, nil, validators{}, stale, err)
+		c.log(ctx, "Serving Stale", bin, err)
+		outcome = "stale"
+		return staleBIN(stale.BIN), nil
+	}
+	if err == nil || errors.Is(err, ErrNotFound) {
+		c.observeLatency(time.Since(start))
+	}
+	c.store(ctx, bin, b, v, stale, err)
+	if fellBack && err == nil {
+		c.store(ctx, bin[:6], b, v, nil, err)
+	}
+	c.changed(bin, stale, b, err)
+
+	return
+}
+
+// send sends req to upstream as the requests of lookups are, see
+// `Client.prepare`, through the middleware.
+func (c *Client) send(req *http.Request) (resp *http.Response, key *apiKey, err error) {
+	if key, err = c.prepare(req); err != nil {
+		return
+	}
+	resp, err = c.doer.Do(req)
+
+	return
+}
+
+// prepare readies req to be sent to upstream: with the headers of c, its
+// API key, returned to be released once done, and signed, counted
+// towards the quota.
+func (c *Client) prepare(req *http.Request) (key *apiKey, err error) {
+	req.Header.Set("Accept-Version", strconv.Itoa(c.apiVersion))
+	req.Header.Set("User-Agent", c.userAgent)
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+
+	if key = c.keys.pick(); key != nil {
+		req.Header.Set(c.keys.header, key.value)
+	}
+
+	if c.signer != nil {
+		if err = c.signer.Sign(req); err != nil {
+			return key, fmt.Errorf("Signing the Request Failed: %w", err)
+		}
+	}
+
+	c.countRequest(key)
+
+	return
+}
+
+// validators are those of the response headers the result of a lookup
+// is revalidated with, along with how long it's fresh for when ttl is
+// told, see `WithCacheControl`.
+type validators struct {
+	etag         string
+	lastModified string
+
+	ttl   time.Duration
+	fresh bool
+
+	// resp is the response the validators are those of, its body closed,
+	// for the policy of the retries to go by, see `WithRetryPolicy`.
+	resp *http.Response
+}
+
+// attempt makes a single lookup request to upstream, conditional on stale
+// having changed when it's the BIN cached and expired, in which case
+// stale is what an answer of 304 Not Modified returns.
+func (c *Client) attempt(ctx context.Context, bin string, stale *Entry) (b *BIN, v validators, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+bin, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	if stale != nil {
+		if stale.ETag != "" {
+			req.Header.Set("If-None-Match", stale.ETag)
+		}
+		if stale.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stale.LastModified)
+		}
+	}
+
+	resp, key, err := c.send(req)
+	if key != nil {
+		defer c.keys.release(key)
+	}
+	if err != nil {
+		return
+	}
+	defer func() { resp.Body.Close() }()
+
+	compressed, err := decompress(resp)
+	if err != nil {
+		err = fmt.Errorf("Decompressing Failed: %w", err)
+		return
+	}
+
+	c.recordQuota(key, resp)
+	v = validators{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified"), resp: resp}
+	v.ttl, v.fresh = freshness(resp.Header)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusNotModified:
+		if stale != nil {
+			// 304s needn't repeat the validators.
+			if v.etag == "" && v.lastModified == "" {
+				v.etag, v.lastModified = stale.ETag, stale.LastModified
+			}
+
+			return stale.BIN, v, nil
+		}
+		fallthrough
+	default:
+		err = fmt.Errorf("Failed Due to Status Code Error: %w", newHTTPError(resp, bin))
+		return
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err = buf.ReadFrom(resp.Body); err != nil {
+		return
+	}
+
+	switch {
+	case c.fields&AllFields != AllFields:
+		b, err = DecodeFields(buf.Bytes(), c.fields)
+	case c.jsonDecoder != nil:
+		b = new(BIN)
+		if err = b.decode(c.jsonDecoder, buf.Bytes()); err != nil {
+			b = nil
+		}
+	default:
+		err = json.Unmarshal(buf.Bytes(), &b)
+	}
+	if err != nil && c.lenient {
+		b, err = decodeLenient(buf.Bytes(), c.fields)
+	}
+
+	var malformed *MalformedError
+	if err != nil && !errors.As(err, &malformed) {
+		err = fmt.Errorf("JSON Unmarshaling Failed: %w", err)
+		return
+	}
+	if malformed == nil {
+		if err = c.checkComplete(b); err != nil {
+			b = nil
+			return
+		}
+	}
+	b.Meta.Provider = c.name()
+	b.Meta.Compressed = compressed
+
+	return
+}
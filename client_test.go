@@ -0,0 +1,377 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cannedBIN = `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"debit","brand":"Visa/Dankort","prepaid":false,"country":{"numeric":"208","alpha2":"DK","name":"Denmark","emoji":"🇩🇰","currency":"DKK","latitude":56,"longitude":10},"bank":{"name":"Jyske Bank","url":"www.jyskebank.dk","phone":"+4589893300","city":"Hjørring"}}`
+
+// canned short-circuits the chain and answers every request with body.
+func canned(status int, body string) Middleware {
+	return func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+func TestClientWithMiddleware(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	c := New(WithMiddleware(trace("first"), trace("second")), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	b, err := c.Search(context.Background(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != "visa" || b.Country.Short != "DK" {
+		t.Fatalf("unexpected BIN decoded: %+v", b)
+	}
+
+	if strings.Join(order, ",") != "first,second" {
+		t.Fatalf("middleware ran in order %v", order)
+	}
+}
+
+func TestClientWithMiddlewareStatusCode(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusNotFound, "")))
+
+	_, err := c.Search(context.Background(), CorrectButOrphanBIN)
+	if err == nil {
+		t.Fatalf("%v is an orphan BIN but Search returned nil for error.", CorrectButOrphanBIN)
+	}
+}
+
+func TestClientAcceptVersion(t *testing.T) {
+	var got []string
+	record := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			got = append(got, req.Header.Get("Accept-Version"))
+			return next.Do(req)
+		})
+	}
+
+	New(WithMiddleware(record, canned(http.StatusOK, cannedBIN))).Search(context.TODO(), CorrectBIN)
+	New(WithAPIVersion(4), WithMiddleware(record, canned(http.StatusOK, cannedBIN))).Search(context.TODO(), CorrectBIN)
+
+	if strings.Join(got, ",") != "3,4" {
+		t.Fatalf("Accept-Version headers sent were %v, want [3 4]", got)
+	}
+}
+
+func TestClientCloseDrains(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-release
+			return next.Do(req)
+		})
+	}
+
+	c := New(WithMiddleware(slow, canned(http.StatusOK, cannedBIN)))
+
+	errc := make(chan error)
+	go func() {
+		_, err := c.Search(context.TODO(), CorrectBIN)
+		errc <- err
+	}()
+	<-started
+
+	closed := make(chan struct{})
+	go func() {
+		c.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the lookup in progress finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-errc; err != nil {
+		t.Fatalf("lookup in progress failed: %v", err)
+	}
+	<-closed
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrClosed) {
+		t.Fatalf("lookup after Close returned %v, want ErrClosed", err)
+	}
+}
+
+func TestClientWithBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mirror/"+CorrectBIN {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	b, err := New(WithBaseURL(srv.URL+"/mirror/")).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("unexpected BIN decoded: %+v", b)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithTimeout(20*time.Millisecond))
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("lookup without deadline returned %v, want the client timeout", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	c.Search(ctx, CorrectBIN)
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("lookup returned after %v, ignoring its longer deadline", elapsed)
+	}
+}
+
+func TestClientUserAgent(t *testing.T) {
+	var got []string
+	record := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			got = append(got, req.Header.Get("User-Agent"))
+			return next.Do(req)
+		})
+	}
+
+	New(WithMiddleware(record, canned(http.StatusOK, cannedBIN))).Search(context.TODO(), CorrectBIN)
+	New(WithUserAgent("acme-checkout/2.1"), WithMiddleware(record, canned(http.StatusOK, cannedBIN))).Search(context.TODO(), CorrectBIN)
+
+	if len(got) != 2 || !strings.HasPrefix(got[0], "binlookup-go/"+Version) || got[1] != "acme-checkout/2.1" {
+		t.Fatalf("User-Agent headers sent were %q", got)
+	}
+}
+
+func TestClientWithHeaders(t *testing.T) {
+	var got http.Header
+	record := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Clone()
+			return next.Do(req)
+		})
+	}
+
+	h := http.Header{"x-gateway-token": {"secret"}, "Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+	c := New(WithHeaders(h), WithHeader("User-Agent", "acme-checkout/2.1"), WithMiddleware(record, canned(http.StatusOK, cannedBIN)))
+	h.Set("Traceparent", "changed")
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for k, want := range map[string]string{
+		"X-Gateway-Token": "secret",
+		"Traceparent":     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"User-Agent":      "acme-checkout/2.1",
+		"Accept-Version":  "3",
+	} {
+		if v := got.Get(k); v != want {
+			t.Errorf("%v sent was %q, want %q", k, v, want)
+		}
+	}
+}
+
+func TestClientCloseCancels(t *testing.T) {
+	started := make(chan struct{})
+	hung := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+	}
+
+	c := New(WithCloseGracePeriod(20*time.Millisecond), WithMiddleware(hung))
+
+	errc := make(chan error)
+	go func() {
+		_, err := c.Search(context.TODO(), CorrectBIN)
+		errc <- err
+	}()
+	<-started
+
+	start := time.Now()
+	c.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Close returned after %v, past its grace period", elapsed)
+	}
+
+	if err := <-errc; !errors.Is(err, ErrClosed) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("lookup canceled by Close returned %v, want ErrClosed", err)
+	}
+}
+
+func TestClientNullableFields(t *testing.T) {
+	b, err := New(WithMiddleware(canned(http.StatusOK, cannedBIN))).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if prepaid, known := b.IsPrepaid(); prepaid || !known {
+		t.Fatalf("IsPrepaid returned %v, %v for a BIN known not to be prepaid", prepaid, known)
+	}
+
+	b, err = New(WithMiddleware(canned(http.StatusOK, `{"scheme":"visa","prepaid":null,"bank":{}}`))).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if _, known := b.IsPrepaid(); known || b.Number != nil {
+		t.Fatalf("omitted fields decoded as known: %+v", b)
+	}
+
+	if b.Bank == nil {
+		t.Fatal("empty bank object decoded as unknown")
+	}
+}
+
+func TestClientWithDryRun(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), CorrectBIN, Entry{BIN: &BIN{Scheme: SchemeVisa}, Expires: time.Now().Add(time.Hour)})
+
+	c := New(WithDryRun(), WithCache(cache), WithRateLimit(1, time.Hour), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+
+	if b, err := c.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeVisa {
+		t.Fatalf("cached lookup returned %+v, %v", b, err)
+	}
+
+	if _, err := c.Search(context.TODO(), "45717360"); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("uncached lookup returned %v, want ErrDryRun", err)
+	}
+
+	if _, err := c.Search(context.TODO(), "12"); !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("invalid BIN returned %v, want ErrInvalidBIN", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, "52882301"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("lookup over the rate limit returned %v, want it to wait its turn", err)
+	}
+
+	if requests != 0 {
+		t.Fatalf("%d requests made in dry run", requests)
+	}
+}
+
+func BenchmarkClientSearch(b *testing.B) {
+	c := New(WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	b.ReportAllocs()
+	for range b.N {
+		c.Search(context.TODO(), CorrectBIN)
+	}
+}
+
+func TestClientWith(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusOK, cannedBIN)), WithRateLimit(10, time.Second), WithRetries(2))
+	d := c.With(WithTimeout(time.Second), WithCache(NewMemoryCache(0)))
+
+	if d.httpClient != c.httpClient || d.limiter != c.limiter {
+		t.Fatal("derived client doesn't share the HTTP client and rate limit")
+	}
+	if d.timeout != time.Second || d.retries != 2 || c.timeout != DefaultTimeout || c.cache != nil {
+		t.Fatalf("derived client configured with a timeout of %v and %d retries", d.timeout, d.retries)
+	}
+
+	if _, err := d.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if e := c.With(WithRateLimit(0, 0)); e.limiter != c.limiter || c.limiter == nil {
+		t.Fatal("derived client replaced the rate limit it shares")
+	}
+
+	d.Close()
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("lookup failed once a derived client closed: %+v", err)
+	}
+
+	d = c.With()
+	c.Close()
+	if _, err := d.Search(context.TODO(), "52882301"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("lookup of a derived client returned %v once c closed, want ErrClosed", err)
+	}
+}
+
+func TestClientWithSharesQuota(t *testing.T) {
+	quota := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err == nil {
+				resp.Header = http.Header{"X-Ratelimit-Limit": {"5"}, "X-Ratelimit-Remaining": {"2"}}
+			}
+			return resp, err
+		})
+	}
+
+	c := New(WithMiddleware(quota, canned(http.StatusOK, cannedBIN)))
+	defer c.Close()
+	d := c.With()
+
+	if _, err := d.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if q, ok := c.Quota(); !ok || q.Remaining != 2 {
+		t.Fatalf("got quota %+v, %v of the lookup of a derived client", q, ok)
+	}
+
+	if _, err := c.Search(context.TODO(), "52882301"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if n, ok := d.QuotaRemaining(); !ok || n != 2 {
+		t.Fatalf("derived client estimated %d, %v requests remaining, want 2", n, ok)
+	}
+}
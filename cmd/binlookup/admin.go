@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/server"
+)
+
+// clientStats are the figures of a client of binlist the admin endpoints
+// dump.
+type clientStats struct {
+	Name  string               `json:"name"`
+	Cache binlookup.CacheStats `json:"cache"`
+}
+
+// datasetStats are those of a dataset.
+type datasetStats struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Len     int    `json:"len"`
+}
+
+// admin returns the admin endpoints of d, authenticated by token.
+func (d *daemon) admin(token string) *server.Admin {
+	return &server.Admin{
+		Token:      token,
+		Stats:      d.stats,
+		Flush:      d.flush,
+		Invalidate: d.invalidate,
+		Refresh:    d.refresh,
+		Switch:     d.providers,
+	}
+}
+
+func (d *daemon) stats() any {
+	s := struct {
+		Providers []binlookup.ProviderState `json:"providers"`
+		Clients   []clientStats             `json:"clients"`
+		Datasets  []datasetStats            `json:"datasets"`
+	}{Providers: d.providers.States()}
+
+	for _, c := range d.clients {
+		s.Clients = append(s.Clients, clientStats{Name: c.Name(), Cache: c.CacheStats()})
+	}
+	for path, store := range d.datasets {
+		active := store.Active()
+		s.Datasets = append(s.Datasets, datasetStats{Path: path, Version: active.Version, Len: active.Len()})
+	}
+	sort.Slice(s.Datasets, func(i, j int) bool { return s.Datasets[i].Path < s.Datasets[j].Path })
+
+	return s
+}
+
+// flush removes the entries of the cache of d, which only those in memory
+// support.
+func (d *daemon) flush(context.Context) error {
+	if d.cache == nil {
+		return nil
+	}
+
+	c, ok := d.cache.(interface{ Clear() })
+	if !ok {
+		return fmt.Errorf("Flushing the %T Cache Not Supported", d.cache)
+	}
+	c.Clear()
+
+	return nil
+}
+
+// invalidate removes the result of bin cached by the clients of d.
+func (d *daemon) invalidate(ctx context.Context, bin string) error {
+	var errs []error
+	for _, c := range d.clients {
+		errs = append(errs, c.Invalidate(ctx, bin))
+	}
+
+	return errors.Join(errs...)
+}
+
+// refresh reloads the datasets of d from their files, stages and then
+// promotes them. Those failing to load or validate are left out, the
+// active ones staying so.
+func (d *daemon) refresh(context.Context) error {
+	var errs []error
+	for path, store := range d.datasets {
+		ds, err := loadDataset(path)
+		if err == nil {
+			err = store.Stage(ds)
+		}
+		if err == nil {
+			err = store.Promote()
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
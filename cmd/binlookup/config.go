@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/dataset"
+	"github.com/0xbkt/binlookup-go/diskcache"
+	"github.com/0xbkt/binlookup-go/rediscache"
+)
+
+// serveConfig is the configuration of serve, read from a JSON file:
+//
+//	{
+//		"listen": ":8080",
+//		"timeout": "5s",
+//		"cors": ["https://shop.example"],
+//		"graphql": true,
+//		"paylike": false,
+//		"providers": [
+//			{
+//				"type": "binlist",
+//				"headers": {"Authorization": "Bearer ${BINLIST_API_KEY}"},
+//				"retries": 2,
+//				"rate_limit": {"requests": 10, "per": "1m"}
+//			},
+//			{"type": "dataset", "path": "/var/lib/binlookup/binlist-data.csv"},
+//			{"type": "static"}
+//		],
+//		"cache": {"backend": "redis", "addr": "redis:6379", "password": "${REDIS_PASSWORD}", "ttl": "24h"},
+//		"admin": {"token": "${BINLOOKUP_ADMIN_TOKEN}"}
+//	}
+//
+// The providers are tried in order, each answering the BINs those before
+// don't know, and can be disabled at runtime through the admin endpoints,
+// served once admin is set, see `server.Admin`. ${VAR} in the strings of the file is replaced with the
+// environment variable VAR, for secrets such as API keys to stay out of
+// it; a variable unset fails the configuration.
+type serveConfig struct {
+	Listen    string           `json:"listen"`
+	Timeout   duration         `json:"timeout"`
+	CORS      []string         `json:"cors"`
+	GraphQL   bool             `json:"graphql"`
+	Paylike   bool             `json:"paylike"`
+	Providers []providerConfig `json:"providers"`
+	Cache     *cacheConfig     `json:"cache"`
+	Admin     *adminConfig     `json:"admin"`
+}
+
+// providerConfig configures a provider of serve: binlist, or an API of
+// the same shape at BaseURL, a dataset file, or the static ranges of the
+// schemes.
+type providerConfig struct {
+	Type string `json:"type"`
+
+	// Name tells the provider apart to the admin endpoints, Type when
+	// empty.
+	Name string `json:"name"`
+
+	// BaseURL, Headers, Timeout, Retries and RateLimit are those of
+	// binlist.
+	BaseURL   string            `json:"base_url"`
+	Headers   map[string]string `json:"headers"`
+	Timeout   duration          `json:"timeout"`
+	Retries   int               `json:"retries"`
+	RateLimit *rateLimitConfig  `json:"rate_limit"`
+
+	// Path is that of the dataset, read as JSON Lines when named .jsonl
+	// or written by import, as the CSV of binlist-data otherwise.
+	Path string `json:"path"`
+}
+
+type rateLimitConfig struct {
+	Requests int      `json:"requests"`
+	Per      duration `json:"per"`
+}
+
+// cacheConfig configures the cache of the binlist providers, which they
+// share: in memory, on disk in Dir, or in the Redis server at Addr.
+type cacheConfig struct {
+	Backend string   `json:"backend"`
+	TTL     duration `json:"ttl"`
+
+	// Size and Policy are those of memory: lru, lfu or arc.
+	Size   int    `json:"size"`
+	Policy string `json:"policy"`
+
+	Dir string `json:"dir"`
+
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+	Prefix   string `json:"prefix"`
+}
+
+// adminConfig configures the admin endpoints of serve, authenticated by
+// Token.
+type adminConfig struct {
+	Token string `json:"token"`
+}
+
+// duration is a time.Duration read from a JSON string such as "1m30s".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Duration Must Be a String Such as \"30s\": %w", err)
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+
+	return nil
+}
+
+// loadConfig reads the configuration at path, interpolating the
+// environment into its strings, and validates it.
+func loadConfig(path string) (*serveConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &serveConfig{Listen: ":8080"}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("Reading %v Failed: %w", path, err)
+	}
+
+	if err := cfg.interpolate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("Invalid Configuration %v:\n%w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// interpolate replaces ${VAR} in the strings of cfg with the environment
+// variables, failing on those unset.
+func (cfg *serveConfig) interpolate() error {
+	var missing []string
+	expand := func(s *string) {
+		*s = os.Expand(*s, func(name string) string {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return v
+		})
+	}
+
+	expand(&cfg.Listen)
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		expand(&p.BaseURL)
+		expand(&p.Path)
+		for k, v := range p.Headers {
+			expand(&v)
+			p.Headers[k] = v
+		}
+	}
+	if c := cfg.Cache; c != nil {
+		expand(&c.Dir)
+		expand(&c.Addr)
+		expand(&c.Password)
+		expand(&c.Prefix)
+	}
+	if a := cfg.Admin; a != nil {
+		expand(&a.Token)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Environment Variables Unset: %v", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// validate returns the problems of cfg joined, nil when there are none.
+func (cfg *serveConfig) validate() error {
+	var errs []error
+	problem := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if cfg.Listen == "" {
+		problem("listen: Address Required")
+	}
+	if cfg.Timeout < 0 {
+		problem("timeout: Negative")
+	}
+	if len(cfg.Providers) == 0 {
+		problem("providers: At Least One Required")
+	}
+
+	for i, p := range cfg.Providers {
+		switch p.Type {
+		case "binlist":
+			if p.Retries < 0 {
+				problem("providers[%d]: retries: Negative", i)
+			}
+			if p.Timeout < 0 {
+				problem("providers[%d]: timeout: Negative", i)
+			}
+			if r := p.RateLimit; r != nil && (r.Requests <= 0 || r.Per <= 0) {
+				problem("providers[%d]: rate_limit: Requests and Per Must Be Positive", i)
+			}
+		case "dataset":
+			if p.Path == "" {
+				problem("providers[%d]: path: Required", i)
+			} else if _, err := os.Stat(p.Path); err != nil {
+				problem("providers[%d]: path: %w", i, err)
+			}
+		case "static":
+		default:
+			problem("providers[%d]: Unknown Type %q, Not binlist, dataset or static", i, p.Type)
+		}
+	}
+
+	if c := cfg.Cache; c != nil {
+		switch c.Backend {
+		case "memory":
+			if c.Policy != "" && c.Policy != "lru" && c.Policy != "lfu" && c.Policy != "arc" {
+				problem("cache: policy: Unknown Policy %q, Not lru, lfu or arc", c.Policy)
+			}
+		case "disk":
+			if c.Dir == "" {
+				problem("cache: dir: Required")
+			}
+		case "redis":
+			if c.Addr == "" {
+				problem("cache: addr: Required")
+			}
+		default:
+			problem("cache: Unknown Backend %q, Not memory, disk or redis", c.Backend)
+		}
+		if c.TTL < 0 {
+			problem("cache: ttl: Negative")
+		}
+	}
+
+	if a := cfg.Admin; a != nil && a.Token == "" {
+		problem("admin: token: Required")
+	}
+
+	return errors.Join(errs...)
+}
+
+// daemon is what serve looks BINs up through: the providers of its
+// configuration behind a switch, along with the clients of binlist to be
+// closed once done and the datasets to be refreshed.
+type daemon struct {
+	providers *binlookup.Switch
+	clients   []*binlookup.Client
+	cache     binlookup.Cache
+	datasets  map[string]*dataset.Store
+}
+
+// daemon returns the daemon cfg configures. Those of it opened already are
+// returned along with the error, for the clients to be closed.
+func (cfg *serveConfig) daemon() (*daemon, error) {
+	d := &daemon{datasets: make(map[string]*dataset.Store)}
+
+	var opts []binlookup.Option
+	if c := cfg.Cache; c != nil {
+		cache, err := c.open()
+		if err != nil {
+			return d, fmt.Errorf("Opening the Cache Failed: %w", err)
+		}
+		d.cache = cache
+		opts = append(opts, binlookup.WithCache(cache))
+		if c.TTL > 0 {
+			opts = append(opts, binlookup.WithCacheTTL(time.Duration(c.TTL)))
+		}
+	}
+
+	var providers []binlookup.Provider
+	for _, p := range cfg.Providers {
+		name := p.Name
+		if name == "" {
+			name = p.Type
+		}
+
+		switch p.Type {
+		case "binlist":
+			c := binlookup.New(append(p.options(), opts...)...)
+			providers, d.clients = append(providers, binlookup.Named(name, c)), append(d.clients, c)
+		case "dataset":
+			ds, err := loadDataset(p.Path)
+			if err != nil {
+				return d, err
+			}
+			s := dataset.NewStore(ds)
+			d.datasets[p.Path] = s
+			providers = append(providers, binlookup.Named(name, s))
+		case "static":
+			providers = append(providers, binlookup.Named(name, binlookup.Static()))
+		}
+	}
+	d.providers = binlookup.NewSwitch(providers...)
+
+	return d, nil
+}
+
+// close closes the clients of d.
+func (d *daemon) close() {
+	for _, c := range d.clients {
+		c.Close()
+	}
+}
+
+// options returns the options of the client of binlist p configures.
+func (p providerConfig) options() []binlookup.Option {
+	opts := []binlookup.Option{binlookup.WithRetries(p.Retries)}
+	if p.BaseURL != "" {
+		opts = append(opts, binlookup.WithBaseURL(p.BaseURL))
+	}
+	if p.Timeout > 0 {
+		opts = append(opts, binlookup.WithTimeout(time.Duration(p.Timeout)))
+	}
+	if r := p.RateLimit; r != nil {
+		opts = append(opts, binlookup.WithRateLimit(r.Requests, time.Duration(r.Per)))
+	}
+
+	for k, v := range p.Headers {
+		opts = append(opts, binlookup.WithHeader(k, v))
+	}
+
+	return opts
+}
+
+// open returns the cache c configures.
+func (c *cacheConfig) open() (binlookup.Cache, error) {
+	switch c.Backend {
+	case "disk":
+		return diskcache.New(c.Dir)
+	case "redis":
+		r := rediscache.New(c.Addr)
+		r.Password, r.Prefix = c.Password, c.Prefix
+		return r, nil
+	}
+
+	policy := binlookup.LRU()
+	switch c.Policy {
+	case "lfu":
+		policy = binlookup.LFU()
+	case "arc":
+		policy = binlookup.ARC()
+	}
+
+	return binlookup.NewMemoryCacheWithPolicy(c.Size, policy), nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes the configuration cfg to a temporary file, returning
+// its path.
+func writeConfig(t *testing.T, cfg string) string {
+	path := filepath.Join(t.TempDir(), "binlookup.json")
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("BINLIST_API_KEY", "secret")
+
+	cfg, err := loadConfig(writeConfig(t, `{
+		"timeout": "5s",
+		"providers": [
+			{"type": "binlist", "headers": {"Authorization": "Bearer ${BINLIST_API_KEY}"}, "rate_limit": {"requests": 10, "per": "1m"}},
+			{"type": "static"}
+		],
+		"cache": {"backend": "memory", "size": 1000, "policy": "lfu", "ttl": "12h"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Listen != ":8080" || time.Duration(cfg.Timeout) != 5*time.Second || time.Duration(cfg.Cache.TTL) != 12*time.Hour {
+		t.Fatalf("loaded %+v", cfg)
+	}
+	if h := cfg.Providers[0].Headers["Authorization"]; h != "Bearer secret" {
+		t.Fatalf("Authorization interpolated as %q", h)
+	}
+	if r := cfg.Providers[0].RateLimit; r.Requests != 10 || time.Duration(r.Per) != time.Minute {
+		t.Fatalf("rate limit %+v", r)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name, cfg string
+		problems  []string
+	}{
+		{"Empty", `{}`, []string{"providers: At Least One Required"}},
+		{"Env", `{"providers": [{"type": "binlist", "headers": {"X-Key": "${BINLOOKUP_UNSET_KEY}"}}]}`, []string{"BINLOOKUP_UNSET_KEY"}},
+		{"Duration", `{"timeout": 5, "providers": [{"type": "static"}]}`, []string{"Duration Must Be a String"}},
+		{"Problems", `{
+			"providers": [{"type": "ldap"}, {"type": "dataset"}, {"type": "binlist", "rate_limit": {"requests": 0}}],
+			"cache": {"backend": "memory", "policy": "mru"}
+		}`, []string{
+			`providers[0]: Unknown Type "ldap"`,
+			"providers[1]: path: Required",
+			"providers[2]: rate_limit",
+			`cache: policy: Unknown Policy "mru"`,
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadConfig(writeConfig(t, tc.cfg))
+			if err == nil {
+				t.Fatal("invalid configuration loaded")
+			}
+
+			for _, p := range tc.problems {
+				if !strings.Contains(err.Error(), p) {
+					t.Errorf("error %q doesn't tell of %q", err, p)
+				}
+			}
+		})
+	}
+}
+
+func TestServeConfig(t *testing.T) {
+	dataset := filepath.Join(t.TempDir(), "bins.jsonl")
+	if err := os.WriteFile(dataset, []byte(`{"bin":"457173","scheme":"visa","country":{"alpha2":"DK"},"bank":{"name":"Jyske Bank"}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(writeConfig(t, `{"providers": [{"type": "dataset", "path": "`+dataset+`"}, {"type": "static"}], "cors": ["*"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv, closeClients, err := cfg.server()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeClients()
+
+	for bin, want := range map[string]string{"45717360": "Jyske Bank", "5555555555554444": `"mastercard"`} {
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+bin, nil))
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("%v served %d %v, want %v", bin, w.Code, w.Body, want)
+		}
+	}
+
+	if err := runServe(nil, []string{"-config", writeConfig(t, `{"providers": [{"type": "static"}]}`), "-check-config"}); err != nil {
+		t.Fatalf("checking a valid configuration failed: %v", err)
+	}
+}
+
+func TestServeAdmin(t *testing.T) {
+	dataset := filepath.Join(t.TempDir(), "bins.jsonl")
+	write := func(bank string) {
+		if err := os.WriteFile(dataset, []byte(`{"bin":"457173","scheme":"visa","country":{"alpha2":"DK"},"bank":{"name":"`+bank+`"}}`+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Jyske Bank")
+
+	t.Setenv("ADMIN_TOKEN", "secret")
+	cfg, err := loadConfig(writeConfig(t, `{"providers": [{"type": "dataset", "path": "`+dataset+`"}, {"type": "static"}], "cache": {"backend": "memory"}, "admin": {"token": "${ADMIN_TOKEN}"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv, closeClients, err := cfg.server()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeClients()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	write("Danske Bank")
+	if w := serve(http.MethodPost, "/admin/refresh"); w.Code != http.StatusNoContent {
+		t.Fatalf("refreshing served %d %v", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/45717360"); !strings.Contains(w.Body.String(), "Danske Bank") {
+		t.Fatalf("lookup served %v once refreshed, want the dataset reloaded", w.Body)
+	}
+
+	if w := serve(http.MethodPost, "/admin/providers/dataset/disable"); w.Code != http.StatusNoContent {
+		t.Fatalf("disabling served %d %v", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/45717360"); strings.Contains(w.Body.String(), "Danske Bank") {
+		t.Fatalf("lookup served %v, want the dataset disabled", w.Body)
+	}
+
+	if w := serve(http.MethodPost, "/admin/cache/flush"); w.Code != http.StatusNoContent {
+		t.Fatalf("flushing served %d %v", w.Code, w.Body)
+	}
+	if w := serve(http.MethodGet, "/admin/stats"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":false`) {
+		t.Fatalf("stats served %d %v", w.Code, w.Body)
+	}
+
+	if _, err := loadConfig(writeConfig(t, `{"providers": [{"type": "static"}], "admin": {}}`)); err == nil || !strings.Contains(err.Error(), "admin: token: Required") {
+		t.Fatalf("admin without a token loaded with %v", err)
+	}
+}
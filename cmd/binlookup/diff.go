@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/dataset"
+)
+
+func runDiff(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: binlookup diff [flags] old new\n\nDatasets are read as JSON Lines when named .jsonl or written by import, as the CSV of binlist-data otherwise.")
+		fs.PrintDefaults()
+	}
+	asJSON := fs.Bool("json", false, "print the changes as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("Two Datasets Are Required")
+	}
+
+	from, err := loadDataset(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := loadDataset(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes := dataset.Diff(from, to)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+
+	printChanges(os.Stdout, changes)
+	return nil
+}
+
+// loadDataset loads the dataset at path, versioned by its file name: as
+// JSON Lines when named .jsonl or starting with an object, as import
+// writes them, as the CSV of binlist-data otherwise.
+func loadDataset(path string) (*dataset.Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	load := dataset.LoadCSV
+	if first, _ := r.Peek(1); strings.EqualFold(filepath.Ext(path), ".jsonl") || string(first) == "{" {
+		load = dataset.Load
+	}
+
+	d, err := load(filepath.Base(path), r)
+	if err != nil {
+		return nil, fmt.Errorf("Loading %v Failed: %w", path, err)
+	}
+
+	return d, nil
+}
+
+// printChanges prints c a line per entry: + for those added, - for those
+// removed and ~ for those changed, followed by the fields changed.
+func printChanges(w io.Writer, c dataset.Changes) {
+	for _, ch := range c.Added {
+		fmt.Fprintf(w, "+ %v %v\n", ch.Key, summary(ch.New))
+	}
+	for _, ch := range c.Removed {
+		fmt.Fprintf(w, "- %v %v\n", ch.Key, summary(ch.Old))
+	}
+	for _, ch := range c.Changed {
+		fmt.Fprintf(w, "~ %v %v\n", ch.Key, strings.Join(ch.Fields, ", "))
+	}
+
+	fmt.Fprintf(w, "%d added, %d removed, %d changed\n", len(c.Added), len(c.Removed), len(c.Changed))
+}
+
+// summary returns the scheme, country and issuer of b.
+func summary(b *binlookup.BIN) string {
+	s := []string{string(b.Scheme), b.Country.Short}
+	if b.Bank != nil {
+		s = append(s, b.Bank.Name)
+	}
+
+	return strings.Join(s, " ")
+}
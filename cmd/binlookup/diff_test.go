@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go/dataset"
+)
+
+func TestPrintChanges(t *testing.T) {
+	dir := t.TempDir()
+	old, new := filepath.Join(dir, "old.csv"), filepath.Join(dir, "new.jsonl")
+
+	if err := os.WriteFile(old, []byte("bin,brand,issuer,alpha_2\n457173,VISA,JYSKE BANK,DK\n528823,MASTERCARD,,US\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(new, []byte(`{"bin":"457173","scheme":"visa","country":{"alpha2":"DK"},"bank":{"name":"NYKREDIT"}}`+"\n"+`{"bin":"41111111","scheme":"visa","country":{"alpha2":"US"}}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := loadDataset(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := loadDataset(new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	printChanges(&out, dataset.Diff(from, to))
+
+	want := `+ 41111111 visa US
+- 528823 mastercard US
+~ 457173 bank.name
+1 added, 1 removed, 1 changed
+`
+	if out.String() != want {
+		t.Fatalf("printed\n%v\nwant\n%v", out.String(), want)
+	}
+}
+
+func TestDiffArgs(t *testing.T) {
+	if err := runDiff(nil, []string{"old.csv"}); err == nil {
+		t.Fatal("diff of a single dataset succeeded")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// enrichColumns are the columns enrich appends.
+var enrichColumns = []string{"scheme", "type", "country", "bank"}
+
+func runEnrich(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("enrich", flag.ContinueOnError)
+	var (
+		input   = fs.String("input", "-", "CSV file to enrich, standard input when -")
+		output  = fs.String("output", "-", "CSV file to write, standard output when -")
+		column  = fs.String("bin-column", "", "name of the column holding the BINs, or card numbers")
+		digits  = fs.Int("digits", 8, "digits of the card numbers looked up, 6 or 8")
+		baseURL = fs.String("base-url", "", "upstream to look up against, binlist when empty")
+		rate    = fs.Int("rate", 0, "requests per minute at most, unlimited when 0")
+		workers = fs.Int("workers", binlookup.DefaultEnricherWorkers, "lookups made at once")
+		quiet   = fs.Bool("quiet", false, "don't report progress on standard error")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *column == "" {
+		return errors.New("-bin-column Is Required")
+	}
+
+	in, out := io.Reader(os.Stdin), io.Writer(os.Stdout)
+	var outFile *os.File
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out, outFile = f, f
+	}
+
+	opts := []binlookup.Option{binlookup.WithCache(binlookup.NewMemoryCache(0)), binlookup.WithRetries(3)}
+	if *baseURL != "" {
+		opts = append(opts, binlookup.WithBaseURL(*baseURL))
+	}
+	if *rate > 0 {
+		opts = append(opts, binlookup.WithRateLimit(*rate, time.Minute))
+	}
+
+	c := binlookup.New(opts...)
+	defer c.Close()
+
+	csvOpts := binlookup.CSVOptions{Lookuper: c, Column: *column, Digits: *digits, Workers: *workers}
+	if !*quiet {
+		csvOpts.OnProgress = func(p binlookup.Progress) {
+			fmt.Fprintf(os.Stderr, "\r%d done, %d failed in %v ", p.Done, p.Failed, p.Elapsed.Round(time.Second))
+		}
+	}
+
+	err := binlookup.EnrichCSV(ctx, in, out, csvOpts)
+	if !*quiet {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if outFile != nil {
+		return outFile.Close()
+	}
+
+	return nil
+}
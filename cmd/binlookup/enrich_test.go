@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go/binlookuptest"
+)
+
+func TestEnrich(t *testing.T) {
+	srv := binlookuptest.NewServer()
+	defer srv.Close()
+
+	srv.Add("45717360", binlookuptest.JyskeBank)
+
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "in.csv"), filepath.Join(dir, "out.csv")
+	if err := os.WriteFile(input, []byte("id,card_bin\n1,4571 7360 1234 5678\n2,99999999\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runEnrich(context.TODO(), []string{"-input", input, "-output", output, "-bin-column", "card_bin", "-base-url", srv.URL, "-quiet"})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "id,card_bin,scheme,type,country,bank\n1,4571 7360 1234 5678,visa,debit,DK,Jyske Bank\n2,99999999,,,,\n"
+	if string(data) != want {
+		t.Fatalf("enriched\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestEnrichMissingColumn(t *testing.T) {
+	err := runEnrich(context.TODO(), []string{"-quiet"})
+	if err == nil || !strings.Contains(err.Error(), "-bin-column") {
+		t.Fatalf("runEnrich returned %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/0xbkt/binlookup-go/dataset"
+)
+
+// importFormats are the formats import reads, by their name. It writes
+// JSON Lines, which the dataset providers of serve read, as does the
+// Import of sqlitedb for a deployment keeping its BINs in SQLite.
+var importFormats = map[string]func(version string, r io.Reader) (*dataset.Dataset, []dataset.Rejection, error){
+	"binlist-data": dataset.ImportCSV,
+	"jsonl":        dataset.Import,
+}
+
+func runImport(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: binlookup import [-format binlist-data] -dest bins.db file.csv\n\nValidates and converts a dataset into the JSON Lines the dataset providers of serve read, reporting the rows rejected.")
+		fs.PrintDefaults()
+	}
+	var (
+		format = fs.String("format", "binlist-data", "format of the dataset: binlist-data, the CSV of its release, or jsonl")
+		dest   = fs.String("dest", "", "file to write the dataset to, replaced once converted")
+		quiet  = fs.Bool("quiet", false, "don't report the rows rejected, only their number")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	load, ok := importFormats[*format]
+	if !ok {
+		return fmt.Errorf("Unknown Format %q, Not binlist-data or jsonl", *format)
+	}
+	if *dest == "" {
+		return errors.New("-dest Is Required")
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("A Dataset Is Required")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	d, rejected, err := load(filepath.Base(fs.Arg(0)), f)
+	if err != nil {
+		return fmt.Errorf("Importing %v Failed: %w", fs.Arg(0), err)
+	}
+	if !*quiet {
+		for _, r := range rejected {
+			fmt.Fprintf(os.Stderr, "binlookup: rejected %v\n", r)
+		}
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	if err := writeDataset(*dest, d); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d BINs imported to %v, %d rows rejected\n", d.Len(), *dest, len(rejected))
+	return nil
+}
+
+// writeDataset replaces the file at path with d, atomically for serve
+// never to read it half written.
+func writeDataset(path string, d *dataset.Dataset) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-import-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := d.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
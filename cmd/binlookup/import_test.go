@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunImport(t *testing.T) {
+	dir := t.TempDir()
+	src, dest := filepath.Join(dir, "binlist-data.csv"), filepath.Join(dir, "bins.db")
+
+	if err := os.WriteFile(src, []byte("bin,brand,issuer,alpha_2\n457173,VISA,JYSKE BANK,DK\n04571,VISA,,DK\n528823,MASTERCARD,,US\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runImport(nil, []string{"-format", "binlist-data", "-dest", dest, "-quiet", src}); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := loadDataset(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 2 {
+		t.Fatalf("imported %d BINs, want 2", d.Len())
+	}
+
+	if err := runImport(nil, []string{"-format", "xls", "-dest", dest, src}); err == nil {
+		t.Fatal("import of an unknown format succeeded")
+	}
+}
@@ -0,0 +1,75 @@
+// Command binlookup looks BINs up from the command line:
+//
+//	binlookup enrich -input txns.csv -bin-column card_bin -output enriched.csv
+//	binlookup diff old.csv new.csv
+//	binlookup import -format binlist-data -dest bins.db binlist-data.csv
+//	binlookup serve -config binlookup.json
+//
+// Run binlookup help for the list of commands, and binlookup <command>
+// -h for the flags of each.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// command is a subcommand of binlookup.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"enrich": {"append the scheme, type, country and bank of BINs to a CSV", runEnrich},
+	"diff":   {"report the BINs added, removed and changed between two datasets", runDiff},
+	"import": {"validate and convert a dataset for the dataset providers of serve", runImport},
+	"serve":  {"serve lookups over HTTP, configured by a JSON file", runServe},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" {
+		usage()
+		return
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "binlookup: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: binlookup <command> [flags]\n\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10v %v\n", name, commands[name].summary)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "binlookup:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/0xbkt/binlookup-go/server"
+)
+
+// shutdownTimeout bounds how long serve lets the requests in progress
+// finish once interrupted.
+const shutdownTimeout = 10 * time.Second
+
+func runServe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: binlookup serve -config binlookup.json [-check-config]\n\nServes lookups over HTTP in the API of binlist, configured by a JSON file.")
+		fs.PrintDefaults()
+	}
+	var (
+		path  = fs.String("config", "binlookup.json", "JSON file configuring the daemon")
+		check = fs.Bool("check-config", false, "validate the configuration and exit")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*path)
+	if err != nil {
+		return err
+	}
+	if *check {
+		fmt.Fprintf(os.Stderr, "%v: OK\n", *path)
+		return nil
+	}
+
+	srv, closeClients, err := cfg.server()
+	if err != nil {
+		return err
+	}
+	defer closeClients()
+
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "binlookup: serving on %v\n", cfg.Listen)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// server returns the HTTP server cfg configures, along with the function
+// closing its clients once it's shut down.
+func (cfg *serveConfig) server() (*http.Server, func(), error) {
+	d, err := cfg.daemon()
+	if err != nil {
+		d.close()
+		return nil, nil, err
+	}
+
+	var opts []server.Option
+	if cfg.Timeout > 0 {
+		opts = append(opts, server.WithTimeout(time.Duration(cfg.Timeout)))
+	}
+	if len(cfg.CORS) > 0 {
+		opts = append(opts, server.WithCORS(cfg.CORS...))
+	}
+	if cfg.GraphQL {
+		opts = append(opts, server.WithGraphQL())
+	}
+	if cfg.Paylike {
+		opts = append(opts, server.WithPaylike())
+	}
+	if a := cfg.Admin; a != nil {
+		opts = append(opts, server.WithAdmin(d.admin(a.Token)))
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           server.New(d.providers, opts...),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return srv, d.close, nil
+}
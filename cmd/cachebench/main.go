@@ -0,0 +1,145 @@
+// Command cachebench compares the cache backends of binlookup-go under
+// the hit ratios and concurrency levels given, printing the results as a
+// JSON array:
+//
+//	cachebench -hit-ratios 0.5,0.9,0.99 -concurrency 1,16,64 -redis localhost:6379
+//
+// The in-memory cache is always measured; the Redis one when its address
+// is given, and the disk one when its directory is.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/cachebench"
+	"github.com/0xbkt/binlookup-go/diskcache"
+	"github.com/0xbkt/binlookup-go/rediscache"
+)
+
+// backend is a cache measured, created anew for every run.
+type backend struct {
+	name  string
+	cache func() binlookup.Cache
+}
+
+func main() {
+	var (
+		keys        = flag.Int("keys", 10000, "number of distinct BINs cached before measuring")
+		ops         = flag.Int("ops", 100000, "number of lookups per run")
+		hitRatios   = flag.String("hit-ratios", "0.9", "comma separated hit ratios to measure")
+		concurrency = flag.String("concurrency", "1,8,64", "comma separated numbers of concurrent callers")
+		memorySize  = flag.Int("memory-size", 0, "size of the in-memory cache, unbounded when 0")
+		redisAddr   = flag.String("redis", "", "address of the Redis server to measure, skipped when empty")
+		diskDir     = flag.String("disk", "", "directory of the disk cache to measure, skipped when empty")
+		gzipValues  = flag.Bool("gzip", false, "gzip the values of the caches keeping them as bytes")
+	)
+	flag.Parse()
+
+	ratios, err := floats(*hitRatios)
+	if err != nil {
+		fail(err)
+	}
+
+	levels, err := ints(*concurrency)
+	if err != nil {
+		fail(err)
+	}
+
+	backends := []backend{
+		{"memory", func() binlookup.Cache { return binlookup.NewMemoryCache(*memorySize) }},
+	}
+	if *redisAddr != "" {
+		backends = append(backends, backend{"redis", func() binlookup.Cache {
+			c := rediscache.New(*redisAddr)
+			c.Prefix = "cachebench:"
+			c.PoolSize = 64
+			if *gzipValues {
+				c.Codec.Compressor = binlookup.Gzip
+			}
+			return c
+		}})
+	}
+
+	if *diskDir != "" {
+		backends = append(backends, backend{"disk", func() binlookup.Cache {
+			c, err := diskcache.New(*diskDir)
+			if err != nil {
+				fail(err)
+			}
+			if *gzipValues {
+				c.Codec.Compressor = binlookup.Gzip
+			}
+			return c
+		}})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var results []cachebench.Result
+	for _, b := range backends {
+		for _, ratio := range ratios {
+			for _, n := range levels {
+				cache := b.cache()
+				r, err := cachebench.Run(ctx, b.name, cache, cachebench.Workload{
+					Keys:        *keys,
+					HitRatio:    ratio,
+					Concurrency: n,
+					Ops:         *ops,
+				})
+				if c, ok := cache.(io.Closer); ok {
+					c.Close()
+				}
+				if err != nil {
+					fail(fmt.Errorf("%v: %w", b.name, err))
+				}
+
+				results = append(results, r)
+			}
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		fail(err)
+	}
+}
+
+func floats(s string) (fs []float64, err error) {
+	for _, f := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, v)
+	}
+
+	return
+}
+
+func ints(s string) (is []int, err error) {
+	for _, i := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(i))
+		if err != nil {
+			return nil, err
+		}
+		is = append(is, v)
+	}
+
+	return
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "cachebench:", err)
+	os.Exit(1)
+}
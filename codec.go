@@ -0,0 +1,156 @@
+package binlookup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Compressor compresses the values of the caches keeping their entries as
+// bytes, see `EntryCodec`. `Gzip` is built in; others such as snappy or
+// zstd plug in by implementing it.
+type Compressor interface {
+	// ID identifies the compression in the header of the values it
+	// compressed, which is how mixed values are told apart. It must not
+	// be zero, and should stay the same across releases.
+	ID() byte
+
+	Compress(src []byte) ([]byte, error)
+	Decompress(src []byte) ([]byte, error)
+}
+
+// The IDs of the well-known compressions, for their implementations to
+// agree on.
+const (
+	GzipID   byte = 1
+	SnappyID byte = 2
+	ZstdID   byte = 3
+)
+
+// Gzip is the `Compressor` of compress/gzip.
+var Gzip Compressor = gzipCompressor{}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) ID() byte { return GzipID }
+
+func (gzipCompressor) Compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// valueMagic starts the values with a header, made of it followed by the
+// `Compressor` ID. Values without one are plain JSON, which starts with
+// '{' instead.
+const valueMagic = 0xB1
+
+// ErrUnknownCompression is returned decoding a cache value compressed
+// with a `Compressor` the `EntryCodec` doesn't know.
+var ErrUnknownCompression = errors.New("Unknown Compression")
+
+// EntryCodec encodes the entries of the caches keeping them as bytes,
+// compressing them with Compressor when set. The zero value encodes them
+// as `MarshalEntry` does, and Binary as `MarshalEntryBinary` does.
+//
+// Every value starts with a header telling how it's compressed, so that
+// values compressed differently, or not at all, coexist: switching
+// Compressor, say while migrating a cache to zstd, doesn't invalidate the
+// entries stored before as long as their compression is still known.
+type EntryCodec struct {
+	// Compressor compresses the values encoded, none when nil.
+	Compressor Compressor
+
+	// MinSize is the size under which values are left uncompressed,
+	// since compressing them would barely save anything.
+	MinSize int
+
+	// Binary encodes the values with `MarshalEntryBinary` rather than as
+	// JSON. Values are decoded whichever they were encoded as, for a cache
+	// to be switched over without being flushed.
+	Binary bool
+
+	// Decompressors are the compressions known when decoding besides
+	// Compressor and `Gzip`.
+	Decompressors []Compressor
+}
+
+// Marshal encodes e.
+func (c *EntryCodec) Marshal(e Entry) ([]byte, error) {
+	marshal := MarshalEntry
+	if c != nil && c.Binary {
+		marshal = MarshalEntryBinary
+	}
+
+	data, err := marshal(e)
+	if err != nil || c == nil || c.Compressor == nil || len(data) < c.MinSize {
+		return data, err
+	}
+
+	z, err := c.Compressor.Compress(data)
+	if err != nil {
+		return nil, fmt.Errorf("Compression Failed: %w", err)
+	}
+
+	return append([]byte{valueMagic, c.Compressor.ID()}, z...), nil
+}
+
+// Unmarshal decodes a value encoded by Marshal, whichever compression it
+// was encoded with.
+func (c *EntryCodec) Unmarshal(data []byte) (e Entry, err error) {
+	if len(data) >= 2 && data[0] == valueMagic {
+		z := c.decompressor(data[1])
+		if z == nil {
+			return e, fmt.Errorf("%w: %d", ErrUnknownCompression, data[1])
+		}
+
+		if data, err = z.Decompress(data[2:]); err != nil {
+			return e, fmt.Errorf("Decompression Failed: %w", err)
+		}
+	}
+
+	if len(data) > 0 && data[0] == binaryMagic {
+		return UnmarshalEntryBinary(data)
+	}
+
+	return UnmarshalEntry(data)
+}
+
+func (c *EntryCodec) decompressor(id byte) Compressor {
+	if c != nil {
+		if c.Compressor != nil && c.Compressor.ID() == id {
+			return c.Compressor
+		}
+
+		for _, z := range c.Decompressors {
+			if z.ID() == id {
+				return z
+			}
+		}
+	}
+
+	if id == GzipID {
+		return Gzip
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package binlookup
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// reverse is a toy `Compressor`, telling apart the values it encoded.
+type reverse struct{}
+
+func (reverse) ID() byte { return 200 }
+
+func (reverse) Compress(src []byte) ([]byte, error) {
+	dst := make([]byte, len(src))
+	for i, c := range src {
+		dst[len(src)-1-i] = c
+	}
+
+	return dst, nil
+}
+
+func (r reverse) Decompress(src []byte) ([]byte, error) {
+	return r.Compress(src)
+}
+
+func TestEntryCodec(t *testing.T) {
+	e := Entry{BIN: &BIN{Scheme: SchemeVisa, Brand: "Visa/Dankort"}, Expires: time.Now().Add(time.Hour).Round(0)}
+
+	plain, _ := new(EntryCodec).Marshal(e)
+	gzipped, _ := (&EntryCodec{Compressor: Gzip}).Marshal(e)
+	reversed, _ := (&EntryCodec{Compressor: reverse{}}).Marshal(e)
+
+	if want, _ := MarshalEntry(e); !bytes.Equal(plain, want) {
+		t.Fatalf("zero EntryCodec encodes %s, want %s", plain, want)
+	}
+
+	if gzipped[0] != valueMagic || gzipped[1] != GzipID {
+		t.Fatalf("gzipped value has no header: %x", gzipped[:2])
+	}
+
+	migrating := &EntryCodec{Compressor: Gzip, Decompressors: []Compressor{reverse{}}}
+	for _, data := range [][]byte{plain, gzipped, reversed} {
+		got, err := migrating.Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.BIN.Brand != e.BIN.Brand || !got.Expires.Equal(e.Expires) {
+			t.Fatalf("%x decoded as %+v", data, got)
+		}
+	}
+
+	if _, err := new(EntryCodec).Unmarshal(reversed); !errors.Is(err, ErrUnknownCompression) {
+		t.Fatalf("value of an unknown compression decoded with %v", err)
+	}
+}
+
+func TestEntryCodecMinSize(t *testing.T) {
+	data, _ := (&EntryCodec{Compressor: Gzip, MinSize: 1 << 20}).Marshal(Entry{BIN: &BIN{}})
+	if data[0] != '{' {
+		t.Fatalf("value under MinSize was compressed: %x", data)
+	}
+}
@@ -0,0 +1,164 @@
+// Package columnar lays BIN lookup results out column by column, one
+// slice per field, which is the shape analytics warehouses and Apache
+// Arrow record batches ingest efficiently.
+//
+// The package has no dependencies; converting a `Batch` into an Arrow
+// record amounts to appending each of its `Columns` to an array builder
+// of the matching type.
+package columnar
+
+import "github.com/0xbkt/binlookup-go"
+
+// Batch holds lookup results as a struct of slices, all of the same
+// length. Row i is the result for BIN[i]; when Found[i] is false the
+// lookup failed and the other columns hold zero values for that row.
+//
+// Prepaid is nil in the rows whose result doesn't tell, which maps to
+// null in Arrow; the number and bank columns hold zero values where the
+// result has no Number or Bank.
+type Batch struct {
+	BIN   []string
+	Found []bool
+
+	Scheme, Type, Brand []string
+	Prepaid             []*bool
+
+	NumberLength []int
+	NumberLuhn   []bool
+
+	CountryNumeric, CountryAlpha2, CountryName, CountryEmoji, CountryCurrency []string
+	CountryLatitude, CountryLongitude                                         []float64
+
+	BankName, BankURL, BankPhone, BankCity []string
+}
+
+// New returns a `Batch` with room for n rows.
+func New(n int) *Batch {
+	return &Batch{
+		BIN:              make([]string, 0, n),
+		Found:            make([]bool, 0, n),
+		Scheme:           make([]string, 0, n),
+		Type:             make([]string, 0, n),
+		Brand:            make([]string, 0, n),
+		Prepaid:          make([]*bool, 0, n),
+		NumberLength:     make([]int, 0, n),
+		NumberLuhn:       make([]bool, 0, n),
+		CountryNumeric:   make([]string, 0, n),
+		CountryAlpha2:    make([]string, 0, n),
+		CountryName:      make([]string, 0, n),
+		CountryEmoji:     make([]string, 0, n),
+		CountryCurrency:  make([]string, 0, n),
+		CountryLatitude:  make([]float64, 0, n),
+		CountryLongitude: make([]float64, 0, n),
+		BankName:         make([]string, 0, n),
+		BankURL:          make([]string, 0, n),
+		BankPhone:        make([]string, 0, n),
+		BankCity:         make([]string, 0, n),
+	}
+}
+
+// FromResults returns a `Batch` of results[i] looked up for bins[i]. A
+// nil result makes a row that isn't Found.
+func FromResults(bins []string, results []*binlookup.BIN) *Batch {
+	b := New(len(bins))
+	for i, bin := range bins {
+		b.Append(bin, results[i])
+	}
+
+	return b
+}
+
+// Len returns the number of rows in b.
+func (b *Batch) Len() int {
+	return len(b.BIN)
+}
+
+// Append adds the result r looked up for bin as a row of b. A nil r makes
+// a row that isn't Found.
+func (b *Batch) Append(bin string, r *binlookup.BIN) {
+	found := r != nil
+	if !found {
+		r = &binlookup.BIN{}
+	}
+
+	b.BIN = append(b.BIN, bin)
+	b.Found = append(b.Found, found)
+	b.Scheme = append(b.Scheme, string(r.Scheme))
+	b.Type = append(b.Type, string(r.Type))
+	b.Brand = append(b.Brand, r.Brand)
+	b.Prepaid = append(b.Prepaid, r.Prepaid)
+
+	var n binlookup.Number
+	if r.Number != nil {
+		n = *r.Number
+	}
+	b.NumberLength = append(b.NumberLength, n.Length)
+	b.NumberLuhn = append(b.NumberLuhn, n.Luhn)
+
+	b.CountryNumeric = append(b.CountryNumeric, r.Country.Numeric)
+	b.CountryAlpha2 = append(b.CountryAlpha2, r.Country.Short)
+	b.CountryName = append(b.CountryName, r.Country.Name)
+	b.CountryEmoji = append(b.CountryEmoji, r.Country.Emoji)
+	b.CountryCurrency = append(b.CountryCurrency, r.Country.Currency)
+	b.CountryLatitude = append(b.CountryLatitude, r.Country.Lat)
+	b.CountryLongitude = append(b.CountryLongitude, r.Country.Long)
+
+	var bank binlookup.Bank
+	if r.Bank != nil {
+		bank = *r.Bank
+	}
+	b.BankName = append(b.BankName, bank.Name)
+	b.BankURL = append(b.BankURL, bank.URL)
+	b.BankPhone = append(b.BankPhone, bank.Phone)
+	b.BankCity = append(b.BankCity, bank.City)
+}
+
+// Tokenize replaces the BIN column of b with the tokens t issues, for
+// exports that mustn't hold even card number prefixes. b is left as is
+// when t fails.
+func (b *Batch) Tokenize(t binlookup.Tokenizer) error {
+	tokens := make([]string, len(b.BIN))
+	for i, bin := range b.BIN {
+		tok, err := t(bin)
+		if err != nil {
+			return err
+		}
+		tokens[i] = tok
+	}
+	b.BIN = tokens
+
+	return nil
+}
+
+// Column is a named column of a `Batch`. Values is one of []string,
+// []bool, []*bool, []int or []float64.
+type Column struct {
+	Name   string
+	Values interface{}
+}
+
+// Columns returns the columns of b in schema order, named after the JSON
+// paths of the fields of `binlookup.BIN`.
+func (b *Batch) Columns() []Column {
+	return []Column{
+		{"bin", b.BIN},
+		{"found", b.Found},
+		{"scheme", b.Scheme},
+		{"type", b.Type},
+		{"brand", b.Brand},
+		{"prepaid", b.Prepaid},
+		{"number.length", b.NumberLength},
+		{"number.luhn", b.NumberLuhn},
+		{"country.numeric", b.CountryNumeric},
+		{"country.alpha2", b.CountryAlpha2},
+		{"country.name", b.CountryName},
+		{"country.emoji", b.CountryEmoji},
+		{"country.currency", b.CountryCurrency},
+		{"country.latitude", b.CountryLatitude},
+		{"country.longitude", b.CountryLongitude},
+		{"bank.name", b.BankName},
+		{"bank.url", b.BankURL},
+		{"bank.phone", b.BankPhone},
+		{"bank.city", b.BankCity},
+	}
+}
@@ -0,0 +1,63 @@
+package columnar
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestFromResults(t *testing.T) {
+	results := []*binlookup.BIN{
+		{Scheme: "visa", Country: binlookup.Country{Short: "DK"}, Bank: &binlookup.Bank{Name: "Jyske Bank"}},
+		nil,
+	}
+
+	b := FromResults([]string{"45717360", "99999999"}, results)
+
+	if b.Len() != 2 {
+		t.Fatalf("batch has %d rows, want 2", b.Len())
+	}
+
+	if !b.Found[0] || b.Found[1] {
+		t.Fatalf("found column is %v", b.Found)
+	}
+
+	if b.Scheme[0] != "visa" || b.CountryAlpha2[0] != "DK" || b.BankName[0] != "Jyske Bank" || b.Scheme[1] != "" {
+		t.Fatalf("unexpected batch %+v", b)
+	}
+
+	if b.Prepaid[0] != nil || b.Prepaid[1] != nil {
+		t.Fatalf("unknown prepaid values aren't null: %v", b.Prepaid)
+	}
+}
+
+func TestColumnsHaveEqualLengths(t *testing.T) {
+	b := FromResults([]string{"45717360", "52882301", "99999999"}, []*binlookup.BIN{{}, {}, nil})
+
+	for _, c := range b.Columns() {
+		if n := reflect.ValueOf(c.Values).Len(); n != b.Len() {
+			t.Fatalf("column %v has %d values, want %d", c.Name, n, b.Len())
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	b := FromResults([]string{"45717360", "52882301"}, []*binlookup.BIN{{}, {}})
+
+	err := b.Tokenize(func(bin string) (string, error) {
+		return "tok_" + bin[len(bin)-2:], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.BIN[0] != "tok_60" || b.BIN[1] != "tok_01" {
+		t.Fatalf("BIN column is %v", b.BIN)
+	}
+
+	if err := b.Tokenize(func(string) (string, error) { return "", errors.New("vault down") }); err == nil || b.BIN[0] != "tok_60" {
+		t.Fatal("failed tokenization altered the batch")
+	}
+}
@@ -0,0 +1,89 @@
+package binlookup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SameCountry reports whether a and b are of the same country, by their
+// alpha-2 code or, lacking it, their numeric one. BINs of no known
+// country aren't of the same country as any, nil ones neither.
+func SameCountry(a, b *BIN) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	switch {
+	case a.Country.Short != "" && b.Country.Short != "":
+		return strings.EqualFold(a.Country.Short, b.Country.Short)
+	case a.Country.Numeric != "" && b.Country.Numeric != "":
+		return a.Country.Numeric == b.Country.Numeric
+	}
+
+	return false
+}
+
+// SameIssuer reports whether a and b are issued by the same bank, by the
+// names of their banks regardless of case, punctuation and legal form,
+// so that "JYSKE BANK A/S" and "Jyske Bank" are the same, or lacking one
+// of them, the hosts of their URLs. Banks of the same name in countries
+// known to differ, such as the subsidiaries of a group, aren't the same
+// issuer. BINs of no known bank aren't of the same issuer as any: a
+// missing bank tells nothing of it.
+func SameIssuer(a, b *BIN) bool {
+	if a == nil || b == nil || a.Bank == nil || b.Bank == nil {
+		return false
+	}
+
+	if a.Country.Short != "" && b.Country.Short != "" && !SameCountry(a, b) {
+		return false
+	}
+
+	if x, y := issuerKey(a.Bank.Name), issuerKey(b.Bank.Name); x != "" && y != "" {
+		return x == y
+	}
+	if x, y := bankHost(a.Bank.URL), bankHost(b.Bank.URL); x != "" && y != "" {
+		return x == y
+	}
+
+	return false
+}
+
+// legalForms are the words of the legal forms bank names end with, as
+// issuerKey leaves them.
+var legalForms = map[string]bool{
+	"ag": true, "as": true, "asa": true, "ab": true, "bv": true, "co": true, "corp": true,
+	"corporation": true, "gmbh": true, "inc": true, "limited": true, "ltd": true, "na": true,
+	"nv": true, "plc": true, "sa": true, "sae": true, "spa": true,
+}
+
+// issuerKey returns the bank name, lower case, of letters and digits
+// only, stripped of the legal form it ends with.
+func issuerKey(name string) string {
+	// The dots and slashes of abbreviations, such as those of A/S and
+	// N.A., go before the name is split into words.
+	name = strings.NewReplacer(".", "", "/", "").Replace(strings.ToLower(name))
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for len(words) > 1 && legalForms[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// bankHost returns the host of the bank URL u, lower case, without its
+// www.
+func bankHost(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	if i := strings.IndexAny(u, "/?#"); i >= 0 {
+		u = u[:i]
+	}
+
+	return strings.TrimPrefix(u, "www.")
+}
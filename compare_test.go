@@ -0,0 +1,50 @@
+package binlookup
+
+import "testing"
+
+func TestSameCountry(t *testing.T) {
+	dk := &BIN{Country: Country{Short: "DK"}}
+
+	for _, tc := range []struct {
+		a, b *BIN
+		want bool
+	}{
+		{dk, &BIN{Country: Country{Short: "dk"}}, true},
+		{dk, &BIN{Country: Country{Short: "SE"}}, false},
+		{&BIN{Country: Country{Numeric: "208"}}, &BIN{Country: Country{Numeric: "208"}}, true},
+		{dk, &BIN{}, false},
+		{&BIN{}, &BIN{}, false},
+		{dk, nil, false},
+	} {
+		if got := SameCountry(tc.a, tc.b); got != tc.want {
+			t.Errorf("SameCountry(%+v, %+v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSameIssuer(t *testing.T) {
+	jyske := &BIN{Country: Country{Short: "DK"}, Bank: &Bank{Name: "JYSKE BANK A/S", URL: "www.jyskebank.dk"}}
+
+	for _, tc := range []struct {
+		name string
+		b    *BIN
+		want bool
+	}{
+		{"Name", &BIN{Country: Country{Short: "DK"}, Bank: &Bank{Name: "Jyske Bank"}}, true},
+		{"URL", &BIN{Bank: &Bank{URL: "https://jyskebank.dk/privat"}}, true},
+		{"Other", &BIN{Country: Country{Short: "DK"}, Bank: &Bank{Name: "Nykredit", URL: "www.jyskebank.dk"}}, false},
+		{"Country", &BIN{Country: Country{Short: "SE"}, Bank: &Bank{Name: "Jyske Bank"}}, false},
+		{"NoBank", &BIN{Country: Country{Short: "DK"}}, false},
+		{"EmptyBank", &BIN{Bank: &Bank{}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SameIssuer(jyske, tc.b); got != tc.want {
+				t.Fatalf("SameIssuer(%+v, %+v) = %v, want %v", jyske.Bank, tc.b.Bank, got, tc.want)
+			}
+		})
+	}
+
+	if !SameIssuer(&BIN{Bank: &Bank{Name: "Citibank, N.A."}}, &BIN{Bank: &Bank{Name: "CITIBANK"}}) {
+		t.Fatal("legal form not ignored")
+	}
+}
@@ -0,0 +1,52 @@
+package binlookup
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipPool holds the readers decompressing the responses of upstream,
+// which are costly to allocate for every lookup.
+var gzipPool sync.Pool
+
+// gzipBody is the body of a response decompressed, returning its reader to
+// gzipPool once closed.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipBody) Close() error {
+	gzipPool.Put(g.Reader)
+	return g.body.Close()
+}
+
+// decompress replaces the body of resp with its decompressed content when
+// upstream compressed it, reporting whether it did. The transport of the
+// package decompresses transparently on its own, but only when it asked
+// for compression itself, unlike those given by `WithTransport`.
+func decompress(resp *http.Response) (compressed bool, err error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp.Uncompressed, nil
+	}
+
+	zr, _ := gzipPool.Get().(*gzip.Reader)
+	if zr == nil {
+		zr, err = gzip.NewReader(resp.Body)
+	} else {
+		err = zr.Reset(resp.Body)
+	}
+	if err != nil {
+		return
+	}
+
+	resp.Body = &gzipBody{zr, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package binlookup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientCompression(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Write([]byte(cannedBIN))
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		if r.URL.Path != "/"+CorrectBIN {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+
+		zw := gzip.NewWriter(w)
+		if r.URL.Path == "/"+CorrectBIN {
+			zw.Write([]byte(cannedBIN))
+		} else {
+			zw.Write([]byte(`{"message":"Slow down"}`))
+		}
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	for _, c := range []*Client{
+		New(WithBaseURL(srv.URL)),
+		New(WithBaseURL(srv.URL), WithTransport(&http.Transport{})),
+	} {
+		b, err := c.Search(context.TODO(), CorrectBIN)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+
+		if b.Bank.Name != "Jyske Bank" || !b.Meta.Compressed {
+			t.Fatalf("lookup returned %+v", b)
+		}
+
+		var he *HTTPError
+		if _, err := c.Search(context.TODO(), "12345678"); !errors.As(err, &he) || he.Message != "Slow down" {
+			t.Fatalf("lookup returned %v", err)
+		}
+	}
+}
+
+func TestClientUncompressed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	b, err := New(WithBaseURL(srv.URL)).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Meta.Compressed {
+		t.Fatal("uncompressed response reported compressed")
+	}
+}
@@ -0,0 +1,58 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLookupCountry(t *testing.T) {
+	for _, code := range []string{"DK", "dnk", "208"} {
+		if c, ok := LookupCountry(code); !ok || c != (CountryCodes{"DK", "DNK", "208", "Denmark"}) {
+			t.Fatalf("LookupCountry(%q) returned %+v, %v", code, c, ok)
+		}
+	}
+
+	if c, ok := LookupCountry("36"); !ok || c.Alpha3 != "AUS" {
+		t.Fatalf("unpadded numeric code resolved as %+v, %v", c, ok)
+	}
+
+	if _, ok := LookupCountry("XX"); ok {
+		t.Fatal("unknown country resolved")
+	}
+}
+
+func TestCountryNormalize(t *testing.T) {
+	c := Country{Short: "AU", Numeric: "36"}
+	if err := c.Normalize(); err != nil || c.Alpha3 != "AUS" || c.Numeric != "036" {
+		t.Fatalf("normalized into %+v, %v", c, err)
+	}
+
+	c = Country{Short: "DK"}
+	if err := c.Normalize(); err != nil || c.Numeric != "208" {
+		t.Fatalf("missing numeric code normalized into %+v, %v", c, err)
+	}
+
+	for _, c := range []Country{{Short: "XX"}, {Short: "DK", Numeric: "036"}, {Short: "DK", Numeric: "2o8"}} {
+		if err := c.Normalize(); !errors.Is(err, ErrUnknownCountry) {
+			t.Fatalf("%+v normalized with %v", c, err)
+		}
+	}
+
+	var b BIN
+	if err := json.Unmarshal([]byte(cannedBIN), &b); err != nil || b.Country.Alpha3 != "DNK" {
+		t.Fatalf("decoded country %+v, %v", b.Country, err)
+	}
+}
+
+func TestCountryTable(t *testing.T) {
+	for _, c := range countryTable {
+		if len(c.Alpha2) != 2 || len(c.Alpha3) != 3 || len(c.Numeric) != 3 || c.Name == "" {
+			t.Fatalf("malformed entry %+v", c)
+		}
+	}
+
+	if len(countryIndex) != 3*len(countryTable) {
+		t.Fatal("codes shared between countries")
+	}
+}
@@ -0,0 +1,276 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultCSVWindow is the number of rows `EnrichCSV` holds at most while
+// their BINs are looked up, unless changed through `CSVOptions.Window`.
+const DefaultCSVWindow = 1024
+
+// CSVColumn is a column `EnrichCSV` appends: its header, and its value
+// for the BIN of a row, nil when the BIN failed to be looked up or isn't
+// known.
+type CSVColumn struct {
+	Name  string
+	Value func(b *BIN) string
+}
+
+// DefaultCSVColumns are the columns `EnrichCSV` appends unless changed
+// through `CSVOptions.Columns`: the scheme, type, country and bank.
+var DefaultCSVColumns = []CSVColumn{
+	{"scheme", func(b *BIN) string { return string(b.Scheme) }},
+	{"type", func(b *BIN) string { return string(b.Type) }},
+	{"country", func(b *BIN) string { return b.Country.Short }},
+	{"bank", func(b *BIN) string {
+		if b.Bank == nil {
+			return ""
+		}
+		return b.Bank.Name
+	}},
+}
+
+// CSVOptions configures `EnrichCSV`.
+type CSVOptions struct {
+	// Lookuper looks the BINs up, `Default` when nil.
+	Lookuper Lookuper
+
+	// Column is the name of the column holding the BINs, or card
+	// numbers, of which the first Digits are looked up: 6 or 8, 8 when
+	// zero.
+	Column string
+	Digits int
+
+	// Columns are the columns appended, `DefaultCSVColumns` when nil.
+	Columns []CSVColumn
+
+	// Comma is the field delimiter of both files, ',' when zero.
+	Comma rune
+
+	// Workers is the number of lookups made at once,
+	// `DefaultEnricherWorkers` when zero.
+	Workers int
+
+	// Window is the number of rows held at most while their BINs are
+	// looked up, `DefaultCSVWindow` when zero. Rows are written in order,
+	// so a slow lookup holds back the rows after it up to Window.
+	Window int
+
+	// OnProgress is given the `Progress` every Interval, a second when
+	// zero, and once over. Done and Failed count rows; Remaining and ETA
+	// stay zero, a stream not telling how many are left.
+	OnProgress func(p Progress)
+	Interval   time.Duration
+}
+
+// csvRow is a row of `EnrichCSV`, along with the lookup of its BIN.
+type csvRow struct {
+	record []string
+	lookup *csvLookup
+}
+
+// csvLookup is the lookup of a BIN, shared by the rows holding it.
+type csvLookup struct {
+	bin  string
+	done chan struct{}
+	r    Result
+}
+
+// EnrichCSV copies the CSV r to w, appending the columns of opts for the
+// BIN in the column of opts to every row. Rows are streamed through a
+// window of them, for files of gigabytes to be enriched in memory bound by
+// the window and the number of distinct BINs, each of which is looked up
+// once.
+//
+// Rows whose BIN failed to be looked up, or isn't known, get empty
+// columns; the number of failures is returned as an error once the whole
+// of r is written.
+func EnrichCSV(ctx context.Context, r io.Reader, w io.Writer, opts CSVOptions) error {
+	l := opts.Lookuper
+	if l == nil {
+		l = Default()
+	}
+	digits := opts.Digits
+	if digits == 0 {
+		digits = 8
+	}
+	columns := opts.Columns
+	if columns == nil {
+		columns = DefaultCSVColumns
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultEnricherWorkers
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultCSVWindow
+	}
+
+	cr := csv.NewReader(r)
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cr.Comma, cw.Comma = opts.Comma, opts.Comma
+	}
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("Reading the Header Failed: %w", err)
+	}
+
+	col := -1
+	for i, name := range header {
+		if name == opts.Column {
+			col = i
+		}
+	}
+	if col < 0 {
+		return fmt.Errorf("No %q Column in the Header", opts.Column)
+	}
+
+	for _, c := range columns {
+		header = append(header, c.Name)
+	}
+	cw.Write(header)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows := make(chan csvRow, window)
+	lookups := make(chan *csvLookup)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for lk := range lookups {
+				lk.r.BIN, lk.r.Err = l.Search(ctx, lk.bin)
+				close(lk.done)
+			}
+		}()
+	}
+
+	// The rows are read on a goroutine of their own, their lookups
+	// started along the way, while they're written here in order.
+	var readErr error
+	go func() {
+		defer close(rows)
+		defer close(lookups)
+
+		seen := make(map[string]*csvLookup)
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+
+			bin := ""
+			if col < len(record) {
+				bin = record[col]
+			}
+			if b, err := BINFromPAN(bin, digits); err == nil {
+				bin = b
+			}
+
+			lk, ok := seen[bin]
+			if !ok {
+				lk = &csvLookup{bin: bin, done: make(chan struct{})}
+				seen[bin] = lk
+
+				select {
+				case lookups <- lk:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case rows <- csvRow{record, lk}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	var p Progress
+	report := func() {
+		if opts.OnProgress != nil {
+			p.Elapsed = time.Since(start)
+			opts.OnProgress(p)
+		}
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	values := make([]string, len(columns))
+	for row := range rows {
+		select {
+		case <-row.lookup.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		r := row.lookup.r
+		failed := r.Err != nil && !errors.Is(r.Err, ErrNotFound)
+		for i, c := range columns {
+			values[i] = ""
+			if r.BIN != nil {
+				values[i] = c.Value(r.BIN)
+			}
+		}
+
+		if err := cw.Write(append(row.record, values...)); err != nil {
+			return err
+		}
+
+		if failed {
+			p.Failed++
+		} else {
+			p.Done++
+		}
+
+		select {
+		case <-t.C:
+			report()
+		default:
+		}
+	}
+	wg.Wait()
+
+	cw.Flush()
+	report()
+
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if readErr != nil {
+		return readErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if p.Failed > 0 {
+		return fmt.Errorf("%d Rows Left Unenriched by Failed Lookups", p.Failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package binlookup_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/binlookuptest"
+)
+
+func TestEnrichCSV(t *testing.T) {
+	srv := binlookuptest.NewServer()
+	defer srv.Close()
+
+	srv.Add("45717360", binlookuptest.JyskeBank)
+	srv.Fail("52882301", http.StatusInternalServerError)
+
+	c := srv.Client()
+	defer c.Close()
+
+	in := `id,card_bin,amount
+1,4571 7360 1234 5678,10.00
+2,99999999,5.00
+3,45717360,7.50
+4,52882301,1.00
+5,45717360,2.50
+`
+
+	var out strings.Builder
+	var last binlookup.Progress
+	err := binlookup.EnrichCSV(context.TODO(), strings.NewReader(in), &out, binlookup.CSVOptions{
+		Lookuper:   c,
+		Column:     "card_bin",
+		Window:     2,
+		OnProgress: func(p binlookup.Progress) { last = p },
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 Rows") {
+		t.Fatalf("EnrichCSV returned %v, want the failure of a row", err)
+	}
+
+	want := `id,card_bin,amount,scheme,type,country,bank
+1,4571 7360 1234 5678,10.00,visa,debit,DK,Jyske Bank
+2,99999999,5.00,,,,
+3,45717360,7.50,visa,debit,DK,Jyske Bank
+4,52882301,1.00,,,,
+5,45717360,2.50,visa,debit,DK,Jyske Bank
+`
+	if out.String() != want {
+		t.Fatalf("enriched\n%v\nwant\n%v", out.String(), want)
+	}
+
+	if n := len(srv.Requests()); n != 3 {
+		t.Fatalf("%d requests made for 3 distinct BINs", n)
+	}
+
+	if last.Done != 4 || last.Failed != 1 {
+		t.Fatalf("final progress %+v", last)
+	}
+}
+
+func TestEnrichCSVColumns(t *testing.T) {
+	srv := binlookuptest.NewServer()
+	defer srv.Close()
+
+	srv.Add("45717360", binlookuptest.JyskeBank)
+
+	c := srv.Client()
+	defer c.Close()
+
+	var out strings.Builder
+	err := binlookup.EnrichCSV(context.TODO(), strings.NewReader("pan;id\n45717360;1\n"), &out, binlookup.CSVOptions{
+		Lookuper: c,
+		Column:   "pan",
+		Comma:    ';',
+		Columns:  []binlookup.CSVColumn{{"currency", func(b *binlookup.BIN) string { return b.Country.Currency }}},
+	})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if want := "pan;id;currency\n45717360;1;DKK\n"; out.String() != want {
+		t.Fatalf("enriched %q, want %q", out.String(), want)
+	}
+}
+
+func TestEnrichCSVMissingColumn(t *testing.T) {
+	err := binlookup.EnrichCSV(context.TODO(), strings.NewReader("id,pan\n"), &strings.Builder{}, binlookup.CSVOptions{Column: "card_bin"})
+	if err == nil || !strings.Contains(err.Error(), "card_bin") {
+		t.Fatalf("EnrichCSV returned %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package binlookup
+
+import "strings"
+
+// Currency holds the ISO 4217 details of a currency.
+type Currency struct {
+	// Code is the alphabetic code, such as EUR, and Numeric the numeric
+	// one, such as 978.
+	Code    string
+	Numeric string
+
+	Name string
+
+	// Symbol is the one amounts are displayed with locally, such as €,
+	// the code itself for the currencies without any.
+	Symbol string
+
+	// MinorUnits is the number of digits after the decimal separator,
+	// such as 2 for the cents of EUR and 0 for JPY.
+	MinorUnits int
+}
+
+// LookupCurrency returns the details of the currency of code, such as the
+// `Country.Currency` of a BIN, with ok false when it isn't an active ISO
+// 4217 one.
+func LookupCurrency(code string) (c Currency, ok bool) {
+	c, ok = currencies[strings.ToUpper(code)]
+	return
+}
+
+// CurrencyDetails returns the details of the currency of c, see
+// `LookupCurrency`.
+func (c Country) CurrencyDetails() (Currency, bool) {
+	return LookupCurrency(c.Currency)
+}
+
+// currencies are the active ISO 4217 currencies, keyed by code.
+var currencies = func() map[string]Currency {
+	m := make(map[string]Currency, len(currencyTable))
+	for _, c := range currencyTable {
+		m[c.Code] = c
+	}
+
+	return m
+}()
+
+var currencyTable = []Currency{
+	{"AED", "784", "UAE Dirham", "د.إ", 2},
+	{"AFN", "971", "Afghani", "؋", 2},
+	{"ALL", "008", "Lek", "L", 2},
+	{"AMD", "051", "Armenian Dram", "֏", 2},
+	{"AOA", "973", "Kwanza", "Kz", 2},
+	{"ARS", "032", "Argentine Peso", "$", 2},
+	{"AUD", "036", "Australian Dollar", "$", 2},
+	{"AWG", "533", "Aruban Florin", "ƒ", 2},
+	{"AZN", "944", "Azerbaijan Manat", "₼", 2},
+	{"BAM", "977", "Convertible Mark", "KM", 2},
+	{"BBD", "052", "Barbados Dollar", "$", 2},
+	{"BDT", "050", "Taka", "৳", 2},
+	{"BGN", "975", "Bulgarian Lev", "лв", 2},
+	{"BHD", "048", "Bahraini Dinar", ".د.ب", 3},
+	{"BIF", "108", "Burundi Franc", "FBu", 0},
+	{"BMD", "060", "Bermudian Dollar", "$", 2},
+	{"BND", "096", "Brunei Dollar", "$", 2},
+	{"BOB", "068", "Boliviano", "Bs", 2},
+	{"BRL", "986", "Brazilian Real", "R$", 2},
+	{"BSD", "044", "Bahamian Dollar", "$", 2},
+	{"BTN", "064", "Ngultrum", "Nu.", 2},
+	{"BWP", "072", "Pula", "P", 2},
+	{"BYN", "933", "Belarusian Ruble", "Br", 2},
+	{"BZD", "084", "Belize Dollar", "$", 2},
+	{"CAD", "124", "Canadian Dollar", "$", 2},
+	{"CDF", "976", "Congolese Franc", "FC", 2},
+	{"CHF", "756", "Swiss Franc", "CHF", 2},
+	{"CLP", "152", "Chilean Peso", "$", 0},
+	{"CNY", "156", "Yuan Renminbi", "¥", 2},
+	{"COP", "170", "Colombian Peso", "$", 2},
+	{"CRC", "188", "Costa Rican Colon", "₡", 2},
+	{"CUP", "192", "Cuban Peso", "$", 2},
+	{"CVE", "132", "Cabo Verde Escudo", "$", 2},
+	{"CZK", "203", "Czech Koruna", "Kč", 2},
+	{"DJF", "262", "Djibouti Franc", "Fdj", 0},
+	{"DKK", "208", "Danish Krone", "kr", 2},
+	{"DOP", "214", "Dominican Peso", "$", 2},
+	{"DZD", "012", "Algerian Dinar", "د.ج", 2},
+	{"EGP", "818", "Egyptian Pound", "£", 2},
+	{"ERN", "232", "Nakfa", "Nfk", 2},
+	{"ETB", "230", "Ethiopian Birr", "Br", 2},
+	{"EUR", "978", "Euro", "€", 2},
+	{"FJD", "242", "Fiji Dollar", "$", 2},
+	{"FKP", "238", "Falkland Islands Pound", "£", 2},
+	{"GBP", "826", "Pound Sterling", "£", 2},
+	{"GEL", "981", "Lari", "₾", 2},
+	{"GHS", "936", "Ghana Cedi", "₵", 2},
+	{"GIP", "292", "Gibraltar Pound", "£", 2},
+	{"GMD", "270", "Dalasi", "D", 2},
+	{"GNF", "324", "Guinean Franc", "FG", 0},
+	{"GTQ", "320", "Quetzal", "Q", 2},
+	{"GYD", "328", "Guyana Dollar", "$", 2},
+	{"HKD", "344", "Hong Kong Dollar", "$", 2},
+	{"HNL", "340", "Lempira", "L", 2},
+	{"HTG", "332", "Gourde", "G", 2},
+	{"HUF", "348", "Forint", "Ft", 2},
+	{"IDR", "360", "Rupiah", "Rp", 2},
+	{"ILS", "376", "New Israeli Sheqel", "₪", 2},
+	{"INR", "356", "Indian Rupee", "₹", 2},
+	{"IQD", "368", "Iraqi Dinar", "ع.د", 3},
+	{"IRR", "364", "Iranian Rial", "﷼", 2},
+	{"ISK", "352", "Iceland Krona", "kr", 0},
+	{"JMD", "388", "Jamaican Dollar", "$", 2},
+	{"JOD", "400", "Jordanian Dinar", "د.ا", 3},
+	{"JPY", "392", "Yen", "¥", 0},
+	{"KES", "404", "Kenyan Shilling", "KSh", 2},
+	{"KGS", "417", "Som", "с", 2},
+	{"KHR", "116", "Riel", "៛", 2},
+	{"KMF", "174", "Comorian Franc", "CF", 0},
+	{"KPW", "408", "North Korean Won", "₩", 2},
+	{"KRW", "410", "Won", "₩", 0},
+	{"KWD", "414", "Kuwaiti Dinar", "د.ك", 3},
+	{"KYD", "136", "Cayman Islands Dollar", "$", 2},
+	{"KZT", "398", "Tenge", "₸", 2},
+	{"LAK", "418", "Lao Kip", "₭", 2},
+	{"LBP", "422", "Lebanese Pound", "ل.ل", 2},
+	{"LKR", "144", "Sri Lanka Rupee", "Rs", 2},
+	{"LRD", "430", "Liberian Dollar", "$", 2},
+	{"LSL", "426", "Loti", "L", 2},
+	{"LYD", "434", "Libyan Dinar", "ل.د", 3},
+	{"MAD", "504", "Moroccan Dirham", "د.م.", 2},
+	{"MDL", "498", "Moldovan Leu", "L", 2},
+	{"MGA", "969", "Malagasy Ariary", "Ar", 2},
+	{"MKD", "807", "Denar", "ден", 2},
+	{"MMK", "104", "Kyat", "K", 2},
+	{"MNT", "496", "Tugrik", "₮", 2},
+	{"MOP", "446", "Pataca", "MOP$", 2},
+	{"MRU", "929", "Ouguiya", "UM", 2},
+	{"MUR", "480", "Mauritius Rupee", "₨", 2},
+	{"MVR", "462", "Rufiyaa", "Rf", 2},
+	{"MWK", "454", "Malawi Kwacha", "MK", 2},
+	{"MXN", "484", "Mexican Peso", "$", 2},
+	{"MYR", "458", "Malaysian Ringgit", "RM", 2},
+	{"MZN", "943", "Mozambique Metical", "MT", 2},
+	{"NAD", "516", "Namibia Dollar", "$", 2},
+	{"NGN", "566", "Naira", "₦", 2},
+	{"NIO", "558", "Cordoba Oro", "C$", 2},
+	{"NOK", "578", "Norwegian Krone", "kr", 2},
+	{"NPR", "524", "Nepalese Rupee", "₨", 2},
+	{"NZD", "554", "New Zealand Dollar", "$", 2},
+	{"OMR", "512", "Rial Omani", "ر.ع.", 3},
+	{"PAB", "590", "Balboa", "B/.", 2},
+	{"PEN", "604", "Sol", "S/", 2},
+	{"PGK", "598", "Kina", "K", 2},
+	{"PHP", "608", "Philippine Peso", "₱", 2},
+	{"PKR", "586", "Pakistan Rupee", "₨", 2},
+	{"PLN", "985", "Zloty", "zł", 2},
+	{"PYG", "600", "Guarani", "₲", 0},
+	{"QAR", "634", "Qatari Rial", "ر.ق", 2},
+	{"RON", "946", "Romanian Leu", "lei", 2},
+	{"RSD", "941", "Serbian Dinar", "дин.", 2},
+	{"RUB", "643", "Russian Ruble", "₽", 2},
+	{"RWF", "646", "Rwanda Franc", "FRw", 0},
+	{"SAR", "682", "Saudi Riyal", "ر.س", 2},
+	{"SBD", "090", "Solomon Islands Dollar", "$", 2},
+	{"SCR", "690", "Seychelles Rupee", "₨", 2},
+	{"SDG", "938", "Sudanese Pound", "ج.س.", 2},
+	{"SEK", "752", "Swedish Krona", "kr", 2},
+	{"SGD", "702", "Singapore Dollar", "$", 2},
+	{"SHP", "654", "Saint Helena Pound", "£", 2},
+	{"SLE", "925", "Leone", "Le", 2},
+	{"SOS", "706", "Somali Shilling", "Sh", 2},
+	{"SRD", "968", "Surinam Dollar", "$", 2},
+	{"SSP", "728", "South Sudanese Pound", "£", 2},
+	{"STN", "930", "Dobra", "Db", 2},
+	{"SYP", "760", "Syrian Pound", "£", 2},
+	{"SZL", "748", "Lilangeni", "E", 2},
+	{"THB", "764", "Baht", "฿", 2},
+	{"TJS", "972", "Somoni", "SM", 2},
+	{"TMT", "934", "Turkmenistan New Manat", "m", 2},
+	{"TND", "788", "Tunisian Dinar", "د.ت", 3},
+	{"TOP", "776", "Pa’anga", "T$", 2},
+	{"TRY", "949", "Turkish Lira", "₺", 2},
+	{"TTD", "780", "Trinidad and Tobago Dollar", "$", 2},
+	{"TWD", "901", "New Taiwan Dollar", "$", 2},
+	{"TZS", "834", "Tanzanian Shilling", "TSh", 2},
+	{"UAH", "980", "Hryvnia", "₴", 2},
+	{"UGX", "800", "Uganda Shilling", "USh", 0},
+	{"USD", "840", "US Dollar", "$", 2},
+	{"UYU", "858", "Peso Uruguayo", "$", 2},
+	{"UZS", "860", "Uzbekistan Sum", "soʻm", 2},
+	{"VES", "928", "Bolívar Soberano", "Bs.", 2},
+	{"VND", "704", "Dong", "₫", 0},
+	{"VUV", "548", "Vatu", "VT", 0},
+	{"WST", "882", "Tala", "T", 2},
+	{"XAF", "950", "CFA Franc BEAC", "FCFA", 0},
+	{"XCD", "951", "East Caribbean Dollar", "$", 2},
+	{"XOF", "952", "CFA Franc BCEAO", "CFA", 0},
+	{"XPF", "953", "CFP Franc", "₣", 0},
+	{"YER", "886", "Yemeni Rial", "﷼", 2},
+	{"ZAR", "710", "Rand", "R", 2},
+	{"ZMW", "967", "Zambian Kwacha", "ZK", 2},
+	{"ZWG", "924", "Zimbabwe Gold", "ZiG", 2},
+}
@@ -0,0 +1,33 @@
+package binlookup
+
+import "testing"
+
+func TestLookupCurrency(t *testing.T) {
+	for code, want := range map[string]Currency{
+		"EUR": {"EUR", "978", "Euro", "€", 2},
+		"jpy": {"JPY", "392", "Yen", "¥", 0},
+		"KWD": {"KWD", "414", "Kuwaiti Dinar", "د.ك", 3},
+	} {
+		if c, ok := LookupCurrency(code); !ok || c != want {
+			t.Fatalf("LookupCurrency(%q) returned %+v, %v", code, c, ok)
+		}
+	}
+
+	if _, ok := LookupCurrency("XXX"); ok {
+		t.Fatal("unknown currency resolved")
+	}
+
+	if c, ok := (Country{Currency: "DKK"}).CurrencyDetails(); !ok || c.Symbol != "kr" {
+		t.Fatalf("CurrencyDetails returned %+v, %v", c, ok)
+	}
+}
+
+func TestCurrencyTable(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range currencyTable {
+		if len(c.Code) != 3 || len(c.Numeric) != 3 || c.Name == "" || c.Symbol == "" || seen[c.Numeric] {
+			t.Fatalf("malformed or duplicate entry %+v", c)
+		}
+		seen[c.Numeric] = true
+	}
+}
@@ -0,0 +1,84 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// LoadCSV reads a `Dataset` of version out of r, in the CSV of the public
+// binlist-data release: a header naming the columns, among which bin,
+// brand, type, category, issuer, alpha_2, country, latitude, longitude,
+// bank_phone and bank_url, and a BIN per row. The brand is the scheme and
+// the category the brand of the BIN; the columns missing are left empty,
+// those unknown ignored. The error of a malformed row tells its number.
+func LoadCSV(version string, r io.Reader) (*Dataset, error) {
+	return (&loader{unit: "Row"}).loadCSV(version, r)
+}
+
+func (l *loader) loadCSV(version string, r io.Reader) (*Dataset, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: Reading the Header Failed: %w", ErrInvalidDataset, err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["bin"]; !ok {
+		return nil, fmt.Errorf("%w: No bin Column in the Header", ErrInvalidDataset)
+	}
+
+	d := &Dataset{Version: version, bins: make(map[string]*binlookup.BIN)}
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if err := l.reject(row, "", err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		b := &binlookup.BIN{
+			Scheme: binlookup.ParseScheme(field("brand")),
+			Type:   binlookup.ParseCardType(field("type")),
+			Brand:  field("category"),
+			Country: binlookup.Country{
+				Short: strings.ToUpper(field("alpha_2")),
+				Name:  field("country"),
+			},
+		}
+		b.Country.Lat, _ = strconv.ParseFloat(field("latitude"), 64)
+		b.Country.Long, _ = strconv.ParseFloat(field("longitude"), 64)
+		b.Country.Normalize()
+
+		if bank := (binlookup.Bank{Name: field("issuer"), URL: field("bank_url"), Phone: field("bank_phone")}); bank != (binlookup.Bank{}) {
+			b.Bank = &bank
+		}
+
+		if err := l.add(d, row, field("bin"), b); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
@@ -0,0 +1,53 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+const release = `bin,brand,type,category,issuer,alpha_2,alpha_3,country,latitude,longitude,bank_phone,bank_url
+457173,VISA,DEBIT,CLASSIC,JYSKE BANK A/S,DK,DNK,Denmark,56,10,+4589893300,www.jyskebank.dk
+528823,MASTERCARD,CREDIT,,,US,USA,United States,38,-97,,
+`
+
+func TestLoadCSV(t *testing.T) {
+	d, err := LoadCSV("2026-10", strings.NewReader(release))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Len() != 2 || d.Validate() != nil {
+		t.Fatalf("loaded %d BINs, validation: %v", d.Len(), d.Validate())
+	}
+
+	b, err := d.Search(context.TODO(), "4571736012345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Scheme != binlookup.SchemeVisa || b.Type != binlookup.TypeDebit || b.Brand != "CLASSIC" || b.Country.Alpha3 != "DNK" || b.Country.Lat != 56 {
+		t.Fatalf("row decoded as %+v", b)
+	}
+	if b.Bank == nil || b.Bank.Name != "JYSKE BANK A/S" || b.Bank.URL != "www.jyskebank.dk" {
+		t.Fatalf("bank decoded as %+v", b.Bank)
+	}
+
+	if b, err := d.Search(context.TODO(), "52882301"); err != nil || b.Bank != nil || b.Country.Long != -97 {
+		t.Fatalf("row without bank decoded as %+v, %v", b, err)
+	}
+}
+
+func TestLoadCSVInvalid(t *testing.T) {
+	if _, err := LoadCSV("broken", strings.NewReader("brand,type\nVISA,DEBIT\n")); !errors.Is(err, ErrInvalidDataset) {
+		t.Fatalf("LoadCSV without a bin column returned %v", err)
+	}
+
+	_, err := LoadCSV("broken", strings.NewReader("bin,brand\n457173,VISA\n04571,VISA\n"))
+	if !errors.Is(err, ErrInvalidDataset) || !strings.Contains(err.Error(), "Row 3") {
+		t.Fatalf("LoadCSV returned %v, want the error of row 3", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package dataset serves BIN lookups out of a dataset loaded in memory,
+// without any network call, and upgrades it in production without risk:
+// a new version is staged alongside the active one, validated and
+// compared against it on samples, then promoted atomically, with the
+// previous one kept for rollback.
+//
+//	s := dataset.NewStore(current)
+//	if err := s.Stage(next); err != nil {
+//		return err
+//	}
+//	cmp, err := s.Compare(ctx, samples)
+//	if err != nil || cmp.Changed() > cmp.Samples/10 {
+//		return fmt.Errorf("too many changes: %v", cmp.Differing)
+//	}
+//	s.Promote()
+//
+// `Updater` does so on a schedule with the public binlist-data release,
+// verified against the checksum the deployment publishes of it, for
+// offline deployments to stay current; its Check runs a comparison like
+// the one above. The package has no dependencies.
+package dataset
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// ErrInvalidDataset is returned loading or staging a malformed
+// `Dataset`.
+var ErrInvalidDataset = errors.New("Invalid Dataset")
+
+// Dataset is a set of BINs keyed by their prefix, of 4 to 8 digits, or by
+// the range of those they're of, as in 40000000-49999999, resolved by the
+// longest prefix through an `Index`. It's safe for concurrent lookups, and
+// mustn't be modified once in use.
+type Dataset struct {
+	// Version names the dataset, such as the date of its release.
+	Version string
+
+	bins  map[string]*binlookup.BIN
+	index Index
+}
+
+// New returns a `Dataset` of version holding bins, keyed by prefix or
+// range. The malformed keys, which `Dataset.Validate` reports, aren't
+// looked up.
+func New(version string, bins map[string]*binlookup.BIN) *Dataset {
+	d := &Dataset{Version: version, bins: bins}
+	for key, b := range bins {
+		d.insert(key, b)
+	}
+
+	return d
+}
+
+// insert indexes b under key, failing when key is malformed.
+func (d *Dataset) insert(key string, b *binlookup.BIN) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	if lo, hi, ok := strings.Cut(key, "-"); ok {
+		return d.index.InsertRange(lo, hi, b)
+	}
+
+	return d.index.Insert(key, b)
+}
+
+// Load reads a `Dataset` of version out of r, in JSON Lines: one BIN per
+// line, in the payload of upstream, along with its prefix, or range such
+// as "40000000-49999999", under "bin":
+//
+//	{"bin":"45717360","scheme":"visa","type":"debit","country":{"alpha2":"DK"}}
+//
+// Blank lines are skipped. The error of a malformed line tells its number.
+func Load(version string, r io.Reader) (*Dataset, error) {
+	return (&loader{unit: "Line"}).load(version, r)
+}
+
+func (l *loader) load(version string, r io.Reader) (*Dataset, error) {
+	d := &Dataset{Version: version, bins: make(map[string]*binlookup.BIN)}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for line := 1; s.Scan(); line++ {
+		data := s.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var key struct {
+			BIN string `json:"bin"`
+		}
+		b := &binlookup.BIN{}
+		err := json.Unmarshal(data, &key)
+		if err == nil {
+			err = json.Unmarshal(data, b)
+		}
+		if err != nil {
+			if err := l.reject(line, key.BIN, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		delete(b.Extra, "bin")
+		if len(b.Extra) == 0 {
+			b.Extra = nil
+		}
+
+		if err := l.add(d, line, key.BIN, b); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Len returns the number of BINs of d.
+func (d *Dataset) Len() int {
+	return len(d.bins)
+}
+
+// Validate checks the BINs of d are keyed by well-formed prefixes or
+// ranges and carry a scheme at least, as an incomplete export would not.
+func (d *Dataset) Validate() error {
+	if len(d.bins) == 0 {
+		return fmt.Errorf("%w: No BINs", ErrInvalidDataset)
+	}
+
+	for prefix, b := range d.bins {
+		if err := validKey(prefix); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDataset, err)
+		}
+
+		if b == nil || b.Scheme == "" {
+			return fmt.Errorf("%w: BIN %v Has No Scheme", ErrInvalidDataset, prefix)
+		}
+	}
+
+	return nil
+}
+
+// validKey checks key is a prefix, or a range of them from the lower to
+// the upper included, of as many digits as each other.
+func validKey(key string) error {
+	lo, hi, ok := strings.Cut(key, "-")
+	if !ok {
+		return validPrefix(key)
+	}
+
+	if err := validPrefix(lo); err != nil {
+		return err
+	}
+	if err := validPrefix(hi); err != nil {
+		return err
+	}
+
+	if len(lo) != len(hi) || lo > hi {
+		return fmt.Errorf("Range %v Isn't of Bounds in Order of the Same Length", key)
+	}
+
+	return nil
+}
+
+func validPrefix(prefix string) error {
+	if len(prefix) > 8 {
+		return fmt.Errorf("Prefix %v Longer Than 8 Digits", binlookup.Redact(prefix))
+	}
+
+	return binlookup.ValidateBIN(prefix)
+}
+
+// Search returns the BIN of d keyed by the longest prefix of bin, or the
+// range holding it, failing with binlookup.ErrNotFound when there is
+// none. The BINs returned are shared and mustn't be modified.
+func (d *Dataset) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	if err := binlookup.ValidateBIN(bin); err != nil {
+		return nil, err
+	}
+
+	if b, _, ok := d.index.Lookup(bin[:min(len(bin), 8)]); ok {
+		return b, nil
+	}
+
+	return nil, binlookup.ErrNotFound
+}
+
+// Prefix returns the prefix of bin its BIN is resolved by in d, with ok
+// false when there is none. For a range, it's that of the prefixes
+// spanning it holding bin, see `Index.InsertRange`.
+// It's a `binlookup.RangeFunc`, so that the cache of a
+// `binlookup.Client` keys the BINs of a range of d by the same entry:
+//
+//	binlookup.New(binlookup.WithCache(cache), binlookup.WithCacheRanges(d.Prefix))
+func (d *Dataset) Prefix(bin string) (prefix string, ok bool) {
+	if !digits(bin) {
+		return "", false
+	}
+
+	_, n, ok := d.index.Lookup(bin[:min(len(bin), 8)])
+	if !ok {
+		return "", false
+	}
+
+	return bin[:n], true
+}
+
+// Capabilities reports those of a dataset: 8 digit BINs and bank data,
+// offline.
+func (d *Dataset) Capabilities() binlookup.Capabilities {
+	return binlookup.Capabilities{EightDigit: true, BankData: true, Offline: true}
+}
+
+// Name returns "dataset" followed by the version of d.
+func (d *Dataset) Name() string {
+	return "dataset " + d.Version
+}
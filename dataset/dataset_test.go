@@ -0,0 +1,76 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+const lines = `{"bin":"457173","scheme":"visa","type":"debit","country":{"alpha2":"DK"}}
+
+{"bin":"45717360","scheme":"visa","brand":"Visa/Dankort","tier":"classic"}
+{"bin":"5288","scheme":"mastercard"}
+`
+
+func TestLoad(t *testing.T) {
+	d, err := Load("2026-10", strings.NewReader(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Len() != 3 || d.Validate() != nil {
+		t.Fatalf("loaded %d BINs, validation: %v", d.Len(), d.Validate())
+	}
+
+	for bin, want := range map[string]string{"45717360": "Visa/Dankort", "45717399": "", "52882301": ""} {
+		b, err := d.Search(context.TODO(), bin)
+		if err != nil || b.Brand != want {
+			t.Fatalf("Search(%q) returned %+v, %v, want brand %q", bin, b, err, want)
+		}
+	}
+
+	b, _ := d.Search(context.TODO(), "45717360")
+	if _, ok := b.Extra["bin"]; ok || string(b.Extra["tier"]) != `"classic"` {
+		t.Fatalf("unexpected extra fields %v", b.Extra)
+	}
+
+	if _, err := d.Search(context.TODO(), "41111111"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("Search of an unknown BIN returned %v", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load("broken", strings.NewReader(`{"bin":"457173","scheme":"visa"}`+"\n"+`{"bin":"0457","scheme":"visa"}`))
+	if !errors.Is(err, ErrInvalidDataset) || !strings.Contains(err.Error(), "Line 2") {
+		t.Fatalf("Load returned %v, want the error of line 2", err)
+	}
+
+	if err := New("empty", nil).Validate(); !errors.Is(err, ErrInvalidDataset) {
+		t.Fatalf("empty dataset validated: %v", err)
+	}
+}
+
+func TestDatasetPrefix(t *testing.T) {
+	d, err := Load("2026-10", strings.NewReader(lines+`{"bin":"40000000-40009999","scheme":"visa"}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for bin, want := range map[string]string{
+		"45717360":         "45717360",
+		"4571736012345678": "45717360",
+		"45717399":         "457173",
+		"457173":           "457173",
+		"52882301":         "5288",
+		"40001234":         "4000",
+		"41111111":         "",
+		"4571x":            "",
+	} {
+		if got, ok := d.Prefix(bin); got != want || ok != (want != "") {
+			t.Errorf("Prefix(%q) returned %q, %v, want %q", bin, got, ok, want)
+		}
+	}
+}
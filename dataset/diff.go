@@ -0,0 +1,119 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Change is an entry of a `Dataset` changed from a version to another:
+// added when Old is nil, removed when New is.
+type Change struct {
+	// Key is the prefix, or range, of the entry.
+	Key string `json:"key"`
+
+	Old *binlookup.BIN `json:"old,omitempty"`
+	New *binlookup.BIN `json:"new,omitempty"`
+
+	// Fields are the JSON paths of the fields changed, such as
+	// bank.name, for the entries in both versions.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Changes are the entries of a `Dataset` changed from a version to
+// another, each of them ordered by key.
+type Changes struct {
+	Added   []Change `json:"added"`
+	Removed []Change `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// Len returns the number of entries changed.
+func (c Changes) Len() int {
+	return len(c.Added) + len(c.Removed) + len(c.Changed)
+}
+
+// Diff returns the entries changed from the dataset from to the dataset
+// to, compared by key, for the changes of a release, such as those of
+// issuers, to be reviewed before it's promoted. Unlike `Store.Compare`,
+// it goes over every entry rather than samples of lookups.
+func Diff(from, to *Dataset) (c Changes) {
+	for key, b := range from.bins {
+		n, ok := to.bins[key]
+		if !ok {
+			c.Removed = append(c.Removed, Change{Key: key, Old: b})
+			continue
+		}
+
+		if fields := changedFields(b, n); len(fields) > 0 {
+			c.Changed = append(c.Changed, Change{Key: key, Old: b, New: n, Fields: fields})
+		}
+	}
+
+	for key, b := range to.bins {
+		if _, ok := from.bins[key]; !ok {
+			c.Added = append(c.Added, Change{Key: key, New: b})
+		}
+	}
+
+	for _, changes := range [][]Change{c.Added, c.Removed, c.Changed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	}
+
+	return
+}
+
+// changedFields returns the JSON paths of the fields a and b differ on,
+// in order.
+func changedFields(a, b *binlookup.BIN) (fields []string) {
+	x, y := flatten(a), flatten(b)
+
+	for path, v := range x {
+		if w, ok := y[path]; !ok || !bytes.Equal(v, w) {
+			fields = append(fields, path)
+		}
+	}
+	for path := range y {
+		if _, ok := x[path]; !ok {
+			fields = append(fields, path)
+		}
+	}
+	sort.Strings(fields)
+
+	return
+}
+
+// flatten returns the values of the JSON encoding of b keyed by their
+// path, objects flattened into the paths of their fields.
+func flatten(b *binlookup.BIN) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	if b == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fields
+	}
+
+	var walk func(path string, v json.RawMessage)
+	walk = func(path string, v json.RawMessage) {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(v, &obj); err != nil || obj == nil {
+			fields[path] = v
+			return
+		}
+
+		for k, v := range obj {
+			if path != "" {
+				k = path + "." + k
+			}
+			walk(k, v)
+		}
+	}
+	walk("", data)
+
+	return fields
+}
@@ -0,0 +1,40 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	from, err := LoadCSV("old", strings.NewReader(release))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := LoadCSV("new", strings.NewReader(`bin,brand,type,category,issuer,alpha_2,country
+457173,VISA,DEBIT,CLASSIC,NYKREDIT,DK,Denmark
+45717360,VISA,DEBIT,GOLD,JYSKE BANK A/S,DK,Denmark
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Diff(from, to)
+	if c.Len() != 3 || len(c.Added) != 1 || len(c.Removed) != 1 || len(c.Changed) != 1 {
+		t.Fatalf("diff is %+v", c)
+	}
+
+	if c.Added[0].Key != "45717360" || c.Removed[0].Key != "528823" || c.Removed[0].Old == nil {
+		t.Fatalf("diff is %+v", c)
+	}
+
+	want := []string{"bank.name", "bank.phone", "bank.url", "country.latitude", "country.longitude"}
+	if ch := c.Changed[0]; ch.Key != "457173" || !reflect.DeepEqual(ch.Fields, want) {
+		t.Fatalf("changed %v on %v, want %v", ch.Key, ch.Fields, want)
+	}
+
+	if c := Diff(from, from); c.Len() != 0 {
+		t.Fatalf("diff of a dataset with itself is %+v", c)
+	}
+}
@@ -0,0 +1,135 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Rejection is a row of a dataset `Import` or `ImportCSV` left out, and
+// why.
+type Rejection struct {
+	// Row is the number of the row of a CSV, or the line of JSON Lines,
+	// from 1.
+	Row int
+
+	// Key is the prefix, or range, of the row, if it was read.
+	Key string
+
+	Err error
+}
+
+func (r Rejection) Error() string {
+	if r.Key == "" {
+		return fmt.Sprintf("Row %d: %v", r.Row, r.Err)
+	}
+
+	return fmt.Sprintf("Row %d (%v): %v", r.Row, binlookup.Redact(r.Key), r.Err)
+}
+
+// Unwrap returns the error the row was rejected with.
+func (r Rejection) Unwrap() error {
+	return r.Err
+}
+
+// errNoScheme rejects the rows of BINs without a scheme, which
+// `Dataset.Validate` fails on.
+var errNoScheme = errors.New("No Scheme")
+
+// Import reads a `Dataset` out of r as `Load` does, leaving out the lines
+// that are malformed or carry no scheme rather than failing on them,
+// returned along as rejected, for external datasets of rows of uneven
+// quality to be taken in.
+func Import(version string, r io.Reader) (d *Dataset, rejected []Rejection, err error) {
+	l := &loader{unit: "Line", lenient: true}
+	d, err = l.load(version, r)
+
+	return d, l.rejected, err
+}
+
+// ImportCSV is to `LoadCSV` what `Import` is to `Load`.
+func ImportCSV(version string, r io.Reader) (d *Dataset, rejected []Rejection, err error) {
+	l := &loader{unit: "Row", lenient: true}
+	d, err = l.loadCSV(version, r)
+
+	return d, l.rejected, err
+}
+
+// loader reads the rows of a dataset, failing on the first malformed,
+// told by their unit, unless lenient, which collects them as rejected.
+type loader struct {
+	unit     string
+	lenient  bool
+	rejected []Rejection
+}
+
+// reject rejects the row, failing the load unless l is lenient.
+func (l *loader) reject(row int, key string, err error) error {
+	if !l.lenient {
+		return fmt.Errorf("%w: %v %d: %w", ErrInvalidDataset, l.unit, row, err)
+	}
+
+	l.rejected = append(l.rejected, Rejection{Row: row, Key: key, Err: err})
+	return nil
+}
+
+// add holds b under key in d, rejecting the row when key is malformed, or
+// when l is lenient and b has no scheme.
+func (l *loader) add(d *Dataset, row int, key string, b *binlookup.BIN) error {
+	var err error
+	if l.lenient && b.Scheme == "" {
+		err = errNoScheme
+	}
+	if err == nil {
+		err = d.insert(key, b)
+	}
+	if err != nil {
+		return l.reject(row, key, err)
+	}
+
+	d.bins[key] = b
+	return nil
+}
+
+// WriteTo writes the BINs of d to w in the JSON Lines `Load` reads,
+// ordered by key, which the Import of sqlitedb stores too when d holds no
+// ranges.
+func (d *Dataset) WriteTo(w io.Writer) (n int64, err error) {
+	keys := make([]string, 0, len(d.bins))
+	for key := range d.bins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		data, err := json.Marshal(d.bins[key])
+		if err != nil {
+			return n, fmt.Errorf("Encoding %v Failed: %w", binlookup.Redact(key), err)
+		}
+
+		k, _ := json.Marshal(key)
+		buf.Reset()
+		buf.WriteString(`{"bin":`)
+		buf.Write(k)
+		if rest := data[1:]; len(rest) > 1 {
+			buf.WriteByte(',')
+			buf.Write(rest)
+		} else {
+			buf.WriteByte('}')
+		}
+		buf.WriteByte('\n')
+
+		m, err := w.Write(buf.Bytes())
+		if n += int64(m); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
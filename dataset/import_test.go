@@ -0,0 +1,76 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestImportCSV(t *testing.T) {
+	d, rejected, err := ImportCSV("2026-10", strings.NewReader(`bin,brand,issuer,alpha_2
+457173,VISA,JYSKE BANK,DK
+04571,VISA,,DK
+528823,,,US
+4111"1111,VISA
+52882301,MASTERCARD,,US
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Len() != 2 || d.Validate() != nil {
+		t.Fatalf("imported %d BINs, validation: %v", d.Len(), d.Validate())
+	}
+
+	var rows []int
+	for _, r := range rejected {
+		rows = append(rows, r.Row)
+	}
+	if len(rejected) != 3 || rows[0] != 3 || rows[1] != 4 || !errors.Is(rejected[1], errNoScheme) || rejected[0].Key != "04571" {
+		t.Fatalf("rejected %v", rejected)
+	}
+	if !strings.HasPrefix(rejected[1].Error(), "Row 4 (528823): ") {
+		t.Fatalf("rejection reads %q", rejected[1].Error())
+	}
+}
+
+func TestImport(t *testing.T) {
+	d, rejected, err := Import("2026-10", strings.NewReader(lines+"{\n"+`{"bin":"0457","scheme":"visa"}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Len() != 3 || len(rejected) != 2 || rejected[0].Row != 5 || rejected[1].Row != 6 {
+		t.Fatalf("imported %d BINs, rejected %v", d.Len(), rejected)
+	}
+}
+
+func TestDatasetWriteTo(t *testing.T) {
+	d, err := Load("2026-10", strings.NewReader(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), `{"bin":"457173","scheme":"visa"`) {
+		t.Fatalf("wrote %v", buf.String())
+	}
+
+	again, err := Load("again", strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := Diff(d, again); c.Len() != 0 {
+		t.Fatalf("dataset written back differs: %+v", c)
+	}
+
+	b, _ := again.Search(context.TODO(), "45717360")
+	if string(b.Extra["tier"]) != `"classic"` {
+		t.Fatalf("extra fields written back as %v", b.Extra)
+	}
+}
@@ -0,0 +1,140 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Index is a trie of BIN prefixes, resolving the longest prefix of a card
+// number it holds in as many steps as the number has digits, whatever the
+// size of the index. Ranges of BINs are held as the prefixes spanning
+// them, see `Index.InsertRange`.
+//
+// The zero value is an empty Index ready to use. It's safe for concurrent
+// lookups once built, not for inserting along with them.
+type Index struct {
+	root node
+	n    int
+}
+
+type node struct {
+	children [10]*node
+	bin      *binlookup.BIN
+}
+
+// Insert holds b under prefix, a string of digits, replacing the BIN
+// prefix held before.
+func (x *Index) Insert(prefix string, b *binlookup.BIN) error {
+	if !digits(prefix) {
+		return fmt.Errorf("Prefix %q Isn't of Digits", binlookup.Redact(prefix))
+	}
+
+	x.insert(prefix, b)
+	return nil
+}
+
+func (x *Index) insert(prefix string, b *binlookup.BIN) {
+	n := &x.root
+	for i := 0; i < len(prefix); i++ {
+		d := prefix[i] - '0'
+		if n.children[d] == nil {
+			n.children[d] = &node{}
+		}
+		n = n.children[d]
+	}
+
+	if n.bin == nil {
+		x.n++
+	}
+	n.bin = b
+}
+
+// InsertRange holds b under the BINs from lo to hi included, of as many
+// digits as each other, such as 400000 to 499999. The range is held as
+// the fewest prefixes spanning it, "4" for the one above, so that a range
+// is as specific as those: a BIN of a longer prefix held in it resolves
+// to that BIN rather than to b, and it resolves to b over a prefix of its
+// own that is shorter.
+func (x *Index) InsertRange(lo, hi string, b *binlookup.BIN) error {
+	if !digits(lo) || !digits(hi) || len(lo) != len(hi) {
+		return fmt.Errorf("Range %v-%v Isn't of Digits of the Same Length", lo, hi)
+	}
+	if lo > hi {
+		return fmt.Errorf("Range %v-%v Is Reversed", lo, hi)
+	}
+
+	for _, prefix := range spans(lo, hi) {
+		x.insert(prefix, b)
+	}
+
+	return nil
+}
+
+// Lookup returns the BIN held under the longest prefix of bin, along with
+// the length of the prefix, with ok false when none is.
+func (x *Index) Lookup(bin string) (b *binlookup.BIN, length int, ok bool) {
+	n := &x.root
+	for i := 0; i < len(bin); i++ {
+		if bin[i] < '0' || bin[i] > '9' {
+			break
+		}
+
+		if n = n.children[bin[i]-'0']; n == nil {
+			break
+		}
+		if n.bin != nil {
+			b, length, ok = n.bin, i+1, true
+		}
+	}
+
+	return
+}
+
+// Len returns the number of prefixes held by x, ranges counting as many
+// as they're spanned by.
+func (x *Index) Len() int {
+	return x.n
+}
+
+// spans returns the fewest prefixes spanning the BINs from lo to hi.
+func spans(lo, hi string) (prefixes []string) {
+	var cover func(prefix, lo, hi string)
+	cover = func(prefix, lo, hi string) {
+		if strings.Trim(lo, "0") == "" && strings.Trim(hi, "9") == "" {
+			prefixes = append(prefixes, prefix)
+			return
+		}
+
+		a, b := lo[0], hi[0]
+		if a == b {
+			cover(prefix+lo[:1], lo[1:], hi[1:])
+			return
+		}
+
+		rest := len(lo) - 1
+		cover(prefix+lo[:1], lo[1:], strings.Repeat("9", rest))
+		for d := a + 1; d < b; d++ {
+			prefixes = append(prefixes, prefix+string(d))
+		}
+		cover(prefix+hi[:1], strings.Repeat("0", rest), hi[1:])
+	}
+	cover("", lo, hi)
+
+	return
+}
+
+func digits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
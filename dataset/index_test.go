@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestSpans(t *testing.T) {
+	for _, tc := range []struct {
+		lo, hi string
+		want   []string
+	}{
+		{"400000", "499999", []string{"4"}},
+		{"45717360", "45717369", []string{"4571736"}},
+		{"45717360", "45717360", []string{"45717360"}},
+		{"510000", "559999", []string{"51", "52", "53", "54", "55"}},
+		{"222100", "272099", []string{"2221", "2222", "2223", "2224", "2225", "2226", "2227", "2228", "2229", "223", "224", "225", "226", "227", "228", "229", "23", "24", "25", "26", "270", "271", "2720"}},
+	} {
+		if got := spans(tc.lo, tc.hi); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("spans(%v, %v) = %v, want %v", tc.lo, tc.hi, got, tc.want)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	visa, jyske, mc := &binlookup.BIN{Scheme: "visa"}, &binlookup.BIN{Brand: "Jyske"}, &binlookup.BIN{Scheme: "mastercard"}
+
+	var x Index
+	if err := x.InsertRange("40000000", "49999999", visa); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.Insert("457173", jyske); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.InsertRange("22210000", "27209999", mc); err != nil {
+		t.Fatal(err)
+	}
+
+	for bin, want := range map[string]struct {
+		b      *binlookup.BIN
+		length int
+	}{
+		"4571736012345678": {jyske, 6},
+		"41111111":         {visa, 1},
+		"2720991234":       {mc, 4},
+		"2221000012345678": {mc, 4},
+		"2300":             {mc, 2},
+	} {
+		b, n, ok := x.Lookup(bin)
+		if !ok || b != want.b || n != want.length {
+			t.Fatalf("Lookup(%v) = %+v, %d, %t", bin, b, n, ok)
+		}
+	}
+
+	for _, bin := range []string{"27210000", "22200000", "5288", ""} {
+		if b, _, ok := x.Lookup(bin); ok {
+			t.Fatalf("Lookup(%v) = %+v", bin, b)
+		}
+	}
+
+	if err := x.InsertRange("4999", "40", visa); err == nil {
+		t.Fatal("range of bounds of different lengths inserted")
+	}
+	if err := x.InsertRange("4999", "4000", visa); err == nil {
+		t.Fatal("reversed range inserted")
+	}
+	if err := x.Insert("45a", visa); err == nil {
+		t.Fatal("prefix of letters inserted")
+	}
+}
+
+func TestLoadRanges(t *testing.T) {
+	d, err := Load("ranges", strings.NewReader(`{"bin":"51000000-55999999","scheme":"mastercard"}
+{"bin":"52882301","scheme":"mastercard","brand":"Debit"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for bin, want := range map[string]string{"52882301": "Debit", "5499000012345678": "", "510000": ""} {
+		b, err := d.Search(context.TODO(), bin)
+		if err != nil || b.Brand != want {
+			t.Fatalf("Search(%v) returned %+v, %v", bin, b, err)
+		}
+	}
+
+	if _, err := Load("broken", strings.NewReader(`{"bin":"5599-5100","scheme":"mastercard"}`)); err == nil {
+		t.Fatal("reversed range loaded")
+	}
+}
+
+func BenchmarkIndexLookup(b *testing.B) {
+	var x Index
+	for i := 400000; i < 500000; i += 7 {
+		x.Insert(strconv.Itoa(i), &binlookup.BIN{})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Lookup("4571736012345678")
+	}
+}
@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Query selects the BINs of a `Dataset` by their issuer, see
+// `Dataset.Find`. Its empty fields select any BIN.
+type Query struct {
+	// Bank selects the BINs of the banks whose name holds its words in
+	// order, whatever their case: "Jyske Bank" selects those of "JYSKE
+	// BANK A/S", "ING" not those of "BANKING CORP".
+	Bank string
+
+	// Country selects the BINs issued in the country of its alpha-2,
+	// alpha-3 or numeric code.
+	Country string
+
+	// Scheme and Type select the BINs of the scheme, and of the type,
+	// such as debit, whatever their case.
+	Scheme binlookup.Scheme
+	Type   binlookup.CardType
+}
+
+// Range is a range of BINs of a `Dataset`, as `Dataset.Find` returns.
+type Range struct {
+	// Key is the prefix, or range, the BINs are keyed by in the dataset.
+	Key string
+
+	// First and Last are the 8 digit BINs the range spans, included:
+	// 45717300 and 45717399 for the prefix 457173.
+	First string
+	Last  string
+
+	BIN *binlookup.BIN
+}
+
+// Find returns the ranges of the BINs of d selected by q, ordered by
+// their first BIN, for the rules routing by issuer, such as those of the
+// BINs of a bank in a country, to be derived out of the dataset. The BINs
+// returned are shared and mustn't be modified. A BIN of a range is looked
+// up to that of a longer prefix held in it, if any.
+func (d *Dataset) Find(q Query) (ranges []Range) {
+	bank := strings.Fields(strings.ToLower(q.Bank))
+
+	country := strings.TrimSpace(q.Country)
+	if c, ok := binlookup.LookupCountry(country); ok {
+		country = c.Alpha2
+	}
+
+	for key, b := range d.bins {
+		if b == nil || validKey(key) != nil {
+			continue
+		}
+
+		if !matchBank(b, bank) ||
+			country != "" && !strings.EqualFold(b.Country.Short, country) ||
+			q.Scheme != "" && binlookup.ParseScheme(string(q.Scheme)) != binlookup.ParseScheme(string(b.Scheme)) ||
+			q.Type != "" && !strings.EqualFold(string(q.Type), string(b.Type)) {
+			continue
+		}
+
+		lo, hi, ok := strings.Cut(key, "-")
+		if !ok {
+			hi = lo
+		}
+		ranges = append(ranges, Range{
+			Key:   key,
+			First: lo + strings.Repeat("0", 8-len(lo)),
+			Last:  hi + strings.Repeat("9", 8-len(hi)),
+			BIN:   b,
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].First != ranges[j].First {
+			return ranges[i].First < ranges[j].First
+		}
+		return ranges[i].Last > ranges[j].Last
+	})
+
+	return
+}
+
+// Find returns the ranges of the BINs of the active `Dataset` of s
+// selected by q, see `Dataset.Find`.
+func (s *Store) Find(q Query) []Range {
+	return s.Active().Find(q)
+}
+
+// matchBank reports whether the name of the bank of b holds the words,
+// lower cased, in order. Any BIN matches no words.
+func matchBank(b *binlookup.BIN, words []string) bool {
+	if len(words) == 0 {
+		return true
+	}
+	if b.Bank == nil {
+		return false
+	}
+
+	name := strings.Fields(strings.ToLower(b.Bank.Name))
+	for i := 0; i+len(words) <= len(name); i++ {
+		if slices.Equal(name[i:i+len(words)], words) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+)
+
+const issuers = `{"bin":"457173","scheme":"visa","type":"debit","country":{"alpha2":"DK"},"bank":{"name":"JYSKE BANK A/S"}}
+{"bin":"45717360","scheme":"visa","type":"credit","country":{"alpha2":"DK"},"bank":{"name":"Jyske Bank"}}
+{"bin":"40000000-40009999","scheme":"visa","type":"debit","country":{"alpha2":"DE"},"bank":{"name":"Jyske Bank"}}
+{"bin":"5288","scheme":"mastercard","country":{"alpha2":"DK"},"bank":{"name":"BANKING CORP"}}
+`
+
+func TestDatasetFind(t *testing.T) {
+	d, err := Load("2026-10", strings.NewReader(issuers))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := func(q Query) string {
+		var k []string
+		for _, r := range d.Find(q) {
+			k = append(k, r.Key)
+		}
+		return strings.Join(k, " ")
+	}
+
+	for _, tt := range []struct {
+		q    Query
+		want string
+	}{
+		{Query{Bank: "jyske bank"}, "40000000-40009999 457173 45717360"},
+		{Query{Bank: "Jyske Bank", Country: "DNK"}, "457173 45717360"},
+		{Query{Bank: "Jyske", Country: "dk", Type: "DEBIT"}, "457173"},
+		{Query{Bank: "ING"}, ""},
+		{Query{Scheme: "MasterCard"}, "5288"},
+		{Query{Country: "276"}, "40000000-40009999"},
+		{Query{}, "40000000-40009999 457173 45717360 5288"},
+	} {
+		if got := keys(tt.q); got != tt.want {
+			t.Errorf("Find(%+v) returned %q, want %q", tt.q, got, tt.want)
+		}
+	}
+
+	r := NewStore(d).Find(Query{Scheme: "mastercard"})
+	if len(r) != 1 || r[0].First != "52880000" || r[0].Last != "52889999" || r[0].BIN.Bank.Name != "BANKING CORP" {
+		t.Fatalf("Find returned %+v", r)
+	}
+	if r := d.Find(Query{Country: "DE"}); r[0].First != "40000000" || r[0].Last != "40009999" {
+		t.Fatalf("Find returned %+v", r)
+	}
+}
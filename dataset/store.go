@@ -0,0 +1,228 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+var (
+	// ErrNoStandby is returned promoting or comparing when no `Dataset`
+	// is staged.
+	ErrNoStandby = errors.New("No Dataset Staged")
+
+	// ErrNoPrevious is returned rolling back when no `Dataset` was
+	// promoted before.
+	ErrNoPrevious = errors.New("No Dataset to Roll Back To")
+
+	// ErrStandbyTaken is returned by `Updater.Update` when a `Dataset` it
+	// didn't stage itself is staged, such as by an operator comparing it.
+	ErrStandbyTaken = errors.New("Another Dataset Is Staged")
+)
+
+// Store is a `binlookup.Provider` looking BINs up in its active
+// `Dataset`, which is swapped atomically: lookups in progress finish on
+// the dataset they started on, none of them ever failing over an upgrade.
+// It's safe for concurrent use.
+type Store struct {
+	active atomic.Pointer[Dataset]
+
+	mu       sync.Mutex
+	standby  *Dataset
+	previous *Dataset
+}
+
+var _ binlookup.Versioner = (*Store)(nil)
+
+// NewStore returns a `Store` with active, which mustn't be nil, as its
+// active `Dataset`.
+func NewStore(active *Dataset) *Store {
+	s := &Store{}
+	s.active.Store(active)
+
+	return s
+}
+
+// Active returns the active `Dataset` of s.
+func (s *Store) Active() *Dataset {
+	return s.active.Load()
+}
+
+// Version returns that of the active `Dataset` of s, for it to be
+// reported by `binlookup.Client.SupportBundle`.
+func (s *Store) Version() string {
+	return s.active.Load().Version
+}
+
+// Standby returns the `Dataset` staged in s, nil when there is none.
+func (s *Store) Standby() *Dataset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.standby
+}
+
+// Stage validates d and loads it into s alongside the active `Dataset`,
+// replacing the one staged before if any.
+func (s *Store) Stage(d *Dataset) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.standby = d
+	return nil
+}
+
+// stageOver stages d as `Store.Stage` does, unless another `Dataset` than
+// staged, nil for none, is staged already.
+func (s *Store) stageOver(d, staged *Dataset) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.standby != nil && s.standby != staged {
+		return ErrStandbyTaken
+	}
+
+	s.standby = d
+	return nil
+}
+
+// promoteStaged promotes d as `Store.Promote` does, unless it's no longer
+// the `Dataset` staged.
+func (s *Store) promoteStaged(d *Dataset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.standby != d {
+		return ErrStandbyTaken
+	}
+
+	s.previous = s.active.Swap(s.standby)
+	s.standby = nil
+
+	return nil
+}
+
+// Promote makes the staged `Dataset` the active one, keeping the active one
+// for `Store.Rollback`.
+func (s *Store) Promote() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.standby == nil {
+		return ErrNoStandby
+	}
+
+	s.previous = s.active.Swap(s.standby)
+	s.standby = nil
+
+	return nil
+}
+
+// Rollback makes the `Dataset` active before the last promotion the active
+// one again, staging the one it replaces.
+func (s *Store) Rollback() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.previous == nil {
+		return ErrNoPrevious
+	}
+
+	s.standby = s.active.Swap(s.previous)
+	s.previous = nil
+
+	return nil
+}
+
+// Search looks bin up in the active `Dataset`.
+func (s *Store) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	return s.active.Load().Search(ctx, bin)
+}
+
+// Prefix returns the prefix of bin its BIN is resolved by in the active
+// `Dataset` of s, see `Dataset.Prefix`.
+func (s *Store) Prefix(bin string) (prefix string, ok bool) {
+	return s.Active().Prefix(bin)
+}
+
+// Capabilities reports those of a `Dataset`.
+func (s *Store) Capabilities() binlookup.Capabilities {
+	return s.active.Load().Capabilities()
+}
+
+// Comparison is how the staged `Dataset` answers samples compared to the
+// active one.
+type Comparison struct {
+	Samples  int
+	Matching int
+
+	// Differing are the samples both resolve differently, Added those
+	// only the staged one resolves and Removed those it no longer does.
+	Differing []string
+	Added     []string
+	Removed   []string
+}
+
+// Changed returns the number of samples answered differently.
+func (c Comparison) Changed() int {
+	return len(c.Differing) + len(c.Added) + len(c.Removed)
+}
+
+// Compare looks samples up in both the active and the staged `Dataset`,
+// such as the BINs of recent traffic, to tell what promoting it changes.
+// Malformed samples are skipped.
+func (s *Store) Compare(ctx context.Context, samples []string) (c Comparison, err error) {
+	standby := s.Standby()
+	if standby == nil {
+		return c, ErrNoStandby
+	}
+	active := s.Active()
+
+	for _, bin := range samples {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if binlookup.ValidateBIN(bin) != nil {
+			continue
+		}
+		c.Samples++
+
+		was, _ := active.Search(ctx, bin)
+		is, _ := standby.Search(ctx, bin)
+
+		switch {
+		case was == nil && is == nil, was != nil && is != nil && same(was, is):
+			c.Matching++
+		case was == nil:
+			c.Added = append(c.Added, bin)
+		case is == nil:
+			c.Removed = append(c.Removed, bin)
+		default:
+			c.Differing = append(c.Differing, bin)
+		}
+	}
+
+	return
+}
+
+// same reports whether a and b encode alike.
+func same(a, b *binlookup.BIN) bool {
+	x, xerr := json.Marshal(a)
+	y, yerr := json.Marshal(b)
+
+	return xerr == nil && yerr == nil && bytes.Equal(x, y)
+}
@@ -0,0 +1,62 @@
+package dataset
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestStore(t *testing.T) {
+	v1 := New("v1", map[string]*binlookup.BIN{
+		"457173": {Scheme: binlookup.SchemeVisa},
+		"528823": {Scheme: binlookup.SchemeMastercard},
+		"411111": {Scheme: binlookup.SchemeVisa},
+	})
+	v2 := New("v2", map[string]*binlookup.BIN{
+		"457173": {Scheme: binlookup.SchemeVisa},
+		"528823": {Scheme: binlookup.SchemeMastercard, Brand: "Debit Mastercard"},
+		"601100": {Scheme: binlookup.SchemeDiscover},
+	})
+
+	s := NewStore(v1)
+	if err := s.Promote(); !errors.Is(err, ErrNoStandby) {
+		t.Fatalf("Promote without a staged dataset returned %v", err)
+	}
+
+	if err := s.Stage(New("broken", map[string]*binlookup.BIN{"457173": {}})); !errors.Is(err, ErrInvalidDataset) {
+		t.Fatalf("Stage of an invalid dataset returned %v", err)
+	}
+
+	if err := s.Stage(v2); err != nil {
+		t.Fatal(err)
+	}
+
+	cmp, err := s.Compare(context.TODO(), []string{"45717360", "52882301", "41111111", "60110000", "99999999", "0bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmp.Samples != 5 || cmp.Matching != 2 || cmp.Changed() != 3 ||
+		strings.Join(cmp.Differing, ",") != "52882301" || cmp.Added[0] != "60110000" || cmp.Removed[0] != "41111111" {
+		t.Fatalf("unexpected comparison %+v", cmp)
+	}
+
+	if b, _ := s.Search(context.TODO(), "52882301"); b.Brand != "" {
+		t.Fatal("staged dataset answered before its promotion")
+	}
+
+	if err := s.Promote(); err != nil || s.Active() != v2 || s.Standby() != nil || s.Version() != "v2" {
+		t.Fatalf("Promote returned %v, leaving %v active", err, s.Version())
+	}
+
+	if err := s.Rollback(); err != nil || s.Active() != v1 || s.Standby() != v2 {
+		t.Fatalf("Rollback returned %v, leaving %v active", err, s.Active().Version)
+	}
+
+	if err := s.Rollback(); !errors.Is(err, ErrNoPrevious) {
+		t.Fatalf("second Rollback returned %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultDataURL is the public binlist-data release `Updater` downloads
+// unless changed, in the CSV `LoadCSV` reads.
+const DefaultDataURL = "https://raw.githubusercontent.com/iannuttall/binlist-data/master/binlist-data.csv"
+
+// DefaultUpdateInterval is how often `Updater.Run` checks for a release,
+// unless changed through its Interval.
+const DefaultUpdateInterval = 24 * time.Hour
+
+// maxRelease bounds the size of the releases downloaded.
+const maxRelease = 256 << 20
+
+var (
+	// ErrChecksumMismatch is returned updating to a release whose SHA-256
+	// isn't the one published along.
+	ErrChecksumMismatch = errors.New("Checksum Mismatch")
+
+	// ErrNoChecksumURL is returned updating without the ChecksumURL of
+	// the release.
+	ErrNoChecksumURL = errors.New("No Checksum URL")
+)
+
+// Updater keeps the active `Dataset` of Store that of the latest release
+// at URL: it downloads the release, verifies its SHA-256 against the one
+// published at ChecksumURL, and stages then promotes it, lookups never
+// failing over the swap. Releases whose dataset doesn't validate, or that
+// Check rejects, aren't promoted, the active one staying so. A `Dataset`
+// staged by another than the Updater, such as to be compared by an
+// operator, is left be: updating fails with `ErrStandbyTaken` until it's
+// promoted or replaced.
+//
+// A Store with an empty dataset active is the one to start from when none
+// was downloaded yet, along with the checksum of the release published by
+// the deployment, binlist-data publishing none of its own:
+//
+//	u := &dataset.Updater{
+//		Store:       dataset.NewStore(dataset.New("empty", nil)),
+//		ChecksumURL: "https://example.com/binlist-data.csv.sha256",
+//	}
+//	go u.Run(ctx)
+type Updater struct {
+	Store *Store
+
+	// URL is that of the release, `DefaultDataURL` when empty, and
+	// ChecksumURL, which is required, that of its SHA-256 in hex, alone or
+	// followed by the file name as sha256sum prints it.
+	URL         string
+	ChecksumURL string
+
+	// Check, when set, is given Store with the release staged before it's
+	// promoted, such as to `Store.Compare` it against the BINs of recent
+	// traffic. An error of Check leaves the release staged, unpromoted,
+	// and is that of the update.
+	Check func(ctx context.Context, s *Store) error
+
+	// Load reads the release, `LoadCSV` when nil.
+	Load func(version string, r io.Reader) (*Dataset, error)
+
+	// Client makes the requests, http.DefaultClient when nil.
+	Client *http.Client
+
+	// Interval is how often `Updater.Run` checks for a release,
+	// `DefaultUpdateInterval` when zero.
+	Interval time.Duration
+
+	// OnUpdate is given the outcome of the checks of `Updater.Run`: the
+	// dataset promoted, nil when the release didn't change, or the error
+	// updating.
+	OnUpdate func(d *Dataset, err error)
+
+	// staged is the `Dataset` the Updater staged last.
+	staged *Dataset
+}
+
+// Run checks for a release every Interval, at once first, until ctx is
+// done, returning its error.
+func (u *Updater) Run(ctx context.Context) error {
+	interval := u.Interval
+	if interval <= 0 {
+		interval = DefaultUpdateInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		d, err := u.Update(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if u.OnUpdate != nil {
+			u.OnUpdate(d, err)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Update downloads the release at URL, unless its checksum is that of
+// the active dataset, verifies it and promotes its dataset once Check
+// passes, returning it. It returns a nil dataset when the release didn't
+// change. The version of the dataset is the beginning of its SHA-256.
+func (u *Updater) Update(ctx context.Context) (*Dataset, error) {
+	url := u.URL
+	if url == "" {
+		url = DefaultDataURL
+	}
+	if u.ChecksumURL == "" {
+		return nil, ErrNoChecksumURL
+	}
+	load := u.Load
+	if load == nil {
+		load = LoadCSV
+	}
+
+	want, err := u.get(ctx, u.ChecksumURL)
+	if err != nil {
+		return nil, fmt.Errorf("Downloading the Checksum Failed: %w", err)
+	}
+	fields := strings.Fields(string(want))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: No Checksum Published", ErrChecksumMismatch)
+	}
+	sum := strings.ToLower(fields[0])
+
+	version := sum[:min(len(sum), 12)]
+	if active := u.Store.Active(); active != nil && active.Version == version {
+		return nil, nil
+	}
+
+	data, err := u.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("Downloading the Release Failed: %w", err)
+	}
+
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != sum {
+		return nil, fmt.Errorf("%w: Release Is %x, Not %v", ErrChecksumMismatch, got, sum)
+	}
+
+	d, err := load(version, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.Store.stageOver(d, u.staged); err != nil {
+		return nil, err
+	}
+	u.staged = d
+
+	if u.Check != nil {
+		if err := u.Check(ctx, u.Store); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.Store.promoteStaged(d); err != nil {
+		return nil, err
+	}
+	u.staged = nil
+
+	return d, nil
+}
+
+// get downloads url.
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Status Code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRelease+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRelease {
+		return nil, fmt.Errorf("Larger Than %d Bytes", maxRelease)
+	}
+
+	return data, nil
+}
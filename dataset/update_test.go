@@ -0,0 +1,165 @@
+package dataset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestUpdater(t *testing.T) {
+	body := release
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/binlist-data.csv":
+			downloads.Add(1)
+			w.Write([]byte(body))
+		case "/binlist-data.csv.sha256":
+			sum := sha256.Sum256([]byte(body))
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  binlist-data.csv\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewStore(New("empty", nil))
+	u := &Updater{Store: s, URL: srv.URL + "/binlist-data.csv", ChecksumURL: srv.URL + "/binlist-data.csv.sha256"}
+
+	d, err := u.Update(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d == nil || s.Active() != d || len(d.Version) != 12 {
+		t.Fatalf("updated to %+v", d)
+	}
+
+	if b, err := s.Search(context.TODO(), "45717360"); err != nil || b.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("lookup returned %+v, %v", b, err)
+	}
+
+	// An unchanged release isn't downloaded again.
+	if d, err := u.Update(context.TODO()); d != nil || err != nil || downloads.Load() != 1 {
+		t.Fatalf("update of an unchanged release returned %+v, %v after %d downloads", d, err, downloads.Load())
+	}
+}
+
+func TestUpdaterChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sum" {
+			w.Write([]byte("0000000000000000000000000000000000000000000000000000000000000000"))
+			return
+		}
+		w.Write([]byte(release))
+	}))
+	defer srv.Close()
+
+	empty := New("empty", nil)
+	s := NewStore(empty)
+	u := &Updater{Store: s, URL: srv.URL + "/data.csv", ChecksumURL: srv.URL + "/sum"}
+
+	if _, err := u.Update(context.TODO()); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("update returned %v", err)
+	}
+
+	if s.Active() != empty || s.Standby() != nil {
+		t.Fatal("release of a mismatching checksum promoted")
+	}
+}
+
+func TestUpdaterRun(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	u := &Updater{Store: NewStore(New("empty", nil)), URL: srv.URL, ChecksumURL: srv.URL + "/sum", OnUpdate: func(d *Dataset, err error) {
+		if err == nil {
+			t.Error("update from a missing release succeeded")
+		}
+		cancel()
+	}}
+
+	if err := u.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+}
+
+// releaseServer serves release at /data.csv and its SHA-256 at /sum.
+func releaseServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sum" {
+			sum := sha256.Sum256([]byte(release))
+			w.Write([]byte(hex.EncodeToString(sum[:])))
+			return
+		}
+		w.Write([]byte(release))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestUpdaterNoChecksumURL(t *testing.T) {
+	srv := releaseServer(t)
+
+	u := &Updater{Store: NewStore(New("empty", nil)), URL: srv.URL + "/data.csv"}
+	if _, err := u.Update(context.TODO()); !errors.Is(err, ErrNoChecksumURL) {
+		t.Fatalf("update without a checksum URL returned %v", err)
+	}
+}
+
+func TestUpdaterStandbyTaken(t *testing.T) {
+	srv := releaseServer(t)
+
+	empty := New("empty", nil)
+	s := NewStore(empty)
+	staged := New("staged", map[string]*binlookup.BIN{"457173": {Scheme: binlookup.SchemeVisa}})
+	if err := s.Stage(staged); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &Updater{Store: s, URL: srv.URL + "/data.csv", ChecksumURL: srv.URL + "/sum"}
+	if _, err := u.Update(context.TODO()); !errors.Is(err, ErrStandbyTaken) {
+		t.Fatalf("update over a dataset staged by an operator returned %v", err)
+	}
+
+	if s.Active() != empty || s.Standby() != staged {
+		t.Fatal("update replaced the dataset staged by an operator")
+	}
+}
+
+func TestUpdaterCheck(t *testing.T) {
+	srv := releaseServer(t)
+
+	empty := New("empty", nil)
+	s := NewStore(empty)
+
+	rejected := errors.New("too many changes")
+	u := &Updater{Store: s, URL: srv.URL + "/data.csv", ChecksumURL: srv.URL + "/sum", Check: func(ctx context.Context, s *Store) error {
+		if _, err := s.Compare(ctx, []string{"45717360"}); err != nil {
+			return err
+		}
+		return rejected
+	}}
+
+	if _, err := u.Update(context.TODO()); !errors.Is(err, rejected) {
+		t.Fatalf("update rejected by its check returned %v", err)
+	}
+	if s.Active() != empty || s.Standby() == nil {
+		t.Fatal("release rejected by the check promoted")
+	}
+
+	// The release the Updater staged itself is staged over once it passes.
+	u.Check = nil
+	if d, err := u.Update(context.TODO()); err != nil || s.Active() != d || s.Standby() != nil {
+		t.Fatalf("update once the check passes returned %+v, %v", d, err)
+	}
+}
@@ -0,0 +1,54 @@
+package binlookup
+
+import "errors"
+
+// BINDigits is how many digits of the BINs looked up a `Client` sends
+// upstream, see `WithBINDigits`.
+type BINDigits int
+
+const (
+	// EightDigits sends up to 8 digits, the length of the BINs of ISO/IEC
+	// 7812-1:2017 the industry is moving to. It's the default.
+	EightDigits BINDigits = iota
+
+	// SixDigits sends up to 6 digits, the length of the BINs before it,
+	// for upstreams and datasets that know of those only.
+	SixDigits
+
+	// EightDigitsFallback sends up to 8 digits, then the first 6 when
+	// upstream doesn't know the 8, as with the ranges not yet split into
+	// 8-digit BINs.
+	EightDigitsFallback
+)
+
+// WithBINDigits sets how many digits of the BINs looked up the `Client`
+// sends upstream, `EightDigits` unless changed. The digits past those are
+// never sent nor cached: BINs are cached under the digits sent, or the
+// prefix of their range, see `WithCacheRanges`. Those resolved falling
+// back to 6 digits are cached under the 6 digits too, for the lookups of
+// those to share the result.
+func WithBINDigits(d BINDigits) Option {
+	return func(c *Client) {
+		c.binDigits = d
+	}
+}
+
+// sent returns the digits of bin c sends upstream.
+func (c *Client) sent(bin string) string {
+	n := 8
+	if c.binDigits == SixDigits {
+		n = 6
+	}
+
+	if len(bin) > n {
+		return bin[:n]
+	}
+
+	return bin
+}
+
+// fallback reports whether the lookup of bin failing with err is made
+// again with its first 6 digits.
+func (c *Client) fallback(bin string, err error) bool {
+	return c.binDigits == EightDigitsFallback && len(bin) > 6 && errors.Is(err, ErrNotFound)
+}
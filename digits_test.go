@@ -0,0 +1,65 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// paths answers with 404 unless the BIN requested is in found, recording
+// the BINs requested.
+func paths(requested *[]string, found ...string) Middleware {
+	return func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			bin := strings.TrimPrefix(req.URL.Path, "/")
+			*requested = append(*requested, bin)
+
+			for _, f := range found {
+				if f == bin {
+					return canned(http.StatusOK, cannedBIN)(nil).Do(req)
+				}
+			}
+
+			return canned(http.StatusNotFound, "")(nil).Do(req)
+		})
+	}
+}
+
+func TestClientWithBINDigits(t *testing.T) {
+	for name, tc := range map[string]struct {
+		digits  BINDigits
+		want    string
+		err     error
+		entries int
+	}{
+		"Eight":         {EightDigits, "45717360", ErrNotFound, 1},
+		"Six":           {SixDigits, "457173", nil, 1},
+		"EightFallback": {EightDigitsFallback, "45717360,457173", nil, 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var requested []string
+			cache := NewMemoryCache(0)
+			c := New(WithBINDigits(tc.digits), WithCache(cache), WithMiddleware(paths(&requested, "457173")))
+
+			if _, err := c.Search(context.TODO(), "4571736012345678"); !errors.Is(err, tc.err) {
+				t.Fatalf("Search returned %v, want %v", err, tc.err)
+			}
+
+			if strings.Join(requested, ",") != tc.want {
+				t.Fatalf("requested %v, want %v", requested, tc.want)
+			}
+
+			if cache.Len() != tc.entries {
+				t.Fatalf("cached %d entries, want %d", cache.Len(), tc.entries)
+			}
+
+			requested = nil
+			c.Search(context.TODO(), "457173")
+			if tc.err == nil && len(requested) != 0 {
+				t.Fatalf("6-digit lookup requested %v, not sharing the entry of the 8", requested)
+			}
+		})
+	}
+}
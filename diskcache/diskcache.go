@@ -0,0 +1,176 @@
+// Package diskcache is a `binlookup.Cache` kept on disk, so that the
+// lookups cached survive restarts of the process.
+//
+// Entries are files of their own in a directory, written atomically, and
+// named after the hash of their key so that the BINs cached don't show in
+// directory listings. The package has no dependencies.
+package diskcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Cache is a `binlookup.Cache` storing its entries in the files of Dir,
+// encoded with Codec. It's safe for concurrent use, by several processes
+// sharing Dir too.
+type Cache struct {
+	// Dir holds the entries, spread over 256 sub-directories.
+	Dir string
+
+	// Codec encodes the entries, compressing them or encoding them in
+	// binary when configured to, see `binlookup.EntryCodec`.
+	// The zero value leaves them uncompressed.
+	Codec binlookup.EntryCodec
+
+	// Retention is how long `Cache.Prune` keeps entries past their
+	// expiry.
+	Retention time.Duration
+}
+
+// New returns a `Cache` storing its entries in dir, creating it when it
+// doesn't exist.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &Cache{Dir: dir}, nil
+}
+
+// path returns the file of the entry of key.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.Dir, name[:2], name)
+}
+
+// Get returns the entry stored under key, unless ctx is done.
+func (c *Cache) Get(ctx context.Context, key string) (e binlookup.Entry, ok bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return e, false, nil
+	}
+	if err != nil {
+		return
+	}
+
+	if e, err = c.Codec.Unmarshal(data); err != nil {
+		return
+	}
+
+	return e, true, nil
+}
+
+// Set stores e under key, replacing the file of the previous entry at
+// once so that readers never see a partial one. It does nothing once ctx
+// is done.
+func (c *Cache) Set(ctx context.Context, key string, e binlookup.Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := c.Codec.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Delete removes the entry stored under key, unless ctx is done.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := os.Remove(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Prune removes the entries expired for longer than the retention of c,
+// along with those that can't be decoded, returning how many it removed.
+// Running it periodically keeps Dir from growing with BINs no longer
+// looked up. It stops once ctx is done, returning its error.
+func (c *Cache) Prune(ctx context.Context) (n int, err error) {
+	now := time.Now()
+
+	err = filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		// Temporary files are being written, unless a crash left them.
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			if fi, err := d.Info(); err == nil && now.Sub(fi.ModTime()) > time.Hour {
+				os.Remove(path)
+			}
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if e, err := c.Codec.Unmarshal(data); err == nil && now.Before(e.Expires.Add(c.Retention)) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		n++
+
+		return nil
+	})
+
+	return
+}
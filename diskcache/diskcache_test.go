@@ -0,0 +1,90 @@
+package diskcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := c.Get(context.TODO(), "45717360"); ok || err != nil {
+		t.Fatalf("Get of a missing key returned %v, %v", ok, err)
+	}
+
+	e := binlookup.Entry{BIN: &binlookup.BIN{Scheme: binlookup.SchemeVisa}, Expires: time.Now().Add(time.Hour)}
+	if err := c.Set(context.TODO(), "45717360", e); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process opening the same directory, as after a restart.
+	reopened, _ := New(dir)
+	got, ok, err := reopened.Get(context.TODO(), "45717360")
+	if !ok || err != nil || got.BIN.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("Get returned %+v, %v, %v", got, ok, err)
+	}
+
+	filepath.WalkDir(dir, func(path string, _ os.DirEntry, _ error) error {
+		if strings.Contains(path, "45717360") {
+			t.Fatalf("BIN shows in the path %v", path)
+		}
+		return nil
+	})
+
+	if err := c.Delete(context.TODO(), "45717360"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := c.Get(context.TODO(), "45717360"); ok {
+		t.Fatal("deleted entry is still cached")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	c, _ := New(t.TempDir())
+	c.Retention = time.Hour
+
+	c.Set(context.TODO(), "fresh", binlookup.Entry{NotFound: true, Expires: time.Now().Add(time.Hour)})
+	c.Set(context.TODO(), "retained", binlookup.Entry{NotFound: true, Expires: time.Now().Add(-time.Minute)})
+	c.Set(context.TODO(), "expired", binlookup.Entry{NotFound: true, Expires: time.Now().Add(-2 * time.Hour)})
+
+	if n, err := c.Prune(context.TODO()); n != 1 || err != nil {
+		t.Fatalf("Prune removed %d entries, %v, want 1", n, err)
+	}
+
+	for key, want := range map[string]bool{"fresh": true, "retained": true, "expired": false} {
+		if _, ok, _ := c.Get(context.TODO(), key); ok != want {
+			t.Fatalf("%v is cached: %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestCacheCanceled(t *testing.T) {
+	c, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	if err := c.Set(ctx, "45717360", binlookup.Entry{NotFound: true}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Set canceled returned %v", err)
+	}
+
+	if _, err := c.Prune(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Prune canceled returned %v", err)
+	}
+}
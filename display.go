@@ -0,0 +1,192 @@
+package binlookup
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IssuerPart is a part of the issuer string built by `DisplayIssuer`.
+type IssuerPart int
+
+const (
+	// IssuerBank is the normalized bank name.
+	IssuerBank IssuerPart = iota
+	// IssuerScheme is the display name of the scheme.
+	IssuerScheme
+	// IssuerCountry is the country, as selected by IssuerFormat.Country.
+	IssuerCountry
+)
+
+// CountryStyle selects how `DisplayIssuer` renders the country.
+type CountryStyle int
+
+const (
+	// CountryName renders the country name, "Denmark".
+	CountryName CountryStyle = iota
+	// CountryAlpha2 renders the ISO 3166-1 alpha-2 code, "DK".
+	CountryAlpha2
+	// CountryEmojiName renders the flag followed by the name, "🇩🇰 Denmark".
+	CountryEmojiName
+)
+
+// IssuerFormat is the formatting rule `DisplayIssuer` applies for a
+// locale. Parts which are unknown for a BIN are left out.
+type IssuerFormat struct {
+	Order     []IssuerPart
+	Separator string
+	Country   CountryStyle
+}
+
+// DefaultIssuerFormat renders "Jyske Bank · Visa · Denmark".
+var DefaultIssuerFormat = IssuerFormat{
+	Order:     []IssuerPart{IssuerBank, IssuerScheme, IssuerCountry},
+	Separator: " · ",
+}
+
+var (
+	issuerFormatsMu sync.RWMutex
+	issuerFormats   = map[string]IssuerFormat{}
+)
+
+// RegisterIssuerFormat sets the format `DisplayIssuerIn` applies for
+// locale, a BCP 47 tag such as "de" or "en-US".
+func RegisterIssuerFormat(locale string, f IssuerFormat) {
+	issuerFormatsMu.Lock()
+	defer issuerFormatsMu.Unlock()
+
+	issuerFormats[strings.ToLower(locale)] = f
+}
+
+// issuerFormat returns the format registered for locale, falling back to
+// its language, then to `DefaultIssuerFormat`.
+func issuerFormat(locale string) IssuerFormat {
+	issuerFormatsMu.RLock()
+	defer issuerFormatsMu.RUnlock()
+
+	locale = strings.ToLower(locale)
+	for locale != "" {
+		if f, ok := issuerFormats[locale]; ok {
+			return f
+		}
+
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+
+	return DefaultIssuerFormat
+}
+
+// DisplayIssuer returns the short issuer string support agents read out
+// to customers, such as "Jyske Bank · Visa · Denmark", formatted with
+// `DefaultIssuerFormat`.
+func DisplayIssuer(b *BIN) string {
+	return DisplayIssuerIn(b, "")
+}
+
+// DisplayIssuerIn is like `DisplayIssuer` with the format registered for
+// locale.
+func DisplayIssuerIn(b *BIN, locale string) string {
+	f := issuerFormat(locale)
+
+	var parts []string
+	for _, p := range f.Order {
+		var v string
+		switch p {
+		case IssuerBank:
+			if b.Bank != nil {
+				v = NormalizeBankName(b.Bank.Name)
+			}
+		case IssuerScheme:
+			v = b.Scheme.DisplayName()
+		case IssuerCountry:
+			v = displayCountry(b.Country, f.Country)
+		}
+
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, f.Separator)
+}
+
+func displayCountry(c Country, style CountryStyle) string {
+	switch style {
+	case CountryAlpha2:
+		return c.Short
+	case CountryEmojiName:
+		return strings.TrimSpace(c.Emoji + " " + c.Name)
+	}
+
+	return c.Name
+}
+
+var schemeNames = map[Scheme]string{
+	SchemeVisa:       "Visa",
+	SchemeMastercard: "Mastercard",
+	SchemeAmex:       "American Express",
+	SchemeDiscover:   "Discover",
+	SchemeDiners:     "Diners Club",
+	SchemeJCB:        "JCB",
+	SchemeUnionPay:   "UnionPay",
+	SchemeMaestro:    "Maestro",
+	SchemeMir:        "Mir",
+	SchemeRuPay:      "RuPay",
+	SchemeElo:        "Elo",
+	SchemeTroy:       "Troy",
+	SchemeDankort:    "Dankort",
+}
+
+// DisplayName returns the name s is marketed under, "American Express"
+// for `SchemeAmex`. Unknown schemes are title cased.
+func (s Scheme) DisplayName() string {
+	if n, ok := schemeNames[Scheme(strings.ToLower(string(s)))]; ok {
+		return n
+	}
+
+	return titleWord(string(s))
+}
+
+// bankConnectors are kept lower case by `NormalizeBankName`.
+var bankConnectors = map[string]bool{
+	"of": true, "and": true, "the": true, "for": true,
+	"de": true, "del": true, "des": true, "du": true, "la": true, "le": true, "y": true,
+}
+
+// NormalizeBankName tidies the bank names upstream sends, often shouted
+// in capitals: "JYSKE BANK" becomes "Jyske Bank". Words of up to three
+// letters are taken for acronyms (AG, ING, PLC) and left as is. Names
+// already in mixed case are only stripped of redundant spaces.
+func NormalizeBankName(name string) string {
+	words := strings.Fields(name)
+	if strings.ToUpper(name) != name {
+		return strings.Join(words, " ")
+	}
+
+	for i, w := range words {
+		lower := strings.ToLower(w)
+		switch {
+		case i > 0 && bankConnectors[lower]:
+			words[i] = lower
+		case utf8.RuneCountInString(strings.Trim(w, ".,&()")) > 3:
+			words[i] = titleWord(lower)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// titleWord upper cases the first letter of w.
+func titleWord(w string) string {
+	r, n := utf8.DecodeRuneInString(w)
+	if n == 0 {
+		return w
+	}
+
+	return string(unicode.ToUpper(r)) + w[n:]
+}
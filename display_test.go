@@ -0,0 +1,53 @@
+package binlookup
+
+import "testing"
+
+func TestDisplayIssuer(t *testing.T) {
+	b := &BIN{
+		Scheme:  "visa",
+		Country: Country{Name: "Denmark", Short: "DK", Emoji: "🇩🇰"},
+		Bank:    &Bank{Name: "JYSKE  BANK"},
+	}
+
+	if s := DisplayIssuer(b); s != "Jyske Bank · Visa · Denmark" {
+		t.Fatalf("got %q", s)
+	}
+
+	if s := DisplayIssuer(&BIN{Scheme: "amex"}); s != "American Express" {
+		t.Fatalf("unknown parts aren't left out: %q", s)
+	}
+}
+
+func TestDisplayIssuerIn(t *testing.T) {
+	RegisterIssuerFormat("de", IssuerFormat{
+		Order:     []IssuerPart{IssuerScheme, IssuerBank, IssuerCountry},
+		Separator: ", ",
+		Country:   CountryAlpha2,
+	})
+	defer RegisterIssuerFormat("de", DefaultIssuerFormat)
+
+	b := &BIN{Scheme: "mastercard", Country: Country{Name: "Germany", Short: "DE"}, Bank: &Bank{Name: "Commerzbank AG"}}
+
+	if s := DisplayIssuerIn(b, "de-AT"); s != "Mastercard, Commerzbank AG, DE" {
+		t.Fatalf("got %q", s)
+	}
+
+	if s := DisplayIssuerIn(b, "fr"); s != "Commerzbank AG · Mastercard · Germany" {
+		t.Fatalf("locale without a format doesn't fall back to the default: %q", s)
+	}
+}
+
+func TestNormalizeBankName(t *testing.T) {
+	cases := map[string]string{
+		"JYSKE BANK":            "Jyske Bank",
+		"BANK OF AMERICA, N.A.": "Bank of America, N.A.",
+		"ING BANK N.V.":         "ING Bank N.V.",
+		"Commerzbank  AG":       "Commerzbank AG",
+	}
+
+	for in, want := range cases {
+		if got := NormalizeBankName(in); got != want {
+			t.Fatalf("NormalizeBankName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package binlookup
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultDNSTTL is the TTL used by `DNSCache` for resolutions whose TTL is
+// unknown, which is the case for the resolver of the standard library.
+const DefaultDNSTTL = 30 * time.Second
+
+// DNSResolveFunc resolves host, returning its addresses together with the
+// TTL of the records they came from. A zero TTL means unknown.
+type DNSResolveFunc func(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error)
+
+// DNSCache is an in-process cache of upstream host resolutions. Entries are
+// kept for the TTL reported by Resolve, clamped to MinTTL and MaxTTL.
+//
+// The zero value is ready to use and resolves through net.DefaultResolver,
+// /etc/hosts and the options of the system included, which doesn't expose
+// record TTLs, so `DefaultDNSTTL` applies. Plug in a TTL-aware Resolve,
+// such as `DNSServerResolve`, to have the records' own TTLs honored.
+type DNSCache struct {
+	Resolve        DNSResolveFunc
+	MinTTL, MaxTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	ips     []net.IPAddr
+	err     error
+	expires time.Time
+	done    chan struct{}
+}
+
+// WithDNSCache makes the `Client` resolve upstream through d.
+func WithDNSCache(d *DNSCache) Option {
+	return func(c *Client) {
+		c.dnsCache = d
+	}
+}
+
+// LookupIPAddr returns the cached addresses of host, resolving it when
+// there are none or they have expired. Concurrent misses for the same
+// host share a single resolution.
+func (d *DNSCache) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	d.mu.Lock()
+	if d.entries == nil {
+		d.entries = make(map[string]*dnsEntry)
+	}
+
+	e, ok := d.entries[host]
+	if ok {
+		select {
+		case <-e.done:
+			if time.Now().Before(e.expires) {
+				d.mu.Unlock()
+				return e.ips, nil
+			}
+		default:
+			d.mu.Unlock()
+			return d.wait(ctx, e)
+		}
+	}
+
+	e = &dnsEntry{done: make(chan struct{})}
+	d.entries[host] = e
+	d.mu.Unlock()
+
+	go d.resolve(host, e)
+
+	return d.wait(ctx, e)
+}
+
+func (d *DNSCache) wait(ctx context.Context, e *dnsEntry) ([]net.IPAddr, error) {
+	select {
+	case <-e.done:
+		return e.ips, e.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve fills e in the background so that a canceled caller doesn't
+// fail the lookup for the others waiting on it.
+func (d *DNSCache) resolve(host string, e *dnsEntry) {
+	resolve := d.Resolve
+	if resolve == nil {
+		resolve = systemResolve
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ips, ttl, err := resolve(ctx, host)
+	if ttl <= 0 {
+		ttl = DefaultDNSTTL
+	}
+	if d.MinTTL > 0 && ttl < d.MinTTL {
+		ttl = d.MinTTL
+	}
+	if d.MaxTTL > 0 && ttl > d.MaxTTL {
+		ttl = d.MaxTTL
+	}
+
+	e.ips, e.err = ips, err
+	if err == nil {
+		e.expires = time.Now().Add(ttl)
+	}
+	close(e.done)
+
+	if err != nil {
+		d.mu.Lock()
+		if d.entries[host] == e {
+			delete(d.entries, host)
+		}
+		d.mu.Unlock()
+	}
+}
+
+func systemResolve(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	return ips, 0, err
+}
@@ -0,0 +1,80 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCacheHonorsTTL(t *testing.T) {
+	var calls int32
+	d := &DNSCache{Resolve: func(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}}, 50 * time.Millisecond, nil
+	}}
+
+	for i := 0; i < 3; i++ {
+		ips, err := d.LookupIPAddr(context.TODO(), "lookup.binlist.net")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ips) != 1 || !ips[0].IP.Equal(net.ParseIP("192.0.2.1")) {
+			t.Fatalf("unexpected addresses %v", ips)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("resolved %d times within the TTL, want 1", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := d.LookupIPAddr(context.TODO(), "lookup.binlist.net"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("resolved %d times after the TTL, want 2", n)
+	}
+}
+
+func TestDNSCacheDoesNotCacheErrors(t *testing.T) {
+	var calls int32
+	d := &DNSCache{Resolve: func(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, time.Hour, errors.New("SERVFAIL")
+	}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.LookupIPAddr(context.TODO(), "lookup.binlist.net"); err == nil {
+			t.Fatal("a failed resolution returned nil error")
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("resolved %d times, want 2", n)
+	}
+}
+
+func TestDNSCacheClampsTTL(t *testing.T) {
+	var calls int32
+	d := &DNSCache{
+		MaxTTL: 10 * time.Millisecond,
+		Resolve: func(ctx context.Context, host string) ([]net.IPAddr, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			return []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}}, time.Hour, nil
+		},
+	}
+
+	d.LookupIPAddr(context.TODO(), "lookup.binlist.net")
+	time.Sleep(20 * time.Millisecond)
+	d.LookupIPAddr(context.TODO(), "lookup.binlist.net")
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("resolved %d times, MaxTTL wasn't applied", n)
+	}
+}
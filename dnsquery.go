@@ -0,0 +1,230 @@
+package binlookup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// errDNSAnswer is returned by the resolutions of `DNSServerResolve`
+// answered with an error, truncated or malformed.
+var errDNSAnswer = errors.New("Unusable DNS Answer")
+
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+	dnsClassIN  = 1
+)
+
+// DNSServerResolve returns a `DNSResolveFunc` querying the A and AAAA
+// records of hosts from the DNS servers, host:port, over UDP, along with
+// the lowest TTL of the records answered, CNAMEs included, for `DNSCache`
+// to honor. The servers are tried in order until one answers.
+//
+// It resolves fully qualified names as they are, without the search
+// domains of resolv.conf, nor /etc/hosts, hence is to be opted in to with
+// `DNSCache` for upstream hosts served by the DNS only. Answers are taken
+// only when both their ID and question are those of the query.
+func DNSServerResolve(servers ...string) DNSResolveFunc {
+	return func(ctx context.Context, host string) (ips []net.IPAddr, ttl time.Duration, err error) {
+		err = errDNSAnswer
+		for _, server := range servers {
+			if ips, ttl, err = queryDNS(ctx, server, host); err == nil {
+				return
+			}
+		}
+
+		return
+	}
+}
+
+// queryDNS resolves host from server, the A and AAAA records at once.
+func queryDNS(ctx context.Context, server, host string) ([]net.IPAddr, time.Duration, error) {
+	type answer struct {
+		ips []net.IPAddr
+		ttl time.Duration
+		err error
+	}
+	answers := make(chan answer, 2)
+	for _, qtype := range []uint16{dnsTypeA, dnsTypeAAAA} {
+		go func() {
+			var a answer
+			a.ips, a.ttl, a.err = queryDNSType(ctx, server, host, qtype)
+			answers <- a
+		}()
+	}
+
+	var (
+		ips []net.IPAddr
+		ttl time.Duration
+	)
+	for range 2 {
+		a := <-answers
+		if a.err != nil {
+			return nil, 0, a.err
+		}
+		if len(a.ips) > 0 && (ttl == 0 || a.ttl < ttl) {
+			ttl = a.ttl
+		}
+		ips = append(ips, a.ips...)
+	}
+	if len(ips) == 0 {
+		return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	return ips, ttl, nil
+}
+
+// queryDNSType resolves the records of qtype of host from server.
+func queryDNSType(ctx context.Context, server, host string, qtype uint16) ([]net.IPAddr, time.Duration, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	var id [2]byte
+	rand.Read(id[:])
+	query, err := dnsQuery(binary.BigEndian.Uint16(id[:]), host, qtype)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, 0, err
+	}
+
+	buf := make([]byte, 1232)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Answers of other IDs are those of earlier queries, or spoofed.
+		if n >= 2 && binary.BigEndian.Uint16(buf) == binary.BigEndian.Uint16(id[:]) {
+			return parseDNSAnswer(buf[:n], query, qtype)
+		}
+	}
+}
+
+// dnsQuery encodes the recursive query of the records of qtype of host.
+func dnsQuery(id uint16, host string, qtype uint16) ([]byte, error) {
+	q := binary.BigEndian.AppendUint16(nil, id)
+	q = append(q, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0)
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, &net.DNSError{Err: "invalid host name", Name: host}
+		}
+		q = append(q, byte(len(label)))
+		q = append(q, label...)
+	}
+	q = append(q, 0)
+	q = binary.BigEndian.AppendUint16(q, qtype)
+	q = binary.BigEndian.AppendUint16(q, dnsClassIN)
+
+	return q, nil
+}
+
+// parseDNSAnswer returns the addresses of qtype of the answer msg to
+// query, along with the lowest TTL of its records.
+func parseDNSAnswer(msg, query []byte, qtype uint16) (ips []net.IPAddr, ttl time.Duration, err error) {
+	if len(msg) < 12 {
+		return nil, 0, errDNSAnswer
+	}
+
+	flags := binary.BigEndian.Uint16(msg[2:])
+	if flags&0x8000 == 0 || flags&0x0200 != 0 {
+		// Not an answer, or truncated.
+		return nil, 0, errDNSAnswer
+	}
+
+	// The question is that of the query, its name of any case.
+	question := query[12:]
+	if binary.BigEndian.Uint16(msg[4:]) != 1 || len(msg) < 12+len(question) || !equalFoldASCII(msg[12:12+len(question)], question) {
+		return nil, 0, errDNSAnswer
+	}
+
+	switch flags & 0x000F {
+	case 0:
+	case 3:
+		return nil, 0, nil
+	default:
+		return nil, 0, errDNSAnswer
+	}
+
+	records := binary.BigEndian.Uint16(msg[6:])
+	off := 12 + len(question)
+
+	first := true
+	for range records {
+		if off = skipDNSName(msg, off); off < 0 || off+10 > len(msg) {
+			return nil, 0, errDNSAnswer
+		}
+		typ := binary.BigEndian.Uint16(msg[off:])
+		rttl := time.Duration(binary.BigEndian.Uint32(msg[off+4:])) * time.Second
+		size := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+		if off+size > len(msg) {
+			return nil, 0, errDNSAnswer
+		}
+		data := msg[off : off+size]
+		off += size
+
+		if first || rttl < ttl {
+			ttl, first = rttl, false
+		}
+		switch {
+		case typ == qtype && typ == dnsTypeA && size == net.IPv4len,
+			typ == qtype && typ == dnsTypeAAAA && size == net.IPv6len:
+			ips = append(ips, net.IPAddr{IP: net.IP(append([]byte(nil), data...))})
+		}
+	}
+
+	return ips, ttl, nil
+}
+
+// skipDNSName returns the offset past the name of msg at off, -1 when
+// it's malformed.
+func skipDNSName(msg []byte, off int) int {
+	for off < len(msg) {
+		switch n := int(msg[off]); {
+		case n == 0:
+			return off + 1
+		case n&0xC0 == 0xC0:
+			if off+2 > len(msg) {
+				return -1
+			}
+			return off + 2
+		default:
+			off += 1 + n
+		}
+	}
+
+	return -1
+}
+
+// equalFoldASCII reports whether a and b are equal, their ASCII letters of
+// any case.
+func equalFoldASCII(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i]|0x20 != b[i]|0x20 || a[i] != b[i] && (a[i]|0x20 < 'a' || a[i]|0x20 > 'z') {
+			return false
+		}
+	}
+	return true
+}
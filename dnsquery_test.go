@@ -0,0 +1,102 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeDNS answers the queries of A records with 192.0.2.1 through a CNAME
+// of TTL 60s to a record of TTL 300s, those of AAAA records with none,
+// and those of missing.example with NXDOMAIN.
+func fakeDNS(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			q := buf[:n]
+			end := skipDNSName(q, 12)
+			qtype := binary.BigEndian.Uint16(q[end:])
+
+			resp := append([]byte(nil), q[:end+4]...)
+			resp[2], resp[3] = 0x81, 0x80
+			if string(q[13:20]) == "missing" {
+				resp[3] |= 3
+			} else if qtype == dnsTypeA {
+				resp[7] = 2
+				// CNAME of the name queried, pointed to at 12.
+				resp = append(resp, 0xC0, 12, 0, 5, 0, 1, 0, 0, 0, 60, 0, 2, 0xC0, 12)
+				resp = append(resp, 0xC0, 12, 0, 1, 0, 1, 0, 0, 1, 44, 0, 4, 192, 0, 2, 1)
+			}
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSServerResolve(t *testing.T) {
+	resolve := DNSServerResolve("127.0.0.1:1", fakeDNS(t))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+	defer cancel()
+
+	ips, ttl, err := resolve(ctx, "lookup.binlist.net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ips) != 1 || !ips[0].IP.Equal(net.ParseIP("192.0.2.1")) || ttl != time.Minute {
+		t.Fatalf("resolved %v with a TTL of %v, want 192.0.2.1 for the 1m of the CNAME", ips, ttl)
+	}
+
+	var de *net.DNSError
+	if _, _, err := resolve(ctx, "missing.example"); !errors.As(err, &de) || !de.IsNotFound {
+		t.Fatalf("resolution of a missing host returned %v", err)
+	}
+}
+
+func TestParseDNSAnswerTruncated(t *testing.T) {
+	msg := make([]byte, 12)
+	msg[2] = 0x82
+
+	if _, _, err := parseDNSAnswer(msg, msg, dnsTypeA); !errors.Is(err, errDNSAnswer) {
+		t.Fatalf("truncated answer parsed with %v", err)
+	}
+}
+
+func TestParseDNSAnswerQuestion(t *testing.T) {
+	query, _ := dnsQuery(1, "lookup.binlist.net", dnsTypeA)
+	answer := func(host string) []byte {
+		msg, _ := dnsQuery(1, host, dnsTypeA)
+		msg[2], msg[3], msg[7] = 0x81, 0x80, 1
+		return append(msg, 0xC0, 12, 0, 1, 0, 1, 0, 0, 1, 44, 0, 4, 192, 0, 2, 1)
+	}
+
+	if ips, _, err := parseDNSAnswer(answer("LOOKUP.binlist.net"), query, dnsTypeA); err != nil || len(ips) != 1 {
+		t.Fatalf("answer to the query in another case parsed to %v with %v", ips, err)
+	}
+	for _, host := range []string{"lookup.example.net", "lookup.binlist.ne"} {
+		if _, _, err := parseDNSAnswer(answer(host), query, dnsTypeA); !errors.Is(err, errDNSAnswer) {
+			t.Fatalf("answer to %s taken for the query with %v", host, err)
+		}
+	}
+
+	nx := answer("lookup.example.net")[:len(query)]
+	nx[3], nx[7] = 0x83, 0
+	if _, _, err := parseDNSAnswer(nx, query, dnsTypeA); !errors.Is(err, errDNSAnswer) {
+		t.Fatalf("NXDOMAIN of another name taken for the query with %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultEnricherWorkers is the number of lookups an `Enricher` makes at
+// once, unless changed through its Workers.
+const DefaultEnricherWorkers = 8
+
+// Progress is how far an `Enricher` is through its BINs.
+type Progress struct {
+	// Done counts the BINs resolved, those not found included, and
+	// Failed those whose lookup failed otherwise.
+	Done      int
+	Failed    int
+	Remaining int
+
+	Elapsed time.Duration
+
+	// ETA is the time left at the pace so far, zero until the first BIN
+	// is over.
+	ETA time.Duration
+}
+
+// Enricher looks large sets of BINs up through Lookuper, such as those of
+// month-end settlement files, reporting its progress along the way. The
+// lookups go through whatever Lookuper does: a `Client` configured with a
+// cache, a rate limit and retries is the one to enrich files with.
+type Enricher struct {
+	Lookuper Lookuper
+
+	// Workers is the number of lookups made at once,
+	// `DefaultEnricherWorkers` when zero.
+	Workers int
+
+	// OnProgress is given the `Progress` every Interval, a second when
+	// zero, and once over.
+	OnProgress func(p Progress)
+	Interval   time.Duration
+}
+
+// Enrich looks bins up, giving onResult the index of each along with its
+// `Result` as it's over, from several goroutines at once. Results aren't
+// retained, for enrichments of millions of rows to run in memory bound by
+// the number of distinct BINs.
+//
+// BINs repeated in bins are looked up once, onResult being given the same
+// `Result` for every index of them, so that the quota is spent on the
+// distinct BINs only. Those a `Client` looks up as the same BIN, such as
+// card numbers of the same first 8 digits, count as repeated too.
+//
+// It returns the error of ctx when done before all of bins are over.
+func (e *Enricher) Enrich(ctx context.Context, bins []string, onResult func(i int, r Result)) error {
+	workers := e.Workers
+	if workers <= 0 {
+		workers = DefaultEnricherWorkers
+	}
+
+	interval := e.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var mu sync.Mutex
+	start := time.Now()
+	p := Progress{Remaining: len(bins)}
+	report := func() {
+		mu.Lock()
+		p.Elapsed = time.Since(start)
+		if over := p.Done + p.Failed; over > 0 {
+			p.ETA = p.Elapsed / time.Duration(over) * time.Duration(p.Remaining)
+		}
+		snapshot := p
+		mu.Unlock()
+
+		if e.OnProgress != nil {
+			e.OnProgress(snapshot)
+		}
+	}
+
+	stop := make(chan struct{})
+	ticked := make(chan struct{})
+	go func() {
+		defer close(ticked)
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				report()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	key := func(bin string) string { return bin }
+	if c, ok := e.Lookuper.(*Client); ok {
+		key = c.batchKey
+	}
+	groups := dedupe(bins, key)
+
+	next := make(chan []int)
+	var wg sync.WaitGroup
+	for range min(workers, len(groups)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for g := range next {
+				b, err := e.Lookuper.Search(ctx, bins[g[0]])
+
+				mu.Lock()
+				if err == nil || errors.Is(err, ErrNotFound) {
+					p.Done += len(g)
+				} else {
+					p.Failed += len(g)
+				}
+				p.Remaining -= len(g)
+				mu.Unlock()
+
+				if onResult != nil {
+					for _, i := range g {
+						onResult(i, Result{b, err})
+					}
+				}
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for _, g := range groups {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		select {
+		case next <- g:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	close(stop)
+	<-ticked
+	report()
+
+	return err
+}
@@ -0,0 +1,99 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnricher(t *testing.T) {
+	l := LookuperFunc(func(ctx context.Context, bin string) (*BIN, error) {
+		switch bin {
+		case "41111111":
+			return nil, ErrNotFound
+		case "42222222":
+			return nil, ErrRateLimited
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		return &BIN{Scheme: SchemeVisa}, nil
+	})
+
+	var mu sync.Mutex
+	var reports []Progress
+	e := &Enricher{Lookuper: l, Workers: 2, Interval: 5 * time.Millisecond, OnProgress: func(p Progress) {
+		mu.Lock()
+		reports = append(reports, p)
+		mu.Unlock()
+	}}
+
+	bins := []string{"45717360", "41111111", "42222222", "45717361", "45717362", "45717363"}
+	results := make([]Result, len(bins))
+	if err := e.Enrich(context.TODO(), bins, func(i int, r Result) { results[i] = r }); err != nil {
+		t.Fatal(err)
+	}
+
+	if results[0].BIN == nil || !errors.Is(results[2].Err, ErrRateLimited) {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	last := reports[len(reports)-1]
+	if last.Done != 5 || last.Failed != 1 || last.Remaining != 0 || last.ETA != 0 {
+		t.Fatalf("final progress %+v", last)
+	}
+
+	if len(reports) < 2 {
+		t.Fatal("no progress reported along the way")
+	}
+}
+
+func TestEnricherDedupe(t *testing.T) {
+	var mu sync.Mutex
+	var searched []string
+	l := LookuperFunc(func(ctx context.Context, bin string) (*BIN, error) {
+		mu.Lock()
+		searched = append(searched, bin)
+		mu.Unlock()
+
+		return &BIN{Scheme: SchemeVisa}, nil
+	})
+
+	var last Progress
+	e := &Enricher{Lookuper: l, OnProgress: func(p Progress) { last = p }}
+
+	bins := []string{"45717360", "41111111", "45717360", "45717360"}
+	results := make([]Result, len(bins))
+	if err := e.Enrich(context.TODO(), bins, func(i int, r Result) {
+		mu.Lock()
+		results[i] = r
+		mu.Unlock()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(searched) != 2 || results[3].BIN == nil || results[3].BIN != results[0].BIN {
+		t.Fatalf("searched %v for results %+v", searched, results)
+	}
+
+	if last.Done != 4 || last.Remaining != 0 {
+		t.Fatalf("final progress %+v", last)
+	}
+}
+
+func TestEnricherCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	var n int
+	l := LookuperFunc(func(context.Context, string) (*BIN, error) {
+		cancel()
+		return &BIN{}, nil
+	})
+
+	e := &Enricher{Lookuper: l, Workers: 1}
+	err := e.Enrich(ctx, []string{"45717360", "45717361", "45717362"}, func(int, Result) { n++ })
+	if !errors.Is(err, context.Canceled) || n == 3 {
+		t.Fatalf("canceled enrichment returned %v after %d results", err, n)
+	}
+}
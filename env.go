@@ -0,0 +1,195 @@
+package binlookup
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIKeyHeader is the header `NewFromEnv` sends the keys of
+// BINLOOKUP_API_KEY in, unless BINLOOKUP_API_KEY_HEADER changes it.
+const DefaultAPIKeyHeader = "X-Api-Key"
+
+// NewFromEnv returns a `Client` configured by the environment variables
+// below, for twelve-factor services, followed by opts, which take
+// precedence. Those unset or empty leave the defaults be.
+//
+//	BINLOOKUP_BASE_URL        the URL of upstream, see `WithBaseURL`
+//	BINLOOKUP_API_VERSION     the version of its API, see `WithAPIVersion`
+//	BINLOOKUP_API_KEY         the API keys, comma separated, see `WithAPIKeys`
+//	BINLOOKUP_API_KEY_HEADER  the header of the keys, `DefaultAPIKeyHeader` unless set
+//	BINLOOKUP_USER_AGENT      the User-Agent, see `WithUserAgent`
+//	BINLOOKUP_TIMEOUT         the timeout of lookups, such as 5s, see `WithTimeout`
+//	BINLOOKUP_RETRIES         the retries of failed lookups, see `WithRetries`
+//	BINLOOKUP_RATE_LIMIT      the requests allowed per period, such as 10/1m, see `WithRateLimit`
+//	BINLOOKUP_PROXY           the URL of the proxy, see `WithProxy`
+//	BINLOOKUP_TLS_CERT        the PEM file of the client certificate, see `WithClientCertificateFiles`
+//	BINLOOKUP_TLS_KEY         the PEM file of its key, required along
+//	BINLOOKUP_TLS_CA          the PEM file of the CAs upstream is verified against, see `WithRootCAs`
+//	BINLOOKUP_CACHE_SIZE      the size of the `MemoryCache` of the client
+//	BINLOOKUP_CACHE_TTL       how long its entries stay fresh, see `WithCacheTTL`
+//
+// The client caches its lookups in memory once either of the last two is
+// set. It fails with the problems of the variables malformed joined,
+// each naming its variable.
+func NewFromEnv(opts ...Option) (*Client, error) {
+	var (
+		env  []Option
+		errs []error
+	)
+	problem := func(name string, err error) {
+		errs = append(errs, fmt.Errorf("%v: %w", name, err))
+	}
+	get := func(name string) string {
+		return strings.TrimSpace(os.Getenv(name))
+	}
+	duration := func(name string) (d time.Duration, ok bool) {
+		v := get(name)
+		if v == "" {
+			return
+		}
+
+		d, err := time.ParseDuration(v)
+		if err == nil && d < 0 {
+			err = errors.New("Negative")
+		}
+		if err != nil {
+			problem(name, err)
+			return
+		}
+
+		return d, true
+	}
+	integer := func(name string) (n int, ok bool) {
+		v := get(name)
+		if v == "" {
+			return
+		}
+
+		n, err := strconv.Atoi(v)
+		if err == nil && n < 0 {
+			err = errors.New("Negative")
+		}
+		if err != nil {
+			problem(name, err)
+			return
+		}
+
+		return n, true
+	}
+
+	if v := get("BINLOOKUP_BASE_URL"); v != "" {
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+			problem("BINLOOKUP_BASE_URL", errors.New("Absolute URL Required"))
+		} else {
+			env = append(env, WithBaseURL(v))
+		}
+	}
+	if v, ok := integer("BINLOOKUP_API_VERSION"); ok {
+		env = append(env, WithAPIVersion(v))
+	}
+
+	if v := get("BINLOOKUP_API_KEY"); v != "" {
+		var keys []string
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+
+		header := get("BINLOOKUP_API_KEY_HEADER")
+		if header == "" {
+			header = DefaultAPIKeyHeader
+		}
+		env = append(env, WithAPIKeys(header, RoundRobin, keys...))
+	}
+	if v := get("BINLOOKUP_USER_AGENT"); v != "" {
+		env = append(env, WithUserAgent(v))
+	}
+
+	if v, ok := duration("BINLOOKUP_TIMEOUT"); ok {
+		env = append(env, WithTimeout(v))
+	}
+	if v, ok := integer("BINLOOKUP_RETRIES"); ok {
+		env = append(env, WithRetries(v))
+	}
+	if v := get("BINLOOKUP_RATE_LIMIT"); v != "" {
+		n, per, err := parseRate(v)
+		if err != nil {
+			problem("BINLOOKUP_RATE_LIMIT", err)
+		} else {
+			env = append(env, WithRateLimit(n, per))
+		}
+	}
+	if v := get("BINLOOKUP_PROXY"); v != "" {
+		if u, err := url.Parse(v); err != nil || u.Host == "" {
+			problem("BINLOOKUP_PROXY", errors.New("Absolute URL Required"))
+		} else {
+			env = append(env, WithProxy(http.ProxyURL(u)))
+		}
+	}
+
+	switch cert, key := get("BINLOOKUP_TLS_CERT"), get("BINLOOKUP_TLS_KEY"); {
+	case cert == "" && key == "":
+	case cert == "" || key == "":
+		problem("BINLOOKUP_TLS_CERT", errors.New("Required Along With BINLOOKUP_TLS_KEY"))
+	default:
+		if _, err := tls.LoadX509KeyPair(cert, key); err != nil {
+			problem("BINLOOKUP_TLS_CERT", err)
+		} else {
+			env = append(env, WithClientCertificateFiles(cert, key))
+		}
+	}
+	if v := get("BINLOOKUP_TLS_CA"); v != "" {
+		pool := x509.NewCertPool()
+		if data, err := os.ReadFile(v); err != nil {
+			problem("BINLOOKUP_TLS_CA", err)
+		} else if !pool.AppendCertsFromPEM(data) {
+			problem("BINLOOKUP_TLS_CA", errors.New("No PEM Certificate"))
+		} else {
+			env = append(env, WithRootCAs(pool))
+		}
+	}
+
+	size, sized := integer("BINLOOKUP_CACHE_SIZE")
+	ttl, expiring := duration("BINLOOKUP_CACHE_TTL")
+	if sized || expiring {
+		env = append(env, WithCache(NewMemoryCache(size)))
+	}
+	if expiring {
+		env = append(env, WithCacheTTL(ttl))
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("Invalid Environment:\n%w", errors.Join(errs...))
+	}
+
+	return New(append(env, opts...)...), nil
+}
+
+// parseRate parses a rate of the form n/per, such as 10/1m.
+func parseRate(s string) (n int, per time.Duration, err error) {
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, errors.New("Rate Must Be of the Form 10/1m")
+	}
+
+	if n, err = strconv.Atoi(strings.TrimSpace(count)); err != nil {
+		return
+	}
+	if per, err = time.ParseDuration(strings.TrimSpace(period)); err != nil {
+		return
+	}
+	if n <= 0 || per <= 0 {
+		err = errors.New("Requests and Period Must Be Positive")
+	}
+
+	return
+}
@@ -0,0 +1,70 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	t.Setenv("BINLOOKUP_BASE_URL", srv.URL+"/")
+	t.Setenv("BINLOOKUP_API_KEY", "k1, k2")
+	t.Setenv("BINLOOKUP_USER_AGENT", "acme-checkout/2.1")
+	t.Setenv("BINLOOKUP_TIMEOUT", "3s")
+	t.Setenv("BINLOOKUP_RETRIES", "2")
+	t.Setenv("BINLOOKUP_RATE_LIMIT", "10/1m")
+	t.Setenv("BINLOOKUP_CACHE_TTL", "1h")
+
+	c, err := NewFromEnv(WithRetries(1))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if got.Get("X-Api-Key") != "k1" || got.Get("User-Agent") != "acme-checkout/2.1" {
+		t.Fatalf("headers sent were %v", got)
+	}
+	if c.timeout != 3*time.Second || c.retries != 1 || c.cacheTTL != time.Hour || c.limiter == nil {
+		t.Fatalf("configured with timeout %v, %d retries and cache TTL %v", c.timeout, c.retries, c.cacheTTL)
+	}
+	if s := c.CacheStats(); s.Hits != 1 {
+		t.Fatalf("cache stats are %+v, want a hit", s)
+	}
+}
+
+func TestNewFromEnvInvalid(t *testing.T) {
+	t.Setenv("BINLOOKUP_BASE_URL", "lookup.binlist.net")
+	t.Setenv("BINLOOKUP_TIMEOUT", "soon")
+	t.Setenv("BINLOOKUP_RETRIES", "-1")
+	t.Setenv("BINLOOKUP_RATE_LIMIT", "10")
+	t.Setenv("BINLOOKUP_TLS_CERT", "client.pem")
+	t.Setenv("BINLOOKUP_TLS_CA", "missing.pem")
+
+	_, err := NewFromEnv()
+	if err == nil {
+		t.Fatal("malformed environment accepted")
+	}
+
+	for _, name := range []string{"BINLOOKUP_BASE_URL", "BINLOOKUP_TIMEOUT", "BINLOOKUP_RETRIES", "BINLOOKUP_RATE_LIMIT", "BINLOOKUP_TLS_CERT", "BINLOOKUP_TLS_CA"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q doesn't name %v", err, name)
+		}
+	}
+}
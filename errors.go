@@ -0,0 +1,284 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidBIN is returned when the BIN given to `Search` is
+	// incorrect in format. No request is made to upstream in that case.
+	ErrInvalidBIN = errors.New("Invalid BIN")
+
+	// ErrBadRequest matches a http.StatusBadRequest sent by upstream.
+	ErrBadRequest = errors.New("Bad Request")
+
+	// ErrNotFound matches a http.StatusNotFound sent by upstream, which
+	// means the BIN isn't present in the DB that upstream queries.
+	ErrNotFound = errors.New("Not Found")
+
+	// ErrRateLimited matches a http.StatusTooManyRequests sent by upstream.
+	ErrRateLimited = errors.New("Rate Limited")
+
+	// ErrClosed is returned by the lookups of a `Client` that is closed.
+	ErrClosed = errors.New("Client Closed")
+
+	// ErrNetworkDisabled is returned by the lookups of a `Client` with
+	// the network disabled which can't be served locally.
+	ErrNetworkDisabled = errors.New("Network Disabled")
+
+	// ErrQueueFull is returned by the lookups a `Client` sheds when its
+	// queue overflows, see `WithQueue`.
+	ErrQueueFull = errors.New("Queue Full")
+
+	// ErrDryRun is returned by the lookups of a `Client` in dry run which
+	// would have made a request, see `WithDryRun`.
+	ErrDryRun = errors.New("Dry Run")
+
+	// ErrIncompleteData is returned by the lookups of a `Client` checking
+	// completeness whose answer lacks the fields expected of any BIN,
+	// see `WithCompletenessCheck`.
+	ErrIncompleteData = errors.New("Incomplete Data")
+
+	// ErrNoQuotaFetcher is returned by `Client.RefreshQuota` when the
+	// `Client` has no `QuotaFetcher`, see `WithQuotaFetcher`.
+	ErrNoQuotaFetcher = errors.New("No Quota Fetcher")
+)
+
+// maxErrorBody is how much of an unsuccessful response body is retained
+// by `HTTPError`.
+const maxErrorBody = 512
+
+// HTTPError is an error returned by `Search` in the event
+// of a HTTP status code, other than `http.StatusOK`, sent by upstream.
+type HTTPError struct {
+	StatusCode int
+
+	// Body is the beginning of the response body, at most 512 bytes.
+	Body []byte
+
+	// Header holds the response headers useful in debugging, such as
+	// Retry-After and the rate limiting ones.
+	Header http.Header
+
+	// URL is the requested URL with the BIN masked.
+	URL string
+
+	// Message and Code are those of the JSON error body some providers
+	// answer with, when Body is one, the BIN masked in Message.
+	Message string
+	Code    string
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%d %v", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+
+	return msg
+}
+
+// Is reports whether e corresponds to one of the sentinel errors of the
+// package, so that errors.Is(err, ErrNotFound) holds for a 404.
+func (e *HTTPError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	}
+
+	return false
+}
+
+// Temporary reports whether the failure e tells of may go away on its
+// own, retrying later: a 429 or a 5xx, which `IsRetryable` counts as
+// retryable.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Timeout reports whether e is a 408 Request Timeout or a 504 Gateway
+// Timeout.
+func (e *HTTPError) Timeout() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout
+}
+
+// newHTTPError builds an `HTTPError` out of resp, consuming up to
+// maxErrorBody bytes of its body.
+func newHTTPError(resp *http.Response, bin string) *HTTPError {
+	e := &HTTPError{StatusCode: resp.StatusCode, Header: make(http.Header)}
+
+	e.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	for k, v := range resp.Header {
+		if debugHeader(k) {
+			e.Header[k] = v
+		}
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.URL = strings.Replace(resp.Request.URL.String(), bin, maskBIN(bin), 1)
+	}
+
+	e.Message, e.Code = parseErrorBody(e.Body)
+	if bin != "" {
+		e.Message = strings.ReplaceAll(e.Message, bin, maskBIN(bin))
+	}
+
+	return e
+}
+
+// errorBody is the union of the shapes of the JSON error bodies in use:
+// {"message": ..., "code": ...}, {"error": ...} with a string or such an
+// object, {"errors": [...]} of them, and the problem details of RFC 9457.
+type errorBody struct {
+	Message     string          `json:"message"`
+	Code        json.RawMessage `json:"code"`
+	Error       json.RawMessage `json:"error"`
+	Description string          `json:"error_description"`
+	Errors      []errorBody     `json:"errors"`
+	Title       string          `json:"title"`
+	Detail      string          `json:"detail"`
+}
+
+// parseErrorBody extracts the message and code of the JSON error body
+// body, if it's one.
+func parseErrorBody(body []byte) (msg, code string) {
+	var b errorBody
+	if json.Unmarshal(body, &b) != nil {
+		return
+	}
+
+	return b.parse()
+}
+
+func (b errorBody) parse() (msg, code string) {
+	code = rawString(b.Code)
+
+	var nested errorBody
+	switch {
+	case b.Message != "":
+		msg = b.Message
+	case json.Unmarshal(b.Error, &msg) == nil && msg != "":
+		if b.Description != "" {
+			msg += ": " + b.Description
+		}
+	case json.Unmarshal(b.Error, &nested) == nil:
+		var ncode string
+		if msg, ncode = nested.parse(); code == "" {
+			code = ncode
+		}
+	case len(b.Errors) > 0:
+		var ncode string
+		if msg, ncode = b.Errors[0].parse(); code == "" {
+			code = ncode
+		}
+	case b.Detail != "":
+		msg = b.Detail
+	default:
+		msg = b.Title
+	}
+
+	return
+}
+
+// rawString returns the JSON string or number v as a string.
+func rawString(v json.RawMessage) string {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+
+	var n json.Number
+	if json.Unmarshal(v, &n) == nil {
+		return n.String()
+	}
+
+	return ""
+}
+
+// debugHeader reports whether the canonical header key k is worth
+// retaining in an `HTTPError`.
+func debugHeader(k string) bool {
+	switch k {
+	case "Content-Type", "Date", "Retry-After", "Server", "Via", "X-Request-Id", "Cf-Ray":
+		return true
+	}
+
+	return strings.HasPrefix(k, "X-Ratelimit-") || strings.HasPrefix(k, "Ratelimit-")
+}
+
+// maskBIN keeps the first four digits of bin and masks the rest.
+func maskBIN(bin string) string {
+	if len(bin) <= 4 {
+		return bin
+	}
+
+	return bin[:4] + strings.Repeat("•", len(bin)-4)
+}
+
+// Redact returns s with the digits past the first 8 of every number in it
+// masked, as in 52882300••••, numbers grouped with single spaces or
+// dashes, as card numbers are written, counting as one. It's what the
+// errors of the package go through when they could quote more of the
+// input than a BIN, so that no card number ever ends up in the logs they
+// are written to, and what messages quoting input are to go through.
+func Redact(s string) string {
+	var (
+		b      strings.Builder
+		digits int
+		masked bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if digits++; digits > 8 {
+				if !masked {
+					b.Grow(len(s))
+					b.WriteString(s[:i])
+					masked = true
+				}
+				b.WriteString("•")
+				continue
+			}
+		case (c == ' ' || c == '-') && digits > 0 && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+		default:
+			digits = 0
+		}
+
+		if masked {
+			b.WriteByte(c)
+		}
+	}
+
+	if !masked {
+		return s
+	}
+
+	return b.String()
+}
+
+// redactedError is an error whose message is redacted, see `Redact`.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return Redact(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
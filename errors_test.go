@@ -0,0 +1,141 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHTTPErrorIs(t *testing.T) {
+	cases := []struct {
+		code   int
+		target error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+
+	for _, c := range cases {
+		m := New(WithMiddleware(canned(c.code, "")))
+
+		_, err := m.Search(context.TODO(), CorrectBIN)
+		if !errors.Is(err, c.target) {
+			t.Fatalf("%d: errors.Is(%v, %v) is false", c.code, err, c.target)
+		}
+
+		var he *HTTPError
+		if !errors.As(err, &he) || he.StatusCode != c.code {
+			t.Fatalf("%d: HTTPError can't be extracted from %v", c.code, err)
+		}
+	}
+
+	if errors.Is(&HTTPError{StatusCode: http.StatusInternalServerError}, ErrNotFound) {
+		t.Fatal("500 matches ErrNotFound")
+	}
+}
+
+func TestHTTPErrorDetails(t *testing.T) {
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			h := make(http.Header)
+			h.Set("Retry-After", "60")
+			h.Set("X-Ratelimit-Remaining", "0")
+			h.Set("Set-Cookie", "session=secret")
+
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     h,
+				Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 2*maxErrorBody))),
+				Request:    req,
+			}, nil
+		})
+	}
+
+	_, err := New(WithMiddleware(upstream)).Search(context.TODO(), CorrectBIN)
+
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("HTTPError can't be extracted from %v", err)
+	}
+
+	if len(he.Body) != maxErrorBody {
+		t.Fatalf("retained %d bytes of body, want %d", len(he.Body), maxErrorBody)
+	}
+
+	if he.Header.Get("Retry-After") != "60" || he.Header.Get("X-Ratelimit-Remaining") != "0" {
+		t.Fatalf("debugging headers weren't retained: %v", he.Header)
+	}
+
+	if he.Header.Get("Set-Cookie") != "" {
+		t.Fatalf("irrelevant headers were retained: %v", he.Header)
+	}
+
+	if strings.Contains(he.URL, CorrectBIN) || !strings.HasSuffix(he.URL, "/5288•••") {
+		t.Fatalf("BIN isn't masked in %v", he.URL)
+	}
+}
+
+func TestErrInvalidBIN(t *testing.T) {
+	_, err := Search(IncorrectBIN)
+	if !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("errors.Is(%v, ErrInvalidBIN) is false", err)
+	}
+}
+
+func TestHTTPErrorBody(t *testing.T) {
+	for body, want := range map[string]string{
+		`{"message":"quota exceeded for key X","code":"quota"}`:          "429 Too Many Requests: quota exceeded for key X (quota)",
+		`{"error":"rate_limited","error_description":"try again later"}`: "429 Too Many Requests: rate_limited: try again later",
+		`{"error":{"message":"slow down","code":42}}`:                    "429 Too Many Requests: slow down (42)",
+		`{"errors":[{"message":"no quota left for 52882301"}]}`:          "429 Too Many Requests: no quota left for 5288••••",
+		`{"title":"Too Many Requests","detail":"1000 requests a day"}`:   "429 Too Many Requests: 1000 requests a day",
+		`<html>busy</html>`: "429 Too Many Requests",
+	} {
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Body: io.NopCloser(strings.NewReader(body))}
+		if got := newHTTPError(resp, "52882301").Error(); got != want {
+			t.Fatalf("error of %s is %q, want %q", body, got, want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for in, want := range map[string]string{
+		"52882300":                     "52882300",
+		"528823001234":                 "52882300••••",
+		"card 4111 1111 1111 1111 set": "card 4111 1111 •••• •••• set",
+		"4111-1111-1111-1111":          "4111-1111-••••-••••",
+		"bins 45717360, 52882300":      "bins 45717360, 52882300",
+		"Status Code 404 at 12":        "Status Code 404 at 12",
+	} {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestClientRedactsErrors(t *testing.T) {
+	const pan = "4571736012345678"
+
+	// Middleware echoing whatever they were handed stand for any error
+	// quoting the input.
+	echo := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("rejected " + pan)
+		})
+	}
+
+	var logged []string
+	c := New(WithMiddleware(echo), WithHooks(Hooks{OnError: func(e Event) { logged = append(logged, e.Err.Error()) }}))
+
+	_, err := c.Search(context.TODO(), pan)
+	if err == nil || strings.Contains(err.Error(), pan[8:]) || !strings.Contains(err.Error(), "45717360••••••••") {
+		t.Fatalf("error of the lookup of a card number is %v", err)
+	}
+	if len(logged) != 1 || strings.Contains(logged[0], pan[8:]) {
+		t.Fatalf("errors hooked quote the card number: %v", logged)
+	}
+}
@@ -0,0 +1,307 @@
+package binlookup
+
+import (
+	"container/list"
+	"slices"
+)
+
+// EvictionPolicy decides which entry a full `MemoryCache` evicts to make
+// room for another, out of the keys it's told of. `LRU` is the policy of
+// `NewMemoryCache`; `LFU` and `ARC` suit the lookups skewed to a few
+// hundred BINs along a long tail better, by keeping the frequent BINs
+// cached through the bursts of those looked up once.
+//
+// A policy is owned by a single cache, whose lock is held along its
+// calls, and so needn't be safe for concurrent use.
+type EvictionPolicy interface {
+	// Add is told of the key of an entry stored, and returns the key of
+	// the entry to evict for it when the cache is full.
+	Add(key string, full bool) (victim string)
+
+	// Touch is told of the key of an entry got or replaced.
+	Touch(key string)
+
+	// Remove is told of the key of an entry deleted.
+	Remove(key string)
+
+	// Keys returns the keys of the entries, the last to be evicted first.
+	Keys() []string
+}
+
+// NewMemoryCacheWithPolicy returns a `MemoryCache` holding up to size
+// entries, or any number of them when size isn't positive, which evicts
+// the entry p picks once full. p mustn't be given to another cache.
+func NewMemoryCacheWithPolicy(size int, p EvictionPolicy) *MemoryCache {
+	return &MemoryCache{size: size, policy: p, entries: make(map[string]Entry)}
+}
+
+// LRU returns the `EvictionPolicy` evicting the least recently used entry.
+func LRU() EvictionPolicy {
+	return &lru{order: list.New(), elems: make(map[string]*list.Element)}
+}
+
+type lru struct {
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func (p *lru) Add(key string, full bool) (victim string) {
+	if full {
+		victim = p.order.Remove(p.order.Back()).(string)
+		delete(p.elems, victim)
+	}
+	p.elems[key] = p.order.PushFront(key)
+
+	return
+}
+
+func (p *lru) Touch(key string) {
+	if el, ok := p.elems[key]; ok {
+		p.order.MoveToFront(el)
+	}
+}
+
+func (p *lru) Remove(key string) {
+	if el, ok := p.elems[key]; ok {
+		p.order.Remove(el)
+		delete(p.elems, key)
+	}
+}
+
+func (p *lru) Keys() []string {
+	return listKeys(nil, p.order)
+}
+
+// LFU returns the `EvictionPolicy` evicting the least frequently used
+// entry, the least recently used of those as frequently. Entries stay as
+// frequent as they were however long ago, so a BIN no longer looked up
+// does outlast those looked up a few times since.
+func LFU() EvictionPolicy {
+	return &lfu{nodes: make(map[string]*lfuNode), buckets: make(map[int]*list.List)}
+}
+
+type lfu struct {
+	nodes   map[string]*lfuNode
+	buckets map[int]*list.List // of the keys of each frequency, the most recently used first
+	min     int
+}
+
+type lfuNode struct {
+	freq int
+	el   *list.Element
+}
+
+func (p *lfu) Add(key string, full bool) (victim string) {
+	// The minimum frequency is 1 once key is added, so the victim goes
+	// without Remove looking for the next.
+	if full {
+		victim = p.bucket(p.min).Back().Value.(string)
+		p.unlink(p.nodes[victim])
+		delete(p.nodes, victim)
+	}
+
+	p.nodes[key] = &lfuNode{freq: 1, el: p.bucket(1).PushFront(key)}
+	p.min = 1
+
+	return
+}
+
+func (p *lfu) Touch(key string) {
+	n, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+
+	p.unlink(n)
+	if p.min == n.freq && p.buckets[n.freq] == nil {
+		p.min++
+	}
+	n.freq++
+	n.el = p.bucket(n.freq).PushFront(key)
+}
+
+func (p *lfu) Remove(key string) {
+	n, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+
+	p.unlink(n)
+	delete(p.nodes, key)
+
+	if p.min == n.freq && p.buckets[n.freq] == nil {
+		p.min = 0
+		for f := range p.buckets {
+			if p.min == 0 || f < p.min {
+				p.min = f
+			}
+		}
+	}
+}
+
+func (p *lfu) Keys() (keys []string) {
+	freqs := make([]int, 0, len(p.buckets))
+	for f := range p.buckets {
+		freqs = append(freqs, f)
+	}
+	slices.Sort(freqs)
+
+	for _, f := range slices.Backward(freqs) {
+		keys = listKeys(keys, p.buckets[f])
+	}
+
+	return
+}
+
+// bucket returns the bucket of the keys of frequency f, made if need be.
+func (p *lfu) bucket(f int) *list.List {
+	b, ok := p.buckets[f]
+	if !ok {
+		b = list.New()
+		p.buckets[f] = b
+	}
+
+	return b
+}
+
+// unlink removes n from its bucket, deleting the bucket once empty.
+func (p *lfu) unlink(n *lfuNode) {
+	b := p.buckets[n.freq]
+	b.Remove(n.el)
+	if b.Len() == 0 {
+		delete(p.buckets, n.freq)
+	}
+}
+
+// ARC returns the `EvictionPolicy` of the Adaptive Replacement Cache: it
+// keeps the entries used once apart from those used since, along with the
+// keys of those evicted lately of each, and shifts room between the two
+// as lookups of the keys evicted tell which of recency and frequency the
+// lookups favor, the way neither `LRU` nor `LFU` does alone.
+func ARC() EvictionPolicy {
+	return &arc{t1: list.New(), t2: list.New(), b1: list.New(), b2: list.New(), elems: make(map[string]*list.Element)}
+}
+
+// arc holds the entries used once in t1 and those used since in t2, the
+// keys of the entries evicted of either in b1 and b2, and the share of
+// the c entries of the cache t1 is aimed at in p.
+type arc struct {
+	t1, t2, b1, b2 *list.List
+	elems          map[string]*list.Element
+	p, c           int
+}
+
+// arcKey is the value of the elements of arc, the list holding it along.
+type arcKey struct {
+	key  string
+	list *list.List
+}
+
+func (p *arc) Add(key string, full bool) (victim string) {
+	if full {
+		p.c = p.t1.Len() + p.t2.Len()
+	}
+
+	el, ghost := p.elems[key]
+	switch {
+	case ghost && el.Value.(*arcKey).list == p.b1:
+		p.p = min(p.p+max(p.b2.Len()/p.b1.Len(), 1), p.c)
+		if full {
+			victim = p.replace(false)
+		}
+		p.move(key, p.t2)
+	case ghost && el.Value.(*arcKey).list == p.b2:
+		p.p = max(p.p-max(p.b1.Len()/p.b2.Len(), 1), 0)
+		if full {
+			victim = p.replace(true)
+		}
+		p.move(key, p.t2)
+	default:
+		if full {
+			if p.t1.Len()+p.b1.Len() >= p.c {
+				if p.t1.Len() < p.c {
+					p.drop(p.b1)
+					victim = p.replace(false)
+				} else {
+					victim = p.drop(p.t1)
+				}
+			} else {
+				if p.t1.Len()+p.t2.Len()+p.b1.Len()+p.b2.Len() >= 2*p.c {
+					p.drop(p.b2)
+				}
+				victim = p.replace(false)
+			}
+		}
+		p.move(key, p.t1)
+	}
+
+	return
+}
+
+func (p *arc) Touch(key string) {
+	if el, ok := p.elems[key]; ok {
+		if l := el.Value.(*arcKey).list; l == p.t1 || l == p.t2 {
+			p.move(key, p.t2)
+		}
+	}
+}
+
+func (p *arc) Remove(key string) {
+	if el, ok := p.elems[key]; ok {
+		el.Value.(*arcKey).list.Remove(el)
+		delete(p.elems, key)
+	}
+}
+
+func (p *arc) Keys() []string {
+	var keys []string
+	for _, l := range []*list.List{p.t2, p.t1} {
+		for el := l.Front(); el != nil; el = el.Next() {
+			keys = append(keys, el.Value.(*arcKey).key)
+		}
+	}
+
+	return keys
+}
+
+// replace evicts the least recently used entry of t1, when it holds more
+// than its share, or of t2 otherwise, keeping its key in b1 or b2.
+func (p *arc) replace(inB2 bool) string {
+	from, to := p.t2, p.b2
+	if n := p.t1.Len(); n > 0 && (n > p.p || (inB2 && n == p.p) || p.t2.Len() == 0) {
+		from, to = p.t1, p.b1
+	}
+
+	key := from.Back().Value.(*arcKey).key
+	p.move(key, to)
+
+	return key
+}
+
+// move moves key to the front of l.
+func (p *arc) move(key string, l *list.List) {
+	p.Remove(key)
+	p.elems[key] = l.PushFront(&arcKey{key: key, list: l})
+}
+
+// drop forgets the least recently used key of l, returning it.
+func (p *arc) drop(l *list.List) string {
+	el := l.Back()
+	if el == nil {
+		return ""
+	}
+
+	key := el.Value.(*arcKey).key
+	p.Remove(key)
+
+	return key
+}
+
+// listKeys appends the keys of l to keys, front to back.
+func listKeys(keys []string, l *list.List) []string {
+	for el := l.Front(); el != nil; el = el.Next() {
+		keys = append(keys, el.Value.(string))
+	}
+
+	return keys
+}
@@ -0,0 +1,125 @@
+package binlookup
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	m := NewMemoryCacheWithPolicy(2, LRU())
+	m.Set(context.TODO(), "a", Entry{})
+	m.Set(context.TODO(), "b", Entry{})
+	m.Get(context.TODO(), "a")
+	m.Set(context.TODO(), "c", Entry{})
+
+	if _, ok, _ := m.Get(context.TODO(), "b"); ok {
+		t.Fatal("least recently used entry not evicted")
+	}
+	if keys := m.policy.Keys(); !slices.Equal(keys, []string{"c", "a"}) {
+		t.Fatalf("keys are %v", keys)
+	}
+}
+
+func TestLFU(t *testing.T) {
+	m := NewMemoryCacheWithPolicy(2, LFU())
+	m.Set(context.TODO(), "hot", Entry{})
+	for range 3 {
+		m.Get(context.TODO(), "hot")
+	}
+
+	// A scan of BINs looked up once doesn't push the frequent one out.
+	for i := range 10 {
+		m.Set(context.TODO(), strconv.Itoa(i), Entry{})
+	}
+
+	if _, ok, _ := m.Get(context.TODO(), "hot"); !ok {
+		t.Fatal("frequently used entry evicted")
+	}
+	if keys := m.policy.Keys(); !slices.Equal(keys, []string{"hot", "9"}) {
+		t.Fatalf("keys are %v", keys)
+	}
+
+	m.Delete(context.TODO(), "9")
+	m.Set(context.TODO(), "x", Entry{})
+	m.Set(context.TODO(), "y", Entry{})
+	if _, ok, _ := m.Get(context.TODO(), "hot"); !ok || m.Len() != 2 {
+		t.Fatal("frequently used entry evicted after a deletion")
+	}
+}
+
+func TestARC(t *testing.T) {
+	m := NewMemoryCacheWithPolicy(4, ARC())
+	for _, key := range []string{"a", "b"} {
+		m.Set(context.TODO(), key, Entry{})
+		m.Get(context.TODO(), key)
+	}
+
+	for i := range 20 {
+		m.Set(context.TODO(), strconv.Itoa(i), Entry{})
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if _, ok, _ := m.Get(context.TODO(), key); !ok {
+			t.Fatalf("entry %v used twice evicted by a scan", key)
+		}
+	}
+}
+
+func TestEvictionPolicies(t *testing.T) {
+	for name, policy := range map[string]func() EvictionPolicy{"LRU": LRU, "LFU": LFU, "ARC": ARC} {
+		t.Run(name, func(t *testing.T) {
+			const size = 16
+			m := NewMemoryCacheWithPolicy(size, policy())
+			r := rand.New(rand.NewPCG(1, 2))
+
+			for range 10000 {
+				key := strconv.Itoa(int(r.ExpFloat64() * 20))
+				switch r.IntN(10) {
+				case 0:
+					m.Delete(context.TODO(), key)
+				case 1, 2, 3:
+					m.Set(context.TODO(), key, Entry{})
+				default:
+					m.Get(context.TODO(), key)
+				}
+
+				if n := m.Len(); n > size {
+					t.Fatalf("holding %d entries, beyond %d", n, size)
+				}
+			}
+
+			keys := m.policy.Keys()
+			slices.Sort(keys)
+			var held []string
+			for k := range m.entries {
+				held = append(held, k)
+			}
+			slices.Sort(held)
+			if !slices.Equal(keys, held) {
+				t.Fatalf("policy keys %v\nentries %v", keys, held)
+			}
+		})
+	}
+}
+
+func BenchmarkEvictionPolicies(b *testing.B) {
+	for name, policy := range map[string]func() EvictionPolicy{"LRU": LRU, "LFU": LFU, "ARC": ARC} {
+		b.Run(name, func(b *testing.B) {
+			m := NewMemoryCacheWithPolicy(200, policy())
+			r := rand.New(rand.NewPCG(1, 2))
+			zipf := rand.NewZipf(r, 1.1, 1, 100000)
+
+			for range b.N {
+				key := strconv.FormatUint(zipf.Uint64(), 10)
+				if _, ok, _ := m.Get(context.TODO(), key); !ok {
+					m.Set(context.TODO(), key, Entry{})
+				}
+			}
+
+			b.ReportMetric(m.Stats().HitRate()*100, "%hit")
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Command cardform is the backend of a checkout page, putting together
+// what binlookup-go offers a card form: the scheme of the card told as
+// its first digits are typed, without any lookup, and the issuer looked
+// up once there are enough digits, through a cache shared by the
+// customers. The page it serves at / calls both from the browser.
+//
+//	go run ./examples/cardform -addr :8080
+//
+// Pages served from elsewhere, such as a frontend dev server, are allowed
+// to call it with -origin.
+package main
+
+import (
+	"embed"
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/server"
+)
+
+//go:embed index.html
+var page embed.FS
+
+// newHandler returns the handler of the backend, serving the page at /
+// and the lookups of l under /api/.
+func newHandler(l binlookup.Lookuper, origins []string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", server.New(l, server.WithCORS(origins...), server.WithTimeout(3*time.Second))))
+	mux.Handle("/", http.FileServer(http.FS(page)))
+
+	return mux
+}
+
+func main() {
+	var (
+		addr   = flag.String("addr", "localhost:8080", "address to listen on")
+		origin = flag.String("origin", "", "comma separated origins allowed to call the API besides the page itself")
+	)
+	flag.Parse()
+
+	c := binlookup.New(
+		binlookup.WithCache(binlookup.NewMemoryCache(10000)),
+		binlookup.WithRetries(2),
+	)
+	defer c.Close()
+
+	var origins []string
+	if *origin != "" {
+		origins = strings.Split(*origin, ",")
+	}
+
+	log.Printf("serving the card form on http://%v", *addr)
+	log.Fatal(http.ListenAndServe(*addr, newHandler(c, origins)))
+}
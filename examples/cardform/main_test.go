@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/binlookuptest"
+)
+
+func TestCardForm(t *testing.T) {
+	upstream := binlookuptest.NewServer()
+	defer upstream.Close()
+	upstream.Add("457173", binlookuptest.JyskeBank)
+
+	srv := httptest.NewServer(newHandler(upstream.Client(binlookup.WithCache(binlookup.NewMemoryCache(0))), []string{"https://shop.example"}))
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, body := get("/"); resp.StatusCode != http.StatusOK || !strings.Contains(body, "Card number") {
+		t.Fatalf("page answered with %d", resp.StatusCode)
+	}
+
+	if _, body := get("/api/scheme/45"); body != `{"scheme":"visa","name":"Visa"}` {
+		t.Fatalf("scheme answered with %s", body)
+	}
+
+	for range 2 {
+		resp, body := get("/api/45717360")
+
+		var b binlookup.BIN
+		if err := json.Unmarshal([]byte(body), &b); err != nil || resp.StatusCode != http.StatusOK || b.Bank.Name != "Jyske Bank" {
+			t.Fatalf("lookup answered with %d %s", resp.StatusCode, body)
+		}
+	}
+
+	if n := len(upstream.Requests()); n != 1 {
+		t.Fatalf("%d requests made upstream for 2 lookups of the same BIN, want 1", n)
+	}
+
+	if resp, _ := get("/api/52882300"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("lookup of an unknown BIN answered with %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,123 @@
+package binlookup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrCacheNotRangeable is returned exporting the cache of a `Client` when
+// it isn't a `CacheRanger`, or when it has none.
+var ErrCacheNotRangeable = errors.New("Cache Not Rangeable")
+
+// CacheRanger is implemented by the caches able to list their entries,
+// such as `MemoryCache`, for `Client.ExportCache` to export them.
+type CacheRanger interface {
+	// Range calls fn with each entry and its key, until fn returns
+	// false or ctx is done.
+	Range(ctx context.Context, fn func(key string, e Entry) bool) error
+}
+
+// exportedEntry is a line of the cache exports: an entry as encoded by
+// `MarshalEntry`, along with its key.
+type exportedEntry struct {
+	Key     string `json:"key"`
+	Version int    `json:"v"`
+	Entry
+}
+
+// ExportCache writes the entries of the cache of c to w in JSON Lines, for
+// `Client.ImportCache` to warm the cache of another environment with.
+// Every line is an entry as `MarshalEntry` encodes it, with its key as
+// "key":
+//
+//	{"key":"45717360","v":2,"bin":{"scheme":"visa",...},"expires":"2026-01-02T03:04:05Z"}
+//
+// Keys are the tokens of the BINs when the cache is tokenized, see
+// `WithCacheTokenizer`, hence only meaningful to the clients with the same
+// tokenizer. Expired entries are exported only when they hold a BIN, still
+// worth revalidating. The cache must be a `CacheRanger`.
+func (c *Client) ExportCache(ctx context.Context, w io.Writer) error {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	r, ok := c.cache.(CacheRanger)
+	if !ok {
+		return ErrCacheNotRangeable
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	now := time.Now()
+
+	var err error
+	rerr := r.Range(ctx, func(key string, e Entry) bool {
+		if e.BIN == nil && !e.Fresh(now) {
+			return true
+		}
+
+		err = enc.Encode(exportedEntry{key, EntryVersion, e})
+		return err == nil
+	})
+	if err != nil {
+		return fmt.Errorf("Exporting Failed: %w", err)
+	}
+	if rerr != nil {
+		return fmt.Errorf("Exporting Failed: %w", rerr)
+	}
+
+	return bw.Flush()
+}
+
+// ImportCache stores the entries read from r, in the format
+// `Client.ExportCache` writes, into the cache of c, migrating those of
+// older versions. It returns how many it stored; those expired without a
+// BIN are skipped. It does nothing without a cache.
+func (c *Client) ImportCache(ctx context.Context, r io.Reader) (n int, err error) {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if c.cache == nil {
+		return
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	now := time.Now()
+
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+
+		var k struct {
+			Key string `json:"key"`
+		}
+		if err = json.Unmarshal(sc.Bytes(), &k); err != nil {
+			return n, fmt.Errorf("Malformed Line %d: %w", line, err)
+		}
+		if k.Key == "" {
+			return n, fmt.Errorf("Line %d Missing Key", line)
+		}
+
+		var e Entry
+		if e, err = UnmarshalEntry(sc.Bytes()); err != nil {
+			return n, fmt.Errorf("Malformed Line %d: %w", line, err)
+		}
+
+		if e.BIN == nil && !e.Fresh(now) {
+			continue
+		}
+
+		if err = c.cache.Set(ctx, k.Key, e); err != nil {
+			return n, fmt.Errorf("Importing Line %d Failed: %w", line, err)
+		}
+		n++
+	}
+
+	return n, sc.Err()
+}
@@ -0,0 +1,56 @@
+package binlookup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientExportCache(t *testing.T) {
+	src := NewMemoryCache(0)
+	src.Set(context.TODO(), "45717360", Entry{BIN: &BIN{Scheme: SchemeVisa}, Expires: time.Now().Add(time.Hour), ETag: `"v1"`})
+	src.Set(context.TODO(), "52882301", Entry{BIN: &BIN{Scheme: SchemeMastercard}, Expires: time.Now().Add(-time.Hour)})
+	src.Set(context.TODO(), "00000000", Entry{NotFound: true, Expires: time.Now().Add(time.Hour)})
+	src.Set(context.TODO(), "11111111", Entry{NotFound: true, Expires: time.Now().Add(-time.Hour)})
+
+	var buf bytes.Buffer
+	if err := New(WithCache(src)).ExportCache(context.TODO(), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Fatalf("exported %d lines, want 3:\n%s", lines, buf.String())
+	}
+	if !strings.Contains(buf.String(), `{"key":"45717360","v":2,"bin":{`) {
+		t.Fatalf("unexpected export:\n%s", buf.String())
+	}
+
+	dst := NewMemoryCache(0)
+	if n, err := New(WithCache(dst)).ImportCache(context.TODO(), &buf); n != 3 || err != nil {
+		t.Fatalf("imported %d entries, %v", n, err)
+	}
+
+	if e, ok, _ := dst.Get(context.TODO(), "45717360"); !ok || e.BIN.Scheme != SchemeVisa || e.ETag != `"v1"` {
+		t.Fatalf("imported entry %+v, %v", e, ok)
+	}
+	if e, ok, _ := dst.Get(context.TODO(), "00000000"); !ok || !e.NotFound {
+		t.Fatalf("imported not found entry %+v, %v", e, ok)
+	}
+
+	if err := New().ExportCache(context.TODO(), &buf); !errors.Is(err, ErrCacheNotRangeable) {
+		t.Fatalf("export without a cache returned %v", err)
+	}
+}
+
+func TestClientImportCacheMalformed(t *testing.T) {
+	c := New(WithCache(NewMemoryCache(0)))
+
+	for _, in := range []string{`{"v":2,"bin":{}}`, `{"key":`, `{"key":"45717360","v":99}`} {
+		if _, err := c.ImportCache(context.TODO(), strings.NewReader(in)); err == nil {
+			t.Fatalf("importing %s succeeded", in)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package binlookup
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+)
+
+// WithExpvar makes the `Client` publish its counters through expvar, as a
+// map under name served at /debug/vars along with those of the runtime,
+// for visibility without running Prometheus nor depending on anything:
+//
+//	lookups       the lookups made, served out of the cache or not
+//	cache_hits    the lookups served out of the cache
+//	cache_misses  the lookups which weren't, expired entries included
+//	cache_incompatible
+//	              the entries missed for their version, see
+//	              `CacheStats.Incompatible`
+//	retries       the attempts retried
+//	not_found     the lookups of BINs not found
+//	errors        the lookups failing otherwise, by the status upstream
+//	              answered with, "network" when it didn't, "other" for
+//	              the failures of no status such as validation
+//
+// The clients published under the same name add up to the same counters,
+// since expvar doesn't allow a name to be published twice.
+func WithExpvar(name string) Option {
+	return func(c *Client) {
+		vars, ok := expvar.Get(name).(*expvar.Map)
+		if !ok {
+			vars = expvar.NewMap(name)
+		}
+		c.vars = vars
+	}
+}
+
+// count adds one to the counter key published by c, if any.
+func (c *Client) count(key string) {
+	if c.vars != nil {
+		c.vars.Add(key, 1)
+	}
+}
+
+// countError adds the lookup failed with err to the counters published by
+// c, if any.
+func (c *Client) countError(err error) {
+	if c.vars == nil || err == nil {
+		return
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		c.vars.Add("not_found", 1)
+		return
+	}
+
+	errs, ok := c.vars.Get("errors").(*expvar.Map)
+	if !ok {
+		errs = new(expvar.Map)
+		c.vars.Set("errors", errs)
+	}
+
+	var (
+		he        *HTTPError
+		malformed *MalformedError
+	)
+	switch {
+	case errors.As(err, &he):
+		errs.Add(strconv.Itoa(he.StatusCode), 1)
+	case errors.Is(err, ErrInvalidBIN), errors.Is(err, ErrClosed), errors.Is(err, ErrNetworkDisabled),
+		errors.Is(err, ErrQueueFull), errors.Is(err, ErrDryRun), errors.Is(err, ErrIncompleteData), errors.As(err, &malformed):
+		errs.Add("other", 1)
+	default:
+		errs.Add("network", 1)
+	}
+}
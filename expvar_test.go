@@ -0,0 +1,48 @@
+package binlookup
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"testing"
+)
+
+func TestClientWithExpvar(t *testing.T) {
+	status := http.StatusOK
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return canned(status, cannedBIN)(nil).Do(req)
+		})
+	}
+
+	c := New(WithExpvar("binlookup_test"), WithCache(NewMemoryCache(0)), WithMiddleware(upstream), WithRetries(1), WithBackoff(ExponentialBackoff{}))
+
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+
+	status = http.StatusServiceUnavailable
+	c.Search(context.TODO(), "52882301")
+
+	status = http.StatusNotFound
+	c.Search(context.TODO(), "41111111")
+	c.Search(context.TODO(), IncorrectBIN)
+
+	vars := expvar.Get("binlookup_test").(*expvar.Map)
+	want := map[string]string{
+		"lookups":      "5",
+		"cache_hits":   "1",
+		"cache_misses": "3",
+		"retries":      "1",
+		"not_found":    "1",
+		"errors":       `{"503": 1, "other": 1}`,
+	}
+	for k, v := range want {
+		if got := vars.Get(k); got == nil || got.String() != v {
+			t.Errorf("%v is %v, want %v", k, got, v)
+		}
+	}
+
+	if New(WithExpvar("binlookup_test")); vars.Get("lookups").String() != "5" {
+		t.Fatal("counters reset publishing them again")
+	}
+}
@@ -0,0 +1,84 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+const extraBIN = `{"scheme":"visa","type":"debit","country":{"alpha2":"DK","name":"Denmark"},"bank":{"name":"Jyske Bank"},"issuer_tier":"gold"}`
+
+func TestDecodeFields(t *testing.T) {
+	b, err := DecodeFields([]byte(extraBIN), FieldScheme|FieldCountry)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != SchemeVisa || b.Country.Short != "DK" || b.Country.Alpha3 != "DNK" {
+		t.Fatalf("selected fields decoded as %+v", b)
+	}
+
+	if b.Type != "" || b.Bank != nil || b.Extra != nil {
+		t.Fatalf("fields not selected decoded as %+v", b)
+	}
+
+	b, err = DecodeFields([]byte(extraBIN), FieldBank|FieldExtra)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != "" || b.Bank == nil || b.Bank.Name != "Jyske Bank" || string(b.Extra["issuer_tier"]) != `"gold"` {
+		t.Fatalf("bank and extra decoded as %+v", b)
+	}
+
+	if _, err := DecodeFields([]byte(`{"scheme":`), FieldScheme); err == nil {
+		t.Fatal("decoding malformed JSON succeeded")
+	}
+}
+
+func TestDecodeFieldsAll(t *testing.T) {
+	b, err := DecodeFields([]byte(extraBIN), AllFields)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	var want BIN
+	if err := json.Unmarshal([]byte(extraBIN), &want); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != want.Scheme || b.Type != want.Type || b.Bank.Name != want.Bank.Name || len(b.Extra) != len(want.Extra) {
+		t.Fatalf("all fields decoded as %+v, want %+v", b, want)
+	}
+}
+
+func TestClientWithFields(t *testing.T) {
+	c := New(WithMiddleware(canned(200, cannedBIN)), WithFields(FieldScheme), WithCompletenessCheck())
+
+	b, err := c.Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != SchemeVisa || b.Bank != nil || b.Country.Short != "" {
+		t.Fatalf("lookup returned %+v", b)
+	}
+}
+
+func BenchmarkDecodeFields(b *testing.B) {
+	data := []byte(cannedBIN)
+
+	b.Run("All", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DecodeFields(data, AllFields)
+		}
+	})
+
+	b.Run("Scheme", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DecodeFields(data, FieldScheme)
+		}
+	})
+}
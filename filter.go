@@ -0,0 +1,100 @@
+package binlookup
+
+import (
+	"slices"
+	"strings"
+)
+
+// EUCountries are the alpha-2 codes of the member states of the European
+// Union, for `Filter` to select the cards issued in or out of it.
+var EUCountries = []string{
+	"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU",
+	"IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK",
+}
+
+// Filter selects BINs among the results of a batch, see `Select`. Each of
+// its fields left empty selects any BIN; those set select the BINs
+// matching them all, such as the prepaid cards issued out of the EU:
+//
+//	prepaid := true
+//	f := binlookup.Filter{Prepaid: &prepaid, NotCountries: binlookup.EUCountries}
+//
+// BINs the field a filter is set on is unknown for aren't selected by it.
+type Filter struct {
+	// Countries are the alpha-2 codes the country of a BIN is to be one
+	// of, and NotCountries those it's not to be, regardless of case.
+	Countries    []string
+	NotCountries []string
+
+	Schemes []Scheme
+	Types   []CardType
+
+	// Prepaid selects the BINs known to be prepaid when it points to
+	// true, and those known not to be when it points to false.
+	Prepaid *bool
+}
+
+// Match reports whether f selects b, nil never being selected.
+func (f Filter) Match(b *BIN) bool {
+	if b == nil {
+		return false
+	}
+
+	country := b.Country.Short
+	if len(f.Countries) > 0 && !containsFold(f.Countries, country) {
+		return false
+	}
+	if len(f.NotCountries) > 0 && (country == "" || containsFold(f.NotCountries, country)) {
+		return false
+	}
+
+	if len(f.Schemes) > 0 && !slices.Contains(f.Schemes, b.Scheme) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, b.Type) {
+		return false
+	}
+
+	if f.Prepaid != nil {
+		if prepaid, known := b.IsPrepaid(); !known || prepaid != *f.Prepaid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Select returns the indices of the BINs of bins f selects, in order,
+// the nil ones being those unresolved.
+func Select(bins []*BIN, f Filter) (indices []int) {
+	for i, b := range bins {
+		if f.Match(b) {
+			indices = append(indices, i)
+		}
+	}
+
+	return
+}
+
+// SelectResults returns the indices of the results f selects the BIN of,
+// in order, such as those of `Client.SearchBatch`, the failed ones never
+// being selected.
+func SelectResults(results []Result, f Filter) (indices []int) {
+	for i, r := range results {
+		if r.Err == nil && f.Match(r.BIN) {
+			indices = append(indices, i)
+		}
+	}
+
+	return
+}
+
+func containsFold(codes []string, code string) bool {
+	for _, c := range codes {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+
+	return false
+}
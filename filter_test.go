@@ -0,0 +1,43 @@
+package binlookup
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	prepaid, notPrepaid := true, false
+	bins := []*BIN{
+		{Scheme: SchemeVisa, Type: TypeDebit, Prepaid: &notPrepaid, Country: Country{Short: "DK"}},
+		{Scheme: SchemeVisa, Type: TypeCredit, Prepaid: &prepaid, Country: Country{Short: "US"}},
+		nil,
+		{Scheme: SchemeMastercard, Prepaid: &prepaid, Country: Country{Short: "DE"}},
+		{Scheme: SchemeMastercard, Country: Country{Short: "GB"}},
+		{Scheme: SchemeVisa, Prepaid: &prepaid},
+	}
+
+	for _, tc := range []struct {
+		name string
+		f    Filter
+		want []int
+	}{
+		{"All", Filter{}, []int{0, 1, 3, 4, 5}},
+		{"PrepaidOutOfEU", Filter{Prepaid: &prepaid, NotCountries: EUCountries}, []int{1}},
+		{"NotPrepaid", Filter{Prepaid: &notPrepaid}, []int{0}},
+		{"Countries", Filter{Countries: []string{"dk", "gb"}}, []int{0, 4}},
+		{"Scheme", Filter{Schemes: []Scheme{SchemeMastercard}}, []int{3, 4}},
+		{"Type", Filter{Types: []CardType{TypeCredit}, Schemes: []Scheme{SchemeVisa}}, []int{1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Select(bins, tc.f); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("selected %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	results := []Result{{BIN: bins[0]}, {BIN: bins[1], Err: errors.New("failed")}, {BIN: bins[1]}}
+	if got := SelectResults(results, Filter{Schemes: []Scheme{SchemeVisa}}); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatalf("selected results %v, want [0 2]", got)
+	}
+}
@@ -0,0 +1,181 @@
+// Package fixtures provides sample payloads of upstream, in the shape
+// binlist answers with, for each major scheme and card type and for the
+// edge cases of its data, so that the tests of the code using binlookup
+// share consistent data rather than each inventing its own. Every
+// `Fixture` is usable both as raw JSON and as a `binlookup.BIN`:
+//
+//	srv := binlookuptest.NewServer()
+//	defer srv.Close()
+//
+//	for _, f := range fixtures.All() {
+//		srv.Add(f.BIN, f.JSON)
+//	}
+//
+//	want := fixtures.VisaDebit.Value()
+//
+// The data is sample data: neither authoritative about the issuers named
+// nor complete about their BINs.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Fixture is a sample payload of upstream for a BIN.
+type Fixture struct {
+	// Name tells the fixture apart, such as visa-debit.
+	Name string
+
+	// BIN is the BIN the payload is the answer for.
+	BIN string
+
+	// JSON is the payload as upstream sends it.
+	JSON string
+}
+
+// Value returns the payload of f decoded, a new `binlookup.BIN` each call
+// for tests to change freely. It panics when the payload doesn't decode,
+// which the tests of the package rule out.
+func (f Fixture) Value() *binlookup.BIN {
+	var b binlookup.BIN
+	if err := json.Unmarshal([]byte(f.JSON), &b); err != nil {
+		panic(fmt.Sprintf("fixtures: Decoding %v Failed: %v", f.Name, err))
+	}
+
+	return &b
+}
+
+// Fixtures of the major schemes and card types.
+var (
+	// VisaDebit is the answer for 45717360, the example of binlist.
+	VisaDebit = Fixture{
+		Name: "visa-debit",
+		BIN:  "45717360",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"debit","brand":"Visa/Dankort","prepaid":false,"country":{"numeric":"208","alpha2":"DK","name":"Denmark","emoji":"🇩🇰","currency":"DKK","latitude":56,"longitude":10},"bank":{"name":"Jyske Bank","url":"www.jyskebank.dk","phone":"+4589893300","city":"Hjørring"}}`,
+	}
+
+	VisaCredit = Fixture{
+		Name: "visa-credit",
+		BIN:  "41472000",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"credit","brand":"Visa Signature","prepaid":false,"country":{"numeric":"840","alpha2":"US","name":"United States of America","emoji":"🇺🇸","currency":"USD","latitude":38,"longitude":-97},"bank":{"name":"Chase","url":"www.chase.com","phone":"+18009359935","city":"New York"}}`,
+	}
+
+	VisaPrepaid = Fixture{
+		Name: "visa-prepaid",
+		BIN:  "43218700",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"debit","brand":"Visa Prepaid","prepaid":true,"country":{"numeric":"826","alpha2":"GB","name":"United Kingdom of Great Britain and Northern Ireland","emoji":"🇬🇧","currency":"GBP","latitude":54,"longitude":-2},"bank":{"name":"Prepaid Financial Services","city":"London"}}`,
+	}
+
+	MastercardDebit = Fixture{
+		Name: "mastercard-debit",
+		BIN:  "53750500",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"mastercard","type":"debit","brand":"Debit Mastercard","prepaid":false,"country":{"numeric":"276","alpha2":"DE","name":"Germany","emoji":"🇩🇪","currency":"EUR","latitude":51,"longitude":9},"bank":{"name":"Deutsche Bank","url":"www.deutsche-bank.de","phone":"+49691000","city":"Frankfurt am Main"}}`,
+	}
+
+	MastercardCredit = Fixture{
+		Name: "mastercard-credit",
+		BIN:  "54512300",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"mastercard","type":"credit","brand":"World Elite","prepaid":false,"country":{"numeric":"124","alpha2":"CA","name":"Canada","emoji":"🇨🇦","currency":"CAD","latitude":60,"longitude":-95},"bank":{"name":"Royal Bank of Canada","url":"www.rbc.com","phone":"+18007692511","city":"Toronto"}}`,
+	}
+
+	AmexCredit = Fixture{
+		Name: "amex-credit",
+		BIN:  "37828200",
+		JSON: `{"number":{"length":15,"luhn":true},"scheme":"amex","type":"credit","brand":"Gold","prepaid":false,"country":{"numeric":"840","alpha2":"US","name":"United States of America","emoji":"🇺🇸","currency":"USD","latitude":38,"longitude":-97},"bank":{"name":"American Express","url":"www.americanexpress.com","phone":"+18005284800"}}`,
+	}
+
+	DiscoverCredit = Fixture{
+		Name: "discover-credit",
+		BIN:  "60110000",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"discover","type":"credit","brand":"Discover It","prepaid":false,"country":{"numeric":"840","alpha2":"US","name":"United States of America","emoji":"🇺🇸","currency":"USD","latitude":38,"longitude":-97},"bank":{"name":"Discover Bank","url":"www.discover.com","phone":"+18003472683"}}`,
+	}
+
+	JCBCredit = Fixture{
+		Name: "jcb-credit",
+		BIN:  "35300000",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"jcb","type":"credit","brand":"JCB Standard","prepaid":false,"country":{"numeric":"392","alpha2":"JP","name":"Japan","emoji":"🇯🇵","currency":"JPY","latitude":36,"longitude":138},"bank":{"name":"JCB Co., Ltd.","url":"www.jcb.co.jp","city":"Tokyo"}}`,
+	}
+
+	UnionPayDebit = Fixture{
+		Name: "unionpay-debit",
+		BIN:  "62220200",
+		JSON: `{"number":{"length":19,"luhn":true},"scheme":"unionpay","type":"debit","brand":"UnionPay Classic","prepaid":false,"country":{"numeric":"156","alpha2":"CN","name":"China","emoji":"🇨🇳","currency":"CNY","latitude":35,"longitude":105},"bank":{"name":"Industrial and Commercial Bank of China","url":"www.icbc.com.cn","phone":"+8695588","city":"Beijing"}}`,
+	}
+
+	MaestroDebit = Fixture{
+		Name: "maestro-debit",
+		BIN:  "67595000",
+		JSON: `{"number":{"luhn":true},"scheme":"maestro","type":"debit","brand":"Maestro","prepaid":false,"country":{"numeric":"528","alpha2":"NL","name":"Netherlands","emoji":"🇳🇱","currency":"EUR","latitude":52.5,"longitude":5.75},"bank":{"name":"ING Bank","url":"www.ing.nl","city":"Amsterdam"}}`,
+	}
+)
+
+// Fixtures of the edge cases of the data of upstream.
+var (
+	// MissingBank has no bank field, as many BINs of smaller issuers.
+	MissingBank = Fixture{
+		Name: "missing-bank",
+		BIN:  "51234500",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"mastercard","type":"credit","brand":"Standard","prepaid":false,"country":{"numeric":"076","alpha2":"BR","name":"Brazil","emoji":"🇧🇷","currency":"BRL","latitude":-10,"longitude":-55}}`,
+	}
+
+	// NullPrepaid has a prepaid of null, upstream not knowing.
+	NullPrepaid = Fixture{
+		Name: "null-prepaid",
+		BIN:  "40000300",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"visa","type":"credit","brand":"Traditional","prepaid":null,"country":{"numeric":"036","alpha2":"AU","name":"Australia","emoji":"🇦🇺","currency":"AUD","latitude":-27,"longitude":133},"bank":{"name":"Commonwealth Bank of Australia","url":"www.commbank.com.au","city":"Sydney"}}`,
+	}
+
+	// EmptyCountry has a country of {}, upstream not knowing.
+	EmptyCountry = Fixture{
+		Name: "empty-country",
+		BIN:  "53990000",
+		JSON: `{"number":{"length":16,"luhn":true},"scheme":"mastercard","type":"debit","brand":"Debit Mastercard","country":{},"bank":{"name":"Payoneer"}}`,
+	}
+
+	// Sparse has nothing but the scheme and type, the number, country
+	// and bank all {}, as upstream answers for the BINs it barely knows.
+	Sparse = Fixture{
+		Name: "sparse",
+		BIN:  "52882300",
+		JSON: `{"number":{},"scheme":"mastercard","type":"debit","country":{},"bank":{}}`,
+	}
+)
+
+// all are the fixtures, in the order `All` returns them.
+var all = []Fixture{
+	VisaDebit, VisaCredit, VisaPrepaid,
+	MastercardDebit, MastercardCredit,
+	AmexCredit, DiscoverCredit, JCBCredit, UnionPayDebit, MaestroDebit,
+	MissingBank, NullPrepaid, EmptyCountry, Sparse,
+}
+
+// All returns every fixture, those of the schemes first and those of the
+// edge cases last.
+func All() []Fixture {
+	return append([]Fixture(nil), all...)
+}
+
+// ByName returns the fixture named name, with false when there is none.
+func ByName(name string) (Fixture, bool) {
+	for _, f := range all {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	return Fixture{}, false
+}
+
+// ByScheme returns the fixtures of scheme, in the order of `All`.
+func ByScheme(scheme binlookup.Scheme) (fs []Fixture) {
+	for _, f := range all {
+		if f.Value().Scheme == scheme {
+			fs = append(fs, f)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,79 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestFixtures(t *testing.T) {
+	names := make(map[string]bool)
+
+	for _, f := range All() {
+		if names[f.Name] {
+			t.Errorf("%v named twice", f.Name)
+		}
+		names[f.Name] = true
+
+		b := f.Value()
+		if scheme, _ := binlookup.DetectScheme(f.BIN); scheme != b.Scheme {
+			t.Errorf("%v: BIN %v is of %v, payload of %v", f.Name, f.BIN, scheme, b.Scheme)
+		}
+		if err := binlookup.ValidateBIN(f.BIN); err != nil {
+			t.Errorf("%v: %v", f.Name, err)
+		}
+
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("%v: %+v", f.Name, err)
+		}
+		var got binlookup.BIN
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%v: %+v", f.Name, err)
+		}
+		if !reflect.DeepEqual(&got, b) {
+			t.Errorf("%v encodes to %s, which decodes to %+v, want %+v", f.Name, data, got, b)
+		}
+	}
+}
+
+func TestFixturesEdgeCases(t *testing.T) {
+	if b := MissingBank.Value(); b.Bank != nil {
+		t.Errorf("MissingBank has bank %+v", b.Bank)
+	}
+	if _, known := NullPrepaid.Value().IsPrepaid(); known {
+		t.Error("NullPrepaid is known to be prepaid or not")
+	}
+	if prepaid, _ := VisaPrepaid.Value().IsPrepaid(); !prepaid {
+		t.Error("VisaPrepaid isn't prepaid")
+	}
+	if c := EmptyCountry.Value().Country; c != (binlookup.Country{}) {
+		t.Errorf("EmptyCountry has country %+v", c)
+	}
+}
+
+func TestFixtureValueIsFresh(t *testing.T) {
+	VisaDebit.Value().Bank.Name = "Changed"
+
+	if name := VisaDebit.Value().Bank.Name; name != "Jyske Bank" {
+		t.Fatalf("bank is %q after changing another value, want Jyske Bank", name)
+	}
+}
+
+func TestByName(t *testing.T) {
+	if f, ok := ByName("amex-credit"); !ok || f.BIN != AmexCredit.BIN {
+		t.Fatalf("ByName(amex-credit) = %+v, %v", f, ok)
+	}
+	if _, ok := ByName("unknown"); ok {
+		t.Fatal("ByName(unknown) found a fixture")
+	}
+}
+
+func TestByScheme(t *testing.T) {
+	fs := ByScheme(binlookup.SchemeVisa)
+	if len(fs) != 4 || fs[0].Name != VisaDebit.Name {
+		t.Fatalf("ByScheme(visa) = %+v", fs)
+	}
+}
@@ -0,0 +1,48 @@
+package binlookup
+
+import "context"
+
+// Future is the pending result of a lookup started by
+// `Client.SearchAsync`. It's safe for concurrent use.
+type Future struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	b   *BIN
+	err error
+}
+
+// SearchAsync starts the lookup of bin under ctx and returns at once, so
+// that a lookup started early, such as once a card number is typed, is
+// answered by the time its result is needed. The lookup is canceled along
+// with ctx or by `Future.Cancel`.
+func (c *Client) SearchAsync(ctx context.Context, bin string) *Future {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future{done: make(chan struct{}), cancel: cancel}
+
+	go func() {
+		defer close(f.done)
+		defer cancel()
+
+		f.b, f.err = c.Search(ctx, bin)
+	}()
+
+	return f
+}
+
+// Done returns a channel closed once the lookup of f is over.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the lookup of f to be over and returns its result.
+func (f *Future) Wait() (*BIN, error) {
+	<-f.done
+	return f.b, f.err
+}
+
+// Cancel cancels the lookup of f, unless it's over already, for it to
+// fail with context.Canceled.
+func (f *Future) Cancel() {
+	f.cancel()
+}
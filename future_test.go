@@ -0,0 +1,50 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientSearchAsync(t *testing.T) {
+	release := make(chan struct{})
+	held := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			<-release
+			return next.Do(req)
+		})
+	}
+
+	c := New(WithMiddleware(held, canned(http.StatusOK, cannedBIN)))
+	f := c.SearchAsync(context.TODO(), CorrectBIN)
+
+	select {
+	case <-f.Done():
+		t.Fatal("future resolved before the lookup was answered")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if b, err := f.Wait(); err != nil || b.Scheme != SchemeVisa {
+		t.Fatalf("future resolved to %+v, %v", b, err)
+	}
+	<-f.Done()
+}
+
+func TestFutureCancel(t *testing.T) {
+	c := New(WithMiddleware(func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+	}))
+
+	f := c.SearchAsync(context.TODO(), CorrectBIN)
+	f.Cancel()
+
+	if _, err := f.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled future resolved to %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge returns a `Provider` looking BINs up through primary, and through
+// each of secondaries in turn as soon as the ones looking up already
+// failed or took longer than after to answer, answering as whichever
+// resolves the BIN first. The lookups made are canceled once one of them
+// succeeds.
+//
+// This tames the latency spikes of a provider at the cost of the extra
+// lookups made while it's slow, such as on the path of a checkout:
+//
+//	p := binlookup.Hedge(client, 300*time.Millisecond, mirror)
+//
+// BINs are truncated to 6 digits for the providers lacking EightDigit, as
+// in `Chain`. A malformed BIN is given up on at once. When all of the
+// lookups fail, the error of primary is returned.
+func Hedge(primary Provider, after time.Duration, secondaries ...Provider) Provider {
+	return hedge{ps: append([]Provider{primary}, secondaries...), after: after}
+}
+
+type hedge struct {
+	ps    []Provider
+	after time.Duration
+}
+
+type hedged struct {
+	i   int
+	b   *BIN
+	err error
+}
+
+func (h hedge) Search(ctx context.Context, bin string) (*BIN, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	answers := make(chan hedged, len(h.ps))
+	start := func(i int) {
+		go func() {
+			b, err := h.ps[i].Search(ctx, truncate(h.ps[i], bin))
+			answers <- hedged{i, b, err}
+		}()
+	}
+
+	t := time.NewTimer(h.after)
+	defer t.Stop()
+
+	start(0)
+	started, pending := 1, 1
+
+	errs := make([]error, len(h.ps))
+	for pending > 0 {
+		select {
+		case a := <-answers:
+			pending--
+			if a.err == nil {
+				return a.b, nil
+			}
+			if errors.Is(a.err, ErrInvalidBIN) {
+				return nil, a.err
+			}
+			errs[a.i] = a.err
+
+			if started < len(h.ps) {
+				start(started)
+				started++
+				pending++
+				t.Reset(h.after)
+			}
+		case <-t.C:
+			if started < len(h.ps) {
+				start(started)
+				started++
+				pending++
+				t.Reset(h.after)
+			}
+		}
+	}
+
+	return nil, errs[0]
+}
+
+// Capabilities of a hedge are combined as those of a `Chain` are.
+func (h hedge) Capabilities() Capabilities {
+	return chain(h.ps).Capabilities()
+}
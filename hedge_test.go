@@ -0,0 +1,64 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// delayed answers after d with b or err, or with the error of the context
+// when it's done first.
+type delayed struct {
+	d   time.Duration
+	b   *BIN
+	err error
+}
+
+func (p delayed) Search(ctx context.Context, bin string) (*BIN, error) {
+	select {
+	case <-time.After(p.d):
+		return p.b, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p delayed) Capabilities() Capabilities {
+	return Capabilities{EightDigit: true}
+}
+
+func TestHedge(t *testing.T) {
+	primary := delayed{d: time.Second, b: &BIN{Scheme: SchemeVisa}}
+	secondary := delayed{d: 10 * time.Millisecond, b: &BIN{Scheme: SchemeMastercard}}
+
+	start := time.Now()
+	b, err := Hedge(primary, 20*time.Millisecond, secondary).Search(context.TODO(), CorrectBIN)
+	if err != nil || b.Scheme != SchemeMastercard {
+		t.Fatalf("hedge answered with %+v, %v, want the secondary's", b, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("hedge waited %v for the slow primary", elapsed)
+	}
+
+	// A primary answering in time isn't hedged.
+	fast := delayed{b: &BIN{Scheme: SchemeVisa}}
+	if b, _ := Hedge(fast, 20*time.Millisecond, secondary).Search(context.TODO(), CorrectBIN); b.Scheme != SchemeVisa {
+		t.Fatalf("hedge answered with %+v, want the primary's", b)
+	}
+}
+
+func TestHedgeFailures(t *testing.T) {
+	primary := &stubProvider{err: ErrNotFound}
+	secondary := delayed{err: ErrRateLimited}
+
+	if _, err := Hedge(primary, time.Hour, secondary).Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("hedge returned %v, want the error of the primary", err)
+	}
+
+	resolving := &stubProvider{b: &BIN{}}
+	if _, err := Hedge(&stubProvider{err: ErrInvalidBIN}, time.Hour, resolving).Search(context.TODO(), IncorrectBIN); !errors.Is(err, ErrInvalidBIN) || len(resolving.bins) != 0 {
+		t.Fatalf("hedge returned %v after an invalid BIN, asking the secondary for %v", err, resolving.bins)
+	}
+}
@@ -0,0 +1,98 @@
+package binlookup
+
+import (
+	"strings"
+	"time"
+)
+
+// Hooks are the callbacks a `Client` calls along its lookups, for metrics,
+// logs and alerts without wrapping it in middleware. Any of them may be
+// nil. They are called on the goroutine of the lookup, which they hold up
+// until they return.
+type Hooks struct {
+	// OnCacheHit is called for the lookups served out of the cache,
+	// Err set when it's a cached not found or error.
+	OnCacheHit func(e Event)
+
+	// OnRequest is called before each attempt to upstream.
+	OnRequest func(e Event)
+
+	// OnResponse is called for each attempt upstream answered, with
+	// StatusCode and Duration.
+	OnResponse func(e Event)
+
+	// OnRetry is called before waiting Delay to retry the attempt which
+	// failed with Err.
+	OnRetry func(e Event)
+
+	// OnFailover is called when a lookup of 8 digits not found fails over
+	// to its 6 digits, see `EightDigitsFallback`.
+	OnFailover func(e Event)
+
+	// OnError is called for the lookups failing, unless for the BIN not
+	// being found.
+	OnError func(e Event)
+}
+
+// Event describes what a `Hooks` callback is called for. Its BIN is masked
+// and so is Err, whose message would otherwise quote the BIN.
+type Event struct {
+	BIN string
+
+	// Attempt is the attempt of the lookup the event is about, from 0.
+	Attempt int
+
+	// StatusCode is the status upstream answered the attempt with.
+	StatusCode int
+
+	// Duration is how long the attempt took.
+	Duration time.Duration
+
+	// Delay is how long the lookup waits before its next attempt.
+	Delay time.Duration
+
+	Err error
+}
+
+// WithHooks makes the `Client` call h along its lookups.
+func WithHooks(h Hooks) Option {
+	return func(c *Client) {
+		c.hooks = h
+	}
+}
+
+// hook calls fn, when set, with e of the lookup of bin, masking bin.
+func hook(fn func(Event), bin string, e Event) {
+	if fn == nil {
+		return
+	}
+
+	e.BIN = maskBIN(bin)
+	if e.Err != nil {
+		e.Err = &maskedError{err: e.Err, bin: bin}
+	}
+
+	fn(e)
+}
+
+// maskedError is an error whose message has the BIN it quotes masked, as
+// well as the 8 and 6 digit prefixes the BIN may have been looked up as.
+type maskedError struct {
+	err error
+	bin string
+}
+
+func (e *maskedError) Error() string {
+	msg := e.err.Error()
+	for _, n := range []int{len(e.bin), 8, 6} {
+		if n <= len(e.bin) && n > 4 {
+			msg = strings.ReplaceAll(msg, e.bin[:n], maskBIN(e.bin[:n]))
+		}
+	}
+
+	return Redact(msg)
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
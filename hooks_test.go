@@ -0,0 +1,72 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientWithHooks(t *testing.T) {
+	var events []string
+	record := func(kind string) func(Event) {
+		return func(e Event) {
+			if strings.Contains(e.BIN, "7173") || (e.Err != nil && strings.Contains(e.Err.Error(), "7173")) {
+				t.Errorf("%v event isn't masked: %+v", kind, e)
+			}
+			events = append(events, kind+":"+http.StatusText(e.StatusCode))
+		}
+	}
+	hooks := Hooks{
+		OnCacheHit: record("hit"),
+		OnRequest:  record("request"),
+		OnResponse: record("response"),
+		OnRetry:    record("retry"),
+		OnFailover: record("failover"),
+		OnError:    record("error"),
+	}
+
+	var requested []string
+	c := New(
+		WithHooks(hooks),
+		WithCache(NewMemoryCache(0)),
+		WithBINDigits(EightDigitsFallback),
+		WithRetries(1),
+		WithBackoff(ConstantBackoff(time.Millisecond)),
+		WithMiddleware(paths(&requested, "457173")),
+	)
+
+	c.Search(context.TODO(), "45717360")
+	c.Search(context.TODO(), "45717360")
+
+	want := "request:,response:Not Found,failover:,request:,response:OK,hit:"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+
+	events = nil
+	c = New(WithHooks(hooks), WithRetries(1), WithBackoff(ConstantBackoff(time.Millisecond)), WithMiddleware(canned(http.StatusBadGateway, "")))
+
+	if _, err := c.Search(context.TODO(), "45717360"); err == nil {
+		t.Fatal("lookup failing upstream succeeded")
+	}
+
+	want = "request:,response:Bad Gateway,retry:,request:,response:Bad Gateway,error:"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+}
+
+func TestMaskedError(t *testing.T) {
+	err := &maskedError{err: &HTTPError{StatusCode: http.StatusNotFound}, bin: "4571736012"}
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("masked error doesn't unwrap")
+	}
+
+	err = &maskedError{err: errors.New("GET /45717360 and /457173 failed"), bin: "4571736012"}
+	if msg := err.Error(); strings.Contains(msg, "7173") {
+		t.Fatalf("BIN isn't masked in %q", msg)
+	}
+}
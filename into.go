@@ -0,0 +1,49 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SearchInto looks bin up as `Client.Search` does, and decodes the payload
+// of its BIN into dest, a pointer as json.Unmarshal takes, for the types
+// of the caller modeling the fields of a provider `BIN` doesn't. The
+// payload is the BIN encoded back, the fields of Extra included, so the
+// lookup goes through the cache, retries and validation all the same;
+// the fields the BIN normalizes, such as the country completed out of its
+// alpha-2 code or translated, see `WithLocale`, are decoded normalized.
+func (c *Client) SearchInto(ctx context.Context, bin string, dest any) error {
+	b, err := c.Search(ctx, bin)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("JSON Marshaling Failed: %w", err)
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("JSON Unmarshaling Failed: %w", err)
+	}
+
+	return nil
+}
+
+// SearchAs looks bin up through c into a new T, see `Client.SearchInto`:
+//
+//	type Issuer struct {
+//		Scheme   string `json:"scheme"`
+//		IssuerID string `json:"issuer_id"`
+//	}
+//
+//	issuer, err := binlookup.SearchAs[Issuer](ctx, c, bin)
+func SearchAs[T any](ctx context.Context, c *Client, bin string) (*T, error) {
+	v := new(T)
+	if err := c.SearchInto(ctx, bin, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,39 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSearchAs(t *testing.T) {
+	type issuer struct {
+		Scheme   string `json:"scheme"`
+		IssuerID string `json:"issuer_id"`
+		Country  struct {
+			Alpha2 string `json:"alpha2"`
+		} `json:"country"`
+	}
+
+	body := strings.Replace(cannedBIN, `{"number"`, `{"issuer_id":"JB-1","number"`, 1)
+	c := New(WithMiddleware(canned(http.StatusOK, body)))
+
+	v, err := SearchAs[issuer](context.TODO(), c, CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if v.Scheme != "visa" || v.IssuerID != "JB-1" || v.Country.Alpha2 != "DK" {
+		t.Fatalf("decoded %+v", v)
+	}
+
+	if err := c.SearchInto(context.TODO(), IncorrectBIN, new(issuer)); err == nil {
+		t.Fatal("lookup of an invalid BIN succeeded")
+	}
+
+	var n int
+	if err := c.SearchInto(context.TODO(), CorrectBIN, &n); err == nil {
+		t.Fatal("decoding a BIN into an int succeeded")
+	}
+}
@@ -0,0 +1,84 @@
+package binlookup
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// SearchAll looks bins up, `WithBatchConcurrency` of them at once, and
+// yields each BIN with its `Result` as soon as it's resolved, hence not
+// in the order of bins:
+//
+//	for bin, res := range c.SearchAll(ctx, bins) {
+//		if res.Err != nil {
+//			continue
+//		}
+//		...
+//	}
+//
+// Breaking out of the loop cancels the lookups in progress and returns
+// once they're over. Every BIN is yielded otherwise, those left once ctx
+// is done with its error.
+func (c *Client) SearchAll(ctx context.Context, bins []string) iter.Seq2[string, Result] {
+	return func(yield func(string, Result) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		stop := make(chan struct{})
+
+		type resolved struct {
+			bin string
+			r   Result
+		}
+		next, results := make(chan string), make(chan resolved)
+
+		var wg sync.WaitGroup
+		defer func() {
+			close(stop)
+			cancel()
+			wg.Wait()
+		}()
+
+		c.reconf.RLock()
+		n := max(c.batchConcurrency, 1)
+		c.reconf.RUnlock()
+
+		for range min(n, len(bins)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for bin := range next {
+					r := Result{Err: ctx.Err()}
+					if r.Err == nil {
+						r.BIN, r.Err = c.Search(ctx, bin)
+					}
+
+					select {
+					case results <- resolved{bin, r}:
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer close(next)
+
+			for _, bin := range bins {
+				select {
+				case next <- bin:
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		for range bins {
+			v := <-results
+			if !yield(v.bin, v.r) {
+				return
+			}
+		}
+	}
+}
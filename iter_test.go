@@ -0,0 +1,68 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientSearchAll(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	var got []string
+	for bin, res := range c.SearchAll(context.TODO(), []string{CorrectBIN, IncorrectBIN, "45717360"}) {
+		switch {
+		case bin == IncorrectBIN && errors.Is(res.Err, ErrInvalidBIN):
+		case bin != IncorrectBIN && res.Err == nil && res.BIN != nil:
+		default:
+			t.Fatalf("%v resolved as %+v", bin, res)
+		}
+		got = append(got, bin)
+	}
+
+	sort.Strings(got)
+	if strings.Join(got, " ") != "0812436 45717360 5288230" {
+		t.Fatalf("yielded %v", got)
+	}
+}
+
+func TestClientSearchAllBreak(t *testing.T) {
+	c := New(WithBatchConcurrency(2), WithMiddleware(slow(20*time.Millisecond), canned(http.StatusOK, cannedBIN)))
+
+	bins := []string{"45717360", "41111111", "52882301", "51000000", "40000000", "37000000"}
+
+	start := time.Now()
+	n := 0
+	for range c.SearchAll(context.TODO(), bins) {
+		if n++; n == 1 {
+			break
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("breaking out took %v, the lookups left weren't stopped", elapsed)
+	}
+}
+
+func TestClientSearchAllCanceled(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	n := 0
+	for _, res := range c.SearchAll(ctx, []string{"45717360", "41111111", "52882301"}) {
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Fatalf("lookup under a canceled context returned %+v", res)
+		}
+		n++
+	}
+
+	if n != 3 {
+		t.Fatalf("yielded %d of 3 BINs", n)
+	}
+}
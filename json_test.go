@@ -0,0 +1,87 @@
+package binlookup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestBINUnmarshalJSONExtra(t *testing.T) {
+	var b BIN
+	if err := json.Unmarshal([]byte(cannedBIN), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Extra != nil {
+		t.Fatalf("modeled fields were kept as extra: %v", b.Extra)
+	}
+
+	payload := `{"scheme":"visa","Brand":"Visa/Dankort","issuer_tier":"gold","tokens":{"apple_pay":true}}`
+	if err := json.Unmarshal([]byte(payload), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Scheme != SchemeVisa || b.Brand != "Visa/Dankort" {
+		t.Fatalf("unexpected BIN decoded: %+v", b)
+	}
+
+	if len(b.Extra) != 2 || string(b.Extra["issuer_tier"]) != `"gold"` || string(b.Extra["tokens"]) != `{"apple_pay":true}` {
+		t.Fatalf("extra fields are %v", b.Extra)
+	}
+}
+
+func TestBINMarshalJSON(t *testing.T) {
+	var b BIN
+	if err := json.Unmarshal([]byte(cannedBIN), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != cannedBIN {
+		t.Fatalf("BIN encoded as\n%s\nwant\n%s", data, cannedBIN)
+	}
+
+	b = BIN{Scheme: SchemeVisa, Extra: map[string]json.RawMessage{"issuer_tier": json.RawMessage(`"gold"`), "scheme": json.RawMessage(`"amex"`)}}
+	if data, _ = json.Marshal(b); string(data) != `{"scheme":"visa","country":{"latitude":0,"longitude":0},"issuer_tier":"gold"}` {
+		t.Fatalf("BIN with extra fields encoded as %s", data)
+	}
+}
+
+// countingDecoder counts the payloads decoded through it with
+// encoding/json.
+type countingDecoder struct{ calls int }
+
+func (d *countingDecoder) Unmarshal(data []byte, v any) error {
+	d.calls++
+	return json.Unmarshal(data, v)
+}
+
+func TestWithJSONDecoder(t *testing.T) {
+	d := new(countingDecoder)
+	c := New(WithMiddleware(canned(http.StatusOK, `{"scheme":"visa","country":{"alpha2":"DK"},"tier":"gold"}`)), WithJSONDecoder(d))
+
+	b, err := c.Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != SchemeVisa || b.Country.Alpha3 != "DNK" || string(b.Extra["tier"]) != `"gold"` {
+		t.Fatalf("decoded %+v", b)
+	}
+	if d.calls == 0 {
+		t.Fatal("decoder unused")
+	}
+}
+
+func TestWithJSONDecoderMalformed(t *testing.T) {
+	c := New(WithMiddleware(canned(http.StatusOK, `{"scheme":`)), WithJSONDecoder(new(countingDecoder)))
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("malformed payload decoded")
+	}
+}
@@ -0,0 +1,279 @@
+package binlookup
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeyRotation is how a `Client` picks which of its API keys to send a
+// request with, see `WithAPIKeys`.
+type KeyRotation int
+
+const (
+	// RoundRobin uses the keys in turn.
+	RoundRobin KeyRotation = iota
+
+	// LeastUsed uses the key with the fewest requests in flight, then
+	// the most requests left by its quota, then the fewest made.
+	LeastUsed
+)
+
+// WithAPIKeys makes the `Client` send each request with one of keys as
+// the value of header, such as X-Api-Key, or Authorization along with
+// keys of the form "Bearer <key>", for the lookups of large jobs to be
+// spread over the keys purchased of a keyed provider. The key is picked
+// by rotation among those with requests left: the `Quota` of each key,
+// and the Retry-After of its 429s, is tracked on its own, keys running
+// out being skipped until they reset, see `Client.KeyStats`.
+//
+// `Client.Quota`, `Client.QuotaRemaining` and `Client.NextAllowedAt` are
+// then those of the keys together, the rate of `WithRateLimit` being to
+// be raised along with the number of keys.
+func WithAPIKeys(header string, rotation KeyRotation, keys ...string) Option {
+	return func(c *Client) {
+		if len(keys) == 0 {
+			c.keys = nil
+			return
+		}
+
+		c.keys = &apiKeys{header: header, rotation: rotation}
+		for _, k := range keys {
+			c.keys.keys = append(c.keys.keys, &apiKey{value: k})
+		}
+	}
+}
+
+// KeyStats are the figures of an API key of a `Client`.
+type KeyStats struct {
+	// Key is the key masked, but for its last 4 characters.
+	Key string
+
+	// Requests counts those made with the key, and InFlight those of
+	// them in progress.
+	Requests int64
+	InFlight int
+
+	// Quota is the last one reported for the key, if QuotaOK, and
+	// Remaining the requests it leaves, see `Client.QuotaRemaining`.
+	Quota     Quota
+	QuotaOK   bool
+	Remaining int
+
+	// RetryAt is when the last 429 of the key asked to retry at.
+	RetryAt time.Time
+}
+
+// KeyStats returns the figures of the API keys of c, in the order they
+// were given to `WithAPIKeys`, none without.
+func (c *Client) KeyStats() []KeyStats {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if c.keys == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+
+	stats := make([]KeyStats, len(c.keys.keys))
+	for i, k := range c.keys.keys {
+		remaining, _ := k.quota.remaining(now)
+		stats[i] = KeyStats{
+			Key:       mask(k.value),
+			Requests:  k.requests,
+			InFlight:  k.inflight,
+			Quota:     k.quota.quota,
+			QuotaOK:   k.quota.ok,
+			Remaining: remaining,
+			RetryAt:   k.quota.retryAt,
+		}
+	}
+
+	return stats
+}
+
+// mask returns key masked but for its last 4 characters, for it not to
+// leak through stats and logs.
+func mask(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+
+	return "****" + key[len(key)-4:]
+}
+
+// apiKeys are the API keys of a `Client`, along with their quotas.
+type apiKeys struct {
+	header   string
+	rotation KeyRotation
+
+	mu   sync.Mutex
+	keys []*apiKey
+	turn int
+}
+
+// apiKey is an API key along with what's known of its quota.
+type apiKey struct {
+	value    string
+	requests int64
+	inflight int
+	quota    quotaState
+}
+
+// len returns the number of keys, none for nil.
+func (ks *apiKeys) len() int {
+	if ks == nil {
+		return 0
+	}
+
+	return len(ks.keys)
+}
+
+// pick returns the key the next request is to be made with, nil when
+// there are no keys, counting it in flight until released.
+func (ks *apiKeys) pick() *apiKey {
+	if ks == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	// Keys which can't be used at now are only picked when none can,
+	// the one available the soonest then.
+	best := -1
+	for j := range ks.keys {
+		i := (ks.turn + j) % len(ks.keys)
+		k := ks.keys[i]
+
+		if best < 0 {
+			best = i
+			continue
+		}
+		if ks.better(k, ks.keys[best], now) {
+			best = i
+		}
+	}
+
+	k := ks.keys[best]
+	ks.turn = best + 1
+	k.inflight++
+
+	return k
+}
+
+// better reports whether a is to be picked over b at now, b coming first
+// in the rotation.
+func (ks *apiKeys) better(a, b *apiKey, now time.Time) bool {
+	an, bn := a.quota.next(now), b.quota.next(now)
+	switch aReady, bReady := !an.After(now), !bn.After(now); {
+	case aReady != bReady:
+		return aReady
+	case !aReady:
+		return an.Before(bn)
+	}
+
+	if ks.rotation != LeastUsed {
+		return false
+	}
+
+	if a.inflight != b.inflight {
+		return a.inflight < b.inflight
+	}
+	ar, aok := a.quota.remaining(now)
+	br, bok := b.quota.remaining(now)
+	if aok && bok && ar != br {
+		return ar > br
+	}
+
+	return a.requests < b.requests
+}
+
+// release counts the request made with k over.
+func (ks *apiKeys) release(k *apiKey) {
+	ks.mu.Lock()
+	k.inflight--
+	ks.mu.Unlock()
+}
+
+// count counts a request about to be made with k.
+func (ks *apiKeys) count(k *apiKey) {
+	ks.mu.Lock()
+	k.requests++
+	k.quota.sent++
+	ks.mu.Unlock()
+}
+
+// record keeps the `Quota` reported by resp to a request made with k.
+func (ks *apiKeys) record(k *apiKey, resp *http.Response) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	k.quota.record(resp, time.Now())
+}
+
+// set replaces the `Quota` known of k with q.
+func (ks *apiKeys) set(k *apiKey, q Quota) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	k.quota.set(q)
+}
+
+// quota returns the `Quota` of the keys together.
+func (ks *apiKeys) quota() (q Quota, ok bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for _, k := range ks.keys {
+		if !k.quota.ok {
+			continue
+		}
+
+		kq := k.quota.quota
+		q.Limit += kq.Limit
+		q.Remaining += kq.Remaining
+		if !kq.ResetAt.IsZero() && (q.ResetAt.IsZero() || kq.ResetAt.Before(q.ResetAt)) {
+			q.ResetAt = kq.ResetAt
+		}
+		q.Window = max(q.Window, kq.Window)
+		ok = true
+	}
+
+	return
+}
+
+// remaining returns the requests the keys leave together at now, ok being
+// false when none of them has anything to estimate it from.
+func (ks *apiKeys) remaining(now time.Time) (n int, ok bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for _, k := range ks.keys {
+		if r, kok := k.quota.remaining(now); kok {
+			n, ok = n+r, true
+		}
+	}
+
+	return
+}
+
+// next returns when the first of the keys may be used.
+func (ks *apiKeys) next(now time.Time) (next time.Time) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	for i, k := range ks.keys {
+		if t := k.quota.next(now); i == 0 || t.Before(next) {
+			next = t
+		}
+	}
+
+	return
+}
@@ -0,0 +1,94 @@
+package binlookup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyed answers cannedBIN, recording the key of each request, with the
+// quota of its key: 2 requests, 429 once spent.
+func keyed(used *[]string) Middleware {
+	remaining := map[string]int{"key-a": 2, "key-b": 2, "key-c": 2}
+
+	return func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			key := req.Header.Get("X-Api-Key")
+			*used = append(*used, key)
+
+			status, body := http.StatusOK, cannedBIN
+			if remaining[key] == 0 {
+				status, body = http.StatusTooManyRequests, ""
+			} else {
+				remaining[key]--
+			}
+
+			h := http.Header{
+				"X-Ratelimit-Limit":     {"2"},
+				"X-Ratelimit-Remaining": {strconv.Itoa(remaining[key])},
+				"X-Ratelimit-Reset":     {"3600"},
+			}
+			if status == http.StatusTooManyRequests {
+				h.Set("Retry-After", "3600")
+			}
+
+			return &http.Response{StatusCode: status, Header: h, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		})
+	}
+}
+
+func TestWithAPIKeysRoundRobin(t *testing.T) {
+	var used []string
+	c := New(WithAPIKeys("X-Api-Key", RoundRobin, "key-a", "key-b", "key-c"), WithMiddleware(keyed(&used)))
+
+	for range 6 {
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	if got := strings.Join(used, " "); got != "key-a key-b key-c key-a key-b key-c" {
+		t.Fatalf("keys used in the order %v", got)
+	}
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 0 {
+		t.Fatalf("estimated %d, %v requests remaining of the keys spent", n, ok)
+	}
+	if q, _ := c.Quota(); q.Limit != 6 {
+		t.Fatalf("quota of the keys together is %+v, want a limit of 6", q)
+	}
+	if d := time.Until(c.NextAllowedAt()); d < 59*time.Minute {
+		t.Fatalf("next request allowed in %v with every key spent", d)
+	}
+
+	stats := c.KeyStats()
+	if len(stats) != 3 || stats[1].Key != "****ey-b" || stats[1].Requests != 2 || !stats[1].QuotaOK || stats[1].Remaining != 0 {
+		t.Fatalf("unexpected key stats %+v", stats)
+	}
+}
+
+func TestWithAPIKeysSkipsSpent(t *testing.T) {
+	var used []string
+	c := New(WithAPIKeys("X-Api-Key", LeastUsed, "key-a", "key-b"), WithMiddleware(keyed(&used)))
+
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+	if got := strings.Join(used, " "); got != "key-a key-b key-a" {
+		t.Fatalf("keys used in the order %v", got)
+	}
+
+	// key-a is spent, so key-b goes on until it is too, then the one
+	// available the soonest is used.
+	c.Search(context.TODO(), CorrectBIN)
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup succeeded with every key spent")
+	}
+	if got := strings.Join(used[3:], " "); got != "key-b key-a" {
+		t.Fatalf("keys used in the order %v once key-a was spent", got)
+	}
+}
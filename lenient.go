@@ -0,0 +1,121 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MalformedError is returned by the lookups of a `Client` decoding leniently
+// along with the BIN upstream answered with, decoded but for the fields it
+// sent malformed, such as a string where a number is expected, which are
+// left zero. See `WithLenientDecoding`.
+type MalformedError struct {
+	// Fields are the JSON paths of the fields left out, such as
+	// country.latitude, in order.
+	Fields []string
+
+	// Err is the error decoding the first of them.
+	Err error
+}
+
+func (e *MalformedError) Error() string {
+	return fmt.Sprintf("Partially Decoded: Malformed %v: %v", strings.Join(e.Fields, ", "), e.Err)
+}
+
+func (e *MalformedError) Unwrap() error {
+	return e.Err
+}
+
+// WithLenientDecoding makes the `Client` answer the lookups whose BIN
+// upstream sends a malformed field of with the BIN decoded but for that
+// field, rather than failing them, along with a `*MalformedError` telling
+// the fields left out:
+//
+//	b, err := c.Search(ctx, bin)
+//	var malformed *binlookup.MalformedError
+//	if errors.As(err, &malformed) {
+//		log.Printf("%v: %v", bin, malformed)
+//		err = nil
+//	}
+//
+// Partial BINs aren't cached, nor retried, and a body which isn't a JSON
+// object still fails the lookup.
+func WithLenientDecoding() Option {
+	return func(c *Client) {
+		c.lenient = true
+	}
+}
+
+// binIndex maps the JSON names of the fields `BIN` models, lowercase, to
+// their index in it.
+var binIndex = func() map[string]int {
+	m := make(map[string]int)
+
+	t := reflect.TypeOf(BIN{})
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && f.Tag.Get("json") != "-" {
+			m[strings.ToLower(jsonName(f))] = i
+		}
+	}
+
+	return m
+}()
+
+// decodeLenient decodes the fields in fields of the payload data of a BIN
+// one by one, those failing to decode left out and reported by the
+// `*MalformedError` returned along the BIN.
+func decodeLenient(data []byte, fields Field) (*BIN, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := new(BIN)
+	bv := reflect.ValueOf(b).Elem()
+
+	var malformed MalformedError
+	for _, k := range keys {
+		i, ok := binIndex[strings.ToLower(k)]
+		if !ok || fields&(1<<i) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(obj[k], bv.Field(i).Addr().Interface()); err != nil {
+			path := k
+			var te *json.UnmarshalTypeError
+			if errors.As(err, &te) && te.Field != "" {
+				path += "." + te.Field
+			}
+
+			malformed.Fields = append(malformed.Fields, path)
+			if malformed.Err == nil {
+				malformed.Err = err
+			}
+		}
+	}
+
+	if fields&FieldExtra != 0 {
+		if err := b.decodeExtra(stdJSON{}, data); err != nil {
+			return nil, err
+		}
+	}
+	if b.Country.Short != "" {
+		b.Country.Normalize()
+	}
+
+	if len(malformed.Fields) > 0 {
+		return b, &malformed
+	}
+
+	return b, nil
+}
@@ -0,0 +1,41 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientWithLenientDecoding(t *testing.T) {
+	body := strings.NewReplacer(`"latitude":56`, `"latitude":"56"`, `"length":16`, `"length":"16"`).Replace(cannedBIN)
+	cache := NewMemoryCache(0)
+	c := New(WithLenientDecoding(), WithCache(cache), WithMiddleware(canned(http.StatusOK, body)))
+
+	b, err := c.Search(context.TODO(), CorrectBIN)
+	var malformed *MalformedError
+	if !errors.As(err, &malformed) {
+		t.Fatalf("lookup returned %v, want a MalformedError", err)
+	}
+	if want := []string{"country.latitude", "number.length"}; !reflect.DeepEqual(malformed.Fields, want) {
+		t.Fatalf("malformed %v, want %v", malformed.Fields, want)
+	}
+
+	if b == nil || b.Bank.Name != "Jyske Bank" || b.Country.Short != "DK" || b.Country.Alpha3 != "DNK" || b.Country.Lat != 0 || !b.Number.Luhn {
+		t.Fatalf("partial BIN is %+v", b)
+	}
+
+	if _, ok, _ := cache.Get(context.TODO(), CorrectBIN); ok {
+		t.Fatal("partial BIN cached")
+	}
+
+	if _, err := New(WithMiddleware(canned(http.StatusOK, body))).Search(context.TODO(), CorrectBIN); err == nil || errors.As(err, &malformed) {
+		t.Fatalf("strict lookup returned %v, want a decoding error", err)
+	}
+
+	if b, err := New(WithLenientDecoding(), WithMiddleware(canned(http.StatusOK, `[]`))).Search(context.TODO(), CorrectBIN); err == nil || b != nil {
+		t.Fatalf("lenient lookup of a body not an object returned %v, %v", b, err)
+	}
+}
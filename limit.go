@@ -0,0 +1,94 @@
+package binlookup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit makes the `Client` send at most n requests per period per
+// upstream, in bursts of up to n, so as to stay within its quota rather
+// than run into 429s. Lookups wait for their turn, or fail with the error
+// of their context when it's done first. Lookups served from cache don't
+// count.
+func WithRateLimit(n int, per time.Duration) Option {
+	return func(c *Client) {
+		if n <= 0 || per <= 0 {
+			c.limiter = nil
+			return
+		}
+
+		c.limiter = &limiter{rate: float64(n) / float64(per), burst: float64(n), tokens: float64(n)}
+	}
+}
+
+// limiter is a token bucket spacing the requests of a `Client`.
+type limiter struct {
+	rate  float64 // tokens per nanosecond
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// peek returns the tokens available at now, negative when lookups are
+// waiting for them.
+func (l *limiter) peek(now time.Time) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := l.tokens
+	if !l.last.IsZero() {
+		tokens = min(tokens+float64(now.Sub(l.last))*l.rate, l.burst)
+	}
+
+	return tokens
+}
+
+// next returns when a token is available, not after now when one is.
+func (l *limiter) next(now time.Time) time.Time {
+	tokens := l.peek(now)
+	if tokens >= 1 {
+		return now
+	}
+
+	return now.Add(time.Duration((1 - tokens) / l.rate))
+}
+
+// wait takes a token, waiting until one is available or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	// The token is taken at once, for the lookups waiting next to queue
+	// behind this one.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,31 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientWithRateLimit(t *testing.T) {
+	c := New(WithRateLimit(2, 200*time.Millisecond), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	start := time.Now()
+	for range 3 {
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("3 lookups took %v under a limit of 2 per 200ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, CorrectBIN); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("lookup waiting past its deadline returned %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package binlookup
+
+import "strings"
+
+// CountryTranslator names the country of the alpha-2 code alpha2 in
+// locale, a BCP 47 tag such as "fr" or "de-CH", with ok false when it
+// can't.
+type CountryTranslator func(alpha2, locale string) (name string, ok bool)
+
+// LocalizeCountryName names the country of the alpha-2 code alpha2 in
+// locale out of the names embedded in the package: a subset of CLDR, for
+// the countries of Europe and the main others in German, French, Spanish,
+// Italian and Dutch. Regional tags fall back to their language, as "fr-CH"
+// does to "fr". It's a `CountryTranslator`.
+func LocalizeCountryName(alpha2, locale string) (name string, ok bool) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(locale, "_", "-")), "-")
+	name, ok = countryNames[lang][strings.ToUpper(alpha2)]
+
+	return
+}
+
+// LocalizedName returns the name of c in locale, see
+// `LocalizeCountryName`, or its Name when there is none.
+func (c Country) LocalizedName(locale string) string {
+	if name, ok := LocalizeCountryName(c.Short, locale); ok {
+		return name
+	}
+
+	return c.Name
+}
+
+// WithLocale makes the `Client` name the countries of the BINs it returns
+// in locale, through tr or `LocalizeCountryName` when nil. Countries tr
+// can't name keep the English name of upstream.
+func WithLocale(locale string, tr CountryTranslator) Option {
+	return func(c *Client) {
+		if tr == nil {
+			tr = LocalizeCountryName
+		}
+		c.locale, c.translator = locale, tr
+	}
+}
+
+// localize returns b with its country named in the locale of c, copying
+// it first since b may be shared through the cache.
+func (c *Client) localize(b *BIN) *BIN {
+	if c.translator == nil || b == nil || b.Country.Short == "" {
+		return b
+	}
+
+	name, ok := c.translator(b.Country.Short, c.locale)
+	if !ok || name == b.Country.Name {
+		return b
+	}
+
+	localized := *b
+	localized.Country.Name = name
+
+	return &localized
+}
+
+// countryNames maps the languages of `LocalizeCountryName` to the names
+// of the countries, by their alpha-2 code.
+var countryNames = map[string]map[string]string{
+	"de": {
+		"AT": "Österreich",
+		"BE": "Belgien",
+		"BG": "Bulgarien",
+		"HR": "Kroatien",
+		"CY": "Zypern",
+		"CZ": "Tschechien",
+		"DK": "Dänemark",
+		"EE": "Estland",
+		"FI": "Finnland",
+		"FR": "Frankreich",
+		"DE": "Deutschland",
+		"GR": "Griechenland",
+		"HU": "Ungarn",
+		"IE": "Irland",
+		"IT": "Italien",
+		"LV": "Lettland",
+		"LT": "Litauen",
+		"LU": "Luxemburg",
+		"MT": "Malta",
+		"NL": "Niederlande",
+		"PL": "Polen",
+		"PT": "Portugal",
+		"RO": "Rumänien",
+		"SK": "Slowakei",
+		"SI": "Slowenien",
+		"ES": "Spanien",
+		"SE": "Schweden",
+		"GB": "Vereinigtes Königreich",
+		"CH": "Schweiz",
+		"NO": "Norwegen",
+		"IS": "Island",
+		"US": "Vereinigte Staaten",
+		"CA": "Kanada",
+		"MX": "Mexiko",
+		"BR": "Brasilien",
+		"AR": "Argentinien",
+		"CN": "China",
+		"JP": "Japan",
+		"KR": "Südkorea",
+		"IN": "Indien",
+		"RU": "Russland",
+		"TR": "Türkei",
+		"UA": "Ukraine",
+		"AU": "Australien",
+		"NZ": "Neuseeland",
+		"ZA": "Südafrika",
+		"AE": "Vereinigte Arabische Emirate",
+	},
+	"fr": {
+		"AT": "Autriche",
+		"BE": "Belgique",
+		"BG": "Bulgarie",
+		"HR": "Croatie",
+		"CY": "Chypre",
+		"CZ": "Tchéquie",
+		"DK": "Danemark",
+		"EE": "Estonie",
+		"FI": "Finlande",
+		"FR": "France",
+		"DE": "Allemagne",
+		"GR": "Grèce",
+		"HU": "Hongrie",
+		"IE": "Irlande",
+		"IT": "Italie",
+		"LV": "Lettonie",
+		"LT": "Lituanie",
+		"LU": "Luxembourg",
+		"MT": "Malte",
+		"NL": "Pays-Bas",
+		"PL": "Pologne",
+		"PT": "Portugal",
+		"RO": "Roumanie",
+		"SK": "Slovaquie",
+		"SI": "Slovénie",
+		"ES": "Espagne",
+		"SE": "Suède",
+		"GB": "Royaume-Uni",
+		"CH": "Suisse",
+		"NO": "Norvège",
+		"IS": "Islande",
+		"US": "États-Unis",
+		"CA": "Canada",
+		"MX": "Mexique",
+		"BR": "Brésil",
+		"AR": "Argentine",
+		"CN": "Chine",
+		"JP": "Japon",
+		"KR": "Corée du Sud",
+		"IN": "Inde",
+		"RU": "Russie",
+		"TR": "Turquie",
+		"UA": "Ukraine",
+		"AU": "Australie",
+		"NZ": "Nouvelle-Zélande",
+		"ZA": "Afrique du Sud",
+		"AE": "Émirats arabes unis",
+	},
+	"es": {
+		"AT": "Austria",
+		"BE": "Bélgica",
+		"BG": "Bulgaria",
+		"HR": "Croacia",
+		"CY": "Chipre",
+		"CZ": "Chequia",
+		"DK": "Dinamarca",
+		"EE": "Estonia",
+		"FI": "Finlandia",
+		"FR": "Francia",
+		"DE": "Alemania",
+		"GR": "Grecia",
+		"HU": "Hungría",
+		"IE": "Irlanda",
+		"IT": "Italia",
+		"LV": "Letonia",
+		"LT": "Lituania",
+		"LU": "Luxemburgo",
+		"MT": "Malta",
+		"NL": "Países Bajos",
+		"PL": "Polonia",
+		"PT": "Portugal",
+		"RO": "Rumanía",
+		"SK": "Eslovaquia",
+		"SI": "Eslovenia",
+		"ES": "España",
+		"SE": "Suecia",
+		"GB": "Reino Unido",
+		"CH": "Suiza",
+		"NO": "Noruega",
+		"IS": "Islandia",
+		"US": "Estados Unidos",
+		"CA": "Canadá",
+		"MX": "México",
+		"BR": "Brasil",
+		"AR": "Argentina",
+		"CN": "China",
+		"JP": "Japón",
+		"KR": "Corea del Sur",
+		"IN": "India",
+		"RU": "Rusia",
+		"TR": "Turquía",
+		"UA": "Ucrania",
+		"AU": "Australia",
+		"NZ": "Nueva Zelanda",
+		"ZA": "Sudáfrica",
+		"AE": "Emiratos Árabes Unidos",
+	},
+	"it": {
+		"AT": "Austria",
+		"BE": "Belgio",
+		"BG": "Bulgaria",
+		"HR": "Croazia",
+		"CY": "Cipro",
+		"CZ": "Cechia",
+		"DK": "Danimarca",
+		"EE": "Estonia",
+		"FI": "Finlandia",
+		"FR": "Francia",
+		"DE": "Germania",
+		"GR": "Grecia",
+		"HU": "Ungheria",
+		"IE": "Irlanda",
+		"IT": "Italia",
+		"LV": "Lettonia",
+		"LT": "Lituania",
+		"LU": "Lussemburgo",
+		"MT": "Malta",
+		"NL": "Paesi Bassi",
+		"PL": "Polonia",
+		"PT": "Portogallo",
+		"RO": "Romania",
+		"SK": "Slovacchia",
+		"SI": "Slovenia",
+		"ES": "Spagna",
+		"SE": "Svezia",
+		"GB": "Regno Unito",
+		"CH": "Svizzera",
+		"NO": "Norvegia",
+		"IS": "Islanda",
+		"US": "Stati Uniti",
+		"CA": "Canada",
+		"MX": "Messico",
+		"BR": "Brasile",
+		"AR": "Argentina",
+		"CN": "Cina",
+		"JP": "Giappone",
+		"KR": "Corea del Sud",
+		"IN": "India",
+		"RU": "Russia",
+		"TR": "Turchia",
+		"UA": "Ucraina",
+		"AU": "Australia",
+		"NZ": "Nuova Zelanda",
+		"ZA": "Sudafrica",
+		"AE": "Emirati Arabi Uniti",
+	},
+	"nl": {
+		"AT": "Oostenrijk",
+		"BE": "België",
+		"BG": "Bulgarije",
+		"HR": "Kroatië",
+		"CY": "Cyprus",
+		"CZ": "Tsjechië",
+		"DK": "Denemarken",
+		"EE": "Estland",
+		"FI": "Finland",
+		"FR": "Frankrijk",
+		"DE": "Duitsland",
+		"GR": "Griekenland",
+		"HU": "Hongarije",
+		"IE": "Ierland",
+		"IT": "Italië",
+		"LV": "Letland",
+		"LT": "Litouwen",
+		"LU": "Luxemburg",
+		"MT": "Malta",
+		"NL": "Nederland",
+		"PL": "Polen",
+		"PT": "Portugal",
+		"RO": "Roemenië",
+		"SK": "Slowakije",
+		"SI": "Slovenië",
+		"ES": "Spanje",
+		"SE": "Zweden",
+		"GB": "Verenigd Koninkrijk",
+		"CH": "Zwitserland",
+		"NO": "Noorwegen",
+		"IS": "IJsland",
+		"US": "Verenigde Staten",
+		"CA": "Canada",
+		"MX": "Mexico",
+		"BR": "Brazilië",
+		"AR": "Argentinië",
+		"CN": "China",
+		"JP": "Japan",
+		"KR": "Zuid-Korea",
+		"IN": "India",
+		"RU": "Rusland",
+		"TR": "Turkije",
+		"UA": "Oekraïne",
+		"AU": "Australië",
+		"NZ": "Nieuw-Zeeland",
+		"ZA": "Zuid-Afrika",
+		"AE": "Verenigde Arabische Emiraten",
+	},
+}
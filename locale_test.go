@@ -0,0 +1,51 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLocalizeCountryName(t *testing.T) {
+	for _, tc := range []struct {
+		alpha2, locale, want string
+		ok                   bool
+	}{
+		{"DE", "fr", "Allemagne", true},
+		{"de", "de-CH", "Deutschland", true},
+		{"DK", "nl_NL", "Denemarken", true},
+		{"DE", "ja", "", false},
+		{"XK", "fr", "", false},
+	} {
+		if name, ok := LocalizeCountryName(tc.alpha2, tc.locale); name != tc.want || ok != tc.ok {
+			t.Fatalf("%v in %v is %q, %v, want %q", tc.alpha2, tc.locale, name, ok, tc.want)
+		}
+	}
+
+	c := Country{Short: "DK", Name: "Denmark"}
+	if c.LocalizedName("de") != "Dänemark" || c.LocalizedName("ja") != "Denmark" {
+		t.Fatal("country isn't localized")
+	}
+}
+
+func TestClientWithLocale(t *testing.T) {
+	cache := NewMemoryCache(0)
+	c := New(WithLocale("fr-FR", nil), WithCache(cache), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	for range 2 {
+		if b, err := c.Search(context.TODO(), CorrectBIN); err != nil || b.Country.Name != "Danemark" {
+			t.Fatalf("lookup returned %+v, %v", b, err)
+		}
+	}
+
+	if e, _, _ := cache.Get(context.TODO(), CorrectBIN); e.BIN.Country.Name != "Denmark" {
+		t.Fatalf("cached BIN named %q", e.BIN.Country.Name)
+	}
+
+	shout := func(alpha2, locale string) (string, bool) { return alpha2 + "!", true }
+	cache.Set(context.TODO(), CorrectBIN, Entry{BIN: &BIN{Country: Country{Short: "DK", Name: "Denmark"}}, Expires: time.Now().Add(time.Hour)})
+	if b, _ := New(WithLocale("x", shout), WithCache(cache)).Search(context.TODO(), CorrectBIN); b.Country.Name != "DK!" {
+		t.Fatalf("country named %q by the translator", b.Country.Name)
+	}
+}
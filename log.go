@@ -0,0 +1,59 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// WithLogger makes the `Client` log its lookups to l: a record at level
+// for each lookup, with the provider, the masked BIN, the outcome of the
+// cache, which is hit, miss, expired or stale, and how long it took, and
+// one for each attempt upstream, with its number, status and duration.
+// The records of failures, those of BINs not found aside, are logged at
+// slog.LevelWarn unless level is higher, with the error, masked as that
+// of `Hooks`. Nothing is logged unless set.
+func WithLogger(l *slog.Logger, level slog.Level) Option {
+	return func(c *Client) {
+		c.logger, c.logLevel = l, level
+	}
+}
+
+// log logs a record of the lookup of bin to the logger of c, if any, at
+// the level of its records of failures when err is one.
+func (c *Client) log(ctx context.Context, msg, bin string, err error, attrs ...slog.Attr) {
+	if c.logger == nil {
+		return
+	}
+
+	level := c.logLevel
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		level = max(level, slog.LevelWarn)
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs = append(attrs, slog.String("provider", c.name()), slog.String("bin", maskBIN(bin)))
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", &maskedError{err: err, bin: bin}))
+	}
+
+	c.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// cacheOutcome returns the outcome of the cache of c for a lookup: hit
+// when it's served out of it, expired when the BIN cached is to be
+// refreshed, miss otherwise, and none without a cache.
+func (c *Client) cacheOutcome(hit bool, stale *Entry) string {
+	switch {
+	case c.cache == nil:
+		return "none"
+	case hit:
+		return "hit"
+	case stale != nil:
+		return "expired"
+	}
+
+	return "miss"
+}
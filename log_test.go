@@ -0,0 +1,68 @@
+package binlookup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	status := http.StatusTooManyRequests
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := canned(status, cannedBIN)(nil).Do(req)
+			status = http.StatusOK
+			return resp, err
+		})
+	}
+
+	c := New(WithLogger(l, slog.LevelDebug), WithCache(NewMemoryCache(0)), WithMiddleware(upstream), WithRetries(1), WithBackoff(ExponentialBackoff{}))
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("%v: %q", err, line)
+		}
+		records = append(records, r)
+	}
+
+	want := []struct {
+		msg, level string
+		attrs      map[string]any
+	}{
+		{"Attempt", "WARN", map[string]any{"attempt": 0.0, "status": 429.0}},
+		{"Retrying", "WARN", map[string]any{"attempt": 0.0}},
+		{"Attempt", "DEBUG", map[string]any{"attempt": 1.0, "status": 200.0}},
+		{"Lookup", "DEBUG", map[string]any{"cache": "miss"}},
+		{"Lookup", "DEBUG", map[string]any{"cache": "hit"}},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("logged %d records, want %d:\n%v", len(records), len(want), buf.String())
+	}
+
+	for i, w := range want {
+		r := records[i]
+		if r["msg"] != w.msg || r["level"] != w.level || r["provider"] != c.Name() || r["bin"] != maskBIN(CorrectBIN) {
+			t.Fatalf("record %d is %v, want %v at %v", i, r, w.msg, w.level)
+		}
+		for k, v := range w.attrs {
+			if r[k] != v {
+				t.Fatalf("record %d has %v %v, want %v", i, k, r[k], v)
+			}
+		}
+	}
+
+	if strings.Contains(buf.String(), CorrectBIN) {
+		t.Fatal("BIN logged unmasked")
+	}
+}
@@ -0,0 +1,54 @@
+package binlookup
+
+// Luhn reports whether number, made of digits only, passes the Luhn
+// checksum card numbers carry in their last digit.
+func Luhn(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// MatchesPAN reports whether the full card number pan is consistent with
+// what b tells about the numbers of its range: their length when known,
+// and the Luhn checksum when they use it. Only the digits are checked
+// when Number is unknown.
+func (b *BIN) MatchesPAN(pan string) bool {
+	for i := 0; i < len(pan); i++ {
+		if pan[i] < '0' || pan[i] > '9' {
+			return false
+		}
+	}
+
+	if n := b.Number; n != nil {
+		if n.Length != 0 && len(pan) != n.Length {
+			return false
+		}
+
+		if n.Luhn && !Luhn(pan) {
+			return false
+		}
+	}
+
+	return pan != ""
+}
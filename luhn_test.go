@@ -0,0 +1,36 @@
+package binlookup
+
+import "testing"
+
+func TestLuhn(t *testing.T) {
+	for _, n := range []string{"4111111111111111", "5500005555555559", "378282246310005", "0"} {
+		if !Luhn(n) {
+			t.Fatalf("%v passes the Luhn checksum but Luhn returned false", n)
+		}
+	}
+
+	for _, n := range []string{"", "4111111111111112", "4111 1111 1111 1111", "411111111111111x"} {
+		if Luhn(n) {
+			t.Fatalf("%q fails the Luhn checksum but Luhn returned true", n)
+		}
+	}
+}
+
+func TestBINMatchesPAN(t *testing.T) {
+	b := &BIN{Number: &Number{Length: 16, Luhn: true}}
+
+	if !b.MatchesPAN("4111111111111111") {
+		t.Fatal("valid PAN doesn't match")
+	}
+
+	for _, pan := range []string{"4111111111111112", "411111111111111", "4111-1111-1111-1111"} {
+		if b.MatchesPAN(pan) {
+			t.Fatalf("%v matches", pan)
+		}
+	}
+
+	unknown := &BIN{}
+	if !unknown.MatchesPAN("4111111111111112") || unknown.MatchesPAN("") {
+		t.Fatal("BIN without number info must only reject non-numerical PANs")
+	}
+}
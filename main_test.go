@@ -0,0 +1,18 @@
+package binlookup_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/binlookuptest"
+)
+
+// TestMain makes the package level `Search` replay the fixtures of
+// testdata/fixtures, recorded with BINLOOKUP_RECORD=1, so that the tests
+// neither reach upstream nor flake on its rate limits.
+func TestMain(m *testing.M) {
+	binlookup.SetDefault(binlookup.New(binlookup.WithTransport(binlookuptest.NewRecorder("testdata/fixtures", binlookuptest.ModeFromEnv()))))
+
+	os.Exit(m.Run())
+}
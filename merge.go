@@ -0,0 +1,140 @@
+package binlookup
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Merge returns a `Provider` querying all of ps concurrently and putting
+// their answers together field by field: each field comes from the first
+// provider, in the order given, to have it set. Which provider supplied
+// each field is recorded in Meta.Sources. The fields of an object such as
+// "number" that are encoded even when zero, as "luhn" is, are set by the
+// provider answering with the object, false or not.
+//
+// A lookup fails only when all of ps fail, with the error of the first.
+// A provider answering with neither a BIN nor an error has no data, as
+// though it failed with `ErrNotFound`.
+func Merge(ps ...Provider) Provider {
+	return merge(ps)
+}
+
+type merge []Provider
+
+func (ps merge) Search(ctx context.Context, bin string) (*BIN, error) {
+	bins := make([]*BIN, len(ps))
+	errs := make([]error, len(ps))
+
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+
+			bins[i], errs[i] = p.Search(ctx, truncate(p, bin))
+			if bins[i] == nil && errs[i] == nil {
+				errs[i] = ErrNotFound
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var b *BIN
+	for i, p := range ps {
+		if errs[i] != nil {
+			continue
+		}
+
+		name := ProviderName(p)
+		if b == nil {
+			b = &BIN{Meta: Meta{Provider: name, Sources: make(map[string]string)}}
+		}
+
+		mergeFields(reflect.ValueOf(b).Elem(), reflect.ValueOf(bins[i]).Elem(), "", name, b.Meta.Sources, false, false)
+	}
+
+	if b == nil {
+		if len(errs) == 0 {
+			return nil, ErrNotFound
+		}
+
+		return nil, errs[0]
+	}
+
+	return b, nil
+}
+
+// Capabilities of a merge are those of its providers combined, the same
+// way as for `Chain`.
+func (ps merge) Capabilities() Capabilities {
+	return chain(ps).Capabilities()
+}
+
+var metaType = reflect.TypeOf(Meta{})
+
+// mergeFields copies the fields set in src over the ones unset in dst,
+// recording name as the source of each copied one under its JSON path.
+// The fields present holds are those of an object src has; answered tells
+// src, a field of theirs encoded even when zero, is set whatever its value.
+// A field answered by a source recorded earlier is kept.
+func mergeFields(dst, src reflect.Value, path, name string, sources map[string]string, present, answered bool) {
+	switch src.Kind() {
+	case reflect.Struct:
+		t := src.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Type == metaType {
+				continue
+			}
+
+			mergeFields(dst.Field(i), src.Field(i), joinPath(path, jsonName(f)), name, sources, present, present && !omitEmpty(f))
+		}
+
+		return
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+
+		if src.Elem().Kind() == reflect.Struct {
+			if dst.IsNil() {
+				dst.Set(reflect.New(src.Elem().Type()))
+			}
+
+			mergeFields(dst.Elem(), src.Elem(), path, name, sources, true, answered)
+			return
+		}
+	}
+
+	if _, ok := sources[path]; ok || !dst.IsZero() || src.IsZero() && !answered {
+		return
+	}
+
+	dst.Set(src)
+	sources[path] = name
+}
+
+// jsonName returns the key f is encoded under in JSON.
+func jsonName(f reflect.StructField) string {
+	if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" && tag != "-" {
+		return tag
+	}
+
+	return strings.ToLower(f.Name)
+}
+
+// omitEmpty reports whether f is left out of JSON when zero.
+func omitEmpty(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return strings.Contains(opts, "omitempty")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
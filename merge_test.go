@@ -0,0 +1,90 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	fast := Named("fast", &stubProvider{b: &BIN{Scheme: "visa", Country: Country{Short: "DK"}}})
+	full := Named("full", &stubProvider{b: &BIN{
+		Scheme:  "mastercard",
+		Brand:   "Visa/Dankort",
+		Country: Country{Short: "SE", Name: "Denmark"},
+		Bank:    &Bank{Name: "Jyske Bank"},
+	}})
+	down := Named("down", &stubProvider{err: errors.New("unreachable")})
+
+	b, err := Merge(down, fast, full).Search(context.TODO(), "45717360")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != "visa" || b.Country.Short != "DK" || b.Country.Name != "Denmark" || b.Brand != "Visa/Dankort" || b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("unexpected merged BIN %+v", b)
+	}
+
+	want := map[string]string{
+		"scheme":         "fast",
+		"country.alpha2": "fast",
+		"country.name":   "full",
+		"brand":          "full",
+		"bank.name":      "full",
+	}
+
+	if len(b.Meta.Sources) != len(want) {
+		t.Fatalf("sources are %v, want %v", b.Meta.Sources, want)
+	}
+
+	for field, name := range want {
+		if b.Meta.Sources[field] != name {
+			t.Fatalf("%v was attributed to %q, want %q", field, b.Meta.Sources[field], name)
+		}
+	}
+
+	if b.Meta.Provider != "fast" {
+		t.Fatalf("merged BIN is attributed to %q, want the first provider answering", b.Meta.Provider)
+	}
+}
+
+func TestMergeAllFail(t *testing.T) {
+	_, err := Merge(&stubProvider{err: ErrNotFound}, &stubProvider{err: errors.New("unreachable")}).Search(context.TODO(), CorrectBIN)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("merge returned %v, want the first provider's error", err)
+	}
+}
+
+func TestMergeNoData(t *testing.T) {
+	empty := Named("empty", &stubProvider{})
+	fast := Named("fast", &stubProvider{b: &BIN{Scheme: "visa"}})
+
+	b, err := Merge(empty, fast).Search(context.TODO(), CorrectBIN)
+	if err != nil || b.Scheme != "visa" || b.Meta.Provider != "fast" {
+		t.Fatalf("merge returned %+v, %v, want the BIN of the provider with data", b, err)
+	}
+
+	if _, err := Merge(&stubProvider{}, &stubProvider{}).Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("merge of providers without data returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestMergeAnsweredFalse(t *testing.T) {
+	first := Named("first", &stubProvider{b: &BIN{Number: &Number{Luhn: false}}})
+	second := Named("second", &stubProvider{b: &BIN{Number: &Number{Length: 16, Luhn: true}, Bank: &Bank{Name: "Jyske Bank"}}})
+
+	b, err := Merge(first, second).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Number.Luhn || b.Number.Length != 16 || b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("unexpected merged BIN %+v, %+v", b.Number, b.Bank)
+	}
+
+	for field, name := range map[string]string{"number.luhn": "first", "number.length": "second", "bank.name": "second"} {
+		if b.Meta.Sources[field] != name {
+			t.Fatalf("%v was attributed to %q, want %q", field, b.Meta.Sources[field], name)
+		}
+	}
+}
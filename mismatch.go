@@ -0,0 +1,47 @@
+package binlookup
+
+import "math"
+
+// earthRadius is the mean radius of the Earth, in kilometres.
+const earthRadius = 6371.0088
+
+// CountryMismatch reports whether the cards of b are issued in another
+// country than merchant, the alpha-2, alpha-3 or numeric code of the
+// country the card is used in, a common fraud signal. known is false when
+// the country of b or merchant is unknown, see `LookupCountry`.
+func (b *BIN) CountryMismatch(merchant string) (mismatch, known bool) {
+	issuer, ok := LookupCountry(b.Country.Short)
+	if !ok {
+		return false, false
+	}
+	m, ok := LookupCountry(merchant)
+	if !ok {
+		return false, false
+	}
+
+	return issuer.Alpha2 != m.Alpha2, true
+}
+
+// Distance returns the great-circle distance from c to o in kilometres,
+// by the latitude and longitude of each, such as how far the country a
+// card is issued in is from that of the merchant. ok is false when either
+// has no coordinates.
+func (c Country) Distance(o Country) (km float64, ok bool) {
+	if !c.located() || !o.located() {
+		return 0, false
+	}
+
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	lat1, lat2 := rad(c.Lat), rad(o.Lat)
+	dLat, dLong := lat2-lat1, rad(o.Long-c.Long)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLong/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h))), true
+}
+
+// located reports whether c has coordinates, which upstream sends along
+// with the alpha-2 code; 0, 0 alone is that of a country unknown.
+func (c Country) located() bool {
+	return c.Short != "" || c.Lat != 0 || c.Long != 0
+}
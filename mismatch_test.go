@@ -0,0 +1,47 @@
+package binlookup
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCountryMismatch(t *testing.T) {
+	b := &BIN{Country: Country{Short: "DK"}}
+
+	for merchant, want := range map[string][2]bool{
+		"DK":  {false, true},
+		"dnk": {false, true},
+		"208": {false, true},
+		"SE":  {true, true},
+		"XX":  {false, false},
+		"":    {false, false},
+	} {
+		if mismatch, known := b.CountryMismatch(merchant); mismatch != want[0] || known != want[1] {
+			t.Errorf("CountryMismatch(%q) = %v, %v, want %v, %v", merchant, mismatch, known, want[0], want[1])
+		}
+	}
+
+	if _, known := (&BIN{}).CountryMismatch("DK"); known {
+		t.Error("CountryMismatch is known for a BIN of unknown country")
+	}
+}
+
+func TestCountryDistance(t *testing.T) {
+	dk := Country{Short: "DK", Lat: 56, Long: 10}
+	us := Country{Short: "US", Lat: 38, Long: -97}
+
+	km, ok := dk.Distance(us)
+	if !ok || math.Abs(km-7521) > 10 {
+		t.Fatalf("Distance = %v, %v, want about 7521 km", km, ok)
+	}
+	if back, _ := us.Distance(dk); math.Abs(back-km) > 1e-6 {
+		t.Fatalf("Distance back is %v, want %v", back, km)
+	}
+	if km, _ := dk.Distance(dk); km != 0 {
+		t.Fatalf("Distance to itself is %v", km)
+	}
+
+	if _, ok := dk.Distance(Country{}); ok {
+		t.Fatal("Distance to a country without coordinates is known")
+	}
+}
@@ -0,0 +1,46 @@
+package binlookup
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPAN is returned by `BINFromPAN` for malformed card numbers.
+// Its errors never include the card number.
+var ErrInvalidPAN = errors.New("Invalid PAN")
+
+// BINFromPAN extracts the BIN, the first digits digits, out of the full
+// card number pan, which may be grouped with spaces or dashes. digits is
+// either 6 or 8.
+//
+// It's the safe way to go from a PAN to a lookup: the PAN never ends up
+// in the errors returned, nor in what's passed to `Search`.
+func BINFromPAN(pan string, digits int) (string, error) {
+	if digits != 6 && digits != 8 {
+		return "", fmt.Errorf("%w: BIN length must be 6 or 8, not %d", ErrInvalidPAN, digits)
+	}
+
+	bin := make([]byte, 0, digits)
+	n := 0
+	for i := 0; i < len(pan); i++ {
+		switch c := pan[i]; {
+		case c == ' ' || c == '-':
+			continue
+		case c < '0' || c > '9':
+			return "", fmt.Errorf("%w: PAN must only hold digits, spaces and dashes", ErrInvalidPAN)
+		case len(bin) < digits:
+			bin = append(bin, c)
+		}
+		n++
+	}
+
+	if n < digits {
+		return "", fmt.Errorf("%w: PAN holds %d digits, fewer than the %d of the BIN", ErrInvalidPAN, n, digits)
+	}
+
+	if bin[0] == '0' {
+		return "", fmt.Errorf("%w: PAN must not start with 0", ErrInvalidPAN)
+	}
+
+	return string(bin), nil
+}
@@ -0,0 +1,49 @@
+package binlookup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBINFromPAN(t *testing.T) {
+	cases := []struct {
+		pan    string
+		digits int
+		want   string
+	}{
+		{"4571 7360 1234 5678", 6, "457173"},
+		{"4571-7360-1234-5678", 8, "45717360"},
+		{"4571736012345678", 8, "45717360"},
+	}
+
+	for _, c := range cases {
+		got, err := BINFromPAN(c.pan, c.digits)
+		if err != nil || got != c.want {
+			t.Fatalf("BINFromPAN(%q, %d) = %q, %v, want %q", c.pan, c.digits, got, err, c.want)
+		}
+	}
+}
+
+func TestBINFromPANNeverLeaksPAN(t *testing.T) {
+	cases := []struct {
+		pan    string
+		digits int
+	}{
+		{"4571 7360 1234 567x", 8},
+		{"4571736012345678", 7},
+		{"45717", 6},
+		{"0571736012345678", 6},
+	}
+
+	for _, c := range cases {
+		_, err := BINFromPAN(c.pan, c.digits)
+		if !errors.Is(err, ErrInvalidPAN) {
+			t.Fatalf("BINFromPAN(%q, %d) returned %v, want ErrInvalidPAN", c.pan, c.digits, err)
+		}
+
+		if strings.Contains(err.Error(), "4571") || strings.Contains(err.Error(), "0571") {
+			t.Fatalf("error %q leaks the PAN", err)
+		}
+	}
+}
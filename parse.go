@@ -0,0 +1,163 @@
+package binlookup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BINNumber is a BIN parsed by `ParseBIN`: 4 to 16 digits, the first of
+// which isn't 0.
+type BINNumber string
+
+// String returns the digits of n.
+func (n BINNumber) String() string {
+	return string(n)
+}
+
+// Prefix returns the first digits digits of n, all of them when it has
+// fewer, such as the 6 or 8 of the BIN of a card number.
+func (n BINNumber) Prefix(digits int) BINNumber {
+	if digits > 0 && len(n) > digits {
+		return n[:digits]
+	}
+
+	return n
+}
+
+// ProblemCode tells what's wrong with a BIN failing to parse, marshaled
+// as its name for API frontends to map to their field errors.
+type ProblemCode int
+
+const (
+	// ProblemEmpty is an empty BIN.
+	ProblemEmpty ProblemCode = iota + 1
+
+	// ProblemTooShort and ProblemTooLong are BINs of fewer than 4 or
+	// more than 16 digits.
+	ProblemTooShort
+	ProblemTooLong
+
+	// ProblemNonDigit is a character other than a digit, at Position.
+	ProblemNonDigit
+
+	// ProblemLeadingZero is a BIN starting with 0, which none does.
+	ProblemLeadingZero
+
+	// ProblemNonStandardLength is a BIN of neither 6 nor 8 digits, see
+	// `ValidateStandardBIN`.
+	ProblemNonStandardLength
+)
+
+var problemNames = map[ProblemCode]string{
+	ProblemEmpty:             "empty",
+	ProblemTooShort:          "too_short",
+	ProblemTooLong:           "too_long",
+	ProblemNonDigit:          "non_digit",
+	ProblemLeadingZero:       "leading_zero",
+	ProblemNonStandardLength: "non_standard_length",
+}
+
+// String returns the name of p, such as non_digit.
+func (p ProblemCode) String() string {
+	if name, ok := problemNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ProblemCode(%d)", int(p))
+}
+
+// MarshalText marshals p as its name.
+func (p ProblemCode) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Problem is a reason a BIN fails to parse.
+type Problem struct {
+	Code ProblemCode `json:"code"`
+
+	// Position is that of the character the problem is at, from 1, and
+	// zero for the problems of the BIN as a whole.
+	Position int `json:"position,omitempty"`
+}
+
+// BINError is the error of the BINs failing to parse or validate, along
+// with every problem they have. It's an `ErrInvalidBIN` one. Neither it
+// nor its message quote the BIN, which may be a card number.
+type BINError struct {
+	Problems []Problem
+}
+
+func (e *BINError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		switch p.Code {
+		case ProblemEmpty:
+			problems[i] = "Empty"
+		case ProblemTooShort:
+			problems[i] = "Fewer Than 4 Digits"
+		case ProblemTooLong:
+			problems[i] = "More Than 16 Digits"
+		case ProblemNonDigit:
+			problems[i] = fmt.Sprintf("Non-Digit at Position %d", p.Position)
+		case ProblemLeadingZero:
+			problems[i] = "Leading Zero"
+		case ProblemNonStandardLength:
+			problems[i] = "Neither 6 nor 8 Digits"
+		default:
+			problems[i] = p.Code.String()
+		}
+	}
+
+	return fmt.Sprintf("%v: %v", ErrInvalidBIN, strings.Join(problems, ", "))
+}
+
+func (e *BINError) Is(target error) bool {
+	return target == ErrInvalidBIN
+}
+
+// Has reports whether code is among the problems of e.
+func (e *BINError) Has(code ProblemCode) bool {
+	for _, p := range e.Problems {
+		if p.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseBIN parses s as a BIN in the format `Search` accepts, failing with
+// a `BINError` listing every problem of s otherwise: a length out of 4 to
+// 16, the position of each character other than a digit, and a leading
+// zero. It's for API frontends to answer with field errors, rather than
+// the message of `ValidateBIN`.
+func ParseBIN(s string) (BINNumber, error) {
+	if validBIN(s) {
+		return BINNumber(s), nil
+	}
+
+	if s == "" {
+		return "", &BINError{Problems: []Problem{{Code: ProblemEmpty}}}
+	}
+
+	var problems []Problem
+	if s[0] == '0' {
+		problems = append(problems, Problem{Code: ProblemLeadingZero, Position: 1})
+	}
+
+	n := 0
+	for _, r := range s {
+		if n++; r < '0' || r > '9' {
+			problems = append(problems, Problem{Code: ProblemNonDigit, Position: n})
+		}
+	}
+
+	switch {
+	case n < 4:
+		problems = append(problems, Problem{Code: ProblemTooShort})
+	case n > 16:
+		problems = append(problems, Problem{Code: ProblemTooLong})
+	}
+
+	return "", &BINError{Problems: problems}
+}
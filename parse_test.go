@@ -0,0 +1,52 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseBIN(t *testing.T) {
+	n, err := ParseBIN("4571736012345678")
+	if err != nil || n.Prefix(8) != "45717360" || n.Prefix(0) != n {
+		t.Fatalf("ParseBIN returned %v, %v", n, err)
+	}
+
+	for s, want := range map[string][]Problem{
+		"":                   {{Code: ProblemEmpty}},
+		"528":                {{Code: ProblemTooShort}},
+		"45717360123456789":  {{Code: ProblemTooLong}},
+		"0812436":            {{Code: ProblemLeadingZero, Position: 1}},
+		"4571 73x0":          {{Code: ProblemNonDigit, Position: 5}, {Code: ProblemNonDigit, Position: 8}},
+		"0€1":                {{Code: ProblemLeadingZero, Position: 1}, {Code: ProblemNonDigit, Position: 2}, {Code: ProblemTooShort}},
+		"45717360123456789x": {{Code: ProblemNonDigit, Position: 18}, {Code: ProblemTooLong}},
+	} {
+		_, err := ParseBIN(s)
+
+		var be *BINError
+		if !errors.As(err, &be) || !errors.Is(err, ErrInvalidBIN) {
+			t.Fatalf("ParseBIN(%q) returned %v, want a BINError", s, err)
+		}
+		if !reflect.DeepEqual(be.Problems, want) {
+			t.Fatalf("ParseBIN(%q) found %+v, want %+v", s, be.Problems, want)
+		}
+	}
+}
+
+func TestBINError(t *testing.T) {
+	_, err := ParseBIN("0812x36")
+
+	if got, want := err.Error(), "Invalid BIN: Leading Zero, Non-Digit at Position 5"; got != want {
+		t.Fatalf("message is %q, want %q", got, want)
+	}
+
+	data, _ := json.Marshal(err.(*BINError).Problems)
+	if got, want := string(data), `[{"code":"leading_zero","position":1},{"code":"non_digit","position":5}]`; got != want {
+		t.Fatalf("problems marshaled as %v, want %v", got, want)
+	}
+
+	if be := ValidateStandardBIN("4571736").(*BINError); !be.Has(ProblemNonStandardLength) {
+		t.Fatalf("standard validation found %+v", be.Problems)
+	}
+}
@@ -0,0 +1,85 @@
+package binlookup
+
+import "encoding/json"
+
+// PaylikeJSON is a `BIN` encoding to JSON in the shape the original
+// JavaScript library, github.com/paylike/binlookup, resolves lookups to,
+// for services ported from Node to compare answers with those still
+// running it, side by side:
+//
+//	{"number":{},"scheme":"visa","type":"debit","brand":"Visa/Dankort","prepaid":false,"country":{...},"bank":{...}}
+//
+// Every key is there whatever upstream knows: number, country and bank
+// are {} when unknown, the fields of which being left out, while scheme,
+// type, brand and prepaid are null. A nil BIN, that of a BIN not found,
+// encodes to null. The fields of Extra are left out.
+type PaylikeJSON struct {
+	*BIN
+}
+
+type paylikeBIN struct {
+	Number  paylikeNumber  `json:"number"`
+	Scheme  *string        `json:"scheme"`
+	Type    *string        `json:"type"`
+	Brand   *string        `json:"brand"`
+	Prepaid *bool          `json:"prepaid"`
+	Country paylikeCountry `json:"country"`
+	Bank    Bank           `json:"bank"`
+}
+
+type paylikeNumber struct {
+	Length int   `json:"length,omitempty"`
+	Luhn   *bool `json:"luhn,omitempty"`
+}
+
+type paylikeCountry struct {
+	Numeric  string   `json:"numeric,omitempty"`
+	Short    string   `json:"alpha2,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Emoji    string   `json:"emoji,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+	Lat      *float64 `json:"latitude,omitempty"`
+	Long     *float64 `json:"longitude,omitempty"`
+}
+
+// MarshalJSON encodes p in the shape of github.com/paylike/binlookup.
+func (p PaylikeJSON) MarshalJSON() ([]byte, error) {
+	b := p.BIN
+	if b == nil {
+		return []byte("null"), nil
+	}
+
+	v := paylikeBIN{
+		Scheme:  nullable(string(b.Scheme)),
+		Type:    nullable(string(b.Type)),
+		Brand:   nullable(b.Brand),
+		Prepaid: b.Prepaid,
+		Country: paylikeCountry{
+			Numeric:  b.Country.Numeric,
+			Short:    b.Country.Short,
+			Name:     b.Country.Name,
+			Emoji:    b.Country.Emoji,
+			Currency: b.Country.Currency,
+		},
+	}
+	if n := b.Number; n != nil {
+		v.Number = paylikeNumber{Length: n.Length, Luhn: &n.Luhn}
+	}
+	if c := b.Country; c.located() {
+		v.Country.Lat, v.Country.Long = &c.Lat, &c.Long
+	}
+	if b.Bank != nil {
+		v.Bank = *b.Bank
+	}
+
+	return json.Marshal(v)
+}
+
+// nullable returns nil for an empty s, a pointer to it otherwise.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,40 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPaylikeJSON(t *testing.T) {
+	var b BIN
+	if err := json.Unmarshal([]byte(cannedBIN), &b); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	b.Extra = nil
+
+	data, err := json.Marshal(PaylikeJSON{&b})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if string(data) != cannedBIN {
+		t.Fatalf("encoded to %s, want %v", data, cannedBIN)
+	}
+}
+
+func TestPaylikeJSONUnknown(t *testing.T) {
+	for b, want := range map[*BIN]string{
+		nil:                  `null`,
+		{Scheme: SchemeVisa}: `{"number":{},"scheme":"visa","type":null,"brand":null,"prepaid":null,"country":{},"bank":{}}`,
+		{Number: &Number{}}:  `{"number":{"luhn":false},"scheme":null,"type":null,"brand":null,"prepaid":null,"country":{},"bank":{}}`,
+		{Country: Country{Short: "DK", Lat: 56, Long: 10}}: `{"number":{},"scheme":null,"type":null,"brand":null,"prepaid":null,"country":{"alpha2":"DK","latitude":56,"longitude":10},"bank":{}}`,
+	} {
+		data, err := json.Marshal(PaylikeJSON{b})
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if string(data) != want {
+			t.Errorf("%+v encoded to %s, want %v", b, data, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+)
+
+// pingBIN is the BIN looked up by `Client.Ping`, one upstream knows.
+const pingBIN = "45717360"
+
+// Pinger is implemented by the lookupers able to check that their upstream
+// is up, such as `Client`, for readiness probes.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that upstream is reachable and accepts the requests of c,
+// its authentication included, with a single request bypassing the cache
+// and the retries of c. It costs a request of the quota, hence probes are
+// best spaced accordingly, and waits its turn under the rate limit of c
+// like lookups do. Upstream answering with 404 is up all the same.
+//
+// It fails with `ErrNetworkDisabled` when c has the network disabled, and
+// otherwise with the error of the request, such as an `HTTPError` of
+// http.StatusUnauthorized or one matching `ErrRateLimited`.
+func (c *Client) Ping(ctx context.Context) error {
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if c.offline {
+		return ErrNetworkDisabled
+	}
+
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	_, _, err := c.attempt(ctx, pingBIN, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
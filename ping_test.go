@@ -0,0 +1,60 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientPing(t *testing.T) {
+	for status, ok := range map[int]bool{
+		http.StatusOK:                  true,
+		http.StatusNotFound:            true,
+		http.StatusUnauthorized:        false,
+		http.StatusTooManyRequests:     false,
+		http.StatusServiceUnavailable:  false,
+		http.StatusInternalServerError: false,
+	} {
+		err := New(WithRetries(3), WithMiddleware(canned(status, cannedBIN))).Ping(context.TODO())
+		if (err == nil) != ok {
+			t.Fatalf("ping answered with %d returned %v", status, err)
+		}
+	}
+
+	if err := New(WithNetworkDisabled()).Ping(context.TODO()); !errors.Is(err, ErrNetworkDisabled) {
+		t.Fatalf("ping with the network disabled returned %v", err)
+	}
+
+	c := New()
+	c.Close()
+	if err := c.Ping(context.TODO()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ping of a closed client returned %v", err)
+	}
+}
+
+func TestClientPingRateLimited(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	c := New(WithRateLimit(1, time.Hour), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+	if err := c.Ping(context.TODO()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := c.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ping over the rate limit returned %v, want it to wait its turn", err)
+	}
+	if requests != 1 {
+		t.Fatalf("%d requests made by pings over a rate limit of 1", requests)
+	}
+}
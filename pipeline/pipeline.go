@@ -0,0 +1,373 @@
+// Package pipeline enriches streams of BINs in stages connected by
+// channels, for BIN lookups to be wired into streaming ETL code with the
+// stages of its own in between:
+//
+//	out := pipeline.Run(ctx, pipeline.FromSlice(ctx, bins),
+//		pipeline.Validate(nil),
+//		pipeline.Dedupe(pipeline.Cached(cache, time.Hour, pipeline.Chain(
+//			pipeline.Limit(limiter),
+//			pipeline.Lookup(lookuper, 8),
+//		))),
+//	)
+//
+// Items flow through every stage in turn. Those a stage resolves, with a
+// BIN or an error, go through the following stages untouched, stages
+// handling the unresolved ones only; custom stages are best written with
+// `Map`, which does so. `Dedupe` and `Cached` wrap the stages resolving
+// what they can't themselves, for them to see their results. Stages don't
+// keep items in order, which `Item.Index` tells.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// ErrDropped is the error of the repeated items `Dedupe` held back whose
+// first occurrence the stages it wraps dropped.
+var ErrDropped = errors.New("Item Dropped")
+
+// Item is a BIN going through a pipeline, along with its result once
+// resolved.
+type Item struct {
+	// Index is the position of the BIN in the input.
+	Index int
+	BIN   string
+
+	Result *binlookup.BIN
+	Err    error
+}
+
+// Resolved reports whether a stage resolved i.
+func (i Item) Resolved() bool {
+	return i.Result != nil || i.Err != nil
+}
+
+// Stage consumes in and returns the channel of the items it outputs,
+// closing it once in is closed and drained, or ctx is done.
+type Stage func(ctx context.Context, in <-chan Item) <-chan Item
+
+// Run connects the stages one to the other, in turn, from in.
+func Run(ctx context.Context, in <-chan Item, stages ...Stage) <-chan Item {
+	for _, s := range stages {
+		in = s(ctx, in)
+	}
+
+	return in
+}
+
+// Chain returns the `Stage` of stages connected in turn, for a sequence
+// of them to be reused as one.
+func Chain(stages ...Stage) Stage {
+	return func(ctx context.Context, in <-chan Item) <-chan Item {
+		return Run(ctx, in, stages...)
+	}
+}
+
+// FromSlice returns the channel of the items of bins, in order.
+func FromSlice(ctx context.Context, bins []string) <-chan Item {
+	out := make(chan Item)
+	go func() {
+		defer close(out)
+
+		for i, bin := range bins {
+			if !send(ctx, out, Item{Index: i, BIN: bin}) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// FromChan returns the channel of the items of the BINs received from
+// bins, numbered in the order received, closing it once bins is closed or
+// ctx is done.
+func FromChan(ctx context.Context, bins <-chan string) <-chan Item {
+	out := make(chan Item)
+	go func() {
+		defer close(out)
+
+		for i := 0; ; i++ {
+			var bin string
+			var ok bool
+			select {
+			case bin, ok = <-bins:
+			case <-ctx.Done():
+				return
+			}
+			if !ok || !send(ctx, out, Item{Index: i, BIN: bin}) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Map returns the `Stage` passing the unresolved items through fn, on
+// workers goroutines at once, at least one. fn may leave them unresolved,
+// for the following stages to resolve.
+func Map(workers int, fn func(ctx context.Context, i Item) Item) Stage {
+	return func(ctx context.Context, in <-chan Item) <-chan Item {
+		out := make(chan Item)
+
+		var wg sync.WaitGroup
+		for range max(workers, 1) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for i := range in {
+					if !i.Resolved() {
+						i = fn(ctx, i)
+					}
+
+					if !send(ctx, out, i) {
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		return out
+	}
+}
+
+// Validate returns the `Stage` resolving the items whose BIN validate
+// fails on with its error, `binlookup.ValidateBIN` when nil.
+func Validate(validate func(bin string) error) Stage {
+	if validate == nil {
+		validate = binlookup.ValidateBIN
+	}
+
+	return Map(1, func(_ context.Context, i Item) Item {
+		i.Err = validate(i.BIN)
+		return i
+	})
+}
+
+// Limit returns the `Stage` holding every unresolved item back until w
+// allows it, such as a golang.org/x/time/rate.Limiter, for the lookups
+// following to stay within a quota. Items w fails to wait for are
+// resolved with its error.
+func Limit(w Waiter) Stage {
+	return Map(1, func(ctx context.Context, i Item) Item {
+		i.Err = w.Wait(ctx)
+		return i
+	})
+}
+
+// Waiter waits until an event is allowed, or ctx is done.
+type Waiter interface {
+	Wait(ctx context.Context) error
+}
+
+// Lookup returns the `Stage` resolving the unresolved items through l, on
+// workers goroutines at once.
+func Lookup(l binlookup.Lookuper, workers int) Stage {
+	return Map(workers, func(ctx context.Context, i Item) Item {
+		i.Result, i.Err = l.Search(ctx, i.BIN)
+		return i
+	})
+}
+
+// Dedupe returns the `Stage` passing the first occurrence only of every
+// BIN through inner, resolving those repeated with the result of the
+// first once inner resolved it, so that inner looks every BIN up once.
+// It keeps the results of the BINs seen, for streams of millions of items
+// to be held in memory bound by the number of distinct BINs.
+//
+// BINs are compared as they are: card numbers of the same BIN aren't
+// repeats of each other until truncated by a stage before.
+func Dedupe(inner Stage) Stage {
+	type seen struct {
+		done    bool
+		result  Item
+		waiting []Item
+	}
+
+	return func(ctx context.Context, in <-chan Item) <-chan Item {
+		var mu sync.Mutex
+		bins := make(map[string]*seen)
+
+		route := func(i Item) (out []Item, forward bool) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			s, ok := bins[i.BIN]
+			switch {
+			case !ok:
+				bins[i.BIN] = &seen{}
+				return nil, true
+			case s.done:
+				return []Item{resolve(i, s.result)}, false
+			}
+
+			s.waiting = append(s.waiting, i)
+			return nil, false
+		}
+
+		after := func(i Item) (out []Item) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			out = append(out, i)
+			if s, ok := bins[i.BIN]; ok && !s.done {
+				for _, w := range s.waiting {
+					out = append(out, resolve(w, i))
+				}
+				*s = seen{done: true, result: i}
+			}
+
+			return
+		}
+
+		flush := func() (out []Item) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, s := range bins {
+				for _, w := range s.waiting {
+					w.Err = ErrDropped
+					out = append(out, w)
+				}
+				s.waiting = nil
+			}
+
+			return
+		}
+
+		return wrap(ctx, in, inner, route, after, flush)
+	}
+}
+
+// Cached returns the `Stage` resolving the items whose BIN c holds fresh,
+// keyed by the BIN itself, and passing the others through inner, caching
+// their results for ttl: the BINs found, and those not found as such.
+// Failing to read or write c doesn't fail the items.
+func Cached(c binlookup.Cache, ttl time.Duration, inner Stage) Stage {
+	return func(ctx context.Context, in <-chan Item) <-chan Item {
+		route := func(i Item) ([]Item, bool) {
+			e, ok, err := c.Get(ctx, i.BIN)
+			if err != nil || !ok || !e.Fresh(time.Now()) {
+				return nil, true
+			}
+
+			switch {
+			case e.NotFound:
+				i.Err = binlookup.ErrNotFound
+			case e.BIN != nil:
+				i.Result = e.BIN
+			default:
+				return nil, true
+			}
+
+			return []Item{i}, false
+		}
+
+		after := func(i Item) []Item {
+			expires := time.Now().Add(ttl)
+			switch {
+			case i.Err == nil && i.Result != nil:
+				c.Set(ctx, i.BIN, binlookup.Entry{BIN: i.Result, Expires: expires})
+			case errors.Is(i.Err, binlookup.ErrNotFound):
+				c.Set(ctx, i.BIN, binlookup.Entry{NotFound: true, Expires: expires})
+			}
+
+			return []Item{i}
+		}
+
+		return wrap(ctx, in, inner, route, after, nil)
+	}
+}
+
+// wrap runs inner within a stage: route is given the items of in, to
+// output some at once or forward them to inner, whose items go through
+// after on their way out. flush, when set, outputs the last items once
+// inner is over. The items resolved go around inner.
+func wrap(ctx context.Context, in <-chan Item, inner Stage, route func(Item) ([]Item, bool), after func(Item) []Item, flush func() []Item) <-chan Item {
+	innerIn := make(chan Item)
+	innerOut := inner(ctx, innerIn)
+	out := make(chan Item)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		defer close(innerIn)
+
+		for i := range in {
+			if i.Resolved() {
+				if !send(ctx, out, i) {
+					return
+				}
+				continue
+			}
+
+			items, forward := route(i)
+			if forward && !send(ctx, innerIn, i) {
+				return
+			}
+			for _, o := range items {
+				if !send(ctx, out, o) {
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := range innerOut {
+			for _, o := range after(i) {
+				if !send(ctx, out, o) {
+					return
+				}
+			}
+		}
+
+		if flush == nil {
+			return
+		}
+		for _, o := range flush() {
+			if !send(ctx, out, o) {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// resolve returns i resolved with the result of r.
+func resolve(i, r Item) Item {
+	i.Result, i.Err = r.Result, r.Err
+	return i
+}
+
+// send sends i to out, reporting false when ctx is done first.
+func send(ctx context.Context, out chan<- Item, i Item) bool {
+	select {
+	case out <- i:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// collect drains out into a slice ordered by index.
+func collect(out <-chan Item) (items []Item) {
+	for i := range out {
+		items = append(items, i)
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].Index < items[b].Index })
+
+	return
+}
+
+// counting is a Lookuper counting the lookups of every BIN, finding those
+// starting with 4 only.
+type counting struct {
+	mu       sync.Mutex
+	searched map[string]int
+}
+
+func (c *counting) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.searched == nil {
+		c.searched = make(map[string]int)
+	}
+	c.searched[bin]++
+
+	if !strings.HasPrefix(bin, "4") {
+		return nil, binlookup.ErrNotFound
+	}
+
+	return &binlookup.BIN{Scheme: binlookup.SchemeVisa}, nil
+}
+
+type waiter struct{ n int }
+
+func (w *waiter) Wait(ctx context.Context) error {
+	w.n++
+	return ctx.Err()
+}
+
+func TestPipeline(t *testing.T) {
+	bins := []string{"45717360", "nope", "52882301", "45717360", "45717360", "52882301"}
+
+	l := &counting{}
+	w := &waiter{}
+	cache := binlookup.NewMemoryCache(0)
+
+	run := func() []Item {
+		return collect(Run(context.TODO(), FromSlice(context.TODO(), bins),
+			Validate(nil),
+			Dedupe(Cached(cache, time.Hour, Chain(Limit(w), Lookup(l, 4)))),
+		))
+	}
+
+	items := run()
+	if len(items) != len(bins) {
+		t.Fatalf("got %d items of %d", len(items), len(bins))
+	}
+
+	for i, it := range items {
+		if it.Index != i || it.BIN != bins[i] {
+			t.Fatalf("item %d is %+v", i, it)
+		}
+	}
+
+	if items[0].Result == nil || items[3].Result != items[0].Result || items[4].Result != items[0].Result {
+		t.Fatalf("repeated BINs resolved as %+v", items)
+	}
+
+	if !errors.Is(items[1].Err, binlookup.ErrInvalidBIN) || !errors.Is(items[2].Err, binlookup.ErrNotFound) || !errors.Is(items[5].Err, binlookup.ErrNotFound) {
+		t.Fatalf("unexpected items %+v", items)
+	}
+
+	if len(l.searched) != 2 || l.searched["45717360"] != 1 || l.searched["52882301"] != 1 || w.n != 2 {
+		t.Fatalf("searched %v after %d waits", l.searched, w.n)
+	}
+
+	// The second run is served out of the cache.
+	if items = run(); items[0].Result == nil || !errors.Is(items[2].Err, binlookup.ErrNotFound) || w.n != 2 {
+		t.Fatalf("cached run returned %+v after %d waits", items, w.n)
+	}
+}
+
+func TestDedupeDropped(t *testing.T) {
+	drop := func(ctx context.Context, in <-chan Item) <-chan Item {
+		out := make(chan Item)
+		go func() {
+			defer close(out)
+			for range in {
+			}
+		}()
+
+		return out
+	}
+
+	items := collect(Run(context.TODO(), FromSlice(context.TODO(), []string{"45717360", "45717360"}), Dedupe(drop)))
+	if len(items) != 1 || items[0].Index != 1 || !errors.Is(items[0].Err, ErrDropped) {
+		t.Fatalf("got %+v", items)
+	}
+}
+
+func TestPipelineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	bins := make(chan string)
+	out := Run(ctx, FromChan(ctx, bins), Lookup(&counting{}, 2))
+
+	bins <- "45717360"
+	if i := <-out; i.Result == nil {
+		t.Fatalf("got %+v", i)
+	}
+
+	cancel()
+	for range out {
+	}
+}
@@ -0,0 +1,28 @@
+package binlookup
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity past which buffers aren't pooled, for
+// the odd huge response not to stay around.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers the responses of upstream are read into.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
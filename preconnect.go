@@ -0,0 +1,120 @@
+package binlookup
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithPreconnect makes the `Client` establish n connections to upstream
+// as soon as it's constructed and keep them warm until `Close` is
+// called, so the first lookups don't pay the TLS handshake.
+//
+// The connections are established by HEAD requests to the base URL,
+// sent as lookups are: with the headers, API key and signature of the
+// `Client`, through its middleware and under its rate limit. Each counts
+// against the quota of upstream like a lookup: n once constructed, then n
+// again at three quarters of the idle timeout, see `WithIdleConnTimeout`,
+// only when lookups went upstream since the connections were last warmed,
+// for those of a busy `Client` not to expire between bursts. An idle
+// `Client` makes none, and with no idle timeout the connections are
+// established once only.
+//
+// Upstream speaking HTTP/2 multiplexes all the requests over a single
+// connection, in which case n beyond 1 makes no difference.
+func WithPreconnect(n int) Option {
+	return func(c *Client) {
+		c.preconnect = n
+	}
+}
+
+// keepWarm warms the connections of c, then again before they'd expire
+// idle when lookups went upstream meanwhile, until c is closed.
+func (c *Client) keepWarm() {
+	c.warm()
+
+	c.reconf.RLock()
+	idle := c.idleConnTimeout
+	c.reconf.RUnlock()
+	if idle <= 0 {
+		return
+	}
+
+	t := time.NewTicker(idle * 3 / 4)
+	defer t.Stop()
+
+	last := c.upstream.Load()
+	for {
+		select {
+		case <-t.C:
+			if n := c.upstream.Load(); n != last {
+				last = n
+				c.warm()
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// warm sends c.preconnect concurrent HEAD requests to upstream, leaving
+// as many connections idle in the pool of the transport. The
+// configuration of c is held while the requests are prepared only, for
+// `Client.Reconfigure` not to wait on the rate limit or upstream.
+func (c *Client) warm() {
+	c.reconf.RLock()
+	n, limiter, url := c.preconnect, c.limiter, c.baseURL+"/"
+	c.reconf.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if limiter != nil {
+				if err := limiter.wait(ctx); err != nil {
+					return
+				}
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return
+			}
+
+			c.reconf.RLock()
+			keys, doer := c.keys, c.doer
+			key, err := c.prepare(req)
+			c.reconf.RUnlock()
+			if key != nil {
+				defer keys.release(key)
+			}
+			if err != nil {
+				return
+			}
+
+			resp, err := doer.Do(req)
+			if err != nil {
+				return
+			}
+
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+}
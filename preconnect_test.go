@@ -0,0 +1,98 @@
+package binlookup
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithPreconnect(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		conns int
+		ready = make(chan struct{})
+	)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-ready
+	}))
+	srv.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	c := New(WithPreconnect(3), WithBaseURL(srv.URL))
+	defer c.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := conns
+		mu.Unlock()
+
+		if n == 3 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections were established, want 3", n)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(ready)
+}
+
+func TestWithPreconnectThroughMiddleware(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		heads []http.Header
+	)
+	record := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			if req.Method == http.MethodHead {
+				heads = append(heads, req.Header.Clone())
+			}
+			mu.Unlock()
+			return canned(http.StatusOK, "")(next).Do(req)
+		})
+	}
+
+	c := New(WithPreconnect(1), WithIdleConnTimeout(40*time.Millisecond), WithHeader("X-Tenant", "acme"), WithMiddleware(record))
+	defer c.Close()
+
+	warmed := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(heads)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := warmed(); n != 1 {
+		t.Fatalf("sent %d warming requests while idle, want 1", n)
+	}
+
+	c.Search(context.TODO(), CorrectBIN)
+	time.Sleep(60 * time.Millisecond)
+	if n := warmed(); n != 2 {
+		t.Fatalf("sent %d warming requests after a lookup, want them sent again before the idle timeout", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if heads[0].Get("X-Tenant") != "acme" || heads[0].Get("User-Agent") != DefaultUserAgent {
+		t.Fatalf("warming request sent with %v", heads[0])
+	}
+}
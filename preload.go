@@ -0,0 +1,104 @@
+package binlookup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Preload looks bins up ahead of time to fill the cache of c, such as
+// with the most common BINs of the day before at startup, so that the
+// first customers don't pay for the lookups of a cold cache. BINs cached
+// and still fresh cost no request, and the others are looked up one after
+// the other within the rate limit of c, see `WithRateLimit`.
+//
+// BINs not found upstream are cached as such and aren't failures. The
+// lookups failing otherwise don't stop the others, and their errors are
+// returned joined. Preload stops when ctx is done or c is closed,
+// returning the error of the lookup interrupted. Without a cache it does
+// nothing.
+func (c *Client) Preload(ctx context.Context, bins []string) error {
+	if !c.caching() {
+		return nil
+	}
+
+	var errs []error
+	for _, bin := range bins {
+		_, err := c.Search(ctx, bin)
+		switch {
+		case err == nil, errors.Is(err, ErrNotFound):
+		case ctx.Err() != nil, errors.Is(err, ErrClosed):
+			return fmt.Errorf("Preloading Failed: %w", err)
+		default:
+			errs = append(errs, fmt.Errorf("Preloading %v Failed: %w", Redact(bin), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PreloadFile preloads the BINs listed in the file at path, see `ReadBINs`,
+// such as a report of the most common ones, the way `Preload` does. It's
+// meant for startup: the lookups are made one after the other, paced by
+// the rate limit of c, and onProgress, when set, is given the `Progress`
+// every second and once over, for it to be logged.
+func (c *Client) PreloadFile(ctx context.Context, path string, onProgress func(p Progress)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Preloading Failed: %w", err)
+	}
+	defer f.Close()
+
+	bins, err := ReadBINs(f)
+	if err != nil {
+		return fmt.Errorf("Preloading Failed: %w", err)
+	}
+
+	if !c.caching() {
+		return nil
+	}
+
+	var errs []error
+	e := &Enricher{Lookuper: c, Workers: 1, OnProgress: onProgress}
+	err = e.Enrich(ctx, bins, func(i int, r Result) {
+		if r.Err != nil && !errors.Is(r.Err, ErrNotFound) && ctx.Err() == nil {
+			errs = append(errs, fmt.Errorf("Preloading %v Failed: %w", Redact(bins[i]), r.Err))
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("Preloading Failed: %w", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReadBINs reads a list of BINs, one per line. Blank lines and those
+// starting with # are skipped, and so are the BINs listed already.
+func ReadBINs(r io.Reader) (bins []string, err error) {
+	seen := make(map[string]bool)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		bin := strings.TrimSpace(sc.Text())
+		if bin == "" || strings.HasPrefix(bin, "#") || seen[bin] {
+			continue
+		}
+
+		seen[bin] = true
+		bins = append(bins, bin)
+	}
+
+	return bins, sc.Err()
+}
+
+// caching reports whether c has a cache.
+func (c *Client) caching() bool {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	return c.cache != nil
+}
@@ -0,0 +1,82 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientPreload(t *testing.T) {
+	var requests []string
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, strings.TrimPrefix(req.URL.Path, "/"))
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	cache.Set(context.TODO(), "52882301", Entry{BIN: &BIN{Scheme: SchemeMastercard}, Expires: time.Now().Add(time.Hour)})
+
+	c := New(WithCache(cache), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+
+	err := c.Preload(context.TODO(), []string{"45717360", "52882301", "4ab"})
+	if !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("Preload returned %v, want the error of the invalid BIN", err)
+	}
+
+	if strings.Join(requests, ",") != "45717360" {
+		t.Fatalf("Preload requested %v, want the uncached BIN only", requests)
+	}
+
+	if e, ok, _ := cache.Get(context.TODO(), "45717360"); !ok || e.BIN == nil {
+		t.Fatal("preloaded BIN isn't cached")
+	}
+}
+
+func TestClientPreloadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bins.txt")
+	if err := os.WriteFile(path, []byte("# top BINs\n45717360\n\n 52882301\n45717360\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []string
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, strings.TrimPrefix(req.URL.Path, "/"))
+			return next.Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+
+	var last Progress
+	if err := c.PreloadFile(context.TODO(), path, func(p Progress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(requests, ",") != "45717360,52882301" || cache.Len() != 2 {
+		t.Fatalf("PreloadFile requested %v", requests)
+	}
+	if last.Done != 2 || last.Remaining != 0 {
+		t.Fatalf("last progress reported is %+v", last)
+	}
+
+	if err := c.PreloadFile(context.TODO(), filepath.Join(t.TempDir(), "missing"), nil); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("PreloadFile of a missing file returned %v", err)
+	}
+}
+
+func TestReadBINs(t *testing.T) {
+	bins, err := ReadBINs(strings.NewReader("457173\r\n# comment\n\n5288\n457173\n"))
+	if want := []string{"457173", "5288"}; err != nil || !reflect.DeepEqual(bins, want) {
+		t.Fatalf("read %q, %v, want %q", bins, err, want)
+	}
+}
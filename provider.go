@@ -0,0 +1,164 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Lookuper is the interface implemented by everything looking BINs up,
+// `Client` and `Provider` included. Application code depending on it
+// rather than on `Client` swaps in fakes in its unit tests, such as a
+// `LookuperFunc`.
+type Lookuper interface {
+	Search(ctx context.Context, bin string) (*BIN, error)
+}
+
+// LookuperFunc is an adapter to allow the use of ordinary functions as
+// `Lookuper`.
+type LookuperFunc func(ctx context.Context, bin string) (*BIN, error)
+
+// Search calls f(ctx, bin).
+func (f LookuperFunc) Search(ctx context.Context, bin string) (*BIN, error) {
+	return f(ctx, bin)
+}
+
+var _ Lookuper = (*Client)(nil)
+
+// Provider is a source of BIN data. `Client`, backed by binlist or a
+// mirror of it, is one.
+type Provider interface {
+	Lookuper
+	Capabilities() Capabilities
+}
+
+// Capabilities describes what a `Provider` is able to serve, so that
+// composite strategies such as `Chain` route lookups accordingly.
+type Capabilities struct {
+	// EightDigit is set when the provider resolves 8 digit BINs. The
+	// others are given the first 6 digits only.
+	EightDigit bool
+
+	// Batch is set when the provider resolves several BINs per request.
+	Batch bool
+
+	// BankData is set when the provider returns the `Bank` of a BIN.
+	BankData bool
+
+	// Offline is set when the provider resolves BINs without any
+	// network call.
+	Offline bool
+}
+
+// Name returns the host of the upstream c looks up against.
+func (c *Client) Name() string {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	return c.name()
+}
+
+// name is `Client.Name`, for c to name itself with its configuration held.
+func (c *Client) name() string {
+	if u, err := url.Parse(c.baseURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+
+	return c.baseURL
+}
+
+// Capabilities reports binlist's: 8 digit BINs, unless sending 6 digits
+// only, and bank data, one BIN per request over the network, unless the
+// network is disabled.
+func (c *Client) Capabilities() Capabilities {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	return Capabilities{EightDigit: c.binDigits != SixDigits, BankData: true, Offline: c.offline || c.dryRun}
+}
+
+// Named gives p the name reported in `Meta` and used by `Merge` to
+// attribute fields.
+func Named(name string, p Provider) Provider {
+	return &named{Provider: p, name: name}
+}
+
+type named struct {
+	Provider
+	name string
+}
+
+func (n *named) Name() string {
+	return n.name
+}
+
+func (n *named) Search(ctx context.Context, bin string) (b *BIN, err error) {
+	if b, err = n.Provider.Search(ctx, bin); err == nil && b != nil {
+		b.Meta.Provider = n.name
+	}
+
+	return
+}
+
+// ProviderName returns the name of p: the result of its Name method when
+// it has one, such as those returned by `Named`, or its type otherwise.
+func ProviderName(p Provider) string {
+	if n, ok := p.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+
+	return fmt.Sprintf("%T", p)
+}
+
+// Chain returns a `Provider` trying each of ps in turn until one resolves
+// the BIN. BINs longer than 6 digits are truncated to 6 for the providers
+// lacking EightDigit. A malformed BIN or a canceled context stops the
+// chain, any other error moves on to the next provider; the error of the
+// last one is returned when none succeeds.
+func Chain(ps ...Provider) Provider {
+	return chain(ps)
+}
+
+type chain []Provider
+
+func (ps chain) Search(ctx context.Context, bin string) (b *BIN, err error) {
+	err = ErrNotFound
+	for _, p := range ps {
+		b, err = p.Search(ctx, truncate(p, bin))
+		if err == nil {
+			return
+		}
+
+		if errors.Is(err, ErrInvalidBIN) || ctx.Err() != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// truncate returns bin truncated to the 6 digits p resolves when it
+// lacks EightDigit.
+func truncate(p Provider, bin string) string {
+	if len(bin) > 6 && !p.Capabilities().EightDigit {
+		return bin[:6]
+	}
+
+	return bin
+}
+
+// Capabilities of a chain are those of its providers combined: it has a
+// capability any of them has, but is only Offline if all of them are.
+// It never batches.
+func (ps chain) Capabilities() (c Capabilities) {
+	c.Offline = len(ps) > 0
+	for _, p := range ps {
+		pc := p.Capabilities()
+		c.EightDigit = c.EightDigit || pc.EightDigit
+		c.BankData = c.BankData || pc.BankData
+		c.Offline = c.Offline && pc.Offline
+	}
+
+	return
+}
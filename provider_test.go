@@ -0,0 +1,100 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubProvider answers every lookup with b or err, recording the BINs
+// it was asked for.
+type stubProvider struct {
+	b    *BIN
+	err  error
+	caps Capabilities
+	bins []string
+}
+
+func (s *stubProvider) Search(ctx context.Context, bin string) (*BIN, error) {
+	s.bins = append(s.bins, bin)
+	return s.b, s.err
+}
+
+func (s *stubProvider) Capabilities() Capabilities {
+	return s.caps
+}
+
+func TestChain(t *testing.T) {
+	first := &stubProvider{err: ErrNotFound}
+	second := &stubProvider{b: &BIN{Scheme: "visa"}, caps: Capabilities{Offline: true}}
+	third := &stubProvider{b: &BIN{Scheme: "mastercard"}}
+
+	b, err := Chain(first, second, third).Search(context.TODO(), "45717360")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Scheme != "visa" {
+		t.Fatalf("chain answered with %+v, want the second provider's", b)
+	}
+
+	if len(first.bins) != 1 || first.bins[0] != "457173" || second.bins[0] != "457173" {
+		t.Fatalf("providers without EightDigit were asked for %v and %v", first.bins, second.bins)
+	}
+
+	if len(third.bins) != 0 {
+		t.Fatal("chain kept going after a provider resolved the BIN")
+	}
+}
+
+func TestChainStopsOnInvalidBIN(t *testing.T) {
+	first := &stubProvider{err: ErrInvalidBIN}
+	second := &stubProvider{b: &BIN{}}
+
+	if _, err := Chain(first, second).Search(context.TODO(), IncorrectBIN); !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("chain returned %v, want ErrInvalidBIN", err)
+	}
+
+	if len(second.bins) != 0 {
+		t.Fatal("chain kept going after an invalid BIN")
+	}
+}
+
+func TestChainCapabilities(t *testing.T) {
+	c := Chain(
+		&stubProvider{caps: Capabilities{Offline: true, EightDigit: true}},
+		&stubProvider{caps: Capabilities{BankData: true}},
+	).Capabilities()
+
+	if !c.EightDigit || !c.BankData || c.Offline || c.Batch {
+		t.Fatalf("unexpected chain capabilities %+v", c)
+	}
+}
+
+// checkout depends on a `Lookuper` the way application code would.
+func checkout(l Lookuper, bin string) (string, error) {
+	b, err := l.Search(context.TODO(), bin)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b.Scheme), nil
+}
+
+func TestLookuperFunc(t *testing.T) {
+	fake := LookuperFunc(func(_ context.Context, bin string) (*BIN, error) {
+		if bin != CorrectBIN {
+			return nil, ErrNotFound
+		}
+
+		return &BIN{Scheme: SchemeMastercard}, nil
+	})
+
+	if s, err := checkout(fake, CorrectBIN); err != nil || s != "mastercard" {
+		t.Fatalf("checkout returned %q, %v", s, err)
+	}
+
+	if _, err := checkout(fake, "45717360"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("checkout returned %v, want the error of the fake", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Package providertest is the conformance suite of `binlookup.Provider`
+// implementations, for third-party adapters to prove they behave as the
+// composite strategies of binlookup, such as `binlookup.Chain`, expect:
+//
+//	func TestConformance(t *testing.T) {
+//		providertest.Run(t, providertest.Config{Known: "45717360", Unknown: "99999999"},
+//			func(t *testing.T, rt http.RoundTripper) binlookup.Provider {
+//				return myprovider.New(myprovider.WithTransport(rt))
+//			})
+//	}
+package providertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// PAN is the card number the suite looks up to check that no more than
+// the BIN of a card number leaves the process.
+const PAN = "4571736012345678"
+
+// errTransport is the failure of the transport the suite injects, which
+// errors of providers must wrap.
+var errTransport = errors.New("Injected Transport Failure")
+
+// Config tells the suite about the provider checked.
+type Config struct {
+	// Known is a BIN the provider resolves.
+	Known string
+
+	// Unknown is a well-formed BIN the provider doesn't know of.
+	Unknown string
+
+	// Transport is where the requests of the provider go after the suite
+	// recorded them, http.DefaultTransport when nil. Tests point it at a
+	// fake upstream rather than the real one.
+	Transport http.RoundTripper
+
+	// Timeout bounds how long the provider takes to give up once its
+	// context is done, a second when zero.
+	Timeout time.Duration
+}
+
+// Run checks the provider newProvider returns, which must send its
+// requests through rt, asserting that:
+//
+//   - Known is resolved and Unknown fails with `binlookup.ErrNotFound`.
+//   - Malformed BINs fail with `binlookup.ErrInvalidBIN`.
+//   - Lookups stop promptly once their context is canceled or past its
+//     deadline, failing with its error.
+//   - The failures of the transport are wrapped, not swallowed.
+//   - No more than 8 digits of a card number end up in the URLs
+//     requested, nor any of it in the errors returned.
+//
+// Each check is a subtest given a provider of its own. Those about the
+// network are skipped for the providers reporting Offline capabilities.
+func Run(t *testing.T, cfg Config, newProvider func(t *testing.T, rt http.RoundTripper) binlookup.Provider) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+
+	setup := func(t *testing.T) (binlookup.Provider, *transport) {
+		rt := &transport{next: cfg.Transport}
+		if rt.next == nil {
+			rt.next = http.DefaultTransport
+		}
+
+		return newProvider(t, rt), rt
+	}
+
+	t.Run("Known", func(t *testing.T) {
+		p, _ := setup(t)
+
+		b, err := p.Search(context.TODO(), cfg.Known)
+		if err != nil || b == nil {
+			t.Fatalf("Search(%q) returned %v, %v", cfg.Known, b, err)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		p, _ := setup(t)
+
+		if _, err := p.Search(context.TODO(), cfg.Unknown); !errors.Is(err, binlookup.ErrNotFound) {
+			t.Fatalf("Search(%q) returned %v, want binlookup.ErrNotFound", cfg.Unknown, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		p, _ := setup(t)
+
+		for _, bin := range []string{"", "0457173", "4571-73", "45717a"} {
+			if _, err := p.Search(context.TODO(), bin); !errors.Is(err, binlookup.ErrInvalidBIN) {
+				t.Errorf("Search(%q) returned %v, want binlookup.ErrInvalidBIN", bin, err)
+			}
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		p, rt := setup(t)
+		if p.Capabilities().Offline {
+			t.Skip("provider is offline")
+		}
+		rt.hang()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		err := within(t, cfg.Timeout, func() error {
+			_, err := p.Search(ctx, cfg.Known)
+			return err
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("canceled Search returned %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		p, rt := setup(t)
+		if p.Capabilities().Offline {
+			t.Skip("provider is offline")
+		}
+		rt.hang()
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+		defer cancel()
+
+		err := within(t, cfg.Timeout, func() error {
+			_, err := p.Search(ctx, cfg.Known)
+			return err
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Search past its deadline returned %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("TransportFailure", func(t *testing.T) {
+		p, rt := setup(t)
+		if p.Capabilities().Offline {
+			t.Skip("provider is offline")
+		}
+		rt.fail()
+
+		if _, err := p.Search(context.TODO(), cfg.Known); !errors.Is(err, errTransport) {
+			t.Fatalf("Search over a failing transport returned %v, want it wrapped", err)
+		}
+	})
+
+	t.Run("PAN", func(t *testing.T) {
+		p, rt := setup(t)
+
+		_, err := p.Search(context.TODO(), PAN)
+		if err != nil && strings.Contains(err.Error(), PAN[6:]) {
+			t.Errorf("error holds the card number: %v", err)
+		}
+
+		for _, u := range rt.urls() {
+			if strings.Contains(u, PAN[:9]) {
+				t.Errorf("more than 8 digits of the card number requested: %v", u)
+			}
+		}
+	})
+}
+
+// within runs f, failing t when it doesn't return within d.
+func within(t *testing.T, d time.Duration, f func() error) error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(d):
+		t.Fatalf("Search didn't return within %v of its context being done", d)
+		return nil
+	}
+}
+
+// transport records the URLs requested before sending the requests to
+// next, hanging or failing them when asked to.
+type transport struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	seen    []string
+	hanging bool
+	failing bool
+}
+
+func (rt *transport) hang() {
+	rt.mu.Lock()
+	rt.hanging = true
+	rt.mu.Unlock()
+}
+
+func (rt *transport) fail() {
+	rt.mu.Lock()
+	rt.failing = true
+	rt.mu.Unlock()
+}
+
+func (rt *transport) urls() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return append([]string(nil), rt.seen...)
+}
+
+func (rt *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.seen = append(rt.seen, req.URL.String())
+	hanging, failing := rt.hanging, rt.failing
+	rt.mu.Unlock()
+
+	switch {
+	case failing:
+		return nil, errTransport
+	case hanging:
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+
+	return rt.next.RoundTrip(req)
+}
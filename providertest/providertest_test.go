@@ -0,0 +1,53 @@
+package providertest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+	"github.com/0xbkt/binlookup-go/binlookuptest"
+)
+
+func TestRunClient(t *testing.T) {
+	srv := binlookuptest.NewServer()
+	defer srv.Close()
+
+	srv.Add("45717360", binlookuptest.JyskeBank)
+
+	Run(t, Config{Known: "45717360", Unknown: "99999999", Transport: http.DefaultTransport},
+		func(t *testing.T, rt http.RoundTripper) binlookup.Provider {
+			c := srv.Client(binlookup.WithTransport(rt))
+			t.Cleanup(func() { c.Close() })
+
+			return c
+		})
+}
+
+// offline is a `binlookup.Provider` answering out of memory.
+type offline map[string]*binlookup.BIN
+
+func (o offline) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	if err := binlookup.ValidateBIN(bin); err != nil {
+		return nil, err
+	}
+
+	if b, ok := o[bin[:min(len(bin), 8)]]; ok {
+		return b, nil
+	}
+
+	return nil, binlookup.ErrNotFound
+}
+
+func (o offline) Capabilities() binlookup.Capabilities {
+	return binlookup.Capabilities{EightDigit: true, Offline: true}
+}
+
+func TestRunOffline(t *testing.T) {
+	p := offline{"45717360": {Scheme: binlookup.SchemeVisa}}
+
+	Run(t, Config{Known: "45717360", Unknown: "99999999"},
+		func(t *testing.T, rt http.RoundTripper) binlookup.Provider {
+			return p
+		})
+}
@@ -0,0 +1,197 @@
+package binlookup
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultProxyCheckInterval is how often `ProxyPool.Run` checks the health
+// of the proxies, unless changed through its Interval.
+const DefaultProxyCheckInterval = 30 * time.Second
+
+// ProxyPool rotates the requests of a `Client` over several outbound
+// proxies, a proxy per request in turn, for the traffic to upstream to be
+// spread over the gateways of an egress. The proxies failing their health
+// checks are left out of the rotation until they pass again; when all of
+// them fail, the rotation goes on over them all rather than failing the
+// requests on checks which may be wrong.
+//
+// Its Proxy is the one to give to `WithProxy`, and Run the loop checking
+// the health of the proxies:
+//
+//	pool := binlookup.NewProxyPool(gateways...)
+//	go pool.Run(ctx)
+//	c := binlookup.New(binlookup.WithProxy(pool.Proxy))
+//
+// It's safe for concurrent use.
+type ProxyPool struct {
+	// Check checks that proxy is healthy. It dials the proxy when nil,
+	// within 5 seconds unless ctx ends earlier.
+	Check func(ctx context.Context, proxy *url.URL) error
+
+	// Interval is how often Run checks the proxies,
+	// `DefaultProxyCheckInterval` when zero.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	proxies []*poolProxy
+	turn    int
+}
+
+// ProxyState is a proxy of a `ProxyPool` along with its health.
+type ProxyState struct {
+	// URL is that of the proxy, its password redacted.
+	URL string
+
+	// Healthy is false once the proxy failed its last check, with Err.
+	Healthy bool
+	Err     error
+
+	// Requests counts those sent through the proxy, and CheckedAt is
+	// when it was last checked, zero before any check.
+	Requests  int64
+	CheckedAt time.Time
+}
+
+type poolProxy struct {
+	url       *url.URL
+	err       error
+	requests  int64
+	checkedAt time.Time
+}
+
+// NewProxyPool returns a `ProxyPool` rotating over proxies, all of them
+// healthy until checked.
+func NewProxyPool(proxies ...*url.URL) *ProxyPool {
+	p := &ProxyPool{}
+	for _, u := range proxies {
+		p.proxies = append(p.proxies, &poolProxy{url: u})
+	}
+
+	return p
+}
+
+// Proxy returns the proxy of the next request, skipping those unhealthy,
+// as http.Transport's Proxy. It returns a nil URL, for the request to go
+// direct, when p has no proxies.
+func (p *ProxyPool) Proxy(*http.Request) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.proxies) == 0 {
+		return nil, nil
+	}
+
+	pick := p.turn % len(p.proxies)
+	for j := range p.proxies {
+		if i := (p.turn + j) % len(p.proxies); p.proxies[i].err == nil {
+			pick = i
+			break
+		}
+	}
+
+	pp := p.proxies[pick]
+	p.turn = pick + 1
+	pp.requests++
+
+	return pp.url, nil
+}
+
+// Run checks the proxies every Interval, at once first, until ctx is
+// done, returning its error.
+func (p *ProxyPool) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultProxyCheckInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		p.CheckAll(ctx)
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CheckAll checks the proxies at once, returning once all of them are
+// checked.
+func (p *ProxyPool) CheckAll(ctx context.Context) {
+	p.mu.Lock()
+	proxies := append([]*poolProxy(nil), p.proxies...)
+	p.mu.Unlock()
+
+	check := p.Check
+	if check == nil {
+		check = dialProxy
+	}
+
+	var wg sync.WaitGroup
+	for _, pp := range proxies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := check(ctx, pp.url)
+			if ctx.Err() != nil {
+				return
+			}
+
+			p.mu.Lock()
+			pp.err, pp.checkedAt = err, time.Now()
+			p.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// States returns the proxies of p, in order, along with their health.
+func (p *ProxyPool) States() []ProxyState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	states := make([]ProxyState, len(p.proxies))
+	for i, pp := range p.proxies {
+		states[i] = ProxyState{
+			URL:       pp.url.Redacted(),
+			Healthy:   pp.err == nil,
+			Err:       pp.err,
+			Requests:  pp.requests,
+			CheckedAt: pp.checkedAt,
+		}
+	}
+
+	return states
+}
+
+// dialProxy checks proxy by opening a TCP connection to it.
+func dialProxy(ctx context.Context, proxy *url.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	port := proxy.Port()
+	if port == "" {
+		port = "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		} else if proxy.Scheme == "socks5" || proxy.Scheme == "socks5h" {
+			port = "1080"
+		}
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(proxy.Hostname(), port))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,92 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProxyPool(t *testing.T) {
+	a, b, c := &url.URL{Scheme: "http", Host: "a:3128"}, &url.URL{Scheme: "http", Host: "b:3128"}, &url.URL{Scheme: "http", User: url.UserPassword("user", "secret"), Host: "c:3128"}
+	p := NewProxyPool(a, b, c)
+
+	next := func() string {
+		u, err := p.Proxy(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Host
+	}
+
+	for _, want := range []string{"a:3128", "b:3128", "c:3128", "a:3128"} {
+		if got := next(); got != want {
+			t.Fatalf("proxied through %v, want %v", got, want)
+		}
+	}
+
+	p.Check = func(_ context.Context, proxy *url.URL) error {
+		if proxy.Host == "b:3128" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	p.CheckAll(context.TODO())
+
+	for _, want := range []string{"c:3128", "a:3128", "c:3128"} {
+		if got := next(); got != want {
+			t.Fatalf("proxied through %v with b unhealthy, want %v", got, want)
+		}
+	}
+
+	states := p.States()
+	if len(states) != 3 || states[1].Healthy || states[1].Err == nil || states[2].URL != "http://user:xxxxx@c:3128" || states[0].Requests != 3 {
+		t.Fatalf("unexpected states %+v", states)
+	}
+
+	p.Check = func(context.Context, *url.URL) error { return errors.New("down") }
+	p.CheckAll(context.TODO())
+	if got := next(); got != "a:3128" {
+		t.Fatalf("proxied through %v with every proxy unhealthy, want the rotation to go on", got)
+	}
+}
+
+func TestProxyPoolClient(t *testing.T) {
+	var proxied int
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied++
+		w.Write([]byte(cannedBIN))
+	}))
+	defer proxy.Close()
+
+	u, _ := url.Parse(proxy.URL)
+	p := NewProxyPool(u)
+	p.CheckAll(context.TODO())
+	if s := p.States(); !s[0].Healthy {
+		t.Fatalf("proxy listening failed its check: %v", s[0].Err)
+	}
+
+	c := New(WithBaseURL("http://upstream.invalid"), WithProxy(p.Proxy))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if proxied != 1 {
+		t.Fatalf("%d requests proxied, want 1", proxied)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	p = NewProxyPool(&url.URL{Scheme: "http", Host: addr})
+	p.CheckAll(context.TODO())
+	if s := p.States(); s[0].Healthy {
+		t.Fatal("proxy not listening passed its check")
+	}
+}
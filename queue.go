@@ -0,0 +1,163 @@
+package binlookup
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// OverflowPolicy is what a `Client` does with the lookups arriving at a
+// full queue, see `WithQueue`.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the lookups arriving at a full queue wait for
+	// room in it, bounded by their context only, the queue holding no
+	// more than depth lookups still, or one with a depth of 0.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest fails the lookup waiting the longest with
+	// `ErrQueueFull`, making room for the one arriving. It favours fresh
+	// lookups, whose callers are the likeliest to still be waiting.
+	OverflowDropOldest
+
+	// OverflowReject fails the lookups arriving at a full queue with
+	// `ErrQueueFull`.
+	OverflowReject
+)
+
+// WithQueue bounds the requests the `Client` has in flight to n, the
+// others waiting in a queue of up to depth lookups for their turn, in
+// order. When the queue is full, policy applies. Lookups served from cache
+// don't queue.
+//
+// Under sustained overload this turns an unbounded pile up of goroutines
+// waiting on upstream into backpressure the callers can act on, such as
+// by shedding load upon `ErrQueueFull`.
+func WithQueue(n, depth int, policy OverflowPolicy) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			c.queue = nil
+			return
+		}
+
+		c.queue = &queue{limit: n, depth: depth, policy: policy, waiting: list.New()}
+		if policy == OverflowBlock {
+			c.queue.room = make(chan struct{}, max(depth, 1))
+		}
+	}
+}
+
+// queue admits up to limit lookups at once, queueing the others.
+type queue struct {
+	limit  int
+	depth  int
+	policy OverflowPolicy
+
+	// room holds a token per lookup waiting with OverflowBlock, those
+	// arriving at a full queue blocking on it for their place.
+	room chan struct{}
+
+	mu      sync.Mutex
+	active  int
+	waiting *list.List
+}
+
+type waiter struct {
+	ready chan struct{}
+	err   error
+	el    *list.Element
+}
+
+// acquire waits for the turn of a lookup, which must call release when it's
+// over unless acquire failed.
+func (q *queue) acquire(ctx context.Context) error {
+	q.mu.Lock()
+	if q.active < q.limit && q.waiting.Len() == 0 {
+		q.active++
+		q.mu.Unlock()
+		return nil
+	}
+
+	if q.room != nil {
+		q.mu.Unlock()
+		select {
+		case q.room <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		q.mu.Lock()
+		if q.active < q.limit && q.waiting.Len() == 0 {
+			q.active++
+			q.mu.Unlock()
+			<-q.room
+			return nil
+		}
+	} else if q.waiting.Len() >= q.depth {
+		switch q.policy {
+		case OverflowReject:
+			q.mu.Unlock()
+			return ErrQueueFull
+		case OverflowDropOldest:
+			if q.waiting.Len() == 0 {
+				q.mu.Unlock()
+				return ErrQueueFull
+			}
+
+			oldest := q.waiting.Remove(q.waiting.Front()).(*waiter)
+			oldest.el, oldest.err = nil, ErrQueueFull
+			close(oldest.ready)
+		}
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	w.el = q.waiting.PushBack(w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	if w.el != nil {
+		q.waiting.Remove(w.el)
+		q.leave()
+		q.mu.Unlock()
+		return ctx.Err()
+	}
+	q.mu.Unlock()
+
+	// The turn came along with ctx being done.
+	if w.err == nil {
+		q.release()
+	}
+
+	return ctx.Err()
+}
+
+// release hands the turn of a lookup over to the one waiting the longest.
+func (q *queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiting.Len() == 0 {
+		q.active--
+		return
+	}
+
+	next := q.waiting.Remove(q.waiting.Front()).(*waiter)
+	next.el = nil
+	q.leave()
+	close(next.ready)
+}
+
+// leave frees the place of a lookup leaving the queue with OverflowBlock,
+// for one arriving at the full queue to take.
+func (q *queue) leave() {
+	if q.room != nil {
+		<-q.room
+	}
+}
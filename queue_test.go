@@ -0,0 +1,123 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// held makes the requests wait until release is closed, signaling started
+// as each one starts.
+func held(started chan<- struct{}, release <-chan struct{}) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+			return next.Do(req)
+		})
+	}
+}
+
+func TestClientWithQueue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy OverflowPolicy
+		// want are the errors of the queued lookup and the one arriving
+		// at the full queue.
+		queued, overflow error
+	}{
+		"Block":      {OverflowBlock, nil, nil},
+		"DropOldest": {OverflowDropOldest, ErrQueueFull, nil},
+		"Reject":     {OverflowReject, nil, ErrQueueFull},
+	} {
+		t.Run(name, func(t *testing.T) {
+			started, release := make(chan struct{}, 3), make(chan struct{})
+			c := New(WithQueue(1, 1, tc.policy), WithMiddleware(held(started, release), canned(http.StatusOK, cannedBIN)))
+
+			search := func() <-chan error {
+				done := make(chan error, 1)
+				go func() {
+					_, err := c.Search(context.TODO(), CorrectBIN)
+					done <- err
+				}()
+
+				return done
+			}
+
+			first := search()
+			<-started
+
+			queued := search()
+			time.Sleep(20 * time.Millisecond)
+			overflow := search()
+			time.Sleep(20 * time.Millisecond)
+
+			close(release)
+
+			if err := <-first; err != nil {
+				t.Fatalf("lookup in flight failed: %v", err)
+			}
+			if err := <-queued; !errors.Is(err, tc.queued) {
+				t.Fatalf("queued lookup returned %v, want %v", err, tc.queued)
+			}
+			if err := <-overflow; !errors.Is(err, tc.overflow) {
+				t.Fatalf("overflowing lookup returned %v, want %v", err, tc.overflow)
+			}
+		})
+	}
+}
+
+func TestClientWithQueueCanceled(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	defer close(release)
+
+	c := New(WithQueue(1, 1, OverflowReject), WithMiddleware(held(started, release), canned(http.StatusOK, cannedBIN)))
+
+	go c.Search(context.TODO(), CorrectBIN)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, CorrectBIN); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("queued lookup past its deadline returned %v", err)
+	}
+
+	// The lookup given up left the queue, making room.
+	ctx, cancel = context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, CorrectBIN); errors.Is(err, ErrQueueFull) {
+		t.Fatal("lookup given up still holds its place in the queue")
+	}
+}
+
+func TestClientWithQueueBlockDepth(t *testing.T) {
+	started, release := make(chan struct{}, 4), make(chan struct{})
+	c := New(WithQueue(1, 2, OverflowBlock), WithMiddleware(held(started, release), canned(http.StatusOK, cannedBIN)))
+
+	done := make(chan error, 4)
+	for range 4 {
+		go func() {
+			_, err := c.Search(context.TODO(), CorrectBIN)
+			done <- err
+		}()
+	}
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	c.queue.mu.Lock()
+	n := c.queue.waiting.Len()
+	c.queue.mu.Unlock()
+	if n != 2 {
+		t.Fatalf("%d lookups queued, want the depth of 2", n)
+	}
+
+	close(release)
+	for range 4 {
+		if err := <-done; err != nil {
+			t.Fatalf("lookup blocked on the full queue failed: %v", err)
+		}
+	}
+}
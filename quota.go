@@ -0,0 +1,361 @@
+package binlookup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota is the request allowance of an upstream, normalized from however
+// the provider reports it.
+type Quota struct {
+	// Limit is the number of requests allowed in Window.
+	Limit int
+
+	// Remaining is the number of requests left until ResetAt.
+	Remaining int
+
+	// ResetAt is when Remaining is replenished. It's zero when unknown.
+	ResetAt time.Time
+
+	// Window is the period Limit applies to, such as a minute or a day.
+	// It's zero when unknown.
+	Window time.Duration
+}
+
+// QuotaReporter is implemented by the providers able to report their
+// `Quota`. Those learning it from response headers report the last one
+// seen, hence ok is false before any response.
+type QuotaReporter interface {
+	Quota() (q Quota, ok bool)
+}
+
+// QuotaFetcher fetches the `Quota` of upstream from the status endpoint
+// of a provider reporting it there rather than in the headers of lookups,
+// for the API key key, or none without `WithAPIKeys`. It normalizes the
+// answer of the provider, which this package doesn't know the format of.
+type QuotaFetcher func(ctx context.Context, key string) (Quota, error)
+
+// WithQuotaFetcher makes `Client.RefreshQuota` fetch the `Quota` of
+// upstream through f.
+func WithQuotaFetcher(f QuotaFetcher) Option {
+	return func(c *Client) {
+		c.quotaFetcher = f
+	}
+}
+
+// quotaWindows maps the window suffixes of the per-window headers some
+// gateways send, as in X-RateLimit-Remaining-Day, to their durations.
+var quotaWindows = map[string]time.Duration{
+	"Second": time.Second,
+	"Minute": time.Minute,
+	"Hour":   time.Hour,
+	"Day":    24 * time.Hour,
+	"Month":  30 * 24 * time.Hour,
+}
+
+// ParseQuota extracts a `Quota` out of the response headers h received
+// at now. It understands the de facto X-RateLimit-Limit, -Remaining and
+// -Reset headers, their per-window variants (X-RateLimit-Remaining-Day),
+// and the IETF RateLimit-Limit, -Remaining, -Reset and -Policy headers.
+//
+// When several windows are reported, the one with the fewest requests
+// remaining is returned, since it's the one that runs out first.
+func ParseQuota(h http.Header, now time.Time) (q Quota, ok bool) {
+	for _, prefix := range []string{"X-Ratelimit-", "Ratelimit-"} {
+		if p, found := parseQuota(h, prefix, "", 0, now); found && (!ok || tighter(p, q)) {
+			q, ok = p, true
+		}
+
+		for suffix, window := range quotaWindows {
+			if p, found := parseQuota(h, prefix, "-"+suffix, window, now); found && (!ok || tighter(p, q)) {
+				q, ok = p, true
+			}
+		}
+	}
+
+	return
+}
+
+func parseQuota(h http.Header, prefix, suffix string, window time.Duration, now time.Time) (q Quota, ok bool) {
+	remaining, err := strconv.Atoi(firstValue(h.Get(prefix + "Remaining" + suffix)))
+	if err != nil {
+		return
+	}
+
+	q = Quota{Remaining: remaining, Window: window}
+	q.Limit, _ = strconv.Atoi(firstValue(h.Get(prefix + "Limit" + suffix)))
+
+	if reset, err := strconv.ParseInt(firstValue(h.Get(prefix+"Reset"+suffix)), 10, 64); err == nil {
+		// Values this large can only be Unix timestamps, the rest
+		// are seconds from now.
+		if reset > 1e9 {
+			q.ResetAt = time.Unix(reset, 0)
+		} else {
+			q.ResetAt = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+
+	if q.Window == 0 {
+		q.Window = policyWindow(h.Get(prefix + "Policy"))
+	}
+
+	return q, true
+}
+
+// tighter reports whether a runs out before b does.
+func tighter(a, b Quota) bool {
+	if a.Remaining != b.Remaining {
+		return a.Remaining < b.Remaining
+	}
+
+	return a.Window > b.Window
+}
+
+// firstValue returns the first item of a comma separated header value,
+// as the IETF headers may list several.
+func firstValue(v string) string {
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+
+	return strings.TrimSpace(v)
+}
+
+// policyWindow extracts the window of a RateLimit-Policy header such as
+// `100;w=3600`.
+func policyWindow(v string) time.Duration {
+	for _, param := range strings.Split(firstValue(v), ";") {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "w=") {
+			continue
+		}
+
+		if s, err := strconv.Atoi(param[2:]); err == nil {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	return 0
+}
+
+// quotaState is what a `Client` knows of the `Quota` of upstream, or of
+// an API key of it: the last one reported, the requests made since, and
+// when a 429 asks to retry.
+type quotaState struct {
+	quota   Quota
+	ok      bool
+	sent    int
+	retryAt time.Time
+}
+
+// sharedQuota is the `quotaState` of a `Client` without API keys, shared
+// with the clients derived from it by `Client.With`.
+type sharedQuota struct {
+	mu sync.Mutex
+	quotaState
+}
+
+// remaining estimates the requests left at now, see
+// `Client.QuotaRemaining`.
+func (s *quotaState) remaining(now time.Time) (n int, ok bool) {
+	switch {
+	case now.Before(s.retryAt):
+		return 0, true
+	case !s.ok:
+		return 0, false
+	case !s.quota.ResetAt.IsZero() && !now.Before(s.quota.ResetAt) && s.quota.Limit > 0:
+		return s.quota.Limit, true
+	case s.quota.ResetAt.IsZero() || now.Before(s.quota.ResetAt):
+		return max(s.quota.Remaining-s.sent, 0), true
+	}
+
+	return 0, false
+}
+
+// next returns when the next request is allowed, not after now when it
+// is at once.
+func (s *quotaState) next(now time.Time) time.Time {
+	next := now
+	if s.retryAt.After(next) {
+		next = s.retryAt
+	}
+	if s.ok && s.quota.Remaining-s.sent <= 0 && s.quota.ResetAt.After(next) {
+		next = s.quota.ResetAt
+	}
+
+	return next
+}
+
+// record keeps the `Quota` reported by resp at now, if any, and the time
+// upstream allows the next request at when resp is a 429.
+func (s *quotaState) record(resp *http.Response, now time.Time) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			s.retryAt = now.Add(time.Duration(secs) * time.Second)
+		}
+	}
+
+	if q, ok := ParseQuota(resp.Header, now); ok {
+		s.set(q)
+	}
+}
+
+// set replaces the `Quota` known with q, the requests made counted from
+// then on.
+func (s *quotaState) set(q Quota) {
+	s.quota, s.ok, s.sent = q, true, 0
+}
+
+// Quota returns the last `Quota` reported by upstream in the response
+// headers of a lookup, or fetched by `Client.RefreshQuota`. With several
+// API keys, see `WithAPIKeys`, it's that of them all: their limits and
+// remaining requests summed, reset at the earliest of their resets.
+func (c *Client) Quota() (q Quota, ok bool) {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	if c.keys != nil {
+		return c.keys.quota()
+	}
+
+	c.quota.mu.Lock()
+	defer c.quota.mu.Unlock()
+
+	return c.quota.quota, c.quota.ok
+}
+
+// QuotaRemaining estimates how many requests the `Client` may still make
+// before running into the limits of upstream, for batch jobs to plan their
+// lookups by. It's the last `Quota` reported, less the requests made
+// since, replenished once past its ResetAt; bounded by the rate limit of
+// the `Client` when set with `WithRateLimit`; and none while a 429 asks to
+// retry later. ok is false when there is nothing to estimate it from.
+// With several API keys, it's the sum of those of the keys.
+func (c *Client) QuotaRemaining() (n int, ok bool) {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	now := time.Now()
+
+	if c.keys != nil {
+		n, ok = c.keys.remaining(now)
+	} else {
+		c.quota.mu.Lock()
+		n, ok = c.quota.remaining(now)
+		c.quota.mu.Unlock()
+	}
+
+	if c.limiter != nil {
+		if tokens := max(int(c.limiter.peek(now)), 0); !ok || tokens < n {
+			n, ok = tokens, true
+		}
+	}
+
+	return
+}
+
+// NextAllowedAt estimates when the `Client` may make its next request
+// without running into the limits of upstream, or those of its own rate
+// limit: after the Retry-After of a 429, at the ResetAt of an exhausted
+// `Quota`, or once the rate limit frees a token. It's not after now when
+// a request may be made at once. With several API keys, it's when the
+// first of them may be used.
+func (c *Client) NextAllowedAt() time.Time {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	now := time.Now()
+
+	var next time.Time
+	if c.keys != nil {
+		next = c.keys.next(now)
+	} else {
+		c.quota.mu.Lock()
+		next = c.quota.next(now)
+		c.quota.mu.Unlock()
+	}
+
+	if c.limiter != nil {
+		if t := c.limiter.next(now); t.After(next) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+// RefreshQuota fetches the `Quota` of upstream, that of each API key
+// with several, through the `QuotaFetcher` of c, see `WithQuotaFetcher`,
+// for it to replace the one known, as those reported by the headers of
+// lookups do. It's for the providers with daily quotas reporting them at
+// a status endpoint only, before the batch jobs planning their lookups by
+// `Client.QuotaRemaining`. The quotas fetched before one failing are kept.
+func (c *Client) RefreshQuota(ctx context.Context) error {
+	c.reconf.RLock()
+	fetch, keys, shared := c.quotaFetcher, c.keys, c.quota
+	c.reconf.RUnlock()
+
+	if fetch == nil {
+		return ErrNoQuotaFetcher
+	}
+
+	if keys == nil {
+		q, err := fetch(ctx, "")
+		if err != nil {
+			return fmt.Errorf("Fetching the Quota Failed: %w", err)
+		}
+
+		shared.mu.Lock()
+		shared.set(q)
+		shared.mu.Unlock()
+
+		return nil
+	}
+
+	keys.mu.Lock()
+	ks := append([]*apiKey(nil), keys.keys...)
+	keys.mu.Unlock()
+
+	for _, k := range ks {
+		q, err := fetch(ctx, k.value)
+		if err != nil {
+			return fmt.Errorf("Fetching the Quota Failed: %w", err)
+		}
+		keys.set(k, q)
+	}
+
+	return nil
+}
+
+// countRequest counts a request about to be made with k, nil without API
+// keys, towards the estimate of the remaining quota.
+func (c *Client) countRequest(k *apiKey) {
+	if k != nil {
+		c.keys.count(k)
+		return
+	}
+
+	c.quota.mu.Lock()
+	c.quota.sent++
+	c.quota.mu.Unlock()
+}
+
+// recordQuota keeps the `Quota` reported by resp to a request made with
+// k, nil without API keys, if any, and the time upstream allows the next
+// request at when resp is a 429.
+func (c *Client) recordQuota(k *apiKey, resp *http.Response) {
+	if k != nil {
+		c.keys.record(k, resp)
+		return
+	}
+
+	c.quota.mu.Lock()
+	defer c.quota.mu.Unlock()
+
+	c.quota.record(resp, time.Now())
+}
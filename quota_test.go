@@ -0,0 +1,190 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuota(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		want   Quota
+	}{
+		{
+			"de facto",
+			map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "4", "X-RateLimit-Reset": "1700000600"},
+			Quota{Limit: 10, Remaining: 4, ResetAt: time.Unix(1700000600, 0)},
+		},
+		{
+			"per window, tightest wins",
+			map[string]string{
+				"X-RateLimit-Limit-Minute": "60", "X-RateLimit-Remaining-Minute": "59",
+				"X-RateLimit-Limit-Day": "1000", "X-RateLimit-Remaining-Day": "3",
+			},
+			Quota{Limit: 1000, Remaining: 3, Window: 24 * time.Hour},
+		},
+		{
+			"IETF",
+			map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "50", "RateLimit-Reset": "30", "RateLimit-Policy": "100;w=3600"},
+			Quota{Limit: 100, Remaining: 50, ResetAt: now.Add(30 * time.Second), Window: time.Hour},
+		},
+	}
+
+	for _, c := range cases {
+		h := make(http.Header)
+		for k, v := range c.header {
+			h.Set(k, v)
+		}
+
+		q, ok := ParseQuota(h, now)
+		if !ok {
+			t.Fatalf("%v: no quota parsed", c.name)
+		}
+
+		if q.Limit != c.want.Limit || q.Remaining != c.want.Remaining || !q.ResetAt.Equal(c.want.ResetAt) || q.Window != c.want.Window {
+			t.Fatalf("%v: got %+v, want %+v", c.name, q, c.want)
+		}
+	}
+
+	if _, ok := ParseQuota(make(http.Header), now); ok {
+		t.Fatal("quota parsed out of no headers")
+	}
+}
+
+func TestClientQuota(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5")
+		w.Header().Set("X-RateLimit-Remaining", "2")
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+
+	if _, ok := c.Quota(); ok {
+		t.Fatal("quota reported before any lookup")
+	}
+
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	q, ok := c.Quota()
+	if !ok || q.Limit != 5 || q.Remaining != 2 {
+		t.Fatalf("got quota %+v, %v", q, ok)
+	}
+}
+
+func TestClientQuotaRemaining(t *testing.T) {
+	var responses []*http.Response
+	answer := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp := responses[0]
+			responses = responses[1:]
+			resp.Request = req
+			return resp, nil
+		})
+	}
+	respond := func(status int, h http.Header) {
+		responses = append(responses, &http.Response{StatusCode: status, Header: h, Body: http.NoBody})
+	}
+
+	c := New(WithMiddleware(answer))
+	if _, ok := c.QuotaRemaining(); ok {
+		t.Fatal("quota estimated before any lookup")
+	}
+
+	respond(http.StatusNotFound, http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Reset": {"60"}})
+	respond(http.StatusNotFound, http.Header{})
+	c.Search(context.TODO(), "45717360")
+	c.Search(context.TODO(), "52882301")
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 4 {
+		t.Fatalf("estimated %d, %v requests remaining, want 4", n, ok)
+	}
+	if next := c.NextAllowedAt(); next.After(time.Now()) {
+		t.Fatalf("next request allowed at %v with quota remaining", next)
+	}
+
+	respond(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}})
+	c.Search(context.TODO(), "45717361")
+
+	if n, _ := c.QuotaRemaining(); n != 0 {
+		t.Fatalf("estimated %d requests remaining after a 429", n)
+	}
+	if d := time.Until(c.NextAllowedAt()); d < 29*time.Second || d > 30*time.Second {
+		t.Fatalf("next request allowed in %v after a Retry-After of 30s", d)
+	}
+}
+
+func TestClientRefreshQuota(t *testing.T) {
+	if err := New().RefreshQuota(context.TODO()); !errors.Is(err, ErrNoQuotaFetcher) {
+		t.Fatalf("refresh without a fetcher returned %v", err)
+	}
+
+	daily := Quota{Limit: 1000, Remaining: 400, ResetAt: time.Now().Add(time.Hour), Window: 24 * time.Hour}
+	fetched := make(map[string]Quota)
+	fetch := func(ctx context.Context, key string) (Quota, error) {
+		if key == "down" {
+			return Quota{}, errors.New("unreachable")
+		}
+		q := daily
+		q.Remaining += len(fetched)
+		fetched[key] = q
+		return q, nil
+	}
+
+	c := New(WithQuotaFetcher(fetch), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+	if err := c.RefreshQuota(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	c.Search(context.TODO(), CorrectBIN)
+
+	if q, ok := c.Quota(); !ok || q != daily || fetched[""] != daily {
+		t.Fatalf("quota %+v, %v after a refresh fetching %+v", q, ok, daily)
+	}
+	if n, _ := c.QuotaRemaining(); n != 399 {
+		t.Fatalf("estimated %d requests remaining, want 399 of the quota fetched", n)
+	}
+
+	clear(fetched)
+	c = New(WithQuotaFetcher(fetch), WithAPIKeys("X-Api-Key", RoundRobin, "a", "b"))
+	if err := c.RefreshQuota(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if q, _ := c.Quota(); len(fetched) != 2 || q.Limit != 2000 || q.Remaining != 801 {
+		t.Fatalf("quota %+v of the keys fetched %v", q, fetched)
+	}
+
+	c = New(WithQuotaFetcher(fetch), WithAPIKeys("X-Api-Key", RoundRobin, "down"))
+	if err := c.RefreshQuota(context.TODO()); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Fatalf("failed refresh returned %v", err)
+	}
+}
+
+func TestClientQuotaRemainingRateLimited(t *testing.T) {
+	c := New(WithRateLimit(3, time.Hour), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 3 {
+		t.Fatalf("estimated %d, %v requests remaining, want 3", n, ok)
+	}
+
+	for range 3 {
+		c.Search(context.TODO(), CorrectBIN)
+	}
+
+	if n, _ := c.QuotaRemaining(); n != 0 {
+		t.Fatalf("estimated %d requests remaining with the rate limit spent", n)
+	}
+	if d := time.Until(c.NextAllowedAt()); d < 19*time.Minute || d > 20*time.Minute {
+		t.Fatalf("next request allowed in %v, want about 20m", d)
+	}
+}
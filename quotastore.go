@@ -0,0 +1,252 @@
+package binlookup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultQuotaSaveInterval is how often the `Client` saves the state of
+// its quotas, unless changed with `WithQuotaStore`.
+const DefaultQuotaSaveInterval = 10 * time.Second
+
+// QuotaStore keeps the state of the rate limit and quotas of a `Client`
+// across restarts, see `WithQuotaStore`. `QuotaFile` keeps it on disk;
+// the caches of the sub-packages reaching over the network, such as that
+// of rediscache, are QuotaStores too.
+type QuotaStore interface {
+	// LoadQuota returns the state saved last, nil when there is none.
+	LoadQuota(ctx context.Context) ([]byte, error)
+
+	// SaveQuota replaces the state saved with data.
+	SaveQuota(ctx context.Context, data []byte) error
+}
+
+// WithQuotaStore makes the `Client` resume the state of its rate limit
+// and quotas, those of each API key included, out of s once constructed,
+// and save it there every interval while it changes, once closed too, so
+// that restarting a process doesn't grant it a fresh allowance and double
+// the rate it sends requests at. A zero interval is
+// `DefaultQuotaSaveInterval`.
+//
+// The state of API keys is saved under a hash of each key, never the key
+// itself. The clients derived with `Client.With` leave the state to the
+// `Client` they derive from.
+func WithQuotaStore(s QuotaStore, interval time.Duration) Option {
+	return func(c *Client) {
+		if interval <= 0 {
+			interval = DefaultQuotaSaveInterval
+		}
+		c.quotaStore, c.quotaSaveInterval = s, interval
+	}
+}
+
+// QuotaFile is a `QuotaStore` keeping the state in the file at its path,
+// replaced atomically.
+type QuotaFile string
+
+// LoadQuota reads the file, nil when it doesn't exist.
+func (f QuotaFile) LoadQuota(_ context.Context) ([]byte, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// SaveQuota replaces the file with data.
+func (f QuotaFile) SaveQuota(_ context.Context, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), ".tmp-quota-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// quotaSnapshot is the state `WithQuotaStore` saves.
+type quotaSnapshot struct {
+	Limiter *limiterSnapshot       `json:"limiter,omitempty"`
+	Quota   *quotaRecord           `json:"quota,omitempty"`
+	Keys    map[string]quotaRecord `json:"keys,omitempty"`
+}
+
+// limiterSnapshot is the state of a limiter: its tokens as of At.
+type limiterSnapshot struct {
+	Tokens float64   `json:"tokens"`
+	At     time.Time `json:"at"`
+}
+
+// quotaRecord is a quotaState, along with the requests made with its API
+// key, if any.
+type quotaRecord struct {
+	Quota    Quota     `json:"quota"`
+	OK       bool      `json:"ok,omitempty"`
+	Sent     int       `json:"sent,omitempty"`
+	RetryAt  time.Time `json:"retry_at,omitzero"`
+	Requests int64     `json:"requests,omitempty"`
+}
+
+func newQuotaRecord(s quotaState) quotaRecord {
+	return quotaRecord{Quota: s.quota, OK: s.ok, Sent: s.sent, RetryAt: s.retryAt}
+}
+
+func (r quotaRecord) state() quotaState {
+	return quotaState{quota: r.Quota, ok: r.OK, sent: r.Sent, retryAt: r.RetryAt}
+}
+
+// keyID returns the hash the state of the API key k is saved under.
+func keyID(k string) string {
+	sum := sha256.Sum256([]byte(k))
+	return hex.EncodeToString(sum[:8])
+}
+
+// snapshotQuota returns the state of the rate limit and quotas of c.
+func (c *Client) snapshotQuota() (s quotaSnapshot) {
+	if l := c.limiter; l != nil {
+		l.mu.Lock()
+		if !l.last.IsZero() {
+			s.Limiter = &limiterSnapshot{Tokens: l.tokens, At: l.last}
+		}
+		l.mu.Unlock()
+	}
+
+	if ks := c.keys; ks != nil {
+		ks.mu.Lock()
+		s.Keys = make(map[string]quotaRecord, len(ks.keys))
+		for _, k := range ks.keys {
+			r := newQuotaRecord(k.quota)
+			r.Requests = k.requests
+			s.Keys[keyID(k.value)] = r
+		}
+		ks.mu.Unlock()
+
+		return
+	}
+
+	c.quota.mu.Lock()
+	r := newQuotaRecord(c.quota.quotaState)
+	c.quota.mu.Unlock()
+	s.Quota = &r
+
+	return
+}
+
+// restoreQuota resumes the state s of the rate limit and quotas of c.
+func (c *Client) restoreQuota(s quotaSnapshot) {
+	if l, ls := c.limiter, s.Limiter; l != nil && ls != nil && !ls.At.After(time.Now()) {
+		l.mu.Lock()
+		l.tokens, l.last = min(ls.Tokens, l.burst), ls.At
+		l.mu.Unlock()
+	}
+
+	if ks := c.keys; ks != nil {
+		ks.mu.Lock()
+		for _, k := range ks.keys {
+			if r, ok := s.Keys[keyID(k.value)]; ok {
+				k.quota, k.requests = r.state(), r.Requests
+			}
+		}
+		ks.mu.Unlock()
+
+		return
+	}
+
+	if s.Quota != nil {
+		c.quota.mu.Lock()
+		c.quota.quotaState = s.Quota.state()
+		c.quota.mu.Unlock()
+	}
+}
+
+// quotaContext returns the context of loading and saving the state of
+// the quotas, bound by the timeout of c, if any.
+func (c *Client) quotaContext() (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// loadQuota resumes the state of the quotas of c saved in its store.
+func (c *Client) loadQuota() {
+	ctx, cancel := c.quotaContext()
+	defer cancel()
+
+	data, err := c.quotaStore.LoadQuota(ctx)
+	if err == nil && data == nil {
+		return
+	}
+
+	var s quotaSnapshot
+	if err == nil {
+		err = json.Unmarshal(data, &s)
+	}
+	if err != nil {
+		c.log(ctx, "Loading the Quota Failed", "", err)
+		return
+	}
+
+	c.restoreQuota(s)
+}
+
+// saveQuota saves the state of the quotas of c in its store, unless it's
+// that saved last.
+func (c *Client) saveQuota() {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	data, err := json.Marshal(c.snapshotQuota())
+	if err != nil {
+		return
+	}
+
+	c.quotaSaveMu.Lock()
+	defer c.quotaSaveMu.Unlock()
+
+	if string(data) == string(c.quotaSaved) {
+		return
+	}
+
+	ctx, cancel := c.quotaContext()
+	defer cancel()
+
+	if err := c.quotaStore.SaveQuota(ctx, data); err != nil {
+		c.log(ctx, "Saving the Quota Failed", "", err)
+		return
+	}
+	c.quotaSaved = data
+}
+
+// persistQuota saves the state of the quotas of c every interval until
+// it's closed.
+func (c *Client) persistQuota() {
+	t := time.NewTicker(c.quotaSaveInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			c.saveQuota()
+		case <-c.done:
+			return
+		}
+	}
+}
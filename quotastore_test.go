@@ -0,0 +1,96 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithQuotaStoreRateLimit(t *testing.T) {
+	store := QuotaFile(filepath.Join(t.TempDir(), "quota.json"))
+	opts := []Option{WithRateLimit(3, time.Hour), WithQuotaStore(store, 0), WithMiddleware(canned(http.StatusOK, cannedBIN))}
+
+	c := New(opts...)
+	for range 2 {
+		if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	c.Close()
+
+	c = New(opts...)
+	defer c.Close()
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 1 {
+		t.Fatalf("estimated %d, %v requests remaining once restarted, want 1", n, ok)
+	}
+}
+
+func TestWithQuotaStoreKeys(t *testing.T) {
+	store := QuotaFile(filepath.Join(t.TempDir(), "quota.json"))
+
+	var used []string
+	opts := []Option{WithAPIKeys("X-Api-Key", LeastUsed, "key-a", "key-b"), WithQuotaStore(store, 0), WithMiddleware(keyed(&used))}
+
+	c := New(opts...)
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+	c.Search(context.TODO(), CorrectBIN)
+	c.Close()
+
+	data, err := os.ReadFile(string(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "key-a") {
+		t.Fatalf("API key saved in the clear: %s", data)
+	}
+
+	c = New(opts...)
+	defer c.Close()
+
+	stats := c.KeyStats()
+	if len(stats) != 2 || stats[0].Requests != 2 || stats[1].Requests != 1 || !stats[0].QuotaOK || stats[0].Remaining != 0 {
+		t.Fatalf("key stats once restarted %+v", stats)
+	}
+
+	used = nil
+	c.Search(context.TODO(), CorrectBIN)
+	if len(used) != 1 || used[0] != "key-b" {
+		t.Fatalf("keys used once restarted %v, want key-b", used)
+	}
+}
+
+func TestWithQuotaStoreCorrupt(t *testing.T) {
+	store := QuotaFile(filepath.Join(t.TempDir(), "quota.json"))
+	os.WriteFile(string(store), []byte("{"), 0o600)
+
+	c := New(WithRateLimit(3, time.Hour), WithQuotaStore(store, 0))
+	defer c.Close()
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 3 {
+		t.Fatalf("estimated %d, %v requests remaining of a corrupt store", n, ok)
+	}
+}
+
+func TestWithQuotaStoreNoTimeout(t *testing.T) {
+	store := QuotaFile(filepath.Join(t.TempDir(), "quota.json"))
+	opts := []Option{WithTimeout(0), WithRateLimit(3, time.Hour), WithQuotaStore(store, 0), WithMiddleware(canned(http.StatusOK, cannedBIN))}
+
+	c := New(opts...)
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	c.Close()
+
+	c = New(opts...)
+	defer c.Close()
+
+	if n, ok := c.QuotaRemaining(); !ok || n != 2 {
+		t.Fatalf("estimated %d, %v requests remaining once restarted without a timeout, want 2", n, ok)
+	}
+}
@@ -0,0 +1,50 @@
+package binlookup
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrDerived is returned reconfiguring a `Client` of `Client.With`, which
+// shares the connections of the one it derives from.
+var ErrDerived = errors.New("Derived Clients Can't Be Reconfigured")
+
+// Reconfigure applies opts over the configuration of c in place, such as
+// to move to another base URL or proxy without the callers of c noticing:
+// it holds the lookups starting meanwhile, waits for those in progress to
+// finish against the configuration they started with, then applies opts,
+// rebuilds the transport and closes the idle connections of the previous
+// one, for no lookup to reach the old endpoint past it. The lookups held
+// resume with the new configuration.
+//
+// The cache, the counters and the state of the quotas are kept; options
+// replacing them, such as `WithRateLimit` or `WithAPIKeys`, replace them
+// as they would at construction. Options appending, such as
+// `WithMiddleware` or `WithHeader`, append to those of c. The clients
+// derived from c with `Client.With` keep the configuration they were
+// derived with, and can't be reconfigured themselves.
+//
+// The methods of c reading its configuration, its lookups, batches,
+// figures and cache exports alike, wait for Reconfigure to be over. The
+// hooks and middleware of c run with its configuration held, and mustn't
+// call the methods of c.
+func (c *Client) Reconfigure(opts ...Option) error {
+	if c.derived {
+		return ErrDerived
+	}
+	if err := c.acquire(); err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+
+	c.reconf.Lock()
+	defer c.reconf.Unlock()
+
+	old := c.httpClient
+	c.apply(opts)
+	c.httpClient = &http.Client{Transport: c.transport(), CheckRedirect: c.redirectPolicy}
+	c.chain()
+	old.CloseIdleConnections()
+
+	return nil
+}
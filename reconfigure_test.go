@@ -0,0 +1,146 @@
+package binlookup
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientReconfigure(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		closed int
+
+		started, release = make(chan struct{}), make(chan struct{})
+	)
+
+	old := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(cannedBIN))
+	}))
+	old.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateClosed {
+			mu.Lock()
+			closed++
+			mu.Unlock()
+		}
+	}
+	old.Start()
+	defer old.Close()
+
+	var requested int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested++
+		mu.Unlock()
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(old.URL))
+	defer c.Close()
+
+	errc := make(chan error)
+	go func() {
+		_, err := c.Search(context.TODO(), CorrectBIN)
+		errc <- err
+	}()
+	<-started
+
+	reconfigured := make(chan error)
+	go func() {
+		reconfigured <- c.Reconfigure(WithBaseURL(srv.URL))
+	}()
+
+	select {
+	case <-reconfigured:
+		t.Fatal("Reconfigure returned with a lookup in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-errc; err != nil {
+		t.Fatalf("The lookup in progress failed: %v", err)
+	}
+	if err := <-reconfigured; err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+
+	if _, err := c.Search(context.TODO(), "457173"); err != nil {
+		t.Fatalf("The lookup after Reconfigure failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n, r := closed, requested
+		mu.Unlock()
+
+		if n == 1 && r == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections to the old endpoint were closed and %d requests reached the new one, want 1 and 1", n, r)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClientReconfigureDerived(t *testing.T) {
+	c := New(WithNetworkDisabled())
+	defer c.Close()
+
+	if err := c.With().Reconfigure(WithBaseURL("http://localhost")); err != ErrDerived {
+		t.Errorf("Reconfigure returned %v, want ErrDerived", err)
+	}
+}
+
+func TestClientReconfigureClosed(t *testing.T) {
+	c := New(WithNetworkDisabled())
+	c.Close()
+
+	if err := c.Reconfigure(WithBaseURL("http://localhost")); err != ErrClosed {
+		t.Errorf("Reconfigure returned %v, want ErrClosed", err)
+	}
+}
+
+func TestClientReconfigureConcurrently(t *testing.T) {
+	c := New(WithCache(NewMemoryCache(10)), WithMiddleware(canned(http.StatusOK, cannedBIN)))
+	defer c.Close()
+
+	// Batches under a deadline serve from cache, as none leaves the time
+	// of a lookup upstream.
+	c.observeLatency(time.Hour)
+
+	var wg sync.WaitGroup
+	for _, f := range []func(){
+		func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+			defer cancel()
+			c.SearchBatch(ctx, []string{CorrectBIN, "457173"})
+		},
+		func() { c.ExportCache(context.TODO(), io.Discard) },
+		func() { c.QuotaRemaining() },
+		func() {
+			if err := c.Reconfigure(WithCache(NewMemoryCache(10)), WithBatchConcurrency(2)); err != nil {
+				t.Error(err)
+			}
+		},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				f()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,65 @@
+package binlookup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRedirectRefused is returned by the lookups upstream redirected in a
+// way the `RedirectPolicy` of the `Client` refuses.
+var ErrRedirectRefused = errors.New("Redirect Refused")
+
+// RedirectPolicy decides whether the `Client` follows the redirect to
+// req, via being the requests made so far, oldest first, as the
+// CheckRedirect of http.Client does: an error refuses it, failing the
+// lookup. `NoRedirects`, `SameHostRedirects` and `MaxRedirects` are built
+// in.
+type RedirectPolicy func(req *http.Request, via []*http.Request) error
+
+// WithRedirectPolicy makes the `Client` follow the redirects of upstream
+// p allows, rather than up to 10 of them wherever they lead, as
+// http.Client does. Like those of the transport, the policy is that of
+// the `Client` derived with `Client.With`, whatever it sets.
+func WithRedirectPolicy(p RedirectPolicy) Option {
+	return func(c *Client) {
+		c.redirectPolicy = p
+	}
+}
+
+// NoRedirects refuses every redirect.
+func NoRedirects() RedirectPolicy {
+	return func(req *http.Request, _ []*http.Request) error {
+		return fmt.Errorf("%w: To %v", ErrRedirectRefused, req.URL.Host)
+	}
+}
+
+// SameHostRedirects follows up to maxHops redirects as long as they stay on
+// the host of the request of the lookup, refusing those leading elsewhere
+// or from https to http.
+func SameHostRedirects(maxHops int) RedirectPolicy {
+	hops := MaxRedirects(maxHops)
+
+	return func(req *http.Request, via []*http.Request) error {
+		from := via[0].URL
+		if req.URL.Host != from.Host {
+			return fmt.Errorf("%w: From %v to %v", ErrRedirectRefused, from.Host, req.URL.Host)
+		}
+		if from.Scheme == "https" && req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: From https to %v", ErrRedirectRefused, req.URL.Scheme)
+		}
+
+		return hops(req, via)
+	}
+}
+
+// MaxRedirects follows up to n redirects of a lookup, wherever they lead.
+func MaxRedirects(n int) RedirectPolicy {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("%w: More Than %d Redirects", ErrRedirectRefused, n)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithRedirectPolicy(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer other.Close()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + CorrectBIN:
+			http.Redirect(w, r, "/v2/"+CorrectBIN, http.StatusFound)
+		case "/v2/" + CorrectBIN:
+			http.Redirect(w, r, "/v3/"+CorrectBIN, http.StatusFound)
+		case "/v3/" + CorrectBIN:
+			w.Write([]byte(cannedBIN))
+		case "/45717361":
+			http.Redirect(w, r, other.URL+"/45717361", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		name   string
+		policy RedirectPolicy
+		bin    string
+		ok     bool
+	}{
+		{"default", nil, "45717361", true},
+		{"none", NoRedirects(), CorrectBIN, false},
+		{"same host", SameHostRedirects(2), CorrectBIN, true},
+		{"same host, too many hops", SameHostRedirects(1), CorrectBIN, false},
+		{"same host, cross-origin", SameHostRedirects(5), "45717361", false},
+		{"max", MaxRedirects(1), "45717361", true},
+		{"max, too many hops", MaxRedirects(1), CorrectBIN, false},
+	} {
+		c := New(WithBaseURL(srv.URL), WithRedirectPolicy(tt.policy))
+
+		_, err := c.Search(context.TODO(), tt.bin)
+		if tt.ok && err != nil {
+			t.Errorf("%v: %+v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrRedirectRefused) {
+			t.Errorf("%v: lookup returned %v, want ErrRedirectRefused", tt.name, err)
+		}
+		if !tt.ok && IsRetryable(err) {
+			t.Errorf("%v: refused redirect is retryable", tt.name)
+		}
+	}
+}
+
+func TestSameHostRedirectsDowngrade(t *testing.T) {
+	from, _ := http.NewRequest(http.MethodGet, "https://lookup.binlist.net/"+CorrectBIN, nil)
+	to, _ := http.NewRequest(http.MethodGet, "http://lookup.binlist.net/"+CorrectBIN, nil)
+
+	if err := SameHostRedirects(5)(to, []*http.Request{from}); !errors.Is(err, ErrRedirectRefused) {
+		t.Fatalf("redirect to http returned %v, want ErrRedirectRefused", err)
+	}
+}
@@ -0,0 +1,316 @@
+// Package rediscache is a `binlookup.Cache` kept in Redis, so that the
+// lookups cached by a fleet of processes are shared between them.
+//
+// The package speaks the Redis protocol itself, only as far as the
+// commands a cache needs, and has no dependencies.
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// DefaultPoolSize is the number of idle connections a `Cache` keeps
+// unless changed in its PoolSize.
+const DefaultPoolSize = 16
+
+// Cache is a `binlookup.Cache` storing its entries in the Redis server at
+// Addr, encoded with Codec. It's safe for concurrent use.
+type Cache struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates the connections when set.
+	Password string
+
+	// DB selects the database of the connections when not zero.
+	DB int
+
+	// Prefix is prepended to the keys of the entries, to share a
+	// server with other applications.
+	Prefix string
+
+	// Retention is how long the keys are kept past the expiry of their
+	// entry. Zero removes them when their entry expires.
+	Retention time.Duration
+
+	// Timeout bounds dialing and every command, none by default.
+	Timeout time.Duration
+
+	// Codec encodes the entries, compressing them or encoding them in
+	// binary when configured to, see `binlookup.EntryCodec`.
+	// The zero value leaves them uncompressed.
+	Codec binlookup.EntryCodec
+
+	// PoolSize is the number of idle connections kept for reuse,
+	// `DefaultPoolSize` when zero.
+	PoolSize int
+
+	mu   sync.Mutex
+	idle []*conn
+}
+
+// New returns a `Cache` storing its entries in the Redis server at addr.
+func New(addr string) *Cache {
+	return &Cache{Addr: addr}
+}
+
+// Get returns the entry stored under key.
+func (c *Cache) Get(ctx context.Context, key string) (e binlookup.Entry, ok bool, err error) {
+	v, err := c.do(ctx, "GET", c.Prefix+key)
+	if err != nil || v == nil {
+		return
+	}
+
+	data, _ := v.([]byte)
+	if e, err = c.Codec.Unmarshal(data); err != nil {
+		return
+	}
+
+	return e, true, nil
+}
+
+// Set stores e under key until its expiry plus the retention of c.
+func (c *Cache) Set(ctx context.Context, key string, e binlookup.Entry) error {
+	data, err := c.Codec.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(e.Expires) + c.Retention
+	if e.Expires.IsZero() {
+		_, err = c.do(ctx, "SET", c.Prefix+key, string(data))
+		return err
+	}
+	if ttl < time.Millisecond {
+		return c.Delete(ctx, key)
+	}
+
+	_, err = c.do(ctx, "SET", c.Prefix+key, string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// Delete removes the entry stored under key.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, "DEL", c.Prefix+key)
+	return err
+}
+
+// QuotaKey is the key, after Prefix, `Cache.SaveQuota` stores the state
+// of the quotas of a `binlookup.Client` under.
+const QuotaKey = "binlookup:quota"
+
+// LoadQuota returns the state of the quotas stored, nil when there is
+// none, making c a `binlookup.QuotaStore`.
+func (c *Cache) LoadQuota(ctx context.Context) ([]byte, error) {
+	v, err := c.do(ctx, "GET", c.Prefix+QuotaKey)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	data, _ := v.([]byte)
+	return data, nil
+}
+
+// SaveQuota stores data as the state of the quotas, shared by the
+// processes using the same server and Prefix.
+func (c *Cache) SaveQuota(ctx context.Context, data []byte) error {
+	_, err := c.do(ctx, "SET", c.Prefix+QuotaKey, string(data))
+	return err
+}
+
+// Close closes the idle connections of c.
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	idle := c.idle
+	c.idle = nil
+	c.mu.Unlock()
+
+	for _, cn := range idle {
+		cn.Close()
+	}
+
+	return nil
+}
+
+// do sends a command over an idle connection, or a new one when there
+// is none, and returns its reply. The command is bound by the deadline of
+// ctx, on top of the timeout of c, and abandoned once ctx is done, the
+// connection along with it.
+func (c *Cache) do(ctx context.Context, args ...string) (v interface{}, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	cn, err := c.get(ctx)
+	if err != nil {
+		return
+	}
+	cn.SetDeadline(c.deadline(ctx))
+
+	stop := context.AfterFunc(ctx, func() {
+		cn.SetDeadline(time.Unix(1, 0))
+	})
+	v, err = cn.do(args...)
+	if !stop() {
+		cn.Close()
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		var rerr redisError
+		if !errors.As(err, &rerr) {
+			cn.Close()
+			return
+		}
+	}
+	c.put(cn)
+
+	return
+}
+
+// deadline returns the deadline of a command under ctx, zero when there
+// is none.
+func (c *Cache) deadline(ctx context.Context) (t time.Time) {
+	if c.Timeout > 0 {
+		t = time.Now().Add(c.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (t.IsZero() || d.Before(t)) {
+		t = d
+	}
+
+	return
+}
+
+func (c *Cache) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	return c.dial(ctx)
+}
+
+func (c *Cache) put(cn *conn) {
+	size := c.PoolSize
+	if size == 0 {
+		size = DefaultPoolSize
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.idle) >= size {
+		cn.Close()
+		return
+	}
+	cn.SetDeadline(time.Time{})
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Cache) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: c.Timeout}
+	nc, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	cn.SetDeadline(c.deadline(ctx))
+
+	if c.Password != "" {
+		if _, err := cn.do("AUTH", c.Password); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+
+	if c.DB != 0 {
+		if _, err := cn.do("SELECT", strconv.Itoa(c.DB)); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+
+	return cn, nil
+}
+
+// redisError is an error reply of the server, after which the
+// connection remains usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "Redis: " + string(e)
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do writes a command as an array of bulk strings and reads its reply.
+func (cn *conn) do(args ...string) (interface{}, error) {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, a...)
+		buf = append(buf, "\r\n"...)
+	}
+
+	if _, err := cn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	return cn.reply()
+}
+
+// reply reads a reply: nil for null ones, a string for simple strings,
+// an int64 for integers and a []byte for bulk strings.
+func (cn *conn) reply() (interface{}, error) {
+	line, err := cn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("Malformed Redis Reply: %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(cn.r, data); err != nil {
+			return nil, err
+		}
+
+		return data[:n], nil
+	}
+
+	return nil, fmt.Errorf("Unsupported Redis Reply: %q", line)
+}
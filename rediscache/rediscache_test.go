@@ -0,0 +1,202 @@
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// fakeRedis serves GET, SET and DEL out of a map, recording the commands
+// it receives.
+type fakeRedis struct {
+	net.Listener
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands []string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	f := &fakeRedis{Listener: l, data: make(map[string]string)}
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(nc)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeRedis) serve(nc net.Conn) {
+	defer nc.Close()
+
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			line, _ = r.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			buf := make([]byte, size+2)
+			io.ReadFull(r, buf)
+			args[i] = string(buf[:size])
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		switch args[0] {
+		case "GET":
+			if v, ok := f.data[args[1]]; ok {
+				io.WriteString(nc, "$"+strconv.Itoa(len(v))+"\r\n"+v+"\r\n")
+			} else {
+				io.WriteString(nc, "$-1\r\n")
+			}
+		case "SET":
+			f.data[args[1]] = args[2]
+			io.WriteString(nc, "+OK\r\n")
+		case "DEL":
+			delete(f.data, args[1])
+			io.WriteString(nc, ":1\r\n")
+		default:
+			io.WriteString(nc, "-ERR unknown command\r\n")
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestCache(t *testing.T) {
+	srv := newFakeRedis(t)
+	c := New(srv.Addr().String())
+	c.Prefix = "bin:"
+	defer c.Close()
+
+	if _, ok, err := c.Get(context.TODO(), "45717360"); ok || err != nil {
+		t.Fatalf("Get of a missing key returned %v, %v", ok, err)
+	}
+
+	e := binlookup.Entry{BIN: &binlookup.BIN{Scheme: binlookup.SchemeVisa}, Expires: time.Now().Add(time.Hour)}
+	if err := c.Set(context.TODO(), "45717360", e); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok, err := c.Get(context.TODO(), "45717360")
+	if !ok || err != nil || got.BIN.Scheme != binlookup.SchemeVisa {
+		t.Fatalf("Get returned %+v, %v, %v", got, ok, err)
+	}
+
+	if err := c.Delete(context.TODO(), "45717360"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := c.Get(context.TODO(), "45717360"); ok {
+		t.Fatal("deleted entry is still cached")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if set := srv.commands[1]; !strings.HasPrefix(set, "SET bin:45717360 ") || !strings.Contains(set, " PX ") {
+		t.Fatalf("entry stored with %q", set)
+	}
+}
+
+func TestCacheErrorReply(t *testing.T) {
+	srv := newFakeRedis(t)
+	c := New(srv.Addr().String())
+	c.DB = 2
+	defer c.Close()
+
+	if _, _, err := c.Get(context.TODO(), "45717360"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Fatalf("Get over a connection failing to SELECT returned %v", err)
+	}
+}
+
+func TestCacheCanceled(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// The server accepts connections but never replies.
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer nc.Close()
+		}
+	}()
+
+	c := New(l.Addr().String())
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, _, err := c.Get(ctx, "45717360"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get canceled returned %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Get canceled returned after %v", d)
+	}
+
+	if len(c.idle) != 0 {
+		t.Fatal("connection of a canceled command kept for reuse")
+	}
+}
+
+func TestCacheQuota(t *testing.T) {
+	srv := newFakeRedis(t)
+	c := New(srv.Addr().String())
+	c.Prefix = "bin:"
+	defer c.Close()
+
+	var _ binlookup.QuotaStore = c
+
+	if data, err := c.LoadQuota(context.TODO()); data != nil || err != nil {
+		t.Fatalf("LoadQuota with none stored returned %q, %v", data, err)
+	}
+
+	if err := c.SaveQuota(context.TODO(), []byte(`{"quota":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := c.LoadQuota(context.TODO())
+	if err != nil || string(data) != `{"quota":{}}` {
+		t.Fatalf("LoadQuota returned %q, %v", data, err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if _, ok := srv.data["bin:"+QuotaKey]; !ok {
+		t.Fatalf("quota stored under %v", srv.data)
+	}
+}
@@ -0,0 +1,221 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Bounds of the default exponential backoff between two attempts.
+const (
+	retryBaseDelay = 100 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+// WithRetries makes the `Client` retry a failed lookup up to n times
+// when the failure is transient: a network error, an attempt timing out,
+// upstream answering with http.StatusTooManyRequests or a 5xx, or with an
+// incomplete BIN, see `WithCompletenessCheck`, unless changed with
+// `WithRetryPolicy`. Attempts are spaced by the
+// `Backoff` of the `Client`, see `WithBackoff`, or by the Retry-After
+// upstream sends along a 429. A retry whose delay would outlast the
+// deadline of the lookup isn't waited for: the lookup fails at once with
+// the error of the last attempt.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// WithPerAttemptTimeout bounds each attempt of a lookup to d, so that a
+// hung attempt is given up and retried within the overall deadline of the
+// lookup rather than consuming all of it. It's disabled unless set.
+func WithPerAttemptTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.perAttempt = d
+	}
+}
+
+// WithRetryBudget bounds the retries of a lookup on top of their number,
+// see `WithRetries`, so that however they're configured, they never hold a
+// payment up for long: no retry is made past maxElapsed since the first
+// attempt, the attempts retried being cut short by then, nor once the
+// delays waited between the attempts add up to more than maxBackoff. A
+// retry whose delay would end past either is given up at once, the lookup
+// failing with the error of the last attempt. Zero leaves either unbounded,
+// as they are unless set. `WithTimeout` bounds the first attempt as well.
+func WithRetryBudget(maxElapsed, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.retryElapsed, c.retryBackoff = maxElapsed, maxBackoff
+	}
+}
+
+// RetryPolicy reports whether the attempt failing with err is to be
+// retried, given resp, the response of upstream to it, its body read and
+// closed, nil when there was none, such as for network errors.
+type RetryPolicy func(err error, resp *http.Response) bool
+
+// DefaultRetryPolicy retries the attempts `IsRetryable` counts as
+// retryable.
+func DefaultRetryPolicy(err error, _ *http.Response) bool {
+	return IsRetryable(err)
+}
+
+// WithRetryPolicy makes the `Client` retry the failed attempts p reports
+// to be retried, rather than those of `DefaultRetryPolicy`, such as to
+// retry the 404s of a provider whose data lags, up to the retries of
+// `WithRetries`. Neither the attempts of lookups whose context is done
+// nor those of a `Client` closed are retried, whatever p reports.
+//
+//	binlookup.WithRetryPolicy(func(err error, resp *http.Response) bool {
+//		return errors.Is(err, binlookup.ErrNotFound) || binlookup.IsRetryable(err)
+//	})
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = p
+	}
+}
+
+// shouldRetry reports whether the attempt failing with err, answered with
+// resp, is to be retried by the policy of c.
+func (c *Client) shouldRetry(err error, resp *http.Response) bool {
+	if c.retryPolicy == nil {
+		return IsRetryable(err)
+	}
+	if errors.Is(err, ErrClosed) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return c.retryPolicy(err, resp)
+}
+
+// retry runs the attempts of a lookup.
+func (c *Client) retry(ctx context.Context, bin string, stale *Entry) (b *BIN, v validators, err error) {
+	var (
+		delay   time.Duration
+		waited  time.Duration
+		attempt = ctx
+		begin   = time.Now()
+	)
+	for i := 0; ; i++ {
+		if c.limiter != nil {
+			if err = c.limiter.wait(ctx); err != nil {
+				return
+			}
+		}
+
+		if c.dryRun {
+			return nil, validators{}, ErrDryRun
+		}
+
+		hook(c.hooks.OnRequest, bin, Event{Attempt: i})
+		start := time.Now()
+		b, v, err = c.attemptWithin(attempt, bin, stale)
+		status := responseStatus(b, stale, err)
+		if status != 0 {
+			hook(c.hooks.OnResponse, bin, Event{Attempt: i, StatusCode: status, Duration: time.Since(start), Err: err})
+		}
+		c.log(ctx, "Attempt", bin, err, slog.Int("attempt", i), slog.Int("status", status), slog.Duration("duration", time.Since(start)))
+
+		if err == nil || i >= c.retries || ctx.Err() != nil || !c.shouldRetry(err, v.resp) {
+			return
+		}
+
+		delay = retryDelay(c.backoff, i, delay, err)
+		if c.retryElapsed > 0 && time.Since(begin)+delay >= c.retryElapsed {
+			return
+		}
+		// A retry the deadline of the lookup would cut short while still
+		// waiting isn't worth the wait.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return
+		}
+		if waited += delay; c.retryBackoff > 0 && waited > c.retryBackoff {
+			return
+		}
+		if c.retryElapsed > 0 && attempt == ctx {
+			var cancel context.CancelFunc
+			attempt, cancel = context.WithDeadline(ctx, begin.Add(c.retryElapsed))
+			defer cancel()
+		}
+
+		hook(c.hooks.OnRetry, bin, Event{Attempt: i, Delay: delay, Err: err})
+		c.count("retries")
+		c.log(ctx, "Retrying", bin, err, slog.Int("attempt", i), slog.Duration("delay", delay))
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// attemptWithin makes an attempt bounded by the per attempt timeout.
+func (c *Client) attemptWithin(ctx context.Context, bin string, stale *Entry) (*BIN, validators, error) {
+	if c.perAttempt <= 0 {
+		return c.attempt(ctx, bin, stale)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.perAttempt)
+	defer cancel()
+
+	return c.attempt(ctx, bin, stale)
+}
+
+// responseStatus returns the status of the response to an attempt which
+// returned b and err, zero when there was none.
+func responseStatus(b *BIN, stale *Entry, err error) int {
+	var he *HTTPError
+	switch {
+	case errors.As(err, &he):
+		return he.StatusCode
+	case err != nil:
+		return 0
+	case stale != nil && b == stale.BIN:
+		return http.StatusNotModified
+	}
+
+	return http.StatusOK
+}
+
+// IsRetryable reports whether the lookup failing with err is worth
+// another attempt, as the retries of a `Client` make them, for the retry
+// frameworks wrapping its lookups to decide on alike: those which failed
+// with a 429 or a 5xx of upstream, see `HTTPError.Temporary`, over the
+// network or past their deadline, or on an answer lacking data, see
+// `WithCompletenessCheck`, and the lookups shed by a full queue. Those of
+// BINs malformed or not found, canceled, redirected against the
+// `RedirectPolicy`, or of a `Client` closed, aren't.
+func IsRetryable(err error) bool {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.Temporary()
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrClosed) || errors.Is(err, ErrRedirectRefused) {
+		return false
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrIncompleteData) ||
+		errors.Is(err, ErrQueueFull)
+}
+
+// retryDelay returns how long to wait after the attempt i failed with err,
+// along b given the previous delay last.
+func retryDelay(b Backoff, i int, last time.Duration, err error) time.Duration {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		if s, perr := strconv.Atoi(he.Header.Get("Retry-After")); perr == nil && s >= 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	return b.Delay(i, last)
+}
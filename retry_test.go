@@ -0,0 +1,249 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	if _, err := New(WithBaseURL(srv.URL), WithRetries(2)).Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("made %d attempts, want 3", n)
+	}
+}
+
+func TestWithRetriesGivesUp(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	if _, err := New(WithBaseURL(srv.URL), WithRetries(3)).Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("lookup returned %v, want ErrNotFound", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("made %d attempts for a 404, want 1", n)
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	var statuses []int
+	policy := func(err error, resp *http.Response) bool {
+		if resp != nil {
+			statuses = append(statuses, resp.StatusCode)
+		}
+		return errors.Is(err, ErrNotFound) || IsRetryable(err)
+	}
+
+	if _, err := New(WithBaseURL(srv.URL), WithRetries(3), WithRetryPolicy(policy)).Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("made %d attempts, want 3", n)
+	}
+	if fmt.Sprint(statuses) != "[404 404]" {
+		t.Fatalf("policy was given statuses %v, want [404 404]", statuses)
+	}
+}
+
+func TestWithRetryPolicyNever(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	never := func(error, *http.Response) bool { return false }
+	if _, err := New(WithBaseURL(srv.URL), WithRetries(3), WithRetryPolicy(never)).Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup succeeded, want the 503")
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("made %d attempts, want 1", n)
+	}
+}
+
+func TestWithPerAttemptTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetries(1), WithPerAttemptTimeout(50*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Search(ctx, CorrectBIN); err != nil {
+		t.Fatalf("hung attempt wasn't retried: %+v", err)
+	}
+}
+
+func TestWithRetryBudget(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	backoff := ExponentialBackoff{Base: 30 * time.Millisecond}
+	for _, tc := range []struct {
+		name                   string
+		maxElapsed, maxBackoff time.Duration
+		attempts               int32
+	}{
+		// The delays are 30, 60, 120 and 240ms.
+		{"Elapsed", 200 * time.Millisecond, 0, 3},
+		{"Backoff", 0, 100 * time.Millisecond, 3},
+		{"Unbounded", 0, 0, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			c := New(WithBaseURL(srv.URL), WithRetries(4), WithBackoff(backoff), WithRetryBudget(tc.maxElapsed, tc.maxBackoff))
+
+			start := time.Now()
+			if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+				t.Fatal("lookup of a failing upstream succeeded")
+			}
+
+			if n := atomic.LoadInt32(&calls); n != tc.attempts {
+				t.Fatalf("made %d attempts, want %d", n, tc.attempts)
+			}
+			if tc.maxElapsed > 0 && time.Since(start) > tc.maxElapsed {
+				t.Fatalf("retried for %v, past the budget of %v", time.Since(start), tc.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestRetriesWithinDeadline(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetries(3), WithBackoff(ExponentialBackoff{Base: time.Second}))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Search(ctx, CorrectBIN)
+
+	var he *HTTPError
+	if !errors.As(err, &he) || he.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("lookup returned %v, want the 503 of the last attempt", err)
+	}
+	if d := time.Since(start); d > 250*time.Millisecond {
+		t.Fatalf("lookup took %v, waiting on a retry past its deadline", d)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("made %d attempts, want 1", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if d := retryDelay(defaultBackoff, 0, 0, errors.New("reset")); d != retryBaseDelay {
+		t.Fatalf("first delay is %v", d)
+	}
+
+	if d := retryDelay(defaultBackoff, 40, 0, errors.New("reset")); d != retryMaxDelay {
+		t.Fatalf("delay isn't capped: %v", d)
+	}
+
+	he := &HTTPError{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"2"}}}
+	if d := retryDelay(defaultBackoff, 0, 0, he); d != 2*time.Second {
+		t.Fatalf("Retry-After isn't honored: %v", d)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	timeout := &net.OpError{Op: "dial", Err: context.DeadlineExceeded}
+
+	for err, want := range map[error]bool{
+		fmt.Errorf("Failed Due to Status Code Error: %w", &HTTPError{StatusCode: http.StatusTooManyRequests}): true,
+		&HTTPError{StatusCode: http.StatusBadGateway}:                                                         true,
+		&HTTPError{StatusCode: http.StatusNotFound}:                                                           false,
+		&HTTPError{StatusCode: http.StatusBadRequest}:                                                         false,
+		timeout:                                  true,
+		context.DeadlineExceeded:                 true,
+		context.Canceled:                         false,
+		ErrQueueFull:                             true,
+		ErrIncompleteData:                        true,
+		ValidateBIN("0812436"):                   false,
+		fmt.Errorf("%w: %w", ErrClosed, timeout): false,
+		&PartialError{Err: context.DeadlineExceeded}: true,
+	} {
+		if got := IsRetryable(err); got != want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
+
+func TestHTTPErrorTemporary(t *testing.T) {
+	var temporary interface {
+		Temporary() bool
+		Timeout() bool
+	}
+
+	err := fmt.Errorf("Failed Due to Status Code Error: %w", &HTTPError{StatusCode: http.StatusGatewayTimeout})
+	if !errors.As(err, &temporary) || !temporary.Temporary() || !temporary.Timeout() {
+		t.Fatal("504 isn't reported temporary and a timeout")
+	}
+
+	if he := (&HTTPError{StatusCode: http.StatusTooManyRequests}); !he.Temporary() || he.Timeout() {
+		t.Fatal("429 isn't reported temporary only")
+	}
+	if he := (&HTTPError{StatusCode: http.StatusNotFound}); he.Temporary() || he.Timeout() {
+		t.Fatal("404 is reported temporary")
+	}
+}
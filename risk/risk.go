@@ -0,0 +1,165 @@
+// Package risk flags the lookups of BINs by rules, such as a prepaid card
+// used abroad, for fraud checks to compute the same signals wherever the
+// BIN data is:
+//
+//	e := risk.New(
+//		risk.PrepaidForeign(40),
+//		risk.BlockedCountry(100, "KP", "IR"),
+//		risk.UnknownBank(10),
+//	)
+//
+//	a := e.Evaluate(b, "DK")
+//	if a.Score >= 50 {
+//		// review the payment
+//	}
+package risk
+
+import (
+	"context"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// Rule names of the rules of the package.
+const (
+	RulePrepaid        = "prepaid"
+	RuleForeign        = "foreign"
+	RulePrepaidForeign = "prepaid_foreign"
+	RuleBlockedCountry = "blocked_country"
+	RuleUnknownBank    = "unknown_bank"
+)
+
+// Input is what the rules are evaluated against.
+type Input struct {
+	BIN *binlookup.BIN
+
+	// Merchant is the country the card is used in: its alpha-2, alpha-3
+	// or numeric code, empty when unknown.
+	Merchant string
+}
+
+// Rule is a risk rule, adding Score to the assessments of the inputs
+// Match reports.
+type Rule struct {
+	Name  string
+	Score int
+	Match func(in Input) bool
+}
+
+// Flag is a rule an input matched.
+type Flag struct {
+	Rule  string `json:"rule"`
+	Score int    `json:"score"`
+}
+
+// Assessment is the outcome of the evaluation of an input: the rules it
+// matched, in the order of the `Evaluator`, and the sum of their scores.
+type Assessment struct {
+	Flags []Flag `json:"flags"`
+	Score int    `json:"score"`
+}
+
+// Has reports whether the rule named rule was matched.
+func (a Assessment) Has(rule string) bool {
+	for _, f := range a.Flags {
+		if f.Rule == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluator evaluates inputs against rules. It's safe for concurrent use
+// once constructed with `New`.
+type Evaluator struct {
+	rules []Rule
+}
+
+// New returns an `Evaluator` of rules.
+func New(rules ...Rule) *Evaluator {
+	return &Evaluator{rules: rules}
+}
+
+// Evaluate returns the assessment of b, the BIN of a card used in the
+// country merchant. A nil b matches no rule.
+func (e *Evaluator) Evaluate(b *binlookup.BIN, merchant string) (a Assessment) {
+	a.Flags = []Flag{}
+	if b == nil {
+		return
+	}
+
+	in := Input{BIN: b, Merchant: merchant}
+	for _, r := range e.rules {
+		if r.Match(in) {
+			a.Flags = append(a.Flags, Flag{Rule: r.Name, Score: r.Score})
+			a.Score += r.Score
+		}
+	}
+
+	return
+}
+
+// Lookup looks bin up through l and returns the assessment of its BIN,
+// along with it.
+func (e *Evaluator) Lookup(ctx context.Context, l binlookup.Lookuper, bin, merchant string) (*binlookup.BIN, Assessment, error) {
+	b, err := l.Search(ctx, bin)
+	if err != nil {
+		return nil, Assessment{}, err
+	}
+
+	return b, e.Evaluate(b, merchant), nil
+}
+
+// Prepaid flags the prepaid cards, see `binlookup.BIN.IsPrepaid`.
+func Prepaid(score int) Rule {
+	return Rule{Name: RulePrepaid, Score: score, Match: prepaid}
+}
+
+// Foreign flags the cards issued in another country than the merchant's,
+// see `binlookup.BIN.CountryMismatch`. Those of either country unknown
+// aren't.
+func Foreign(score int) Rule {
+	return Rule{Name: RuleForeign, Score: score, Match: foreign}
+}
+
+// PrepaidForeign flags the prepaid cards issued in another country than
+// the merchant's.
+func PrepaidForeign(score int) Rule {
+	return Rule{Name: RulePrepaidForeign, Score: score, Match: func(in Input) bool {
+		return prepaid(in) && foreign(in)
+	}}
+}
+
+// BlockedCountry flags the cards issued in one of countries, given by
+// their alpha-2, alpha-3 or numeric codes.
+func BlockedCountry(score int, countries ...string) Rule {
+	blocked := make(map[string]bool, len(countries))
+	for _, code := range countries {
+		if c, ok := binlookup.LookupCountry(code); ok {
+			blocked[c.Alpha2] = true
+		}
+	}
+
+	return Rule{Name: RuleBlockedCountry, Score: score, Match: func(in Input) bool {
+		c, ok := binlookup.LookupCountry(in.BIN.Country.Short)
+		return ok && blocked[c.Alpha2]
+	}}
+}
+
+// UnknownBank flags the cards whose issuer upstream doesn't name.
+func UnknownBank(score int) Rule {
+	return Rule{Name: RuleUnknownBank, Score: score, Match: func(in Input) bool {
+		return in.BIN.Bank == nil || in.BIN.Bank.Name == ""
+	}}
+}
+
+func prepaid(in Input) bool {
+	p, _ := in.BIN.IsPrepaid()
+	return p
+}
+
+func foreign(in Input) bool {
+	m, _ := in.BIN.CountryMismatch(in.Merchant)
+	return m
+}
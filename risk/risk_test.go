@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+func TestEvaluate(t *testing.T) {
+	yes := true
+	e := New(Prepaid(5), Foreign(10), PrepaidForeign(40), BlockedCountry(100, "PRK", "ir"), UnknownBank(15))
+
+	for _, tt := range []struct {
+		b        *binlookup.BIN
+		merchant string
+		flags    []string
+		score    int
+	}{
+		{&binlookup.BIN{Country: binlookup.Country{Short: "DK"}, Bank: &binlookup.Bank{Name: "Jyske Bank"}}, "DK", nil, 0},
+		{&binlookup.BIN{Prepaid: &yes, Country: binlookup.Country{Short: "DK"}, Bank: &binlookup.Bank{Name: "Jyske Bank"}}, "SE", []string{RulePrepaid, RuleForeign, RulePrepaidForeign}, 55},
+		{&binlookup.BIN{Prepaid: &yes, Country: binlookup.Country{Short: "DK"}}, "", []string{RulePrepaid, RuleUnknownBank}, 20},
+		{&binlookup.BIN{Country: binlookup.Country{Short: "IR"}, Bank: &binlookup.Bank{}}, "IR", []string{RuleBlockedCountry, RuleUnknownBank}, 115},
+		{nil, "DK", nil, 0},
+	} {
+		a := e.Evaluate(tt.b, tt.merchant)
+
+		var flags []string
+		for _, f := range a.Flags {
+			flags = append(flags, f.Rule)
+		}
+		if !reflect.DeepEqual(flags, tt.flags) || a.Score != tt.score {
+			t.Errorf("Evaluate(%+v, %q) = %v, %d, want %v, %d", tt.b, tt.merchant, flags, a.Score, tt.flags, tt.score)
+		}
+		for _, rule := range tt.flags {
+			if !a.Has(rule) {
+				t.Errorf("assessment %+v hasn't %v", a, rule)
+			}
+		}
+	}
+}
+
+func TestEvaluateCustomRule(t *testing.T) {
+	amex := Rule{Name: "amex", Score: 3, Match: func(in Input) bool { return in.BIN.Scheme == binlookup.SchemeAmex }}
+
+	if a := New(amex).Evaluate(&binlookup.BIN{Scheme: binlookup.SchemeAmex}, "US"); !a.Has("amex") || a.Score != 3 {
+		t.Fatalf("assessment is %+v", a)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	l := binlookup.LookuperFunc(func(_ context.Context, bin string) (*binlookup.BIN, error) {
+		if bin != "45717360" {
+			return nil, binlookup.ErrNotFound
+		}
+		return &binlookup.BIN{Country: binlookup.Country{Short: "DK"}}, nil
+	})
+	e := New(Foreign(10))
+
+	b, a, err := e.Lookup(context.TODO(), l, "45717360", "US")
+	if err != nil || b == nil || !a.Has(RuleForeign) {
+		t.Fatalf("Lookup = %+v, %+v, %v", b, a, err)
+	}
+
+	if _, _, err := e.Lookup(context.TODO(), l, "99999999", "US"); !errors.Is(err, binlookup.ErrNotFound) {
+		t.Fatalf("Lookup returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,95 @@
+package binlookup
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// Timeout returns a `Provider` bounding the lookups of p to d, on top of
+// the deadline of their context, so that each provider of a chain is
+// given the time it needs: a fast paid API less than binlist. The lookups
+// timing out fail with context.DeadlineExceeded, for the chain to move on.
+func Timeout(p Provider, d time.Duration) Provider {
+	return &timeout{Provider: p, d: d}
+}
+
+type timeout struct {
+	Provider
+	d time.Duration
+}
+
+func (t *timeout) Name() string {
+	return ProviderName(t.Provider)
+}
+
+func (t *timeout) Search(ctx context.Context, bin string) (*BIN, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.d)
+	defer cancel()
+
+	return t.Provider.Search(ctx, bin)
+}
+
+// WeightedProvider is a `Provider` of `Weighted`, along with its weight.
+type WeightedProvider struct {
+	Provider Provider
+	Weight   float64
+}
+
+// Weighted returns a `Provider` spreading lookups over ps by their weight:
+// each lookup tries first a provider picked at random in proportion to its
+// weight, then moves on to the others the way `Chain` does, in an order
+// drawn the same way. Providers weighing zero or less are tried last, in
+// their order, as standbys. For priority rather than weighted routing,
+// `Chain` tries its providers in order.
+func Weighted(ps ...WeightedProvider) Provider {
+	return weighted(ps)
+}
+
+type weighted []WeightedProvider
+
+func (ws weighted) Search(ctx context.Context, bin string) (*BIN, error) {
+	return ws.order().Search(ctx, bin)
+}
+
+func (ws weighted) Capabilities() Capabilities {
+	return ws.chain().Capabilities()
+}
+
+func (ws weighted) chain() chain {
+	ps := make(chain, len(ws))
+	for i, w := range ws {
+		ps[i] = w.Provider
+	}
+
+	return ps
+}
+
+// order draws the order the providers are tried in for a lookup, sorting
+// them by u^(1/weight) for u uniform in [0, 1), which puts a provider
+// first with a probability proportional to its weight.
+func (ws weighted) order() chain {
+	keys := make([]float64, len(ws))
+	for i, w := range ws {
+		keys[i] = -1
+		if w.Weight > 0 {
+			keys[i] = math.Pow(rand.Float64(), 1/w.Weight)
+		}
+	}
+
+	ps := ws.chain()
+	idx := make([]int, len(ws))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return keys[idx[a]] > keys[idx[b]] })
+
+	ordered := make(chain, len(ps))
+	for i, j := range idx {
+		ordered[i] = ps[j]
+	}
+
+	return ordered
+}
@@ -0,0 +1,49 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	slow := delayed{d: time.Second, b: &BIN{Scheme: SchemeVisa}}
+
+	start := time.Now()
+	if _, err := Timeout(slow, 20*time.Millisecond).Search(context.TODO(), CorrectBIN); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("slow provider returned %v", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("slow provider returned after %v", d)
+	}
+
+	fast := &stubProvider{b: &BIN{Scheme: SchemeVisa}}
+	if b, err := Chain(Timeout(slow, 20*time.Millisecond), fast).Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeVisa {
+		t.Fatalf("chain returned %+v, %v", b, err)
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	heavy := &stubProvider{b: &BIN{Scheme: SchemeVisa}}
+	light := &stubProvider{b: &BIN{Scheme: SchemeMastercard}}
+	standby := &stubProvider{b: &BIN{Scheme: SchemeAmex}}
+
+	p := Weighted(WeightedProvider{standby, 0}, WeightedProvider{heavy, 3}, WeightedProvider{light, 1})
+	for range 2000 {
+		p.Search(context.TODO(), CorrectBIN)
+	}
+
+	if len(standby.bins) != 0 {
+		t.Fatalf("standby tried %d times while the others answered", len(standby.bins))
+	}
+	if share := float64(len(heavy.bins)) / 2000; share < 0.7 || share > 0.8 {
+		t.Fatalf("provider weighing 3 of 4 answered %v of the lookups", share)
+	}
+
+	heavy.b, heavy.err = nil, errors.New("down")
+	light.b, light.err = nil, errors.New("down")
+	if b, err := p.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeAmex {
+		t.Fatalf("lookup with the weighted providers down returned %+v, %v", b, err)
+	}
+}
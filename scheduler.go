@@ -0,0 +1,257 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Scheduler looks large queues of BINs up through Lookuper spread evenly
+// over time, a lookup every Per/Rate, so as to stay just under the quota
+// of upstream rather than burst through it and run into 429s, such as
+// nightly enrichments do. Unlike an `Enricher`, which makes its lookups as
+// fast as its workers go, the pace is that of the schedule, falling behind
+// it never being caught up with in a burst.
+//
+// When Lookuper is a `Client`, the pace is kept under the `Quota` it
+// reports too, its remaining requests spread until the quota resets when
+// fewer than the lookups left, lookups waiting out its
+// `Client.NextAllowedAt`; and the BINs it has
+// cached are looked up at once, not taking a turn of the schedule.
+//
+// A Scheduler can be paused and resumed while running, and reports its
+// `Progress`, the time left estimated by the schedule. It's to run a
+// queue at a time.
+type Scheduler struct {
+	Lookuper Lookuper
+
+	// Rate is the number of lookups made per Per. When zero, as when
+	// Per is, the pace is that of the quota of the `Client` only, the
+	// lookups being made back to back when there is none.
+	Rate int
+	Per  time.Duration
+
+	// Workers bounds the lookups in flight at once, those upstream being
+	// slower than the pace, `DefaultEnricherWorkers` when zero.
+	Workers int
+
+	mu       sync.Mutex
+	paused   bool
+	resumed  chan struct{}
+	start    time.Time
+	progress Progress
+	spacing  time.Duration
+}
+
+// Run looks bins up on schedule, giving onResult the index of each along
+// with its `Result` as it's over, from several goroutines at once. BINs
+// repeated in bins are looked up once, as `Enricher.Enrich` does.
+//
+// It returns the error of ctx when done before all of bins are over, the
+// lookups in flight being waited for.
+func (s *Scheduler) Run(ctx context.Context, bins []string, onResult func(i int, r Result)) error {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = DefaultEnricherWorkers
+	}
+
+	c, _ := s.Lookuper.(*Client)
+	key := func(bin string) string { return bin }
+	if c != nil {
+		key = c.batchKey
+	}
+	groups := dedupe(bins, key)
+
+	s.mu.Lock()
+	s.start = time.Now()
+	s.progress = Progress{Remaining: len(bins)}
+	s.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, workers)
+		next = time.Now()
+		err  error
+	)
+	for i, g := range groups {
+		bin := bins[g[0]]
+
+		if c == nil || !c.fresh(ctx, bin) {
+			spacing := s.pace(c, len(groups)-i)
+
+			slot := next
+			if c != nil {
+				if t := c.NextAllowedAt(); t.After(slot) {
+					slot = t
+				}
+			}
+			if err = s.wait(ctx, slot); err != nil {
+				break
+			}
+			if now := time.Now(); now.After(slot) {
+				slot = now
+			}
+			next = slot.Add(spacing)
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			b, err := s.Lookuper.Search(ctx, bin)
+			s.record(len(g), err)
+
+			if onResult != nil {
+				for _, i := range g {
+					onResult(i, Result{b, err})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return err
+}
+
+// Pause stops s from starting lookups, those in flight being left to
+// finish, until it's resumed.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.paused {
+		s.paused, s.resumed = true, make(chan struct{})
+	}
+}
+
+// Resume resumes starting the lookups of s on schedule, from the time
+// it's resumed on rather than in a burst catching up with those missed.
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused {
+		s.paused = false
+		close(s.resumed)
+	}
+}
+
+// Paused reports whether s is paused.
+func (s *Scheduler) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.paused
+}
+
+// Progress returns how far s is through the BINs of its queue. Its ETA is
+// that of the schedule of the BINs remaining, at the pace of the last
+// lookup started, which doesn't account for the time s stays paused.
+func (s *Scheduler) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.progress
+	if !s.start.IsZero() {
+		p.Elapsed = time.Since(s.start)
+	}
+	p.ETA = s.spacing * time.Duration(p.Remaining)
+
+	return p
+}
+
+// record counts n BINs over, as failed when their lookup failed otherwise
+// than with `ErrNotFound`.
+func (s *Scheduler) record(n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil || errors.Is(err, ErrNotFound) {
+		s.progress.Done += n
+	} else {
+		s.progress.Failed += n
+	}
+	s.progress.Remaining -= n
+}
+
+// pace returns the time between the lookups of s: that of Rate, or longer
+// for the remaining quota of c, if any, to last until it resets when the
+// lookups left are more than it covers.
+func (s *Scheduler) pace(c *Client, left int) (spacing time.Duration) {
+	if s.Rate > 0 && s.Per > 0 {
+		spacing = s.Per / time.Duration(s.Rate)
+	}
+
+	if c != nil {
+		now := time.Now()
+		if q, ok := c.Quota(); ok && q.ResetAt.After(now) {
+			if n, ok := c.QuotaRemaining(); ok && n > 0 && left > n {
+				spacing = max(spacing, q.ResetAt.Sub(now)/time.Duration(n))
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.spacing = spacing
+	s.mu.Unlock()
+
+	return
+}
+
+// wait returns once until is past and s isn't paused, or with the error
+// of ctx when it's done first.
+func (s *Scheduler) wait(ctx context.Context, until time.Time) error {
+	for {
+		s.mu.Lock()
+		paused, resumed := s.paused, s.resumed
+		s.mu.Unlock()
+
+		if paused {
+			select {
+			case <-resumed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		d := time.Until(until)
+		if d <= 0 {
+			return ctx.Err()
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// fresh reports whether the result of bin is cached by c, and fresh.
+func (c *Client) fresh(ctx context.Context, bin string) bool {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	key, ok := c.cacheKey(c.sent(bin))
+	if !ok || c.validate(bin) != nil {
+		return false
+	}
+
+	e, ok, err := c.cache.Get(ctx, key)
+
+	return err == nil && ok && e.Fresh(time.Now())
+}
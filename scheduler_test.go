@@ -0,0 +1,147 @@
+package binlookup
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	l := LookuperFunc(func(ctx context.Context, bin string) (*BIN, error) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+
+		return &BIN{Scheme: SchemeVisa}, nil
+	})
+
+	s := &Scheduler{Lookuper: l, Rate: 10, Per: 200 * time.Millisecond}
+	bins := []string{"45717360", "45717361", "45717362", "45717363", "45717360"}
+	results := make([]Result, len(bins))
+	if err := s.Run(context.TODO(), bins, func(i int, r Result) { results[i] = r }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(times) != 4 {
+		t.Fatalf("%d lookups made for 4 distinct BINs", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 15*time.Millisecond {
+			t.Fatalf("lookups %d and %d were %v apart, want 20ms", i-1, i, gap)
+		}
+	}
+	if results[4].BIN == nil {
+		t.Fatalf("repeated BIN left without a result: %+v", results)
+	}
+
+	if p := s.Progress(); p.Done != 5 || p.Remaining != 0 || p.ETA != 0 {
+		t.Fatalf("progress is %+v once over", p)
+	}
+}
+
+func TestSchedulerPause(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	l := LookuperFunc(func(ctx context.Context, bin string) (*BIN, error) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+
+		return nil, ErrNotFound
+	})
+
+	s := &Scheduler{Lookuper: l, Rate: 1, Per: 10 * time.Millisecond}
+
+	done := make(chan error)
+	s.Pause()
+	go func() { done <- s.Run(context.TODO(), []string{"45717360", "45717361", "45717362"}, nil) }()
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	n := len(times)
+	mu.Unlock()
+	if n != 0 || !s.Paused() {
+		t.Fatalf("%d lookups made while paused", n)
+	}
+	if p := s.Progress(); p.Remaining != 3 || p.ETA != 30*time.Millisecond {
+		t.Fatalf("progress is %+v while paused, want 3 remaining in 30ms", p)
+	}
+
+	resumed := time.Now()
+	s.Resume()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 3 || times[1].Sub(resumed) < 5*time.Millisecond {
+		t.Fatalf("lookups caught up in a burst once resumed: %v", times)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	s.Pause()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := s.Run(ctx, []string{"45717360"}, nil); err != context.Canceled {
+		t.Fatalf("run canceled while paused returned %v", err)
+	}
+}
+
+func TestSchedulerClient(t *testing.T) {
+	var requests int
+	count := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.Do(req)
+		})
+	}
+
+	c := New(WithCache(NewMemoryCache(0)), WithMiddleware(count, canned(http.StatusOK, cannedBIN)))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	s := &Scheduler{Lookuper: c, Rate: 1, Per: time.Hour}
+	if err := s.Run(ctx, []string{CorrectBIN, CorrectBIN}, nil); err != nil {
+		t.Fatalf("cached BIN waited for its turn: %v", err)
+	}
+	if requests != 1 || s.Progress().Done != 2 {
+		t.Fatalf("%d requests made, progress %+v", requests, s.Progress())
+	}
+}
+
+func TestSchedulerPace(t *testing.T) {
+	quota := func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err == nil {
+				resp.Header = http.Header{"X-Ratelimit-Remaining": {"1001"}, "X-Ratelimit-Reset": {"72000"}}
+			}
+			return resp, err
+		})
+	}
+
+	c := New(WithMiddleware(quota, canned(http.StatusOK, cannedBIN)))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	s := &Scheduler{Lookuper: c}
+	if d := s.pace(c, 10); d != 0 {
+		t.Fatalf("10 lookups left within a quota of 1000 are %v apart, want none", d)
+	}
+	if d := s.pace(c, 2000); d < 71*time.Second || d > 72*time.Second {
+		t.Fatalf("2000 lookups left over a quota of 1000 for 20h are %v apart, want 72s", d)
+	}
+}
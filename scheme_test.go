@@ -0,0 +1,76 @@
+package binlookup
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDetectScheme(t *testing.T) {
+	cases := map[string]Scheme{
+		"4":        SchemeVisa,
+		"45717360": SchemeVisa,
+		"5288230":  SchemeMastercard,
+		"2221":     SchemeMastercard,
+		"2720":     SchemeMastercard,
+		"378282":   SchemeAmex,
+		"601100":   SchemeDiscover,
+		"3530":     SchemeJCB,
+		"6212":     SchemeUnionPay,
+		"2200":     SchemeMir,
+		"6759":     SchemeMaestro,
+		"509091":   SchemeElo,
+		"438935":   SchemeElo,
+		"5019":     SchemeDankort,
+		"9792":     SchemeTroy,
+		"3056":     SchemeDiners,
+	}
+
+	for bin, want := range cases {
+		if got, ok := DetectScheme(bin); !ok || got != want {
+			t.Fatalf("DetectScheme(%v) = %v, %v, want %v", bin, got, ok, want)
+		}
+	}
+
+	for _, bin := range []string{"", "1", "2", "2721", "9999", "45x7"} {
+		if got, ok := DetectScheme(bin); ok {
+			t.Fatalf("DetectScheme(%q) = %v, want no match", bin, got)
+		}
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	cases := map[string]Scheme{
+		"visa":             SchemeVisa,
+		"VISA":             SchemeVisa,
+		" Visa ":           SchemeVisa,
+		"MasterCard":       SchemeMastercard,
+		"master card":      SchemeMastercard,
+		"American Express": SchemeAmex,
+		"Diners Club":      SchemeDiners,
+		"China UnionPay":   SchemeUnionPay,
+		"BankCard":         "bankcard",
+	}
+
+	for in, want := range cases {
+		if got := ParseScheme(in); got != want {
+			t.Fatalf("ParseScheme(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseCardType(t *testing.T) {
+	if ParseCardType(" DEBIT") != TypeDebit || ParseCardType("Credit") != TypeCredit {
+		t.Fatal("card types aren't normalized")
+	}
+}
+
+func TestBINNormalizesSchemeAndType(t *testing.T) {
+	var b BIN
+	if err := json.Unmarshal([]byte(`{"scheme":"MasterCard","type":"DEBIT"}`), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Scheme != SchemeMastercard || b.Type != TypeDebit {
+		t.Fatalf("decoded scheme %q and type %q", b.Scheme, b.Type)
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// adminPrefix is where the admin endpoints are served once enabled.
+const adminPrefix = "/admin/"
+
+// Admin configures the admin endpoints of a `Server`, for on-call
+// engineers to react to the incidents of providers without a redeploy:
+//
+//	GET /admin/stats                        the figures Stats returns
+//	POST /admin/cache/flush                 Flush the cache
+//	DELETE /admin/cache/{bin}               Invalidate the cached result of a BIN
+//	POST /admin/refresh                     Refresh the datasets
+//	GET /admin/providers                    the providers of Switch, enabled or not
+//	POST /admin/providers/{name}/enable     enable a provider of Switch
+//	POST /admin/providers/{name}/disable    disable a provider of Switch
+//
+// Every request is to be authenticated by Token, sent as a bearer token,
+// and is refused with 401 otherwise, every one of them when Token is
+// empty. The endpoints of the fields left nil answer 404.
+type Admin struct {
+	Token string
+
+	// Stats returns the figures to encode as JSON, such as the
+	// `binlookup.CacheStats` of the clients.
+	Stats func() any
+
+	// Flush removes every result cached, and Invalidate that of a BIN.
+	Flush      func(ctx context.Context) error
+	Invalidate func(ctx context.Context, bin string) error
+
+	// Refresh reloads the datasets looked up, such as a
+	// `dataset.Updater` does.
+	Refresh func(ctx context.Context) error
+
+	// Switch holds the providers which are enabled and disabled.
+	Switch *binlookup.Switch
+}
+
+// WithAdmin serves the admin endpoints a configures under /admin/.
+func WithAdmin(a *Admin) Option {
+	return func(s *Server) {
+		s.admin = a
+	}
+}
+
+func (a *Admin) serve(w http.ResponseWriter, r *http.Request) {
+	if !a.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="binlookup admin"`)
+		writeJSON(w, http.StatusUnauthorized, errorBody{http.StatusText(http.StatusUnauthorized)})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, adminPrefix)
+	route := func(method string, handler bool) bool {
+		if !handler {
+			writeJSON(w, http.StatusNotFound, errorBody{http.StatusText(http.StatusNotFound)})
+			return false
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, errorBody{http.StatusText(http.StatusMethodNotAllowed)})
+			return false
+		}
+		return true
+	}
+
+	switch {
+	case path == "stats":
+		if route(http.MethodGet, a.Stats != nil) {
+			writeJSON(w, http.StatusOK, a.Stats())
+		}
+	case path == "cache/flush":
+		if route(http.MethodPost, a.Flush != nil) {
+			a.done(w, a.Flush(r.Context()))
+		}
+	case strings.HasPrefix(path, "cache/"):
+		if route(http.MethodDelete, a.Invalidate != nil) {
+			a.done(w, a.Invalidate(r.Context(), strings.TrimPrefix(path, "cache/")))
+		}
+	case path == "refresh":
+		if route(http.MethodPost, a.Refresh != nil) {
+			a.done(w, a.Refresh(r.Context()))
+		}
+	case path == "providers":
+		if route(http.MethodGet, a.Switch != nil) {
+			writeJSON(w, http.StatusOK, a.Switch.States())
+		}
+	case strings.HasPrefix(path, "providers/"):
+		name, action, _ := strings.Cut(strings.TrimPrefix(path, "providers/"), "/")
+		if (action == "enable" || action == "disable") && route(http.MethodPost, a.Switch != nil) {
+			a.done(w, a.Switch.SetEnabled(name, action == "enable"))
+			return
+		}
+		fallthrough
+	default:
+		writeJSON(w, http.StatusNotFound, errorBody{http.StatusText(http.StatusNotFound)})
+	}
+}
+
+// authorized reports whether r is authenticated by the token of a.
+func (a *Admin) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && a.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1
+}
+
+// done answers an admin request which failed with err, or 204 when err is
+// nil. Unlike those of lookups, its errors are for the operators to see.
+func (a *Admin) done(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, binlookup.ErrUnknownProvider):
+		writeJSON(w, http.StatusNotFound, errorBody{err.Error()})
+	case errors.Is(err, binlookup.ErrInvalidBIN):
+		writeJSON(w, http.StatusBadRequest, errorBody{err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, errorBody{err.Error()})
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// fakeProvider is fake as a `binlookup.Provider`.
+type fakeProvider struct{ binlookup.LookuperFunc }
+
+func (fakeProvider) Capabilities() binlookup.Capabilities {
+	return binlookup.Capabilities{EightDigit: true, BankData: true}
+}
+
+func TestServerAdmin(t *testing.T) {
+	var (
+		flushed     bool
+		invalidated string
+	)
+	sw := binlookup.NewSwitch(binlookup.Named("fake", fakeProvider{fake}), binlookup.Static())
+	s := New(sw, WithAdmin(&Admin{
+		Token: "secret",
+		Stats: func() any { return map[string]int{"lookups": 3} },
+		Flush: func(context.Context) error {
+			flushed = true
+			return nil
+		},
+		Invalidate: func(_ context.Context, bin string) error {
+			invalidated = bin
+			return binlookup.ValidateBIN(bin)
+		},
+		Switch: sw,
+	}))
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+
+	for _, header := range []http.Header{nil, {"Authorization": {"Bearer wrong"}}} {
+		if w := serve(s, http.MethodGet, "/admin/stats", header); w.Code != http.StatusUnauthorized {
+			t.Fatalf("unauthenticated request served %d, want 401", w.Code)
+		}
+	}
+
+	w := serve(s, http.MethodGet, "/admin/stats", auth)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"lookups":3}` {
+		t.Fatalf("stats served %d %v", w.Code, w.Body)
+	}
+
+	if w := serve(s, http.MethodGet, "/admin/cache/flush", auth); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("flushing through GET served %d, want 405", w.Code)
+	}
+	if w := serve(s, http.MethodPost, "/admin/cache/flush", auth); w.Code != http.StatusNoContent || !flushed {
+		t.Fatalf("flushing served %d, flushed %v", w.Code, flushed)
+	}
+
+	if w := serve(s, http.MethodDelete, "/admin/cache/45717360", auth); w.Code != http.StatusNoContent || invalidated != "45717360" {
+		t.Fatalf("invalidating served %d, invalidated %q", w.Code, invalidated)
+	}
+	if w := serve(s, http.MethodDelete, "/admin/cache/4571x", auth); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalidating a malformed BIN served %d, want 400", w.Code)
+	}
+
+	if w := serve(s, http.MethodPost, "/admin/refresh", auth); w.Code != http.StatusNotFound {
+		t.Fatalf("refreshing without Refresh served %d, want 404", w.Code)
+	}
+
+	if w := serve(s, http.MethodPost, "/admin/providers/fake/disable", auth); w.Code != http.StatusNoContent {
+		t.Fatalf("disabling served %d %v", w.Code, w.Body)
+	}
+	if w := serve(s, http.MethodPost, "/admin/providers/other/disable", auth); w.Code != http.StatusNotFound {
+		t.Fatalf("disabling an unknown provider served %d, want 404", w.Code)
+	}
+
+	var states []binlookup.ProviderState
+	w = serve(s, http.MethodGet, "/admin/providers", auth)
+	if err := json.Unmarshal(w.Body.Bytes(), &states); err != nil || len(states) != 2 || states[0].Enabled || !states[1].Enabled {
+		t.Fatalf("providers served %d %v", w.Code, w.Body)
+	}
+
+	if w := serve(s, http.MethodGet, "/45717360", nil); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Jyske Bank") {
+		t.Fatalf("lookup served %d %v, want the static provider's", w.Code, w.Body)
+	}
+}
+
+func TestServerAdminTokenRequired(t *testing.T) {
+	s := New(fake, WithAdmin(&Admin{Stats: func() any { return nil }}))
+
+	if w := serve(s, http.MethodGet, "/admin/stats", http.Header{"Authorization": {"Bearer "}}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("admin without a token served %d, want 401", w.Code)
+	}
+}
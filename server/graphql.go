@@ -0,0 +1,574 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// graphQLPath is where the GraphQL endpoint is served once enabled.
+const graphQLPath = "/graphql"
+
+// maxGraphQLBody bounds the size of the GraphQL requests read.
+const maxGraphQLBody = 64 << 10
+
+// WithGraphQL serves a GraphQL endpoint at /graphql, over GET with the
+// query in the query string and over POST with a JSON body, for clients
+// to ask for the fields they need only:
+//
+//	{ bin(iin: "45717360") { scheme country { emoji } } }
+//
+// Its schema has a single query, bin(iin: String!), resolving to the BIN
+// whose fields, and those of its objects, are named as in the JSON of
+// upstream. The endpoint implements queries only, with aliases and
+// variables, but neither fragments nor directives.
+func WithGraphQL() Option {
+	return func(s *Server) {
+		s.graphql = true
+	}
+}
+
+// graphQLRequest is a GraphQL request, as sent in the body of a POST.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphQLError is an error of a GraphQL response.
+type graphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []graphQLError `json:"errors,omitempty"`
+}
+
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: []graphQLError{{Message: "Malformed Variables"}}})
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: []graphQLError{{Message: "Malformed Request"}}})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{http.StatusText(http.StatusMethodNotAllowed)})
+		return
+	}
+
+	sel, err := parseGraphQL(req.Query, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: []graphQLError{{Message: err.Error()}}})
+		return
+	}
+
+	ctx := r.Context()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var resp graphQLResponse
+	data := object{}
+	for _, f := range sel {
+		switch f.name {
+		case "__typename":
+			data = append(data, member{f.key(), "Query"})
+		case "bin":
+			v, errs := s.resolveBIN(ctx, f)
+			data = append(data, member{f.key(), v})
+			resp.Errors = append(resp.Errors, errs...)
+		default:
+			resp.Errors = append(resp.Errors, graphQLError{Message: fmt.Sprintf("Cannot Query Field %q on Query", f.name)})
+		}
+	}
+	resp.Data = data
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// resolveBIN looks up the BIN of the field f and selects its fields.
+func (s *Server) resolveBIN(ctx context.Context, f field) (v interface{}, errs []graphQLError) {
+	path := []interface{}{f.key()}
+
+	iin, ok := f.args["iin"].(string)
+	if !ok {
+		return nil, []graphQLError{{Message: `Argument "iin" of Type String! Is Required`, Path: path}}
+	}
+	if f.sel == nil {
+		return nil, []graphQLError{{Message: `Field "bin" Must Have a Selection of Subfields`, Path: path}}
+	}
+
+	b, err := s.lookuper.Search(ctx, iin)
+	if err != nil {
+		// As for the REST endpoint, only the status text is sent.
+		return nil, []graphQLError{{Message: http.StatusText(statusOf(err)), Path: path}}
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, []graphQLError{{Message: http.StatusText(http.StatusInternalServerError), Path: path}}
+	}
+
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+
+	return project(m, f.sel, path, "")
+}
+
+// project selects the fields sel of the JSON object m, the BIN field typ
+// or the BIN itself when empty, at path.
+func project(m map[string]interface{}, sel []field, path []interface{}, typ string) (v object, errs []graphQLError) {
+	v = object{}
+	for _, f := range sel {
+		p := append(append([]interface{}(nil), path...), f.key())
+
+		if f.name == "__typename" {
+			v = append(v, member{f.key(), typeName(typ)})
+			continue
+		}
+
+		fv, known := m[f.name]
+		if !known && !nullable(typ, f.name) {
+			errs = append(errs, graphQLError{Message: fmt.Sprintf("Cannot Query Field %q", f.name), Path: p})
+			continue
+		}
+
+		sub, isObject := fv.(map[string]interface{})
+		switch {
+		case fv == nil:
+			v = append(v, member{f.key(), nil})
+		case isObject && f.sel == nil:
+			errs = append(errs, graphQLError{Message: fmt.Sprintf("Field %q Must Have a Selection of Subfields", f.name), Path: p})
+		case isObject:
+			o, oerrs := project(sub, f.sel, p, f.name)
+			v = append(v, member{f.key(), o})
+			errs = append(errs, oerrs...)
+		case f.sel != nil:
+			errs = append(errs, graphQLError{Message: fmt.Sprintf("Field %q Has No Subfields", f.name), Path: p})
+		default:
+			v = append(v, member{f.key(), fv})
+		}
+	}
+
+	return
+}
+
+// schemaObjects maps the object fields of the schema to the fields they
+// have, for the fields a BIN lacks, such as those of a missing bank, to
+// resolve to null rather than fail.
+var schemaObjects = map[string][]string{
+	"number":  {"length", "luhn"},
+	"country": {"numeric", "alpha2", "name", "emoji", "currency", "latitude", "longitude"},
+	"bank":    {"name", "url", "phone", "city"},
+}
+
+// nullable reports whether name is a field of the BIN field typ, or of
+// the BIN when empty, which the JSON of a BIN may omit.
+func nullable(typ, name string) bool {
+	if typ == "" {
+		_, ok := schemaObjects[name]
+		return ok || name == "scheme" || name == "type" || name == "brand" || name == "prepaid"
+	}
+
+	for _, f := range schemaObjects[typ] {
+		if f == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeName returns the GraphQL type of the BIN field typ, or of the BIN
+// when empty.
+func typeName(typ string) string {
+	if typ == "" {
+		return "BIN"
+	}
+
+	return strings.ToUpper(typ[:1]) + typ[1:]
+}
+
+// object is a JSON object keeping the order of its members, which GraphQL
+// responses follow that of the query in.
+type object []member
+
+type member struct {
+	key   string
+	value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(m.key)
+		v, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// field is a field selected by a GraphQL query.
+type field struct {
+	alias, name string
+	args        map[string]interface{}
+	sel         []field
+}
+
+// key returns the key of f in the response.
+func (f field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+
+	return f.name
+}
+
+// errGraphQLSyntax is the error of the queries that can't be parsed.
+var errGraphQLSyntax = errors.New("Syntax Error")
+
+// parseGraphQL parses the query document q into the fields it selects,
+// with the values of vars substituted for its variables.
+func parseGraphQL(q string, vars map[string]interface{}) ([]field, error) {
+	p := &parser{src: q, vars: vars}
+	p.next()
+
+	if p.tok == "query" {
+		p.next()
+		if p.kind == tokenName {
+			p.next()
+		}
+		if p.tok == "(" {
+			if err := p.variableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if p.kind != tokenEOF {
+		return nil, p.errorf("unexpected %q", p.tok)
+	}
+
+	return sel, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenNumber
+	tokenPunct
+	tokenInvalid
+)
+
+// parser is a recursive descent parser of the subset of GraphQL served.
+type parser struct {
+	src  string
+	pos  int
+	vars map[string]interface{}
+
+	kind tokenKind
+	tok  string
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	// The tokens quoted may be those of card numbers.
+	return fmt.Errorf("%w at %d: %v", errGraphQLSyntax, p.pos, binlookup.Redact(fmt.Sprintf(format, args...)))
+}
+
+// next moves to the next token, skipping whitespace, commas and comments.
+func (p *parser) next() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ',' {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos >= len(p.src) {
+		p.kind, p.tok = tokenEOF, ""
+		return
+	}
+
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.kind = tokenName
+	case c == '-' || isDigit(c):
+		p.pos++
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || strings.IndexByte(".eE+-", p.src[p.pos]) >= 0) {
+			p.pos++
+		}
+		p.kind = tokenNumber
+	case c == '"':
+		p.pos++
+		for p.pos < len(p.src) && p.src[p.pos] != '"' {
+			if p.src[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		p.pos++
+		p.kind = tokenString
+		if p.pos > len(p.src) {
+			p.pos, p.kind = len(p.src), tokenInvalid
+		}
+	case strings.IndexByte("{}():$![]=", c) >= 0:
+		p.pos++
+		p.kind = tokenPunct
+	default:
+		p.pos++
+		p.kind = tokenInvalid
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+func (p *parser) expect(tok string) error {
+	if p.tok != tok || (p.kind != tokenPunct && p.kind != tokenName) {
+		return p.errorf("expected %q, found %q", tok, p.tok)
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	if p.kind != tokenName {
+		return "", p.errorf("expected a name, found %q", p.tok)
+	}
+	name := p.tok
+	p.next()
+
+	return name, nil
+}
+
+// variableDefinitions parses the definitions of the variables of an
+// operation, which are only checked for being given when non-null.
+func (p *parser) variableDefinitions() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for p.tok != ")" {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+
+		required, err := p.typeRef()
+		if err != nil {
+			return err
+		}
+
+		if p.tok == "=" {
+			p.next()
+			v, err := p.value()
+			if err != nil {
+				return err
+			}
+			if _, ok := p.vars[name]; !ok {
+				if p.vars == nil {
+					p.vars = make(map[string]interface{})
+				}
+				p.vars[name] = v
+			}
+		}
+
+		if _, ok := p.vars[name]; required && !ok {
+			return fmt.Errorf("Variable $%v Is Required", name)
+		}
+	}
+
+	return p.expect(")")
+}
+
+// typeRef parses a type reference, reporting whether it's non-null.
+func (p *parser) typeRef() (required bool, err error) {
+	if p.tok == "[" {
+		p.next()
+		if _, err = p.typeRef(); err != nil {
+			return
+		}
+		if err = p.expect("]"); err != nil {
+			return
+		}
+	} else if _, err = p.name(); err != nil {
+		return
+	}
+
+	if p.tok == "!" {
+		p.next()
+		required = true
+	}
+
+	return
+}
+
+func (p *parser) selectionSet() (sel []field, err error) {
+	if err = p.expect("{"); err != nil {
+		return
+	}
+
+	for p.tok != "}" {
+		if p.kind == tokenEOF {
+			return nil, p.errorf("unterminated selection set")
+		}
+
+		var f field
+		if f, err = p.field(); err != nil {
+			return
+		}
+		sel = append(sel, f)
+	}
+	p.next()
+
+	if len(sel) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+
+	return
+}
+
+func (p *parser) field() (f field, err error) {
+	if f.name, err = p.name(); err != nil {
+		return
+	}
+
+	if p.tok == ":" {
+		p.next()
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return
+		}
+	}
+
+	if p.tok == "(" {
+		p.next()
+		f.args = make(map[string]interface{})
+		for p.tok != ")" {
+			var name string
+			if name, err = p.name(); err != nil {
+				return
+			}
+			if err = p.expect(":"); err != nil {
+				return
+			}
+			if f.args[name], err = p.value(); err != nil {
+				return
+			}
+		}
+		p.next()
+	}
+
+	if p.tok == "{" {
+		f.sel, err = p.selectionSet()
+	}
+
+	return
+}
+
+// value parses an argument value: a string, a number, a boolean, null or
+// a variable.
+func (p *parser) value() (v interface{}, err error) {
+	switch p.kind {
+	case tokenString:
+		var s string
+		if s, err = strconv.Unquote(p.tok); err != nil {
+			return nil, p.errorf("malformed string %v", p.tok)
+		}
+		v = s
+	case tokenNumber:
+		if v, err = strconv.ParseFloat(p.tok, 64); err != nil {
+			return nil, p.errorf("malformed number %v", p.tok)
+		}
+	case tokenName:
+		switch p.tok {
+		case "true", "false":
+			v = p.tok == "true"
+		case "null":
+		default:
+			v = p.tok
+		}
+	default:
+		if p.tok != "$" {
+			return nil, p.errorf("expected a value, found %q", p.tok)
+		}
+
+		p.next()
+		var name string
+		if name, err = p.name(); err != nil {
+			return
+		}
+
+		return p.vars[name], nil
+	}
+	p.next()
+
+	return
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
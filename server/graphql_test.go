@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func graphQL(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestServerGraphQL(t *testing.T) {
+	s := New(fake, WithGraphQL())
+
+	for name, tc := range map[string]struct {
+		body string
+		want string
+	}{
+		"Fields": {
+			`{"query":"{ bin(iin: \"45717360\") { scheme bank { name } country { emoji } } }"}`,
+			`{"data":{"bin":{"scheme":"visa","bank":{"name":"Jyske Bank"},"country":{"emoji":null}}}}`,
+		},
+		"Variables": {
+			`{"query":"query Lookup($iin: String!) { card: bin(iin: $iin) { __typename t: type } }","variables":{"iin":"45717360"}}`,
+			`{"data":{"card":{"__typename":"BIN","t":null}}}`,
+		},
+		"LookupError": {
+			`{"query":"{ a: bin(iin: \"42424242\") { scheme } b: bin(iin: \"45717360\") { scheme } }"}`,
+			`{"data":{"a":null,"b":{"scheme":"visa"}},"errors":[{"message":"Too Many Requests","path":["a"]}]}`,
+		},
+		"UnknownField": {
+			`{"query":"{ bin(iin: \"45717360\") { scheme cvv } }"}`,
+			`{"data":{"bin":{"scheme":"visa"}},"errors":[{"message":"Cannot Query Field \"cvv\"","path":["bin","cvv"]}]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := graphQL(s, tc.body)
+			if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != tc.want {
+				t.Fatalf("answered with %d %s, want %s", w.Code, w.Body, tc.want)
+			}
+		})
+	}
+}
+
+func TestServerGraphQLMalformed(t *testing.T) {
+	s := New(fake, WithGraphQL())
+
+	for _, body := range []string{
+		`{"query":"{ bin(iin: \"45717360\") { scheme }"}`,
+		`{"query":"query ($iin: String!) { bin(iin: $iin) { scheme } }"}`,
+		`{"query":"{}"}`,
+		`not json`,
+	} {
+		if w := graphQL(s, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s answered with %d %s", body, w.Code, w.Body)
+		}
+	}
+}
+
+func TestServerGraphQLGet(t *testing.T) {
+	q := url.Values{"query": {`{ bin(iin: "45717360") { scheme } }`}}
+	w := serve(New(fake, WithGraphQL()), http.MethodGet, "/graphql?"+q.Encode(), nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"scheme":"visa"`) {
+		t.Fatalf("answered with %d %s", w.Code, w.Body)
+	}
+
+	if w := serve(New(fake), http.MethodGet, "/graphql?"+q.Encode(), nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("GraphQL served unless enabled, answering with %d", w.Code)
+	}
+}
+
+func TestServerGraphQLRedacts(t *testing.T) {
+	s := New(fake, WithGraphQL())
+
+	w := graphQL(s, `{"query":"{ 4571736012345678 }"}`)
+	if w.Code != http.StatusBadRequest || strings.Contains(w.Body.String(), "12345678") {
+		t.Fatalf("answered with %d %s, quoting the card number", w.Code, w.Body)
+	}
+}
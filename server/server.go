@@ -0,0 +1,318 @@
+// Package server serves BIN lookups over HTTP, in the API of binlist, so
+// that browsers and services in other languages share the cache, limits
+// and providers of a single `binlookup.Client`:
+//
+//	GET /{bin}            the BIN as upstream encodes it, see `WithPaylike`
+//	GET /scheme/{prefix}  the scheme told by the first digits alone
+//	GET|POST /graphql     the fields of BINs asked for, see `WithGraphQL`
+//	GET /healthz          whether upstream is up, see `WithHealthTTL`
+//	/admin/...            the admin endpoints, see `WithAdmin`
+//
+// The second one answers out of `binlookup.DetectScheme`, without any
+// lookup, for card forms to render the brand of a card as it's typed.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// DefaultTimeout bounds the lookups served, unless changed with
+// `WithTimeout`.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultHealthTTL is how long the outcome of a health check is reused,
+// unless changed with `WithHealthTTL`.
+const DefaultHealthTTL = time.Minute
+
+// Server is the http.Handler serving lookups through a
+// `binlookup.Lookuper`.
+type Server struct {
+	lookuper binlookup.Lookuper
+	timeout  time.Duration
+	origins  []string
+	graphql  bool
+	admin    *Admin
+	paylike  bool
+
+	healthTTL time.Duration
+	healthMu  sync.Mutex
+	checked   time.Time
+	healthErr error
+}
+
+// Option configures a `Server`.
+type Option func(*Server)
+
+// WithTimeout bounds every lookup to d, `DefaultTimeout` unless changed.
+// Zero leaves them bound by the Lookuper only.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.timeout = d
+	}
+}
+
+// WithCORS allows the browsers on origins to call the `Server`, answering
+// their preflight requests. An origin of "*" allows any.
+func WithCORS(origins ...string) Option {
+	return func(s *Server) {
+		s.origins = append(s.origins, origins...)
+	}
+}
+
+// WithHealthTTL makes the `Server` reuse the outcome of a health check for
+// d, `DefaultHealthTTL` unless changed, since checking upstream costs a
+// request of its quota while probes may come every few seconds. Zero checks
+// upstream on every probe.
+func WithHealthTTL(d time.Duration) Option {
+	return func(s *Server) {
+		s.healthTTL = d
+	}
+}
+
+// WithPaylike makes the `Server` answer lookups in the shape of the
+// original JavaScript library, see `binlookup.PaylikeJSON`, rather than in
+// that of upstream, for the clients of a Node service built on it to be
+// moved over unchanged. BINs not found are still answered with 404.
+func WithPaylike() Option {
+	return func(s *Server) {
+		s.paylike = true
+	}
+}
+
+// New returns a `Server` looking BINs up through l.
+func New(l binlookup.Lookuper, opts ...Option) *Server {
+	s := &Server{lookuper: l, timeout: DefaultTimeout, healthTTL: DefaultHealthTTL}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// ServeHTTP serves r.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.cors(w, r) {
+		return
+	}
+
+	if s.graphql && r.URL.Path == graphQLPath {
+		s.serveGraphQL(w, r)
+		return
+	}
+
+	if s.admin != nil && strings.HasPrefix(r.URL.Path, adminPrefix) {
+		s.admin.serve(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{http.StatusText(http.StatusMethodNotAllowed)})
+		return
+	}
+
+	if r.URL.Path == "/healthz" {
+		s.health(w, r)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if prefix, ok := strings.CutPrefix(path, "scheme/"); ok {
+		s.scheme(w, prefix)
+		return
+	}
+
+	if path == "" || strings.Contains(path, "/") {
+		writeJSON(w, http.StatusNotFound, errorBody{http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	s.search(w, r, path)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, bin string) {
+	ctx := r.Context()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	b, err := s.lookuper.Search(ctx, bin)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if s.paylike {
+		writeJSON(w, http.StatusOK, binlookup.PaylikeJSON{BIN: b})
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+// health answers whether upstream is up, by the `binlookup.Pinger` the
+// Lookuper is, and 200 at once when it isn't one.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	ok := struct {
+		Status string `json:"status"`
+	}{"ok"}
+
+	p, pinger := s.lookuper.(binlookup.Pinger)
+	if !pinger {
+		writeJSON(w, http.StatusOK, ok)
+		return
+	}
+
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	if s.checked.IsZero() || time.Since(s.checked) >= s.healthTTL {
+		ctx := r.Context()
+		if s.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+		}
+
+		// Probes hanging up don't tell anything about upstream.
+		if err := p.Ping(ctx); r.Context().Err() == nil {
+			s.healthErr, s.checked = err, time.Now()
+		} else {
+			s.healthErr = err
+		}
+	}
+
+	if s.healthErr != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorBody{http.StatusText(http.StatusServiceUnavailable)})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ok)
+}
+
+func (s *Server) scheme(w http.ResponseWriter, prefix string) {
+	scheme, ok := binlookup.DetectScheme(prefix)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorBody{http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Scheme binlookup.Scheme `json:"scheme"`
+		Name   string           `json:"name"`
+	}{scheme, scheme.DisplayName()})
+}
+
+// cors sets the CORS headers of the answer to r, and reports whether r is
+// a preflight request it answered.
+func (s *Server) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(s.origins) == 0 {
+		return false
+	}
+
+	w.Header().Add("Vary", "Origin")
+	if !s.allowed(origin) {
+		return false
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+
+	methods := "GET, OPTIONS"
+	if s.graphql {
+		methods = "GET, POST, OPTIONS"
+	}
+	w.Header().Set("Access-Control-Allow-Methods", methods)
+	w.Header().Set("Access-Control-Allow-Headers", "Accept-Version, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+	w.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+func (s *Server) allowed(origin string) bool {
+	for _, o := range s.origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// writeError answers with the status matching err, the way upstream
+// would: 400 for invalid BINs, 404 for unknown ones and 429 when rate
+// limited, along with the Retry-After of upstream.
+func writeError(w http.ResponseWriter, err error) {
+	status := statusOf(err)
+
+	var he *binlookup.HTTPError
+	if errors.As(err, &he) {
+		if v := he.Header.Get("Retry-After"); v != "" {
+			w.Header().Set("Retry-After", v)
+		}
+	}
+
+	// The problems of malformed BINs, for the callers to tell which, are
+	// sent along.
+	var be *binlookup.BINError
+	if errors.As(err, &be) {
+		writeJSON(w, status, struct {
+			errorBody
+			Problems []binlookup.Problem `json:"problems"`
+		}{errorBody{http.StatusText(status)}, be.Problems})
+		return
+	}
+
+	// Only the status text is sent otherwise, the errors of lookups
+	// holding details, such as URLs, that aren't for the callers to see.
+	writeJSON(w, status, errorBody{http.StatusText(status)})
+}
+
+// statusOf returns the status matching the error of a lookup.
+func statusOf(err error) (status int) {
+	status = http.StatusBadGateway
+	switch {
+	case errors.Is(err, binlookup.ErrInvalidBIN), errors.Is(err, binlookup.ErrBadRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, binlookup.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, binlookup.ErrRateLimited):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, binlookup.ErrNetworkDisabled), errors.Is(err, binlookup.ErrClosed):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+
+	return
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status, body = http.StatusInternalServerError, []byte(`{"error":"Internal Server Error"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
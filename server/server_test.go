@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+var fake = binlookup.LookuperFunc(func(_ context.Context, bin string) (*binlookup.BIN, error) {
+	switch bin {
+	case "45717360":
+		return &binlookup.BIN{Scheme: binlookup.SchemeVisa, Bank: &binlookup.Bank{Name: "Jyske Bank"}}, nil
+	case "42424242":
+		return nil, fmt.Errorf("Failed Due to Status Code Error: %w", &binlookup.HTTPError{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": {"60"}},
+		})
+	case "52882300":
+		return nil, errors.New("connection reset")
+	}
+
+	return nil, binlookup.ValidateBIN(bin)
+})
+
+func serve(h http.Handler, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestServerSearch(t *testing.T) {
+	s := New(fake)
+
+	w := serve(s, http.MethodGet, "/45717360", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("lookup answered with %d", w.Code)
+	}
+
+	var b binlookup.BIN
+	if err := json.Unmarshal(w.Body.Bytes(), &b); err != nil || b.Bank.Name != "Jyske Bank" {
+		t.Fatalf("lookup answered with %s", w.Body)
+	}
+
+	for path, want := range map[string]int{
+		"/42424242": http.StatusTooManyRequests,
+		"/52882300": http.StatusBadGateway,
+		"/0812436":  http.StatusBadRequest,
+	} {
+		if w := serve(s, http.MethodGet, path, nil); w.Code != want {
+			t.Fatalf("%v answered with %d, want %d", path, w.Code, want)
+		}
+	}
+
+	if w := serve(s, http.MethodGet, "/42424242", nil); w.Header().Get("Retry-After") != "60" {
+		t.Fatal("Retry-After of upstream isn't passed on")
+	}
+	if w := serve(s, http.MethodGet, "/0812x36", nil); strings.TrimSpace(w.Body.String()) != `{"error":"Bad Request","problems":[{"code":"leading_zero","position":1},{"code":"non_digit","position":5}]}` {
+		t.Fatalf("malformed BIN answered with %s", w.Body)
+	}
+}
+
+func TestServerPaylike(t *testing.T) {
+	w := serve(New(fake, WithPaylike()), http.MethodGet, "/45717360", nil)
+
+	want := `{"number":{},"scheme":"visa","type":null,"brand":null,"prepaid":null,"country":{},"bank":{"name":"Jyske Bank"}}`
+	if got := strings.TrimSpace(w.Body.String()); w.Code != http.StatusOK || got != want {
+		t.Fatalf("lookup answered with %d %v, want %v", w.Code, got, want)
+	}
+}
+
+func TestServerScheme(t *testing.T) {
+	s := New(binlookup.LookuperFunc(func(context.Context, string) (*binlookup.BIN, error) {
+		t.Fatal("scheme fast path looked the BIN up")
+		return nil, nil
+	}))
+
+	w := serve(s, http.MethodGet, "/scheme/4", nil)
+	if w.Code != http.StatusOK || w.Body.String() != `{"scheme":"visa","name":"Visa"}` {
+		t.Fatalf("scheme answered with %d %s", w.Code, w.Body)
+	}
+
+	if w := serve(s, http.MethodGet, "/scheme/0", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown scheme answered with %d", w.Code)
+	}
+}
+
+func TestServerCORS(t *testing.T) {
+	s := New(fake, WithCORS("https://shop.example"))
+
+	preflight := http.Header{"Origin": {"https://shop.example"}, "Access-Control-Request-Method": {"GET"}}
+	w := serve(s, http.MethodOptions, "/45717360", preflight)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://shop.example" {
+		t.Fatalf("preflight answered with %d %v", w.Code, w.Header())
+	}
+
+	w = serve(s, http.MethodGet, "/45717360", http.Header{"Origin": {"https://evil.example"}})
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("origin not allowed was allowed")
+	}
+}
+
+type pinger struct {
+	binlookup.Lookuper
+	pings int
+	err   error
+}
+
+func (p *pinger) Ping(context.Context) error {
+	p.pings++
+	return p.err
+}
+
+func TestServerHealth(t *testing.T) {
+	if w := serve(New(fake), http.MethodGet, "/healthz", nil); w.Code != http.StatusOK {
+		t.Fatalf("health of a lookuper without Ping answered with %d", w.Code)
+	}
+
+	p := &pinger{Lookuper: fake, err: errors.New("connection refused")}
+	s := New(p)
+
+	if w := serve(s, http.MethodGet, "/healthz", nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("health with upstream down answered with %d", w.Code)
+	}
+
+	p.err = nil
+	if w := serve(s, http.MethodGet, "/healthz", nil); w.Code != http.StatusServiceUnavailable || p.pings != 1 {
+		t.Fatalf("health checked %d times within its TTL", p.pings)
+	}
+
+	s = New(p, WithHealthTTL(0))
+	if w := serve(s, http.MethodGet, "/healthz", nil); w.Code != http.StatusOK || p.pings != 2 {
+		t.Fatalf("health with upstream up answered with %d", w.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package binlookup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner(t *testing.T) {
+	s := &HMACSigner{Secret: []byte("secret"), KeyID: "k1", KeyIDHeader: "X-Key-Id", Now: func() time.Time { return time.Unix(1767225600, 0) }}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://lookup.binlist.net/45717360", nil)
+	if err := s.Sign(req); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("GET\n/45717360\n1767225600\n"))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := req.Header.Get(DefaultSignatureHeader); got != want {
+		t.Fatalf("signature is %v, want %v", got, want)
+	}
+	if req.Header.Get(DefaultTimestampHeader) != "1767225600" || req.Header.Get("X-Key-Id") != "k1" {
+		t.Fatalf("headers are %v", req.Header)
+	}
+
+	if err := new(HMACSigner).Sign(req); err == nil {
+		t.Fatal("signed without a secret")
+	}
+}
+
+func TestWithSigner(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(r.Method + "\n" + r.URL.EscapedPath() + "\n" + r.Header.Get(DefaultTimestampHeader) + "\n"))
+		if !hmac.Equal([]byte(r.Header.Get(DefaultSignatureHeader)), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetries(1), WithBackoff(ExponentialBackoff{}), WithSigner(&HMACSigner{Secret: []byte("secret")}))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("%d signed attempts, want 2", n)
+	}
+
+	failing := SignerFunc(func(*http.Request) error { return errors.New("vault sealed") })
+	c = New(WithBaseURL(srv.URL), WithRetries(3), WithSigner(failing))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup succeeded unsigned")
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("unsigned request sent, %d attempts", n)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build sqlite
+
+// The tests of this file run the statements of the package against SQLite
+// itself, out of the pure Go driver of modernc.org/sqlite:
+//
+//	go test -tags sqlite ./sqlitedb
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite returns a `DB` of a SQLite database of its own.
+func openSQLite(t *testing.T) (*DB, *sql.DB) {
+	sdb, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "bins.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+
+	db, err := Open(context.TODO(), sdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, sdb
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	db, _ := openSQLite(t)
+	testSearch(t, db)
+
+	// Importing again replaces the BINs of the same prefix or range.
+	if _, err := db.Import(context.TODO(), strings.NewReader(export)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.Len(context.TODO()); err != nil || n != 4 {
+		t.Fatalf("Len returned %d, %v after importing again", n, err)
+	}
+}
+
+func TestSQLiteSearchOverlapping(t *testing.T) {
+	db, _ := openSQLite(t)
+	testOverlapping(t, db)
+}
+
+func TestSQLiteProbeIndexed(t *testing.T) {
+	_, sdb := openSQLite(t)
+
+	rows, err := sdb.Query("EXPLAIN QUERY PLAN "+probe, 8, "45717360")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatal(err)
+		}
+		plan = append(plan, detail)
+	}
+
+	if p := strings.Join(plan, "; "); !strings.Contains(p, "INDEX bins_range (digits=? AND low<?)") || strings.Contains(p, "TEMP B-TREE") {
+		t.Fatalf("probe planned as %q, want a seek of bins_range", p)
+	}
+}
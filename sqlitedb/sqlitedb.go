@@ -0,0 +1,269 @@
+// Package sqlitedb serves BIN lookups out of a local SQLite database of
+// BIN prefixes and ranges, for deployments without any network access to
+// upstream:
+//
+//	sdb, _ := sql.Open("sqlite", "bins.db")
+//	db, err := sqlitedb.Open(ctx, sdb)
+//	if err != nil {
+//		return err
+//	}
+//	n, err := db.Import(ctx, export)
+//
+//	srv := server.New(binlookup.StaticFallback(db))
+//
+// The database is opened by the caller with the SQLite driver of their
+// choosing, which keeps the package free of cgo and of any dependency.
+// The statements are those of SQLite; other databases accepting them do
+// as well.
+package sqlitedb
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// ErrInvalidImport is returned importing a malformed line.
+var ErrInvalidImport = errors.New("Invalid Import")
+
+// schema creates the table of the BINs, keyed by the range of 4 to 8
+// digits they're of, from low to high included, a prefix being the range
+// of its own from and to itself. The scheme and country are copied out of
+// the JSON of the BIN for the database to be queried on its own. The
+// ranges are indexed by their number of digits and low bound, for the
+// lookups to probe each length by a seek of the index.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS bins (
+		low     TEXT NOT NULL,
+		high    TEXT NOT NULL,
+		digits  INTEGER NOT NULL,
+		scheme  TEXT NOT NULL,
+		country TEXT NOT NULL,
+		data    TEXT NOT NULL,
+		PRIMARY KEY (low, high)
+	)`,
+	`CREATE INDEX IF NOT EXISTS bins_range ON bins (digits, low, high)`,
+	`CREATE INDEX IF NOT EXISTS bins_country ON bins (country)`,
+}
+
+// probe returns the range of the digits given starting last at or before
+// the BIN given, the widest of those starting there, out of a seek of
+// bins_range. Whether it holds the BIN is up to its high bound.
+const probe = `SELECT high, data FROM bins WHERE digits = ? AND low <= ? ORDER BY low DESC, high DESC LIMIT 1`
+
+// upsert stores a BIN, replacing that of the same range.
+const upsert = `INSERT OR REPLACE INTO bins (low, high, digits, scheme, country, data) VALUES (?, ?, ?, ?, ?, ?)`
+
+// importBatch is the number of BINs imported per transaction, for an
+// import of millions of them not to hold a single one for its duration.
+const importBatch = 10000
+
+// DB is a `binlookup.Provider` looking BINs up in a database. It's safe
+// for concurrent use, as is the *sql.DB it wraps.
+type DB struct {
+	db *sql.DB
+}
+
+// Open returns the `DB` of db, creating its table when it doesn't exist.
+func Open(ctx context.Context, db *sql.DB) (*DB, error) {
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("Creating the Schema Failed: %w", err)
+		}
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Import stores the BINs read out of r into d, replacing those of the
+// same prefix or range, and returns how many it stored. r holds JSON
+// Lines, in the format of `dataset.Load`: one BIN per line, in the payload
+// of upstream, along with its prefix, or range such as
+// "40000000-49999999", under "bin". Blank lines are skipped.
+//
+// BINs are committed by batches: an import failing midway, with the error
+// of the malformed line telling its number, leaves those before it
+// stored, and can be run again as a whole.
+func (d *DB) Import(ctx context.Context, r io.Reader) (n int, err error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+
+	var tx *sql.Tx
+	var stmt *sql.Stmt
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+
+		err := tx.Commit()
+		tx = nil
+		return err
+	}
+
+	pending := 0
+	for line := 1; s.Scan(); line++ {
+		data := s.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		low, high, b, err := decode(data)
+		if err != nil {
+			return n, fmt.Errorf("%w: Line %d: %w", ErrInvalidImport, line, err)
+		}
+
+		payload, err := json.Marshal(b)
+		if err != nil {
+			return n, fmt.Errorf("%w: Line %d: %w", ErrInvalidImport, line, err)
+		}
+
+		if tx == nil {
+			if tx, err = d.db.BeginTx(ctx, nil); err != nil {
+				return n, err
+			}
+			if stmt, err = tx.PrepareContext(ctx, upsert); err != nil {
+				return n, err
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx, low, high, len(low), string(b.Scheme), b.Country.Short, string(payload)); err != nil {
+			return n, fmt.Errorf("Importing Line %d Failed: %w", line, err)
+		}
+
+		if pending++; pending == importBatch {
+			if err := commit(); err != nil {
+				return n, err
+			}
+			n, pending = n+pending, 0
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return n, err
+	}
+
+	if err := commit(); err != nil {
+		return n, err
+	}
+
+	return n + pending, nil
+}
+
+// decode decodes a line of an import, into the bounds of its range.
+func decode(data []byte) (low, high string, b *binlookup.BIN, err error) {
+	var key struct {
+		BIN string `json:"bin"`
+	}
+	if err = json.Unmarshal(data, &key); err != nil {
+		return
+	}
+
+	b = &binlookup.BIN{}
+	if err = json.Unmarshal(data, b); err != nil {
+		return
+	}
+	delete(b.Extra, "bin")
+	if len(b.Extra) == 0 {
+		b.Extra = nil
+	}
+
+	low, high, ok := strings.Cut(key.BIN, "-")
+	if !ok {
+		high = low
+	}
+
+	for _, bound := range []string{low, high} {
+		if len(bound) > 8 {
+			return "", "", nil, fmt.Errorf("Prefix %v Longer Than 8 Digits", binlookup.Redact(bound))
+		}
+		if err = binlookup.ValidateBIN(bound); err != nil {
+			return "", "", nil, err
+		}
+	}
+	if len(low) != len(high) || low > high {
+		return "", "", nil, fmt.Errorf("Range %v Isn't of Bounds in Order of the Same Length", key.BIN)
+	}
+
+	return low, high, b, nil
+}
+
+// Search returns the BIN of d of the longest prefix of bin, or of the
+// range of the longest bounds holding it, failing with
+// binlookup.ErrNotFound when there is none. A BIN shorter than the bounds
+// of a range is held in it when all of the BINs it spans are. Of the
+// ranges of the same length overlapping, the one starting last before bin
+// resolves it.
+func (d *DB) Search(ctx context.Context, bin string) (*binlookup.BIN, error) {
+	if err := binlookup.ValidateBIN(bin); err != nil {
+		return nil, err
+	}
+
+	data, err := d.probe(ctx, bin)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &binlookup.BIN{}
+	if err := json.Unmarshal([]byte(data), b); err != nil {
+		return nil, err
+	}
+	b.Meta.Provider = d.Name()
+
+	return b, nil
+}
+
+// probe returns the data of the BIN of the range holding bin, probing the
+// lengths of 8 digits to 4 in turn.
+func (d *DB) probe(ctx context.Context, bin string) (data string, err error) {
+	for n := 8; n >= 4; n-- {
+		// The lowest and highest BINs of n digits spanned by bin, the
+		// digits it's short of padded with 0s and 9s respectively.
+		p := bin[:min(len(bin), n)]
+		lo, hi := p+strings.Repeat("0", n-len(p)), p+strings.Repeat("9", n-len(p))
+
+		var high string
+		err = d.db.QueryRowContext(ctx, probe, n, lo).Scan(&high, &data)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if hi <= high {
+			return data, nil
+		}
+	}
+
+	return "", binlookup.ErrNotFound
+}
+
+// Len returns the number of BINs of d.
+func (d *DB) Len(ctx context.Context) (n int, err error) {
+	err = d.db.QueryRowContext(ctx, `SELECT count(*) FROM bins`).Scan(&n)
+	return
+}
+
+// Capabilities reports those of a database: 8 digit BINs and bank data,
+// offline.
+func (d *DB) Capabilities() binlookup.Capabilities {
+	return binlookup.Capabilities{EightDigit: true, BankData: true, Offline: true}
+}
+
+// Name returns "sqlite".
+func (d *DB) Name() string {
+	return "sqlite"
+}
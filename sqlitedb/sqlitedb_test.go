@@ -0,0 +1,281 @@
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/0xbkt/binlookup-go"
+)
+
+// fakeDriver is a database/sql driver running the statements of the
+// package against a map of the rows by their range, standing in for
+// SQLite in the tests, see sqlite_test.go for SQLite itself. A
+// transaction writes to the map on commit only.
+type fakeDriver struct {
+	mu      sync.Mutex
+	rows    map[string][]string
+	commits int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d       *fakeDriver
+	pending map[string][]string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = make(map[string][]string)
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	for k, v := range c.pending {
+		c.d.rows[k] = v
+	}
+	c.pending = nil
+	c.d.commits++
+
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+		return driver.RowsAffected(0), nil
+	case s.query == upsert:
+		row := make([]string, len(args))
+		for i, a := range args {
+			row[i] = fmt.Sprint(a)
+		}
+		key := row[0] + "-" + row[1]
+		if s.c.pending != nil {
+			s.c.pending[key] = row
+			return driver.RowsAffected(1), nil
+		}
+
+		s.c.d.mu.Lock()
+		s.c.d.rows[key] = row
+		s.c.d.mu.Unlock()
+		return driver.RowsAffected(1), nil
+	}
+
+	return nil, errors.New("unexpected statement " + s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.mu.Lock()
+	defer s.c.d.mu.Unlock()
+
+	switch s.query {
+	case probe:
+		digits, bin := fmt.Sprint(args[0]), args[1].(string)
+
+		var best []string
+		for _, row := range s.c.d.rows {
+			if row[2] != digits || row[0] > bin {
+				continue
+			}
+			if best == nil || row[0] > best[0] || row[0] == best[0] && row[1] > best[1] {
+				best = row
+			}
+		}
+		if best == nil {
+			return &fakeRows{}, nil
+		}
+		return &fakeRows{values: []driver.Value{best[1], best[5]}}, nil
+	case `SELECT count(*) FROM bins`:
+		return &fakeRows{values: []driver.Value{int64(len(s.c.d.rows))}}, nil
+	}
+
+	return nil, errors.New("unexpected query " + s.query)
+}
+
+type fakeRows struct {
+	values []driver.Value
+	read   bool
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, len(r.values)) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read || r.values == nil {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+
+	return nil
+}
+
+var (
+	fake     = &fakeDriver{}
+	register sync.Once
+)
+
+// open returns a `DB` of a database of its own.
+func open(t *testing.T) (*DB, *fakeDriver) {
+	register.Do(func() { sql.Register("fake", fake) })
+
+	fake.mu.Lock()
+	fake.rows, fake.commits = make(map[string][]string), 0
+	fake.mu.Unlock()
+
+	sdb, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+
+	db, err := Open(context.TODO(), sdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, fake
+}
+
+const export = `{"bin":"4571","scheme":"visa","country":{"alpha2":"DK"}}
+{"bin":"45717360","scheme":"visa","type":"debit","country":{"alpha2":"DK"},"bank":{"name":"Jyske Bank"}}
+
+{"bin":"528823","scheme":"mastercard","country":{"alpha2":"US"},"tier":"gold"}
+{"bin":"36000000-36499949","scheme":"diners","country":{"alpha2":"US"}}
+`
+
+func TestDBSearch(t *testing.T) {
+	db, _ := open(t)
+	testSearch(t, db)
+}
+
+// testSearch imports export into db and looks its BINs up.
+func testSearch(t *testing.T, db *DB) {
+	n, err := db.Import(context.TODO(), strings.NewReader(export))
+	if err != nil || n != 4 {
+		t.Fatalf("imported %d BINs: %v", n, err)
+	}
+
+	if n, err := db.Len(context.TODO()); err != nil || n != 4 {
+		t.Fatalf("Len returned %d, %v", n, err)
+	}
+
+	for bin, want := range map[string]string{
+		"4571736012345678": "Jyske Bank",
+		"45717360":         "Jyske Bank",
+		"45719999":         "",
+		"457199":           "",
+	} {
+		b, err := db.Search(context.TODO(), bin)
+		if err != nil {
+			t.Fatalf("looking %v up: %v", bin, err)
+		}
+
+		if b.Scheme != binlookup.SchemeVisa || (want == "") != (b.Bank == nil) || b.Meta.Provider != "sqlite" {
+			t.Fatalf("%v resolved as %+v", bin, b)
+		}
+	}
+
+	b, err := db.Search(context.TODO(), "52882301")
+	if err != nil || string(b.Extra["tier"]) != `"gold"` {
+		t.Fatalf("lookup returned %+v, %v", b, err)
+	}
+
+	for _, bin := range []string{"36499949", "3600000012345678", "360000"} {
+		if b, err := db.Search(context.TODO(), bin); err != nil || b.Scheme != binlookup.SchemeDiners {
+			t.Fatalf("lookup of %v in a range returned %+v, %v", bin, b, err)
+		}
+	}
+
+	for _, bin := range []string{"37000000", "36499950", "364999"} {
+		if _, err := db.Search(context.TODO(), bin); !errors.Is(err, binlookup.ErrNotFound) {
+			t.Fatalf("lookup of unknown BIN %v returned %v", bin, err)
+		}
+	}
+
+	if _, err := db.Search(context.TODO(), "abc"); !errors.Is(err, binlookup.ErrInvalidBIN) {
+		t.Fatalf("lookup of an invalid BIN returned %v", err)
+	}
+}
+
+func TestDBSearchOverlapping(t *testing.T) {
+	db, _ := open(t)
+	testOverlapping(t, db)
+}
+
+// testOverlapping imports ranges of the same length overlapping into db,
+// the one starting last before a BIN resolving it.
+func testOverlapping(t *testing.T, db *DB) {
+	_, err := db.Import(context.TODO(), strings.NewReader(`{"bin":"40000000-49999999","scheme":"visa"}
+{"bin":"45000000-45999999","scheme":"mastercard"}
+{"bin":"45000000-45499999","scheme":"amex"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for bin, want := range map[string]binlookup.Scheme{
+		"41000000": binlookup.SchemeVisa,
+		"45400000": binlookup.SchemeMastercard,
+		"455000":   binlookup.SchemeMastercard,
+	} {
+		if b, err := db.Search(context.TODO(), bin); err != nil || b.Scheme != want {
+			t.Fatalf("lookup of %v returned %+v, %v, want %v", bin, b, err, want)
+		}
+	}
+}
+
+func TestDBImportInvalid(t *testing.T) {
+	db, fake := open(t)
+
+	n, err := db.Import(context.TODO(), strings.NewReader(export+`{"bin":"012345","scheme":"visa"}`+"\n"))
+	if !errors.Is(err, ErrInvalidImport) || !strings.Contains(err.Error(), "Line 6") {
+		t.Fatalf("import returned %v", err)
+	}
+
+	// The batch of the malformed line is rolled back.
+	if n != 0 || len(fake.rows) != 0 || fake.commits != 0 {
+		t.Fatalf("imported %d BINs, %d stored", n, len(fake.rows))
+	}
+}
+
+func TestDBImportInvalidRange(t *testing.T) {
+	db, _ := open(t)
+
+	for _, key := range []string{"37999999-37000000", "370000-3799999", "37000000-"} {
+		_, err := db.Import(context.TODO(), strings.NewReader(`{"bin":"`+key+`","scheme":"amex"}`))
+		if !errors.Is(err, ErrInvalidImport) {
+			t.Fatalf("import of range %v returned %v", key, err)
+		}
+	}
+}
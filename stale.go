@@ -0,0 +1,76 @@
+package binlookup
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Failure is a set of the classes of failures of upstream, for
+// `WithStaleIfError` to serve stale BINs on.
+type Failure uint
+
+// The classes of failures of upstream.
+const (
+	// FailureRateLimited is upstream answering 429 Too Many Requests.
+	FailureRateLimited Failure = 1 << iota
+
+	// FailureClientError is upstream answering a 4xx other than 429 and
+	// 404 Not Found, which is an answer, such as a 401 of a revoked key.
+	FailureClientError
+
+	// FailureServerError is upstream answering a 5xx.
+	FailureServerError
+
+	// FailureNetwork is upstream not answering: the connection failing or
+	// the lookup timing out.
+	FailureNetwork
+
+	AllFailures = FailureRateLimited | FailureClientError | FailureServerError | FailureNetwork
+)
+
+// WithStaleIfError makes the `Client` answer the lookups failing upstream
+// for one of the classes of failures given with the BIN it cached last,
+// however long it expired, rather than with the error, where checkout
+// staying up matters more than the freshness of the issuer. The BINs
+// served so have `Meta.Stale` set, and stay cached as they were for the
+// next lookups to revalidate them. Lookups of BINs never cached, or out
+// of the cache since, fail as they would.
+//
+// Stale BINs are only as old as the cache keeps its expired entries:
+// `MemoryCache` keeps them until evicted, while caches expiring entries on
+// their own, like Redis, drop them.
+func WithStaleIfError(failures Failure) Option {
+	return func(c *Client) {
+		c.staleIfError = failures
+	}
+}
+
+// serveStale reports whether the lookup failing with err is to be
+// answered stale by c.
+func (c *Client) serveStale(err error) bool {
+	var malformed *MalformedError
+	if c.staleIfError == 0 || err == nil || errors.Is(err, ErrNotFound) || errors.As(err, &malformed) {
+		return false
+	}
+
+	var he *HTTPError
+	switch {
+	case !errors.As(err, &he):
+		return c.staleIfError&FailureNetwork != 0
+	case he.StatusCode == http.StatusTooManyRequests:
+		return c.staleIfError&FailureRateLimited != 0
+	case he.StatusCode >= http.StatusInternalServerError:
+		return c.staleIfError&FailureServerError != 0
+	default:
+		return c.staleIfError&FailureClientError != 0
+	}
+}
+
+// staleBIN returns a copy of b flagged as stale, b being shared by the
+// lookups served out of the cache.
+func staleBIN(b *BIN) *BIN {
+	s := *b
+	s.Meta.Stale = true
+
+	return &s
+}
@@ -0,0 +1,57 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientStaleIfError(t *testing.T) {
+	status := http.StatusOK
+	upstream := func(Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if status == 0 {
+				return nil, errors.New("connection refused")
+			}
+			return canned(status, cannedBIN)(nil).Do(req)
+		})
+	}
+
+	cache := NewMemoryCache(0)
+	c := New(WithCache(cache), WithMiddleware(upstream), WithErrorTTL(time.Minute), WithStaleIfError(FailureRateLimited|FailureNetwork))
+
+	cached, err := c.Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	expire := func() {
+		e, _, _ := cache.Get(context.TODO(), CorrectBIN)
+		e.Expires = time.Now().Add(-time.Hour)
+		cache.Set(context.TODO(), CorrectBIN, e)
+	}
+
+	for _, status = range []int{http.StatusTooManyRequests, 0} {
+		expire()
+
+		b, err := c.Search(context.TODO(), CorrectBIN)
+		if err != nil {
+			t.Fatalf("lookup failing with %d not served stale: %v", status, err)
+		}
+		if !b.Meta.Stale || b.Bank.Name != cached.Bank.Name || cached.Meta.Stale {
+			t.Fatalf("served %+v, want a stale copy of the cached BIN", b.Meta)
+		}
+	}
+
+	status = http.StatusServiceUnavailable
+	expire()
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup failing with 503 served stale")
+	}
+
+	if _, err := c.Search(context.TODO(), "52882301"); err == nil {
+		t.Fatal("lookup of a BIN never cached succeeded")
+	}
+}
@@ -0,0 +1,107 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+)
+
+// staticCountry is the country the cards of a domestic scheme are issued
+// in, along with its currency.
+type staticCountry struct {
+	alpha2, currency string
+}
+
+// staticCountries are the countries of the domestic schemes, whose ranges
+// alone tell the country of a card.
+var staticCountries = map[Scheme]staticCountry{
+	SchemeMir:     {"RU", "RUB"},
+	SchemeRuPay:   {"IN", "INR"},
+	SchemeElo:     {"BR", "BRL"},
+	SchemeTroy:    {"TR", "TRY"},
+	SchemeDankort: {"DK", "DKK"},
+}
+
+// Static returns a `Provider` answering out of the well-known ranges of
+// the schemes embedded in the package, without any network call: the
+// scheme of the BIN, see `DetectScheme`, and the country of those of the
+// domestic schemes, such as Mir or Elo. Its answers are flagged with
+// `Meta.Degraded`, and BINs of no known range aren't found.
+//
+// It's meant as the last resort of a chain, see `StaticFallback`, for a
+// checkout to go on with a partial answer rather than fail.
+func Static() Provider {
+	return static{}
+}
+
+type static struct{}
+
+func (static) Name() string { return "static" }
+
+func (static) Capabilities() Capabilities {
+	return Capabilities{EightDigit: true, Offline: true}
+}
+
+func (static) Search(_ context.Context, bin string) (*BIN, error) {
+	if err := ValidateBIN(bin); err != nil {
+		return nil, err
+	}
+
+	scheme, ok := DetectScheme(bin)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	b := &BIN{Scheme: scheme, Meta: Meta{Provider: "static", Degraded: true}}
+	if sc, ok := staticCountries[scheme]; ok {
+		codes, _ := LookupCountry(sc.alpha2)
+		b.Country = Country{
+			Numeric:  codes.Numeric,
+			Short:    codes.Alpha2,
+			Alpha3:   codes.Alpha3,
+			Name:     codes.Name,
+			Emoji:    flag(codes.Alpha2),
+			Currency: sc.currency,
+		}
+	}
+
+	return b, nil
+}
+
+// flag returns the emoji of the flag of the country of alpha2.
+func flag(alpha2 string) string {
+	runes := make([]rune, 0, 2)
+	for _, c := range alpha2 {
+		runes = append(runes, 0x1F1E6+c-'A')
+	}
+
+	return string(runes)
+}
+
+// StaticFallback returns a `Provider` looking BINs up through p, answering
+// out of `Static` when p fails. BINs p doesn't find, or finds malformed,
+// aren't answered by Static, and neither are the lookups whose context is
+// done.
+func StaticFallback(p Provider) Provider {
+	return &staticFallback{p}
+}
+
+type staticFallback struct {
+	Provider
+}
+
+func (f *staticFallback) Name() string {
+	return ProviderName(f.Provider)
+}
+
+func (f *staticFallback) Search(ctx context.Context, bin string) (*BIN, error) {
+	b, err := f.Provider.Search(ctx, bin)
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidBIN) || ctx.Err() != nil {
+		return b, err
+	}
+
+	if sb, serr := (static{}).Search(ctx, bin); serr == nil {
+		return sb, nil
+	}
+
+	return nil, err
+}
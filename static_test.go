@@ -0,0 +1,41 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStatic(t *testing.T) {
+	b, err := Static().Search(context.TODO(), "22004567")
+	if err != nil || b.Scheme != SchemeMir || b.Country.Short != "RU" || b.Country.Emoji != "🇷🇺" || b.Country.Currency != "RUB" || !b.Meta.Degraded {
+		t.Fatalf("static answer is %+v, %v", b, err)
+	}
+
+	if b, err := Static().Search(context.TODO(), "45717360"); err != nil || b.Scheme != SchemeVisa || b.Country.Short != "" {
+		t.Fatalf("static answer is %+v, %v", b, err)
+	}
+
+	if _, err := Static().Search(context.TODO(), "99999999"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("static answer of an unknown range is %v", err)
+	}
+}
+
+func TestStaticFallback(t *testing.T) {
+	stub := &stubProvider{err: errors.New("connection reset")}
+	p := StaticFallback(stub)
+
+	if b, err := p.Search(context.TODO(), "45717360"); err != nil || b.Scheme != SchemeVisa || !b.Meta.Degraded {
+		t.Fatalf("fallback answer is %+v, %v", b, err)
+	}
+
+	stub.err = ErrNotFound
+	if _, err := p.Search(context.TODO(), "45717360"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("BIN not found answered with %v", err)
+	}
+
+	stub.b, stub.err = &BIN{Scheme: SchemeAmex}, nil
+	if b, err := p.Search(context.TODO(), "45717360"); err != nil || b.Meta.Degraded {
+		t.Fatalf("answer of the provider is %+v, %v", b, err)
+	}
+}
@@ -0,0 +1,213 @@
+package binlookup
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxErrorSamples is the number of recent lookup errors a `Client` keeps
+// for its support bundle.
+const maxErrorSamples = 20
+
+// errorSample is a lookup error kept for the support bundle, its BIN
+// masked.
+type errorSample struct {
+	Time  time.Time `json:"time"`
+	BIN   string    `json:"bin"`
+	Error string    `json:"error"`
+}
+
+// recordError keeps the error err of the lookup of bin as a sample.
+func (c *Client) recordError(bin string, err error) {
+	masked := maskBIN(bin)
+	s := errorSample{Time: time.Now(), BIN: masked, Error: Redact(strings.ReplaceAll(err.Error(), bin, masked))}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.errorSamples) == maxErrorSamples {
+		c.errorSamples = append(c.errorSamples[:0], c.errorSamples[1:]...)
+	}
+	c.errorSamples = append(c.errorSamples, s)
+}
+
+// Versioner is implemented by the datasets a support bundle reports the
+// versions of, such as the stores of the dataset package.
+type Versioner interface {
+	// Version names the dataset in use, such as the date of its release.
+	Version() string
+}
+
+// SupportBundle writes diagnostics about c to w as a zip, for them to be
+// attached to the issues filed against this package:
+//
+//	config.json    the configuration of c, redacted
+//	errors.json    the most recent lookup errors, BINs masked
+//	stats.json     the figures of its cache, quota and latency
+//	versions.json  the versions of the package, Go, the platform and
+//	               datasets, in their order
+//
+// Nothing secret goes in: the credentials in URLs, the proxy, TLS and
+// middleware configurations, and the BINs looked up, are left out or
+// masked. The bundle still is worth a look before it's shared.
+func (c *Client) SupportBundle(w io.Writer, datasets ...Versioner) error {
+	versions := make([]string, len(datasets))
+	for i, d := range datasets {
+		versions[i] = d.Version()
+	}
+
+	z := zip.NewWriter(w)
+
+	for _, f := range []struct {
+		name string
+		v    any
+	}{
+		{"config.json", c.supportConfig()},
+		{"errors.json", c.supportErrors()},
+		{"stats.json", c.supportStats()},
+		{"versions.json", map[string]any{
+			"binlookup": Version,
+			"go":        runtime.Version(),
+			"platform":  runtime.GOOS + "/" + runtime.GOARCH,
+			"datasets":  versions,
+		}},
+	} {
+		fw, err := z.Create(f.name)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(f.v, "", "\t")
+		if err != nil {
+			return fmt.Errorf("Encoding %v Failed: %w", f.name, err)
+		}
+
+		if _, err := fw.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+func (c *Client) supportConfig() map[string]any {
+	c.reconf.RLock()
+	defer c.reconf.RUnlock()
+
+	base := c.baseURL
+	if u, err := url.Parse(base); err == nil {
+		u.User, u.RawQuery = nil, ""
+		base = u.String()
+	}
+
+	// The values of the headers may be secrets, such as the tokens of
+	// gateways; their names only are.
+	headers := make([]string, 0, len(c.header))
+	for k := range c.header {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+
+	cfg := map[string]any{
+		"base_url":                base,
+		"api_version":             c.apiVersion,
+		"user_agent":              c.userAgent,
+		"headers":                 headers,
+		"signer":                  fmt.Sprintf("%T", c.signer),
+		"quota_store":             fmt.Sprintf("%T", c.quotaStore),
+		"quota_fetcher":           c.quotaFetcher != nil,
+		"timeout":                 c.timeout.String(),
+		"retries":                 c.retries,
+		"retry_policy":            c.retryPolicy != nil,
+		"backoff":                 fmt.Sprintf("%T", c.backoff),
+		"per_attempt_timeout":     c.perAttempt.String(),
+		"connect_timeout":         c.connectTimeout.String(),
+		"tls_handshake_timeout":   c.tlsHandshakeTimeout.String(),
+		"response_header_timeout": c.responseHeaderTimeout.String(),
+		"close_grace_period":      c.gracePeriod.String(),
+		"retry_max_elapsed":       c.retryElapsed.String(),
+		"retry_max_backoff":       c.retryBackoff.String(),
+		"ip_family":               c.ipFamily,
+		"preconnect":              c.preconnect,
+		"max_idle_conns_per_host": c.maxIdleConnsPerHost,
+		"idle_conn_timeout":       c.idleConnTimeout.String(),
+		"keep_alive":              c.keepAlive.String(),
+		"http2_only":              c.http2Only,
+		"network_disabled":        c.offline,
+		"dry_run":                 c.dryRun,
+		"middleware":              len(c.middleware),
+		"custom_transport":        c.roundTripper != nil,
+		"proxy":                   c.proxy != nil,
+		"redirect_policy":         c.redirectPolicy != nil,
+		"tls_config":              c.tlsConfig != nil,
+		"pinned_keys":             len(c.pins),
+		"client_certificate":      c.clientCert != nil,
+		"root_cas":                c.rootCAs != nil,
+		"dns_cache":               c.dnsCache != nil,
+		"custom_resolver":         c.resolver != nil,
+		"custom_dialer":           c.dial != nil,
+		"batch_concurrency":       c.batchConcurrency,
+		"bin_digits":              c.binDigits,
+		"strict_validation":       c.strict,
+		"completeness_check":      c.complete,
+		"lenient_decoding":        c.lenient,
+		"fields":                  fmt.Sprintf("%#x", uint(c.fields)),
+		"json_decoder":            fmt.Sprintf("%T", c.jsonDecoder),
+		"rate_limited":            c.limiter != nil,
+		"api_keys":                c.keys.len(),
+		"queued":                  c.queue != nil,
+		"logger":                  c.logger != nil,
+		"log_level":               c.logLevel.String(),
+		"expvar":                  c.vars != nil,
+	}
+
+	if c.cache != nil {
+		cfg["cache"] = fmt.Sprintf("%T", c.cache)
+		cfg["cache_ttl"] = c.cacheTTL.String()
+		cfg["cache_jitter"] = c.cacheJitter
+		if c.cacheControl {
+			cfg["cache_control_min_ttl"] = c.minTTL.String()
+			cfg["cache_control_max_ttl"] = c.maxTTL.String()
+		}
+		cfg["not_found_ttl"] = c.notFoundTTL.String()
+		cfg["error_ttl"] = c.errorTTL.String()
+		cfg["cache_tokenized"] = c.cacheTokenizer != nil
+		cfg["cache_ranges"] = c.cacheRanges != nil
+		cfg["change_handler"] = c.onChange != nil
+		cfg["stale_if_error"] = c.staleIfError
+	}
+
+	return cfg
+}
+
+func (c *Client) supportErrors() []errorSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]errorSample{}, c.errorSamples...)
+}
+
+func (c *Client) supportStats() map[string]any {
+	stats := map[string]any{
+		"provider":     c.Name(),
+		"capabilities": c.Capabilities(),
+		"latency":      time.Duration(c.latency.Load()).String(),
+	}
+
+	if c.caching() {
+		stats["cache"] = c.CacheStats()
+	}
+
+	if q, ok := c.Quota(); ok {
+		stats["quota"] = q
+	}
+
+	return stats
+}
@@ -0,0 +1,85 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrUnknownProvider is returned switching a provider a `Switch` doesn't
+// hold.
+var ErrUnknownProvider = errors.New("Unknown Provider")
+
+// Switch is a `Provider` chaining providers the way `Chain` does, any of
+// which can be disabled and enabled again at runtime, such as a provider
+// having an incident, without a redeploy. Its providers are told apart
+// by name, see `ProviderName`. It's safe for concurrent use.
+type Switch struct {
+	ps       []Provider
+	disabled []atomic.Bool
+}
+
+// ProviderState is a provider of a `Switch` along with whether it's
+// enabled.
+type ProviderState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NewSwitch returns a `Switch` chaining ps, all of them enabled.
+func NewSwitch(ps ...Provider) *Switch {
+	return &Switch{ps: ps, disabled: make([]atomic.Bool, len(ps))}
+}
+
+// Search looks bin up through the providers enabled, see `Chain`. It
+// fails with `ErrNotFound` when none is.
+func (s *Switch) Search(ctx context.Context, bin string) (*BIN, error) {
+	return s.enabled().Search(ctx, bin)
+}
+
+// Capabilities are those of the providers enabled, chained.
+func (s *Switch) Capabilities() Capabilities {
+	return s.enabled().Capabilities()
+}
+
+// SetEnabled enables or disables the providers of s named name, failing
+// with `ErrUnknownProvider` when there are none.
+func (s *Switch) SetEnabled(name string, enabled bool) error {
+	found := false
+	for i, p := range s.ps {
+		if ProviderName(p) == name {
+			s.disabled[i].Store(!enabled)
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %v", ErrUnknownProvider, name)
+	}
+
+	return nil
+}
+
+// States returns the providers of s, in order, along with whether each is
+// enabled.
+func (s *Switch) States() []ProviderState {
+	states := make([]ProviderState, len(s.ps))
+	for i, p := range s.ps {
+		states[i] = ProviderState{Name: ProviderName(p), Enabled: !s.disabled[i].Load()}
+	}
+
+	return states
+}
+
+// enabled returns the chain of the providers of s enabled.
+func (s *Switch) enabled() chain {
+	ps := make(chain, 0, len(s.ps))
+	for i, p := range s.ps {
+		if !s.disabled[i].Load() {
+			ps = append(ps, p)
+		}
+	}
+
+	return ps
+}
@@ -0,0 +1,42 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSwitch(t *testing.T) {
+	first := &stubProvider{b: &BIN{Scheme: SchemeVisa}}
+	second := &stubProvider{b: &BIN{Scheme: SchemeMastercard}, caps: Capabilities{Offline: true}}
+	s := NewSwitch(Named("first", first), Named("second", second))
+
+	if b, err := s.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeVisa {
+		t.Fatalf("switch answered with %+v, %v, want the first provider's", b, err)
+	}
+
+	if err := s.SetEnabled("first", false); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if b, err := s.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeMastercard {
+		t.Fatalf("switch answered with %+v, %v, want the second provider's", b, err)
+	}
+	if !s.Capabilities().Offline {
+		t.Fatal("capabilities are still those of the provider disabled")
+	}
+
+	want := []ProviderState{{Name: "first", Enabled: false}, {Name: "second", Enabled: true}}
+	if got := s.States(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("states are %+v, want %+v", got, want)
+	}
+
+	s.SetEnabled("second", false)
+	if _, err := s.Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("switch with none enabled failed with %v, want ErrNotFound", err)
+	}
+
+	if err := s.SetEnabled("third", true); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("enabling an unknown provider failed with %v, want ErrUnknownProvider", err)
+	}
+}
@@ -0,0 +1,177 @@
+package binlookup
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrPinMismatch is returned when no certificate presented by upstream
+// matches the pins given to `WithPinnedSPKI`.
+var ErrPinMismatch = errors.New("Certificate Pin Mismatch")
+
+// WithTLSConfig sets the TLS configuration used to connect to upstream,
+// such as the root CAs trusted or the minimum version accepted.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithClientCertificate makes the `Client` present cert to upstream when
+// asked for a certificate, for the mirrors requiring mutual TLS. It takes
+// precedence over the certificates of `WithTLSConfig`.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *Client) {
+		c.clientCert = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		}
+	}
+}
+
+// WithClientCertificateFiles is `WithClientCertificate` with the PEM
+// encoded certificate, chain included, and key at certFile and keyFile.
+// They're read at the first handshake, then whenever either changes, for
+// the certificates rotated by a service mesh or an agent to be taken up
+// without restarting; the certificate read last is presented until the
+// new pair loads, as while a rotation is halfway written.
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return func(c *Client) {
+		c.clientCert = (&certFiles{cert: certFile, key: keyFile}).get
+	}
+}
+
+// WithRootCAs makes the `Client` verify upstream against the CAs of pool
+// rather than those of the system, such as the internal CA of a private
+// mirror. It takes precedence over the root CAs of `WithTLSConfig`.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		c.rootCAs = pool
+	}
+}
+
+// certFiles is a client certificate read out of its files, see
+// `WithClientCertificateFiles`.
+type certFiles struct {
+	cert, key string
+
+	mu      sync.Mutex
+	loaded  *tls.Certificate
+	modTime [2]time.Time
+}
+
+// get returns the certificate of f, read again when its files changed.
+func (f *certFiles) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var mod [2]time.Time
+	for i, name := range []string{f.cert, f.key} {
+		if fi, err := os.Stat(name); err == nil {
+			mod[i] = fi.ModTime()
+		}
+	}
+	if f.loaded != nil && mod == f.modTime {
+		return f.loaded, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(f.cert, f.key)
+	if err != nil {
+		if f.loaded != nil {
+			return f.loaded, nil
+		}
+		return nil, fmt.Errorf("Loading the Client Certificate Failed: %w", err)
+	}
+	f.loaded, f.modTime = &cert, mod
+
+	return f.loaded, nil
+}
+
+// WithPinnedSPKI pins the public keys upstream may present. Each pin is
+// the base64 encoded SHA-256 hash of a DER encoded SubjectPublicKeyInfo,
+// the format of HPKP's pin-sha256, which can be computed with:
+//
+//	openssl x509 -pubkey -noout -in cert.pem | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+//
+// The connection is refused unless a certificate of the verified chain,
+// leaf or CA, matches one of the pins. Pinning comes on top of the regular
+// certificate verification rather than replacing it.
+func WithPinnedSPKI(pins ...string) Option {
+	return func(c *Client) {
+		c.pins = append(c.pins, pins...)
+	}
+}
+
+// tlsClientConfig builds the TLS configuration of the transport of c, nil
+// meaning the default one.
+func (c *Client) tlsClientConfig() *tls.Config {
+	if c.tlsConfig == nil && len(c.pins) == 0 && c.clientCert == nil && c.rootCAs == nil {
+		return nil
+	}
+
+	cfg := &tls.Config{}
+	if c.tlsConfig != nil {
+		cfg = c.tlsConfig.Clone()
+	}
+	if c.clientCert != nil {
+		cfg.GetClientCertificate = c.clientCert
+	}
+	if c.rootCAs != nil {
+		cfg.RootCAs = c.rootCAs
+	}
+
+	if len(c.pins) > 0 {
+		pins := make(map[string]bool, len(c.pins))
+		for _, p := range c.pins {
+			pins[p] = true
+		}
+
+		verify := cfg.VerifyConnection
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+
+			return verifyPins(cs, pins, cfg.InsecureSkipVerify)
+		}
+	}
+
+	return cfg
+}
+
+// verifyPins checks a certificate of the verified chains of cs matches
+// one of pins. The certificates presented past those are left out, since
+// a server may send any along with a chain that verifies. Nothing is
+// verified when insecure, InsecureSkipVerify being set, hence the leaf
+// alone is matched then.
+func verifyPins(cs tls.ConnectionState, pins map[string]bool, insecure bool) error {
+	var certs []*x509.Certificate
+	for _, chain := range cs.VerifiedChains {
+		certs = append(certs, chain...)
+	}
+	if insecure && len(cs.PeerCertificates) > 0 {
+		certs = append(certs, cs.PeerCertificates[0])
+	}
+
+	for _, cert := range certs {
+		if pins[SPKIHash(cert)] {
+			return nil
+		}
+	}
+
+	return ErrPinMismatch
+}
+
+// SPKIHash returns the pin of cert as expected by `WithPinnedSPKI`.
+func SPKIHash(cert *x509.Certificate) string {
+	h := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(h[:])
+}
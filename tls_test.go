@@ -0,0 +1,204 @@
+package binlookup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithPinnedSPKI(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	cfg := &tls.Config{RootCAs: roots}
+
+	pinned := New(WithBaseURL(srv.URL), WithTLSConfig(cfg), WithPinnedSPKI("bm90IHRoZSBwaW4=", SPKIHash(srv.Certificate())))
+	if _, err := pinned.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("lookup with a matching pin failed: %+v", err)
+	}
+
+	mismatched := New(WithBaseURL(srv.URL), WithTLSConfig(cfg), WithPinnedSPKI("bm90IHRoZSBwaW4="))
+	if _, err := mismatched.Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("lookup with no matching pin returned %v, want ErrPinMismatch", err)
+	}
+}
+
+func TestVerifyPinsOutsideTheChain(t *testing.T) {
+	leaf, _, _ := clientCertificate(t, "leaf")
+	extra, _, _ := clientCertificate(t, "pinned")
+	leafCert, _ := x509.ParseCertificate(leaf.Certificate[0])
+	extraCert, _ := x509.ParseCertificate(extra.Certificate[0])
+
+	pins := map[string]bool{SPKIHash(extraCert): true}
+	cs := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{leafCert, extraCert},
+		VerifiedChains:   [][]*x509.Certificate{{leafCert}},
+	}
+
+	if err := verifyPins(cs, pins, false); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("pin of a certificate outside the verified chain matched: %v", err)
+	}
+	if err := verifyPins(cs, pins, true); !errors.Is(err, ErrPinMismatch) {
+		t.Fatalf("pin of a certificate past the leaf matched unverified: %v", err)
+	}
+
+	cs.VerifiedChains = [][]*x509.Certificate{{leafCert, extraCert}}
+	if err := verifyPins(cs, pins, false); err != nil {
+		t.Fatalf("pin of the verified chain didn't match: %v", err)
+	}
+
+	cs.VerifiedChains = nil
+	if err := verifyPins(cs, map[string]bool{SPKIHash(leafCert): true}, true); err != nil {
+		t.Fatalf("pin of the leaf didn't match unverified: %v", err)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	if _, err := New(WithBaseURL(srv.URL)).Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup against an untrusted certificate succeeded")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	if _, err := New(WithBaseURL(srv.URL), WithTLSConfig(&tls.Config{RootCAs: roots})).Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+// clientCertificate returns a self-signed client certificate of name,
+// along with its certificate and key in PEM.
+func clientCertificate(t *testing.T, name string) (cert tls.Certificate, certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if cert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// mutualTLS returns a server requiring one of the client certificates
+// certs, recording the names of those presented, along with the roots
+// trusting it.
+func mutualTLS(t *testing.T, seen *[]string, certs ...tls.Certificate) (*httptest.Server, *x509.CertPool) {
+	clients := x509.NewCertPool()
+	for _, c := range certs {
+		leaf, _ := x509.ParseCertificate(c.Certificate[0])
+		clients.AddCert(leaf)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*seen = append(*seen, r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.Write([]byte(cannedBIN))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	return srv, roots
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	cert, _, _ := clientCertificate(t, "checkout")
+
+	var seen []string
+	srv, roots := mutualTLS(t, &seen, cert)
+
+	if _, err := New(WithBaseURL(srv.URL), WithRootCAs(roots)).Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup without a client certificate succeeded")
+	}
+
+	c := New(WithBaseURL(srv.URL), WithRootCAs(roots), WithClientCertificate(cert))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(seen) != 1 || seen[0] != "checkout" {
+		t.Fatalf("client certificates presented %v", seen)
+	}
+}
+
+func TestWithClientCertificateFiles(t *testing.T) {
+	first, firstCert, firstKey := clientCertificate(t, "first")
+	second, secondCert, secondKey := clientCertificate(t, "second")
+
+	var seen []string
+	srv, roots := mutualTLS(t, &seen, first, second)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, firstCert, 0o600)
+	os.WriteFile(keyFile, firstKey, 0o600)
+
+	c := New(WithBaseURL(srv.URL), WithRootCAs(roots), WithClientCertificateFiles(certFile, keyFile))
+	if _, err := c.Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// A rotation halfway written keeps the certificate read last.
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(certFile, secondCert, 0o600)
+	os.Chtimes(certFile, later, later)
+	c.httpClient.CloseIdleConnections()
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	os.WriteFile(keyFile, secondKey, 0o600)
+	os.Chtimes(keyFile, later, later)
+	c.httpClient.CloseIdleConnections()
+	if _, err := c.Search(context.TODO(), "41111111"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(seen) != 3 || seen[0] != "first" || seen[1] != "first" || seen[2] != "second" {
+		t.Fatalf("client certificates presented %v, want the rotated one last", seen)
+	}
+}
@@ -0,0 +1,10 @@
+package binlookup
+
+// Tokenizer replaces a BIN with a token, such as one issued by a vault,
+// before it's persisted. The components of the package persisting BINs
+// accept one for organizations whose policy forbids storing even card
+// number prefixes.
+//
+// A Tokenizer must be deterministic, since what's persisted is looked up
+// again by the token of the BIN.
+type Tokenizer func(bin string) (string, error)
@@ -0,0 +1,378 @@
+package binlookup
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// IPFamily selects which address family is used when dialing upstream.
+type IPFamily int
+
+const (
+	// DualStack races IPv4 and IPv6 the way net.Dialer does by default
+	// (RFC 6555, Happy Eyeballs).
+	DualStack IPFamily = iota
+	// PreferIPv4 dials IPv4 addresses first, racing IPv6 ones once they
+	// fail or take longer than net.Dialer waits before falling back.
+	PreferIPv4
+	// PreferIPv6 dials IPv6 addresses first, racing IPv4 ones the same.
+	PreferIPv6
+	// IPv4Only never dials IPv6 addresses.
+	IPv4Only
+	// IPv6Only never dials IPv4 addresses.
+	IPv6Only
+)
+
+// WithIPFamily controls the address family preference used when dialing
+// upstream. The default is `DualStack`.
+func WithIPFamily(f IPFamily) Option {
+	return func(c *Client) {
+		c.ipFamily = f
+	}
+}
+
+// DialFunc dials addr on network, the way net.Dialer.DialContext does.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialContext makes the `Client` open its connections to upstream
+// through dial instead of a net.Dialer, such as to dial the addresses of
+// an allowlist whatever the host resolves to. `WithConnectTimeout` and
+// `WithKeepAlive` are then left to dial, while `WithIPFamily`,
+// `WithResolver` and `WithDNSCache` still apply, handing it the addresses
+// they resolved.
+func WithDialContext(dial DialFunc) Option {
+	return func(c *Client) {
+		c.dial = dial
+	}
+}
+
+// WithResolver makes the `Client` resolve upstream through r instead of
+// net.DefaultResolver, such as a resolver dialing the DNS servers of a
+// split-horizon. A `DNSCache` set with `WithDNSCache` resolves through its
+// own Resolve instead.
+func WithResolver(r *net.Resolver) Option {
+	return func(c *Client) {
+		c.resolver = r
+	}
+}
+
+// Default budgets of the phases of a request, see `WithConnectTimeout`
+// and `WithTLSHandshakeTimeout`.
+const (
+	DefaultConnectTimeout      = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// WithConnectTimeout bounds establishing the TCP connection to upstream,
+// `DefaultConnectTimeout` unless changed. Zero disables it.
+func WithConnectTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.connectTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout bounds the TLS handshake with upstream,
+// `DefaultTLSHandshakeTimeout` unless changed. Zero disables it.
+func WithTLSHandshakeTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.tlsHandshakeTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout bounds the wait for the response headers once
+// the request is sent. It's disabled unless set.
+//
+// These budgets apply to each phase on its own, while `WithTimeout`
+// bounds the lookup as a whole; networks slow to set up connections can
+// be given generous connect and handshake budgets with a tight total one
+// still protecting the requests made over warm connections.
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.responseHeaderTimeout = d
+	}
+}
+
+// Default settings of the connection pool of the transport a `Client`
+// builds, see `WithMaxIdleConnsPerHost`, `WithIdleConnTimeout` and
+// `WithKeepAlive`.
+const (
+	DefaultMaxIdleConnsPerHost = http.DefaultMaxIdleConnsPerHost
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+)
+
+// WithMaxIdleConnsPerHost sets how many idle connections to upstream are
+// kept for reuse, `DefaultMaxIdleConnsPerHost` unless changed. Batches
+// making more lookups at once than that open and close connections all
+// along otherwise, see `WithBatchConcurrency`.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.maxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection to upstream is kept
+// for reuse, `DefaultIdleConnTimeout` unless changed. Zero keeps them
+// until upstream closes them.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.idleConnTimeout = d
+	}
+}
+
+// WithKeepAlive sets the period of the TCP keep-alive probes of the
+// connections to upstream, `DefaultKeepAlive` unless changed. A negative d
+// disables keep-alives altogether: neither are probes sent nor are
+// connections reused, each request opening its own.
+func WithKeepAlive(d time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = d
+	}
+}
+
+// WithHTTP2Only makes the `Client` speak HTTP/2 only to upstream, over
+// TLS or in cleartext to a base URL of http, failing against servers that
+// don't rather than falling back to HTTP/1.1. Its requests are then
+// multiplexed over a single connection, however many are made at once.
+func WithHTTP2Only() Option {
+	return func(c *Client) {
+		c.http2Only = true
+	}
+}
+
+// WithTransport makes the `Client` send its requests through rt instead
+// of the transport it builds, which the dialing, proxy and TLS options of
+// the package configure; they have no effect along with WithTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.roundTripper = rt
+	}
+}
+
+// WithProxy sets the function picking the proxy of each request, as in
+// http.Transport. Authenticated proxies take their credentials in the
+// URL:
+//
+//	binlookup.WithProxy(http.ProxyURL(&url.URL{
+//		Scheme: "http",
+//		User:   url.UserPassword("user", "secret"),
+//		Host:   "proxy.internal:3128",
+//	}))
+//
+// The default is http.ProxyFromEnvironment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.proxy = proxy
+	}
+}
+
+// transport builds the http.RoundTripper of a `Client` from its options.
+func (c *Client) transport() http.RoundTripper {
+	if c.roundTripper != nil {
+		return c.roundTripper
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.proxy != nil {
+		t.Proxy = c.proxy
+	}
+
+	if cfg := c.tlsClientConfig(); cfg != nil {
+		t.TLSClientConfig = cfg
+	}
+
+	t.TLSHandshakeTimeout = c.tlsHandshakeTimeout
+	t.ResponseHeaderTimeout = c.responseHeaderTimeout
+
+	t.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	t.IdleConnTimeout = c.idleConnTimeout
+	t.DisableKeepAlives = c.keepAlive < 0
+
+	if c.http2Only {
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP2(true)
+		t.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	dial := c.dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: c.connectTimeout, KeepAlive: c.keepAlive}).DialContext
+	}
+
+	t.DialContext = dial
+	if c.ipFamily != DualStack || c.dnsCache != nil || c.resolver != nil {
+		t.DialContext = (&resolvingDialer{dial: dial, family: c.ipFamily, dns: c.dnsCache, resolver: c.resolver}).DialContext
+	}
+
+	if c.preconnect > t.MaxIdleConnsPerHost {
+		t.MaxIdleConnsPerHost = c.preconnect
+	}
+
+	return t
+}
+
+// fallbackDelay is how long resolvingDialer dials the addresses of the
+// primary family before racing those of the other, as net.Dialer does.
+const fallbackDelay = 300 * time.Millisecond
+
+// resolvingDialer resolves the host itself, through dns or resolver when
+// set, and dials its addresses in the order dictated by family, racing
+// the two families as net.Dialer does when both remain.
+type resolvingDialer struct {
+	dial     DialFunc
+	family   IPFamily
+	dns      *DNSCache
+	resolver *net.Resolver
+}
+
+func (r *resolvingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := r.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, fallback := r.order(ips)
+	if len(primary) == 0 {
+		primary, fallback = fallback, nil
+	}
+	if len(primary) == 0 {
+		return nil, &net.DNSError{Err: "no addresses of the requested family", Name: host, IsNotFound: true}
+	}
+	if len(fallback) == 0 {
+		return r.dialSerial(ctx, network, port, primary)
+	}
+
+	return r.dialParallel(ctx, network, port, primary, fallback)
+}
+
+// dialParallel races the addresses of primary against those of fallback,
+// started once primary has been dialed for fallbackDelay or failed, and
+// returns the first connection established (RFC 6555).
+func (r *resolvingDialer) dialParallel(ctx context.Context, network, port string, primary, fallback []net.IPAddr) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialed struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan dialed)
+	race := func(ips []net.IPAddr, primary bool) {
+		conn, err := r.dialSerial(ctx, network, port, ips)
+		select {
+		case results <- dialed{conn, err, primary}:
+		case <-ctx.Done():
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	go race(primary, true)
+
+	t := time.NewTimer(fallbackDelay)
+	defer t.Stop()
+
+	var (
+		started bool
+		first   error
+	)
+	for pending := 1; ; {
+		select {
+		case <-t.C:
+			if !started {
+				started, pending = true, pending+1
+				go race(fallback, false)
+			}
+		case d := <-results:
+			pending--
+			if d.err == nil {
+				return d.conn, nil
+			}
+			if first == nil || d.primary {
+				first = d.err
+			}
+			if !started {
+				started, pending = true, pending+1
+				t.Stop()
+				go race(fallback, false)
+			}
+			if pending == 0 {
+				return nil, first
+			}
+		}
+	}
+}
+
+// dialSerial dials ips one by one until a connection is established.
+func (r *resolvingDialer) dialSerial(ctx context.Context, network, port string, ips []net.IPAddr) (net.Conn, error) {
+	var last error
+	for _, ip := range ips {
+		conn, err := r.dial(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+
+		last = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, last
+}
+
+func (r *resolvingDialer) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	if r.dns != nil {
+		return r.dns.LookupIPAddr(ctx, host)
+	}
+
+	if r.resolver != nil {
+		return r.resolver.LookupIPAddr(ctx, host)
+	}
+
+	return net.DefaultResolver.LookupIPAddr(ctx, host)
+}
+
+// order splits ips by family preference into those dialed first and
+// those raced against them, dropping the ones that must never be dialed.
+// `DualStack` prefers the family of the first address, as net.Dialer does.
+func (r *resolvingDialer) order(ips []net.IPAddr) (primary, fallback []net.IPAddr) {
+	var v4, v6 []net.IPAddr
+	for _, ip := range ips {
+		if ip.IP.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+
+	switch r.family {
+	case PreferIPv4:
+		return v4, v6
+	case PreferIPv6:
+		return v6, v4
+	case IPv4Only:
+		return v4, nil
+	case IPv6Only:
+		return v6, nil
+	}
+
+	if len(ips) > 0 && ips[0].IP.To4() == nil {
+		return v6, v4
+	}
+	return v4, v6
+}
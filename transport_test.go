@@ -0,0 +1,227 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestResolvingDialerOrder(t *testing.T) {
+	v4 := net.IPAddr{IP: net.ParseIP("104.18.0.1")}
+	v6 := net.IPAddr{IP: net.ParseIP("2606:4700::1")}
+	ips := []net.IPAddr{v6, v4}
+
+	cases := []struct {
+		family IPFamily
+		want   []net.IPAddr
+	}{
+		{PreferIPv4, []net.IPAddr{v4, v6}},
+		{PreferIPv6, []net.IPAddr{v6, v4}},
+		{IPv4Only, []net.IPAddr{v4}},
+		{IPv6Only, []net.IPAddr{v6}},
+		{DualStack, []net.IPAddr{v6, v4}},
+	}
+
+	for _, c := range cases {
+		primary, fallback := (&resolvingDialer{family: c.family}).order(ips)
+		got := append(primary, fallback...)
+		if len(got) != len(c.want) {
+			t.Fatalf("family %v: got %v, want %v", c.family, got, c.want)
+		}
+
+		for i := range got {
+			if !got[i].IP.Equal(c.want[i].IP) {
+				t.Fatalf("family %v: got %v, want %v", c.family, got, c.want)
+			}
+		}
+	}
+}
+
+func TestResolvingDialerRacesFamilies(t *testing.T) {
+	dns := &DNSCache{Resolve: func(context.Context, string) ([]net.IPAddr, time.Duration, error) {
+		return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("192.0.2.1")}}, time.Minute, nil
+	}}
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == "[2001:db8::1]:443" {
+			// An IPv6 route that's dead, hanging until the dial timeout.
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := (&resolvingDialer{dial: dial, dns: dns}).DialContext(ctx, "tcp", "lookup.binlist.net:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if elapsed := time.Since(start); elapsed < fallbackDelay || elapsed > time.Second {
+		t.Fatalf("connected in %v, want IPv4 raced after %v", elapsed, fallbackDelay)
+	}
+
+	failing := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("unreachable")
+	}
+	if _, err := (&resolvingDialer{dial: failing, dns: dns}).DialContext(ctx, "tcp", "lookup.binlist.net:443"); err == nil {
+		t.Fatal("dialing unreachable addresses succeeded")
+	}
+}
+
+func TestResolvingDialerNoAddresses(t *testing.T) {
+	f := &resolvingDialer{dial: (&net.Dialer{}).DialContext, family: IPv6Only}
+
+	_, err := f.DialContext(context.TODO(), "tcp", "127.0.0.1:1")
+	if err == nil {
+		t.Fatal("dialing an IPv4 literal with IPv6Only succeeded")
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var used bool
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	if _, err := New(WithBaseURL(srv.URL), WithTransport(rt)).Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if !used {
+		t.Fatal("lookup didn't go through the given transport")
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(cannedBIN))
+	}))
+	defer proxy.Close()
+
+	u, _ := url.Parse(proxy.URL)
+	if _, err := New(WithBaseURL("http://mirror.invalid"), WithProxy(http.ProxyURL(u))).Search(context.TODO(), CorrectBIN); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if proxied != "http://mirror.invalid/"+CorrectBIN {
+		t.Fatalf("proxy received %q", proxied)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := New(WithBaseURL(srv.URL), WithTimeout(time.Minute), WithResponseHeaderTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	if _, err := c.Search(context.TODO(), CorrectBIN); err == nil {
+		t.Fatal("lookup outlasting the response header timeout succeeded")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("lookup returned after %v", elapsed)
+	}
+}
+
+func TestTransportTimeouts(t *testing.T) {
+	tr := New(WithConnectTimeout(time.Second), WithTLSHandshakeTimeout(2*time.Second)).httpClient.Transport.(*http.Transport)
+
+	if tr.TLSHandshakeTimeout != 2*time.Second {
+		t.Fatalf("TLS handshake timeout is %v", tr.TLSHandshakeTimeout)
+	}
+
+	if tr.ResponseHeaderTimeout != 0 {
+		t.Fatalf("response header timeout is %v by default", tr.ResponseHeaderTimeout)
+	}
+}
+
+func TestTransportPool(t *testing.T) {
+	tr := New().httpClient.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr.IdleConnTimeout != DefaultIdleConnTimeout || tr.DisableKeepAlives {
+		t.Fatalf("default pool is %d idle connections for %v, keep-alives disabled %t", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.DisableKeepAlives)
+	}
+
+	tr = New(WithMaxIdleConnsPerHost(64), WithIdleConnTimeout(time.Minute), WithKeepAlive(-1)).httpClient.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 64 || tr.IdleConnTimeout != time.Minute || !tr.DisableKeepAlives {
+		t.Fatalf("pool is %d idle connections for %v, keep-alives disabled %t", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout, tr.DisableKeepAlives)
+	}
+
+	tr = New(WithHTTP2Only()).httpClient.Transport.(*http.Transport)
+	if p := tr.Protocols; p == nil || !p.HTTP2() || p.HTTP1() || !p.UnencryptedHTTP2() {
+		t.Fatalf("protocols are %v", p)
+	}
+}
+
+func TestWithDialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	var dialed string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+
+	b, err := New(WithBaseURL("http://lookup.invalid"), WithDialContext(dial)).Search(context.TODO(), CorrectBIN)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if b.Bank.Name != "Jyske Bank" || dialed != "lookup.invalid:80" {
+		t.Fatalf("lookup dialing %q returned %+v", dialed, b)
+	}
+}
+
+func TestWithResolver(t *testing.T) {
+	var queried bool
+	r := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			queried = true
+			return nil, errors.New("unreachable")
+		},
+	}
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		t.Fatalf("dialed %s without resolving", addr)
+		return nil, nil
+	}
+
+	_, err := New(WithBaseURL("http://lookup.invalid"), WithResolver(r), WithDialContext(dial), WithRetries(0)).Search(context.TODO(), CorrectBIN)
+
+	var de *net.DNSError
+	if !errors.As(err, &de) || !queried {
+		t.Fatalf("lookup returned %v, resolver queried %t", err, queried)
+	}
+}
@@ -0,0 +1,134 @@
+package binlookup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidateBIN checks bin is in the format `Search` accepts, so that input
+// can be rejected at an API boundary before any lookup. The error is a
+// `BINError`, see `ParseBIN`.
+func ValidateBIN(bin string) error {
+	_, err := ParseBIN(bin)
+	return err
+}
+
+// validBIN reports whether bin is 4 to 16 digits, the first of which
+// isn't 0. It's what the regexp ^[1-9]\d{3,15}$ matches, without the cost
+// of one on the path of every lookup.
+func validBIN(bin string) bool {
+	if len(bin) < 4 || len(bin) > 16 || bin[0] == '0' {
+		return false
+	}
+
+	for i := 0; i < len(bin); i++ {
+		if bin[i] < '0' || bin[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateStandardBIN checks bin is a BIN of a standard length: the 8
+// digits of ISO/IEC 7812-1:2017, or the 6 digits before it. Unlike
+// `ValidateBIN`, which accepts any prefix of a card number `Search` can
+// look up, it's for systems storing BINs, which key on either length.
+// The error is a `BINError`.
+func ValidateStandardBIN(bin string) error {
+	if err := ValidateBIN(bin); err != nil {
+		return err
+	}
+
+	if len(bin) != 6 && len(bin) != 8 {
+		return &BINError{Problems: []Problem{{Code: ProblemNonStandardLength}}}
+	}
+
+	return nil
+}
+
+// WithStrictValidation makes the `Client` accept BINs of the standard
+// lengths only, as `ValidateStandardBIN` does, rather than any prefix of a
+// card number. Inputs longer than 8 digits fail with `ErrInvalidBIN`
+// instead of being truncated, which guarantees that no near-full card
+// number is sent over the wire even by callers that would have.
+func WithStrictValidation() Option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// validate checks bin is in the format c accepts.
+func (c *Client) validate(bin string) error {
+	if c.strict {
+		return ValidateStandardBIN(bin)
+	}
+
+	return ValidateBIN(bin)
+}
+
+// WithCompletenessCheck makes the `Client` check that the BINs upstream
+// answers with have a scheme and the alpha-2 code of their country, and
+// fail with `ErrIncompleteData` otherwise, upstream answering an empty
+// object with a 200 at times. Such answers are never cached, and retried as
+// the transient failures they are, see `WithRetries`.
+func WithCompletenessCheck() Option {
+	return func(c *Client) {
+		c.complete = true
+	}
+}
+
+// checkComplete checks b has the fields expected of any BIN, of those c
+// decodes, when c checks completeness.
+func (c *Client) checkComplete(b *BIN) error {
+	if !c.complete || b == nil {
+		return nil
+	}
+
+	var missing []string
+	if c.fields&FieldScheme != 0 && b.Scheme == "" {
+		missing = append(missing, "scheme")
+	}
+	if c.fields&FieldCountry != 0 && b.Country.Short == "" {
+		missing = append(missing, "country.alpha2")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: Missing %s", ErrIncompleteData, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// FormatBIN returns the BIN bin of digits digits, as data pipelines hand
+// them over as integers, or of as many digits as it has when digits is
+// zero. Integers can't carry leading zeros, which BINs never start with
+// anyway: a bin shorter than digits, which would have to start with 0,
+// tells of digits dropped upstream and fails with `ErrInvalidBIN`, as does
+// one longer than digits.
+func FormatBIN(bin uint64, digits int) (string, error) {
+	s := strconv.FormatUint(bin, 10)
+	if digits != 0 && len(s) != digits {
+		return "", fmt.Errorf("%w: BIN holds %d digits, not %d, some of which may have been dropped.", ErrInvalidBIN, len(s), digits)
+	}
+
+	if err := ValidateBIN(s); err != nil {
+		return "", err
+	}
+
+	return s, nil
+}
+
+// SearchInt looks up the BIN bin handed over as an integer, see
+// `FormatBIN` and `Client.Search`. Signed integers converted to uint64
+// while negative are too long to be BINs, and fail with `ErrInvalidBIN`.
+func (c *Client) SearchInt(ctx context.Context, bin uint64) (*BIN, error) {
+	s, err := FormatBIN(bin, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Search(ctx, s)
+}
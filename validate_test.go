@@ -0,0 +1,129 @@
+package binlookup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValidateBIN(t *testing.T) {
+	for _, bin := range []string{"5288", CorrectBIN, "45717360", "4571736012345678"} {
+		if err := ValidateBIN(bin); err != nil {
+			t.Fatalf("%v is a correct BIN but ValidateBIN returned %v", bin, err)
+		}
+	}
+
+	for _, bin := range []string{"", "528", IncorrectBIN, "45717360123456789", "4571 7360", "4571x360"} {
+		if err := ValidateBIN(bin); !errors.Is(err, ErrInvalidBIN) {
+			t.Fatalf("%q is an incorrect BIN but ValidateBIN returned %v", bin, err)
+		}
+	}
+}
+
+func TestValidateStandardBIN(t *testing.T) {
+	for bin, valid := range map[string]bool{"457173": true, "45717360": true, "4571736": false, "4571": false, "0571736": false} {
+		if err := ValidateStandardBIN(bin); (err == nil) != valid || err != nil && !errors.Is(err, ErrInvalidBIN) {
+			t.Fatalf("ValidateStandardBIN(%q) returned %v", bin, err)
+		}
+	}
+}
+
+func TestClientWithStrictValidation(t *testing.T) {
+	var requested []string
+	c := New(WithStrictValidation(), WithMiddleware(paths(&requested, "45717360")))
+
+	for _, bin := range []string{"4571736012345678", "4571736", "5288"} {
+		if _, err := c.Search(context.TODO(), bin); !errors.Is(err, ErrInvalidBIN) {
+			t.Fatalf("strict Search(%q) returned %v, want ErrInvalidBIN", bin, err)
+		}
+	}
+
+	if _, err := c.Search(context.TODO(), "45717360"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if len(requested) != 1 {
+		t.Fatalf("requested %v", requested)
+	}
+}
+
+func TestFormatBIN(t *testing.T) {
+	for _, tc := range []struct {
+		bin    uint64
+		digits int
+		want   string
+	}{
+		{528823, 0, "528823"},
+		{528823, 6, "528823"},
+		{45717360, 8, "45717360"},
+	} {
+		if got, err := FormatBIN(tc.bin, tc.digits); err != nil || got != tc.want {
+			t.Fatalf("FormatBIN(%d, %d) returned %q, %v", tc.bin, tc.digits, got, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		bin    uint64
+		digits int
+	}{{52882, 6}, {45717360, 6}, {0, 0}, {528, 0}} {
+		if _, err := FormatBIN(tc.bin, tc.digits); !errors.Is(err, ErrInvalidBIN) {
+			t.Fatalf("FormatBIN(%d, %d) returned %v, want ErrInvalidBIN", tc.bin, tc.digits, err)
+		}
+	}
+}
+
+func TestClientSearchInt(t *testing.T) {
+	var requested []string
+	c := New(WithMiddleware(paths(&requested, "45717360")))
+
+	if _, err := c.SearchInt(context.TODO(), 45717360); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	n := -45717360
+	if _, err := c.SearchInt(context.TODO(), uint64(n)); !errors.Is(err, ErrInvalidBIN) {
+		t.Fatalf("negative BIN returned %v", err)
+	}
+
+	if len(requested) != 1 {
+		t.Fatalf("requested %v", requested)
+	}
+}
+
+func TestClientWithCompletenessCheck(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		w.Write([]byte(cannedBIN))
+	}))
+	defer srv.Close()
+
+	if b, err := New(WithBaseURL(srv.URL)).Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != "" {
+		t.Fatalf("lookup without the check returned %+v, %v", b, err)
+	}
+
+	c := New(WithBaseURL(srv.URL), WithCompletenessCheck(), WithRetries(1), WithBackoff(ConstantBackoff(0)))
+	n.Store(0)
+	if b, err := c.Search(context.TODO(), CorrectBIN); err != nil || b.Scheme != SchemeVisa || n.Load() != 2 {
+		t.Fatalf("lookup returned %+v, %v after %d requests", b, err, n.Load())
+	}
+
+	c = New(WithBaseURL(srv.URL), WithCompletenessCheck())
+	n.Store(0)
+	if _, err := c.Search(context.TODO(), CorrectBIN); !errors.Is(err, ErrIncompleteData) {
+		t.Fatalf("lookup of an empty answer returned %v", err)
+	}
+}
+
+func BenchmarkValidateBIN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ValidateBIN(CorrectBIN)
+	}
+}